@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/integrity"
+	"github.com/zenon-network/go-zenon/node"
+)
+
+var (
+	verifyFromFlag = &cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First verified momentum, 0 for the genesis or the first verifiable momentum of a pruned node",
+	}
+	verifyToFlag = &cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last verified momentum, 0 for the frontier momentum",
+	}
+	verifyFastFlag = &cli.BoolFlag{
+		Name:  "fast",
+		Usage: "Verify sampled ranges of momentums instead of all of them",
+	}
+	verifySamplesFlag = &cli.Uint64Flag{
+		Name:  "samples",
+		Usage: "Number of sampled ranges in fast mode",
+		Value: integrity.DefaultSampleCount,
+	}
+	verifySampleSizeFlag = &cli.Uint64Flag{
+		Name:  "sample-size",
+		Usage: "Number of momentums of a sampled range in fast mode",
+		Value: integrity.DefaultSampleSize,
+	}
+	verifyRepairFlag = &cli.BoolFlag{
+		Name:  "repair",
+		Usage: "Roll back the chain to the last consistent momentum if a divergence is found",
+	}
+
+	dbCommand = &cli.Command{
+		Name:     "db",
+		Usage:    "Low level database operations",
+		Category: "DATABASE COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action:    dbVerifyAction,
+				Name:      "verify",
+				Usage:     "Check the consistency of the chain database",
+				ArgsUsage: " ",
+				Flags:     []cli.Flag{verifyFromFlag, verifyToFlag, verifyFastFlag, verifySamplesFlag, verifySampleSizeFlag, verifyRepairFlag},
+			},
+		},
+	}
+)
+
+func dbVerifyAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	config := &integrity.Config{
+		From:        ctx.Uint64(verifyFromFlag.Name),
+		To:          ctx.Uint64(verifyToFlag.Name),
+		Fast:        ctx.Bool(verifyFastFlag.Name),
+		SampleCount: ctx.Uint64(verifySamplesFlag.Name),
+		SampleSize:  ctx.Uint64(verifySampleSizeFlag.Name),
+	}
+	report, repaired, err := node.VerifyDB(cfg, config, ctx.Bool(verifyRepairFlag.Name), newProgress("verified"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("verified %v momentums between %v and %v\n", report.Verified, report.From, report.To)
+	if report.Divergence == nil {
+		fmt.Printf("no divergence found\n")
+		return nil
+	}
+	fmt.Printf("first divergence at momentum %v at height %v: %v\n", report.Divergence.Momentum.Hash, report.Divergence.Momentum.Height, report.Divergence.Reason)
+	if repaired != nil {
+		fmt.Printf("rolled back the chain to momentum %v at height %v, the node syncs again from it\n", repaired.Hash, repaired.Height)
+		return nil
+	}
+	return fmt.Errorf("db is inconsistent, run with --%v to roll back to the last consistent momentum", verifyRepairFlag.Name)
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/node"
+)
+
+const (
+	// progressInterval is the minimum time between two progress reports
+	progressInterval = 5 * time.Second
+)
+
+var (
+	exportCompressFlag = &cli.BoolFlag{
+		Name:  "compress",
+		Usage: "Compress the exported momentums with gzip",
+	}
+
+	exportCommand = &cli.Command{
+		Action:    exportAction,
+		Name:      "export",
+		Usage:     "Export momentums and their account-blocks into a file",
+		ArgsUsage: "<file> [from] [to]",
+		Category:  "DATABASE COMMANDS",
+		Flags:     []cli.Flag{exportCompressFlag},
+	}
+	importCommand = &cli.Command{
+		Action:    importAction,
+		Name:      "import",
+		Usage:     "Verify and insert the momentums of an exported file, resuming from the frontier momentum",
+		ArgsUsage: "<file>",
+		Category:  "DATABASE COMMANDS",
+	}
+)
+
+// newProgress prints the progress of a db command at most once every progressInterval
+func newProgress(action string) func(height, target uint64) {
+	start := time.Now()
+	last := start
+	return func(height, target uint64) {
+		if now := time.Now(); height == target || now.Sub(last) >= progressInterval {
+			last = now
+			fmt.Printf("%v momentum %v/%v, elapsed %v\n", action, height, target, now.Sub(start).Round(time.Second))
+		}
+	}
+}
+
+func parseHeightArg(ctx *cli.Context, index int) (uint64, error) {
+	if ctx.NArg() <= index {
+		return 0, nil
+	}
+	height, err := strconv.ParseUint(ctx.Args().Get(index), 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid height %v", ctx.Args().Get(index))
+	}
+	return height, nil
+}
+
+func exportAction(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 3 {
+		return errors.New("expected the path of the export file and optionally the momentum range")
+	}
+	from, err := parseHeightArg(ctx, 1)
+	if err != nil {
+		return err
+	}
+	if from == 0 {
+		from = 1
+	}
+	to, err := parseHeightArg(ctx, 2)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	header, err := node.ExportChain(cfg, ctx.Args().First(), from, to, ctx.Bool(exportCompressFlag.Name), newProgress("exported"))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("exported momentums %v to %v to %v\n", header.From, header.To, ctx.Args().First())
+	return nil
+}
+
+func importAction(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("expected the path of the export file")
+	}
+
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	header, inserted, err := node.ImportChain(cfg, ctx.Args().First(), newProgress("imported"))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported %v new momentums of momentums %v to %v\n", inserted, header.From, header.To)
+	return nil
+}
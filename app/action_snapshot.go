@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/node"
+	"github.com/zenon-network/go-zenon/snapshot"
+)
+
+var (
+	snapshotHeightFlag = &cli.Uint64Flag{
+		Name:  "height",
+		Usage: "Height of the exported momentum, 0 for the frontier momentum",
+	}
+	snapshotWindowFlag = &cli.Uint64Flag{
+		Name:  "window",
+		Usage: "Number of recent momentums included in the snapshot",
+		Value: snapshot.DefaultWindow,
+	}
+	snapshotOutputFlag = &cli.StringFlag{
+		Name:  "output",
+		Usage: "Path of the exported snapshot",
+		Value: "znn.snapshot",
+	}
+	snapshotRootFlag = &cli.StringFlag{
+		Name:     "root",
+		Usage:    "Trusted root of the imported snapshot, as printed by the export",
+		Required: true,
+	}
+
+	snapshotCommand = &cli.Command{
+		Name:     "snapshot",
+		Usage:    "Export or import state snapshots for fast bootstrap",
+		Category: "DATABASE COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action:    snapshotExportAction,
+				Name:      "export",
+				Usage:     "Export the state at a momentum into a snapshot file",
+				ArgsUsage: " ",
+				Flags:     []cli.Flag{snapshotHeightFlag, snapshotWindowFlag, snapshotOutputFlag},
+			},
+			{
+				Action:    snapshotImportAction,
+				Name:      "import",
+				Usage:     "Restore an empty node from a snapshot file",
+				ArgsUsage: "<file>",
+				Flags:     []cli.Flag{snapshotRootFlag},
+			},
+		},
+	}
+)
+
+func snapshotExportAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	header, root, err := node.ExportSnapshot(cfg, ctx.String(snapshotOutputFlag.Name), ctx.Uint64(snapshotHeightFlag.Name), ctx.Uint64(snapshotWindowFlag.Name))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("exported snapshot of momentum %v at height %v to %v\n", header.Momentum.Hash, header.Momentum.Height, ctx.String(snapshotOutputFlag.Name))
+	fmt.Printf("snapshot root: %v\n", root)
+	return nil
+}
+
+func snapshotImportAction(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("expected the path of the snapshot file")
+	}
+	trusted, err := types.HexToHash(ctx.String(snapshotRootFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	header, root, err := node.ImportSnapshot(cfg, ctx.Args().First(), trusted)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported snapshot of momentum %v at height %v\n", header.Momentum.Hash, header.Momentum.Height)
+	fmt.Printf("snapshot root: %v\n", root)
+	return nil
+}
@@ -54,6 +54,10 @@ func init() {
 	app.Commands = []*cli.Command{
 		versionCommand,
 		licenseCommand,
+		snapshotCommand,
+		exportCommand,
+		importCommand,
+		dbCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -57,6 +57,16 @@ func applyFlagsToConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.GenesisFile = genesisFile
 	}
 
+	if ctx.IsSet(ArchiveFlag.Name) {
+		cfg.ArchiveMode = ctx.Bool(ArchiveFlag.Name)
+	}
+	if ctx.IsSet(PruneFlag.Name) {
+		cfg.Pruning.Enabled = ctx.Bool(PruneFlag.Name)
+	}
+	if ctx.IsSet(PruneRetentionFlag.Name) {
+		cfg.Pruning.Retention = ctx.Uint64(PruneRetentionFlag.Name)
+	}
+
 	// Network Config
 	if identity := ctx.String(IdentityFlag.Name); ctx.IsSet(IdentityFlag.Name) && len(identity) > 0 {
 		cfg.Name = identity
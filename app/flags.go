@@ -3,6 +3,7 @@ package app
 import (
 	"github.com/zenon-network/go-zenon/node"
 	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/pruner"
 
 	"github.com/urfave/cli/v2"
 )
@@ -56,6 +57,19 @@ var (
 		Name:  "name", //mapping:p2p.Name
 		Usage: "Node's name. Visible in the network.",
 	}
+	ArchiveFlag = &cli.BoolFlag{
+		Name:  "archive",
+		Usage: "Serve account and embedded contract state at any momentum height.",
+	}
+	PruneFlag = &cli.BoolFlag{
+		Name:  "prune",
+		Usage: "Delete the account-blocks and momentum patches older than the retention. Pruned data can't be served over RPC or p2p.",
+	}
+	PruneRetentionFlag = &cli.Uint64Flag{
+		Name:  "prune.retention",
+		Usage: "Number of recent momentums kept in full by a pruned node",
+		Value: pruner.DefaultRetention,
+	}
 
 	// network
 
@@ -131,6 +145,9 @@ var (
 		WalletDirFlag,
 		GenesisFileFlag,
 		IdentityFlag,
+		ArchiveFlag,
+		PruneFlag,
+		PruneRetentionFlag,
 
 		// network
 		ListenHostFlag,
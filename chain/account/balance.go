@@ -58,3 +58,12 @@ func (as *accountStore) GetBalanceMap() (map[types.ZenonTokenStandard]*big.Int,
 	}
 	return result, nil
 }
+
+// ParseBalanceKey returns the token standard of a balance key, as found in the patch of an account-block
+func ParseBalanceKey(key []byte) (types.ZenonTokenStandard, bool) {
+	if len(key) != len(balanceKeyPrefix)+types.ZenonTokenStandardSize || key[0] != balanceKeyPrefix[0] {
+		return types.ZeroTokenStandard, false
+	}
+	zts, err := types.BytesToZTS(key[len(balanceKeyPrefix):])
+	return zts, err == nil
+}
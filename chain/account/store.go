@@ -11,6 +11,14 @@ func getStorageIterator() []byte {
 	return storageKeyPrefix
 }
 
+// ParseStorageKey returns the contract storage key of an account storage key, as found in the patch of an account-block
+func ParseStorageKey(key []byte) ([]byte, bool) {
+	if len(key) <= len(storageKeyPrefix) || key[0] != storageKeyPrefix[0] {
+		return nil, false
+	}
+	return key[len(storageKeyPrefix):], true
+}
+
 type accountStore struct {
 	address types.Address
 	db.DB
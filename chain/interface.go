@@ -41,6 +41,14 @@ type MomentumPool interface {
 
 	GetFrontierMomentumStore() store.Momentum
 	GetMomentumStore(identifier types.HashHeight) store.Momentum
+	// GetMomentumPatch returns the changes applied by a momentum to the momentum store
+	GetMomentumPatch(identifier types.HashHeight) db.Patch
+
+	// PruneTo deletes the patches and the account-block bodies which are no longer required, of all momentums up to height.
+	// Momentum headers are never pruned. The chain can't be rolled back below the pruned height.
+	PruneTo(height uint64) error
+	// GetPrunedHeight returns the height of the last pruned momentum, 0 if nothing is pruned
+	GetPrunedHeight() uint64
 }
 
 type AccountPool interface {
@@ -4,6 +4,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 )
@@ -44,7 +45,11 @@ func (ms *momentumStore) GetAccountBlockByHash(hash types.Hash) (*nom.AccountBlo
 
 	if header, err := types.DeserializeAccountHeader(data); err != nil {
 		return nil, err
+	} else if block, err := ms.GetAccountStore(header.Address).ByHeight(header.Height); err != nil {
+		return nil, err
+	} else if block == nil {
+		return nil, store.ErrDataPruned
 	} else {
-		return ms.GetAccountStore(header.Address).ByHeight(header.Height)
+		return block, nil
 	}
 }
@@ -21,6 +21,32 @@ type momentumStore struct {
 func getAccountStorePrefix(address types.Address) []byte {
 	return common.JoinBytes(accountStorePrefix, address.Bytes())
 }
+
+// ParseAccountBalanceKey returns the address and token standard of an account balance key, as found in the patch of a momentum
+func ParseAccountBalanceKey(key []byte) (types.Address, types.ZenonTokenStandard, bool) {
+	if len(key) < len(accountStorePrefix)+types.AddressSize || key[0] != accountStorePrefix[0] {
+		return types.ZeroAddress, types.ZeroTokenStandard, false
+	}
+	address, err := types.BytesToAddress(key[len(accountStorePrefix) : len(accountStorePrefix)+types.AddressSize])
+	if err != nil {
+		return types.ZeroAddress, types.ZeroTokenStandard, false
+	}
+	zts, ok := account.ParseBalanceKey(key[len(accountStorePrefix)+types.AddressSize:])
+	return address, zts, ok
+}
+
+// ParseAccountStorageKey returns the address and contract storage key of an account storage key, as found in the patch of a momentum
+func ParseAccountStorageKey(key []byte) (types.Address, []byte, bool) {
+	if len(key) < len(accountStorePrefix)+types.AddressSize || key[0] != accountStorePrefix[0] {
+		return types.ZeroAddress, nil, false
+	}
+	address, err := types.BytesToAddress(key[len(accountStorePrefix) : len(accountStorePrefix)+types.AddressSize])
+	if err != nil {
+		return types.ZeroAddress, nil, false
+	}
+	storageKey, ok := account.ParseStorageKey(key[len(accountStorePrefix)+types.AddressSize:])
+	return address, storageKey, ok
+}
 func getAccountMailboxPrefix(address types.Address) []byte {
 	return common.JoinBytes(accountMailboxPrefix, address.Bytes())
 }
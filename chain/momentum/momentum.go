@@ -6,6 +6,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
 )
@@ -69,6 +70,9 @@ func (ms *momentumStore) PrefetchMomentum(momentum *nom.Momentum) (*nom.Detailed
 		if err != nil {
 			return nil, fmt.Errorf("error while prefetching account-blocks for insert-momentum event. %w", err)
 		}
+		if accountBlocks[index] == nil {
+			return nil, store.ErrDataPruned
+		}
 	}
 
 	return &nom.DetailedMomentum{
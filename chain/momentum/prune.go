@@ -0,0 +1,99 @@
+package momentum
+
+import (
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// GetPrunableKeys returns the keys of the account-block bodies which are no longer required once momentum is pruned.
+// An account-block is required while it is the frontier of its account, since the next account-block builds on top of it,
+// and, for send-blocks, while it isn't received, since the receive-block references it.
+// Both conditions are released by account-blocks confirmed later, so the candidates of a momentum are the previous
+// account-blocks and the send-blocks received by its content. Since the chain can't be rolled back below a pruned
+// momentum, the conditions are checked against the momentum itself. Account-blocks of the genesis are never pruned.
+func GetPrunableKeys(ms store.Momentum, momentum *nom.Momentum) ([][]byte, error) {
+	keys := make([][]byte, 0, len(momentum.Content))
+	seen := make(map[types.Hash]struct{})
+	for _, header := range momentum.Content {
+		block, err := ms.GetAccountBlock(*header)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, store.ErrDataPruned
+		}
+
+		candidates := make([]*nom.AccountBlock, 0, 2)
+		if header.Height > 1 {
+			previous, err := ms.GetAccountBlockByHeight(header.Address, header.Height-1)
+			if err != nil {
+				return nil, err
+			}
+			if previous != nil {
+				candidates = append(candidates, previous)
+			}
+		}
+		if block.IsReceiveBlock() && block.BlockType != nom.BlockTypeGenesisReceive {
+			send, err := ms.GetAccountBlockByHash(block.FromBlockHash)
+			if err != nil && err != store.ErrDataPruned {
+				return nil, err
+			}
+			if send != nil {
+				candidates = append(candidates, send)
+			}
+		}
+
+		for _, candidate := range candidates {
+			if _, ok := seen[candidate.Hash]; ok {
+				continue
+			}
+			seen[candidate.Hash] = struct{}{}
+			if prunable, err := isPrunable(ms, candidate, momentum.Height); err != nil {
+				return nil, err
+			} else if prunable {
+				keys = append(keys, common.JoinBytes(getAccountStorePrefix(candidate.Address), db.GetEntryByHeightKey(candidate.Height)))
+			}
+		}
+	}
+	return keys, nil
+}
+
+// isPrunable checks if block is neither the frontier of its account nor an unreceived send-block at height
+func isPrunable(ms store.Momentum, block *nom.AccountBlock, height uint64) (bool, error) {
+	if confirmed, err := ms.GetBlockConfirmationHeight(block.Hash); err != nil {
+		return false, err
+	} else if confirmed <= 1 || confirmed > height {
+		return false, nil
+	}
+
+	next, err := ms.GetAccountBlockByHeight(block.Address, block.Height+1)
+	if err != nil {
+		return false, err
+	}
+	if next == nil {
+		// the next account-block is either missing or already pruned, in which case it is confirmed
+		if ms.GetAccountStore(block.Address).Identifier().Height <= block.Height {
+			return false, nil
+		}
+	} else if confirmed, err := ms.GetBlockConfirmationHeight(next.Hash); err != nil {
+		return false, err
+	} else if confirmed == 0 || confirmed > height {
+		return false, nil
+	}
+
+	if block.IsSendBlock() {
+		receiveHeader := ms.GetAccountMailbox(block.Address).GetBlockWhichReceives(block.Hash)
+		if receiveHeader == nil {
+			return false, nil
+		}
+		if confirmed, err := ms.GetBlockConfirmationHeight(receiveHeader.Hash); err != nil {
+			return false, err
+		} else if confirmed == 0 || confirmed > height {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -83,6 +83,9 @@ func (c *momentumPool) RollbackTo(insertLocker sync.Locker, identifier types.Has
 	c.changes.Lock()
 	defer c.changes.Unlock()
 	c.log.Info("preparing to rollback momentums", "identifier", identifier)
+	if prunedHeight := c.chainManager.PrunedHeight(); identifier.Height <= prunedHeight {
+		return errors.Errorf("can't rollback momentums to %v. Momentums up to height %v are pruned", identifier, prunedHeight)
+	}
 	store := c.getFrontierStore()
 	momentum, err := store.GetMomentumByHeight(identifier.Height)
 	if err != nil {
@@ -163,9 +166,10 @@ func (c *momentumPool) GetFrontierMomentumStore() store.Momentum {
 	defer c.changes.Unlock()
 	return c.getFrontierStore()
 }
+
+// GetMomentumStore doesn't hold the changes lock, the manager takes its snapshot under its own lock
+// and rebuilds deep versions from it without blocking the insertion of momentums.
 func (c *momentumPool) GetMomentumStore(identifier types.HashHeight) store.Momentum {
-	c.changes.Lock()
-	defer c.changes.Unlock()
 	momentumDB := c.chainManager.Get(identifier)
 	if momentumDB == nil {
 		return nil
@@ -173,6 +177,43 @@ func (c *momentumPool) GetMomentumStore(identifier types.HashHeight) store.Momen
 
 	return momentum.NewStore(c.genesis, momentumDB)
 }
+func (c *momentumPool) GetMomentumPatch(identifier types.HashHeight) db.Patch {
+	c.changes.Lock()
+	defer c.changes.Unlock()
+	return c.chainManager.GetPatch(identifier)
+}
+func (c *momentumPool) PruneTo(height uint64) error {
+	// the genesis momentum is never pruned
+	for current := c.GetPrunedHeight() + 1; current <= height; current += 1 {
+		if current == 1 {
+			continue
+		}
+		if err := c.prune(current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *momentumPool) prune(height uint64) error {
+	c.changes.Lock()
+	defer c.changes.Unlock()
+	store := c.getFrontierStore()
+	m, err := store.GetMomentumByHeight(height)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return errors.Errorf("can't prune momentum at height %v. Momentum not found", height)
+	}
+	keys, err := momentum.GetPrunableKeys(store, m)
+	if err != nil {
+		return err
+	}
+	return c.chainManager.Prune(height, keys)
+}
+func (c *momentumPool) GetPrunedHeight() uint64 {
+	return c.chainManager.PrunedHeight()
+}
 func (c *momentumPool) GetStableAccountDB(address types.Address) db.DB {
 	c.changes.Lock()
 	defer c.changes.Unlock()
@@ -0,0 +1,151 @@
+package chain
+
+import (
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+)
+
+const (
+	// ReceiptStatusPending is set while the send-block isn't received
+	ReceiptStatusPending = "pending"
+	// ReceiptStatusReceived is set while the receive-block isn't confirmed by a momentum yet
+	ReceiptStatusReceived = "received"
+	// ReceiptStatusConfirmed is set once the receive-block is confirmed by a momentum, the receipt is final
+	ReceiptStatusConfirmed = "confirmed"
+
+	ExecutionStatusSuccess = "success"
+	ExecutionStatusFailed  = "failed"
+)
+
+type ReceiptTransfer struct {
+	Hash          types.Hash               `json:"hash"`
+	ToAddress     types.Address            `json:"toAddress"`
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	Amount        string                   `json:"amount"`
+}
+
+// Receipt is the outcome of a send-block.
+// For embedded contracts, ExecutionStatus tells whether the call succeeded. Failed calls refund the sent tokens,
+// successful calls can send tokens to other addresses which are listed in Transfers.
+type Receipt struct {
+	SendBlockHash       types.Hash           `json:"sendBlockHash"`
+	Status              string               `json:"status"`
+	SendConfirmation    *ReceiptConfirmation `json:"sendConfirmation"`
+	ReceiveBlockHash    types.Hash           `json:"receiveBlockHash"`
+	ReceiveConfirmation *ReceiptConfirmation `json:"receiveConfirmation"`
+
+	Embedded        bool               `json:"embedded"`
+	ExecutionStatus string             `json:"executionStatus"`
+	Refund          *ReceiptTransfer   `json:"refund"`
+	Transfers       []*ReceiptTransfer `json:"transfers"`
+}
+
+// IsFinal returns true if the receipt can't change anymore
+func (r *Receipt) IsFinal() bool {
+	return r.Status == ReceiptStatusConfirmed
+}
+
+// ReceiptConfirmation is the momentum which confirms a block of the receipt
+type ReceiptConfirmation struct {
+	NumConfirmations  uint64     `json:"numConfirmations"`
+	MomentumHeight    uint64     `json:"momentumHeight"`
+	MomentumHash      types.Hash `json:"momentumHash"`
+	MomentumTimestamp int64      `json:"momentumTimestamp"`
+}
+
+func getReceiptConfirmation(momentumStore store.Momentum, hash types.Hash) (*ReceiptConfirmation, error) {
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	height, err := momentumStore.GetBlockConfirmationHeight(hash)
+	if err != nil {
+		return nil, err
+	}
+	if height == 0 || height > frontier.Height {
+		return nil, nil
+	}
+	momentum, err := momentumStore.GetMomentumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return &ReceiptConfirmation{
+		NumConfirmations:  frontier.Height - momentum.Height + 1,
+		MomentumHeight:    momentum.Height,
+		MomentumHash:      momentum.Hash,
+		MomentumTimestamp: momentum.Timestamp.Unix(),
+	}, nil
+}
+
+// GetReceipt resolves the outcome of a send-block which is confirmed by a momentum.
+// Returns nil if the send-block isn't confirmed yet.
+func GetReceipt(c Chain, sendHash types.Hash) (*Receipt, error) {
+	momentumStore := c.GetFrontierMomentumStore()
+	sendBlock, err := momentumStore.GetAccountBlockByHash(sendHash)
+	if err != nil {
+		return nil, err
+	}
+	if sendBlock == nil || !sendBlock.IsSendBlock() {
+		return nil, nil
+	}
+
+	receipt := &Receipt{
+		SendBlockHash: sendHash,
+		Status:        ReceiptStatusPending,
+		Embedded:      types.IsEmbeddedAddress(sendBlock.ToAddress),
+		Transfers:     make([]*ReceiptTransfer, 0),
+	}
+	if receipt.SendConfirmation, err = getReceiptConfirmation(momentumStore, sendHash); err != nil {
+		return nil, err
+	}
+
+	receiveBlock, err := momentumStore.GetBlockWhichReceives(sendHash)
+	if err != nil {
+		return nil, err
+	}
+	if receiveBlock != nil {
+		receipt.Status = ReceiptStatusConfirmed
+		if receipt.ReceiveConfirmation, err = getReceiptConfirmation(momentumStore, receiveBlock.Hash); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, block := range c.GetUncommittedAccountBlocksByAddress(sendBlock.ToAddress) {
+			if block.FromBlockHash == sendHash {
+				receiveBlock = block
+				receipt.Status = ReceiptStatusReceived
+				break
+			}
+		}
+	}
+	if receiveBlock == nil {
+		return receipt, nil
+	}
+	receipt.ReceiveBlockHash = receiveBlock.Hash
+
+	if receiveBlock.BlockType != nom.BlockTypeContractReceive {
+		return receipt, nil
+	}
+	// embedded contracts write the result of the call in the data of the receive-block
+	receipt.ExecutionStatus = ExecutionStatusFailed
+	if len(receiveBlock.Data) == 8 && common.BytesToUint64(receiveBlock.Data) == constants.ReceiveResultSuccess {
+		receipt.ExecutionStatus = ExecutionStatusSuccess
+	}
+	for _, dBlock := range receiveBlock.DescendantBlocks {
+		transfer := &ReceiptTransfer{
+			Hash:          dBlock.Hash,
+			ToAddress:     dBlock.ToAddress,
+			TokenStandard: dBlock.TokenStandard,
+			Amount:        dBlock.Amount.String(),
+		}
+		// failed calls only have the refund of the sent tokens as descendant
+		if receipt.ExecutionStatus == ExecutionStatusFailed {
+			receipt.Refund = transfer
+		} else {
+			receipt.Transfers = append(receipt.Transfers, transfer)
+		}
+	}
+	return receipt, nil
+}
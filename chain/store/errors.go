@@ -0,0 +1,7 @@
+package store
+
+import "github.com/pkg/errors"
+
+var (
+	ErrDataPruned = errors.Errorf("data is pruned, the node only keeps the recent momentums in full")
+)
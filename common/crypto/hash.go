@@ -3,6 +3,8 @@ package crypto
 import (
 	"crypto/sha256"
 
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ripemd160"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -22,6 +24,22 @@ func HashSHA256(data ...[]byte) []byte {
 	return d.Sum(nil)
 }
 
+// HashHASH160 returns RIPEMD160(SHA256(data)), as used by Bitcoin scripts
+func HashHASH160(data ...[]byte) []byte {
+	d := ripemd160.New()
+	d.Write(HashSHA256(data...))
+	return d.Sum(nil)
+}
+
+// HashBLAKE2b returns the 32 bytes BLAKE2b digest of data
+func HashBLAKE2b(data ...[]byte) []byte {
+	d, _ := blake2b.New256(nil)
+	for _, item := range data {
+		d.Write(item)
+	}
+	return d.Sum(nil)
+}
+
 func Keccak256(data ...[]byte) []byte {
 	d := sha3.NewLegacyKeccak256()
 	for _, item := range data {
@@ -15,3 +15,13 @@ func TestEmptyHashSHA256(t *testing.T) {
 	h := HashSHA256()
 	common.ExpectBytes(t, h, `0xe3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855`)
 }
+
+func TestEmptyHashHASH160(t *testing.T) {
+	h := HashHASH160()
+	common.ExpectBytes(t, h, `0xb472a266d0bd89c13706a4132ccfb16f7c3b9fcb`)
+}
+
+func TestEmptyHashBLAKE2b(t *testing.T) {
+	h := HashBLAKE2b()
+	common.ExpectBytes(t, h, `0x0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8`)
+}
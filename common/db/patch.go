@@ -115,6 +115,36 @@ func (pa *patchApplierWO) Delete(key []byte) {
 	}
 }
 
+type patchCounter struct {
+	count int
+}
+
+func (pc *patchCounter) Put([]byte, []byte) {
+	pc.count += 1
+}
+func (pc *patchCounter) Delete([]byte) {
+	pc.count += 1
+}
+
+// patchTruncater copies the first entries of a patch
+type patchTruncater struct {
+	Patch
+	left int
+}
+
+func (pt *patchTruncater) Put(key []byte, value []byte) {
+	if pt.left > 0 {
+		pt.Patch.Put(key, value)
+		pt.left -= 1
+	}
+}
+func (pt *patchTruncater) Delete(key []byte) {
+	if pt.left > 0 {
+		pt.Patch.Delete(key)
+		pt.left -= 1
+	}
+}
+
 func DebugPatch(patch Patch) string {
 	pp := new(patchPrinter)
 	err := patch.Replay(pp)
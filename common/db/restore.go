@@ -0,0 +1,70 @@
+package db
+
+import (
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+
+	"github.com/zenon-network/go-zenon/common"
+)
+
+const (
+	// restoreBatchSize is the number of entries written in a batch while restoring a leveldb manager
+	restoreBatchSize = 10000
+)
+
+// LevelDBRestorer writes the frontier of an empty leveldb manager and is used to import state snapshots.
+// There are no patches or rollbacks for the versions up to the restored frontier, so they are marked as pruned.
+type LevelDBRestorer struct {
+	ldb   *leveldb.DB
+	batch *leveldb.Batch
+}
+
+func NewLevelDBRestorer(dir string) (*LevelDBRestorer, error) {
+	opts := &opt.Options{OpenFilesCacheCapacity: getOpenFilesCacheCapacity()}
+	ldb, err := leveldb.OpenFile(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	iterator := ldb.NewIterator(nil, nil)
+	empty := !iterator.Next()
+	iterator.Release()
+	if !empty {
+		common.DealWithErr(ldb.Close())
+		return nil, errors.Errorf("can't restore db %v, it is not empty", dir)
+	}
+	return &LevelDBRestorer{
+		ldb:   ldb,
+		batch: new(leveldb.Batch),
+	}, nil
+}
+
+func (r *LevelDBRestorer) Put(key, value []byte) error {
+	r.batch.Put(common.JoinBytes(frontierByte, key), common.JoinBytes(existsByte, value))
+	if r.batch.Len() < restoreBatchSize {
+		return nil
+	}
+	return r.flush()
+}
+func (r *LevelDBRestorer) flush() error {
+	if err := r.ldb.Write(r.batch, nil); err != nil {
+		return err
+	}
+	r.batch.Reset()
+	return nil
+}
+
+// Close writes the remaining entries and marks the versions up to the restored frontier as pruned
+func (r *LevelDBRestorer) Close() error {
+	defer func() {
+		common.DealWithErr(r.ldb.Close())
+	}()
+	if err := r.flush(); err != nil {
+		return err
+	}
+	frontierIdentifier := GetFrontierIdentifier(NewLevelDBWrapper(r.ldb).Subset(frontierByte))
+	if frontierIdentifier.IsZero() {
+		return errors.Errorf("restored db has no frontier")
+	}
+	return r.ldb.Put(prunedByte, common.Uint64ToBytes(frontierIdentifier.Height), nil)
+}
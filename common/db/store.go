@@ -17,6 +17,14 @@ func getEntryByHeightKey(height uint64) []byte {
 	return common.JoinBytes(entryByHeightPrefix, common.Uint64ToBytes(height))
 }
 
+// GetEntryByHeightKey returns the key under which the entry at height is stored
+func GetEntryByHeightKey(height uint64) []byte {
+	return getEntryByHeightKey(height)
+}
+
+// frontierEntries is the number of entries written by SetFrontier
+const frontierEntries = 3
+
 func SetFrontier(db DB, version types.HashHeight, data []byte) error {
 	if err := db.Put(getFrontierIdentifierKey(), version.Serialize()); err != nil {
 		return err
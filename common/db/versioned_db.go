@@ -16,13 +16,18 @@ import (
 const (
 	l1CacheSize                  = 400
 	l2CacheSize                  = 100
+	checkpointCacheSize          = 100
 	maximumCacheHeightDifference = 360
+	// checkpointInterval is the distance between the heights of two checkpoints. Versions are rebuilt on top of the
+	// checkpoint right above them, so a read replays at most checkpointInterval rollbacks once its checkpoint is cached.
+	checkpointInterval = 1000
 )
 
 var (
 	frontierByte = []byte{85}
 	patchByte    = []byte{102}
 	rollbackByte = []byte{119}
+	prunedByte   = []byte{112}
 )
 
 func absDiff(x, y uint64) uint64 {
@@ -47,10 +52,17 @@ type Manager interface {
 	Frontier() DB
 	Get(types.HashHeight) DB
 	GetPatch(identifier types.HashHeight) Patch
+	// GetRollback returns the patch which reverts the version to its previous version
+	GetRollback(identifier types.HashHeight) Patch
 
 	Add(Transaction) error
 	Pop() error
 
+	// Prune deletes the patch and the rollback of the version at height, as well as the given keys of the frontier.
+	// Versions up to the pruned height can no longer be retrieved or rolled back to.
+	Prune(height uint64, keys [][]byte) error
+	PrunedHeight() uint64
+
 	Stop() error
 	Location() string
 }
@@ -98,6 +110,15 @@ func (m *memdbManager) GetPatch(identifier types.HashHeight) Patch {
 	defer m.changes.Unlock()
 	return m.patches[identifier]
 }
+func (m *memdbManager) GetRollback(identifier types.HashHeight) Patch {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	previous, ok := m.versions[m.previous[identifier]]
+	if !ok {
+		return nil
+	}
+	return RollbackPatch(previous, m.patches[identifier])
+}
 func (m *memdbManager) Add(transaction Transaction) error {
 	commits := transaction.GetCommits()
 	previous := commits[0].Previous()
@@ -169,6 +190,12 @@ func (m *memdbManager) Pop() error {
 	m.frontierIdentifier = previous
 	return nil
 }
+func (m *memdbManager) Prune(uint64, [][]byte) error {
+	return errors.Errorf("can't prune in-memory db")
+}
+func (m *memdbManager) PrunedHeight() uint64 {
+	return 0
+}
 func (m *memdbManager) Stop() error {
 	m.frontierIdentifier = types.ZeroHashHeight
 	m.versions = nil
@@ -179,18 +206,71 @@ func (m *memdbManager) Location() string {
 	return "in-memory"
 }
 
+// rollbackCache holds the changes which turn frontier into a version, as raw values
 type rollbackCache struct {
+	lock     sync.Mutex
 	frontier types.HashHeight
 	raw      db
 }
 
+// extend moves the changes to a newer frontier. The values read through raw don't change,
+// since the rollbacks only add the keys which were unchanged up to the previous frontier.
+// Returns false if the frontier of the changes isn't part of the chain which ends at frontierIdentifier.
+func (c *rollbackCache) extend(snapshot *leveldb.Snapshot, frontier DB, frontierIdentifier types.HashHeight) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.frontier == frontierIdentifier {
+		return true
+	}
+	if c.frontier.Height > frontierIdentifier.Height {
+		return false
+	}
+	identifier, err := GetIdentifierByHash(frontier, c.frontier.Hash)
+	if err == leveldb.ErrNotFound {
+		return false
+	}
+	common.DealWithErr(err)
+	if *identifier != c.frontier {
+		return false
+	}
+
+	for i := c.frontier.Height + 1; i <= frontierIdentifier.Height; i += 1 {
+		if err := ApplyWithoutOverride(c.raw, getRollbackFrom(snapshot, i)); err != nil {
+			common.DealWithErr(err)
+		}
+	}
+	c.frontier = frontierIdentifier
+	return true
+}
+
+// copyWithoutOverride copies the raw values of from which aren't set in to
+func copyWithoutOverride(to, from db) error {
+	iterator := from.NewIterator(nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		if ok, err := to.Has(iterator.Key()); err != nil {
+			return err
+		} else if !ok {
+			if err := to.Put(iterator.Key(), iterator.Value()); err != nil {
+				return err
+			}
+		}
+	}
+	return iterator.Error()
+}
+
 type ldbManager struct {
 	location string
 	l1Cache  *lru.Cache
 	l2Cache  *lru.Cache
 	ldb      *leveldb.DB
 	changes  sync.Mutex
-	stopped  bool
+	// reads is held by the versions which are rebuilt outside the changes lock, so the db isn't closed under them
+	reads   sync.RWMutex
+	stopped bool
+	pruned  uint64
+	// checkpoints are keyed by height, a multiple of checkpointInterval
+	checkpoints *lru.Cache
 }
 
 func NewLevelDBManager(dir string) Manager {
@@ -201,11 +281,22 @@ func NewLevelDBManager(dir string) Manager {
 	common.DealWithErr(err)
 	l2Cache, err := lru.New(l2CacheSize)
 	common.DealWithErr(err)
+	checkpoints, err := lru.New(checkpointCacheSize)
+	common.DealWithErr(err)
+	pruned := uint64(0)
+	if data, err := ldb.Get(prunedByte, nil); err == nil {
+		pruned = common.BytesToUint64(data)
+	} else if err != leveldb.ErrNotFound {
+		common.DealWithErr(err)
+	}
 	return &ldbManager{
 		location: dir,
 		l1Cache:  l1Cache,
 		l2Cache:  l2Cache,
 		ldb:      ldb,
+		pruned:   pruned,
+
+		checkpoints: checkpoints,
 	}
 }
 
@@ -219,12 +310,17 @@ func (m *ldbManager) Frontier() DB {
 	return NewLevelDBSnapshotWrapper(snapshot).Subset(frontierByte)
 }
 func (m *ldbManager) Get(identifier types.HashHeight) DB {
+	m.reads.RLock()
+	defer m.reads.RUnlock()
 	m.changes.Lock()
-	defer m.changes.Unlock()
 	if m.stopped {
+		m.changes.Unlock()
 		return nil
 	}
 	snapshot, _ := m.ldb.GetSnapshot()
+	pruned := m.pruned
+	m.changes.Unlock()
+
 	// check if has snapshot
 	frontier := NewLevelDBSnapshotWrapper(snapshot).Subset(frontierByte)
 	frontierIdentifier := GetFrontierIdentifier(frontier)
@@ -235,6 +331,10 @@ func (m *ldbManager) Get(identifier types.HashHeight) DB {
 	if identifier == frontierIdentifier {
 		return frontier
 	}
+	// the rollbacks required to rebuild pruned versions are gone
+	if identifier.Height <= pruned {
+		return nil
+	}
 
 	trueIdentifier, err := GetIdentifierByHash(frontier, identifier.Hash)
 	if err == leveldb.ErrNotFound {
@@ -245,38 +345,23 @@ func (m *ldbManager) Get(identifier types.HashHeight) DB {
 		return nil
 	}
 
-	var rawChanges db
-	var toIdentifier types.HashHeight
-
-	if cache, ok := m.l1Cache.Get(identifier); ok {
-		toIdentifier = cache.(*rollbackCache).frontier
-		rawChanges = cache.(*rollbackCache).raw
-	} else if cache, ok := m.l2Cache.Get(identifier); ok {
-		toIdentifier = cache.(*rollbackCache).frontier
-		rawChanges = cache.(*rollbackCache).raw
-	} else {
-		rawChanges = newMemDBInternal()
-		toIdentifier = identifier
+	var cache *rollbackCache
+	if cached, ok := m.l1Cache.Get(identifier); ok {
+		cache = cached.(*rollbackCache)
+	} else if cached, ok := m.l2Cache.Get(identifier); ok {
+		cache = cached.(*rollbackCache)
 	}
 
-	for i := toIdentifier.Height + 1; i <= frontierIdentifier.Height; i += 1 {
-		rollback := m.getRollback(i)
-		if err := ApplyWithoutOverride(rawChanges, rollback); err != nil {
-			common.DealWithErr(err)
+	// the rollbacks are read from the snapshot, so deep versions are rebuilt without blocking the insertion of new ones
+	if cache == nil || !cache.extend(snapshot, frontier, frontierIdentifier) {
+		cache = m.rebuild(snapshot, frontier, frontierIdentifier, identifier.Height)
+		if absDiff(identifier.Height, frontierIdentifier.Height) < maximumCacheHeightDifference {
+			m.l1Cache.Add(identifier, cache)
+		} else {
+			m.l2Cache.Add(identifier, cache)
 		}
 	}
-
-	if absDiff(identifier.Height, frontierIdentifier.Height) < maximumCacheHeightDifference {
-		m.l1Cache.Add(identifier, &rollbackCache{
-			frontier: frontierIdentifier,
-			raw:      rawChanges,
-		})
-	} else {
-		m.l2Cache.Add(identifier, &rollbackCache{
-			frontier: frontierIdentifier,
-			raw:      rawChanges,
-		})
-	}
+	rawChanges := cache.raw
 
 	u := newMergedDb([]db{
 		newMemDBInternal(),
@@ -288,6 +373,41 @@ func (m *ldbManager) Get(identifier types.HashHeight) DB {
 	})
 	return enableDelete(u)
 }
+
+// rebuild returns the changes which turn the frontier into the version at height. The rollbacks are replayed
+// up to the checkpoint right above the version, the changes of the checkpoint cover the rest.
+func (m *ldbManager) rebuild(snapshot *leveldb.Snapshot, frontier DB, frontierIdentifier types.HashHeight, height uint64) *rollbackCache {
+	checkpoint := (height/checkpointInterval + 1) * checkpointInterval
+	if checkpoint > frontierIdentifier.Height {
+		checkpoint = frontierIdentifier.Height
+	}
+
+	raw := newMemDBInternal()
+	for i := height + 1; i <= checkpoint; i += 1 {
+		if err := ApplyWithoutOverride(raw, getRollbackFrom(snapshot, i)); err != nil {
+			common.DealWithErr(err)
+		}
+	}
+	if checkpoint != frontierIdentifier.Height {
+		if err := copyWithoutOverride(raw, m.getCheckpoint(snapshot, frontier, frontierIdentifier, checkpoint)); err != nil {
+			common.DealWithErr(err)
+		}
+	}
+	return &rollbackCache{
+		frontier: frontierIdentifier,
+		raw:      raw,
+	}
+}
+func (m *ldbManager) getCheckpoint(snapshot *leveldb.Snapshot, frontier DB, frontierIdentifier types.HashHeight, height uint64) db {
+	if cached, ok := m.checkpoints.Get(height); ok {
+		if cache := cached.(*rollbackCache); cache.extend(snapshot, frontier, frontierIdentifier) {
+			return cache.raw
+		}
+	}
+	cache := m.rebuild(snapshot, frontier, frontierIdentifier, height)
+	m.checkpoints.Add(height, cache)
+	return cache.raw
+}
 func (m *ldbManager) GetPatch(identifier types.HashHeight) Patch {
 	m.changes.Lock()
 	defer m.changes.Unlock()
@@ -296,6 +416,14 @@ func (m *ldbManager) GetPatch(identifier types.HashHeight) Patch {
 	}
 	return m.getPatch(identifier)
 }
+func (m *ldbManager) GetRollback(identifier types.HashHeight) Patch {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	if m.stopped {
+		return nil
+	}
+	return m.getRollback(identifier.Height)
+}
 func (m *ldbManager) getPatch(identifier types.HashHeight) Patch {
 	snapshot, _ := m.ldb.GetSnapshot()
 	value, err := snapshot.Get(common.JoinBytes(patchByte, common.Uint64ToBytes(identifier.Height)), nil)
@@ -310,6 +438,9 @@ func (m *ldbManager) getPatch(identifier types.HashHeight) Patch {
 }
 func (m *ldbManager) getRollback(height uint64) Patch {
 	snapshot, _ := m.ldb.GetSnapshot()
+	return getRollbackFrom(snapshot, height)
+}
+func getRollbackFrom(snapshot *leveldb.Snapshot, height uint64) Patch {
 	value, err := snapshot.Get(common.JoinBytes(rollbackByte, common.Uint64ToBytes(height)), nil)
 	if err == leveldb.ErrNotFound {
 		return nil
@@ -373,8 +504,37 @@ func (m *ldbManager) Add(transaction Transaction) error {
 	}
 	return nil
 }
+
+// GetTransactionChanges returns the changes of the transaction which produced the patch of a version,
+// by dropping the frontier entries appended to the patch for each of the commits of the transaction.
+func GetTransactionChanges(patch Patch, commits int) (Patch, error) {
+	counter := &patchCounter{}
+	if err := patch.Replay(counter); err != nil {
+		return nil, err
+	}
+	if counter.count < commits*frontierEntries {
+		return nil, errors.Errorf("patch has %v entries, expected at least %v frontier entries", counter.count, commits*frontierEntries)
+	}
+	changes := &patchTruncater{
+		Patch: NewPatch(),
+		left:  counter.count - commits*frontierEntries,
+	}
+	if err := patch.Replay(changes); err != nil {
+		return nil, err
+	}
+	return changes.Patch, nil
+}
 func (m *ldbManager) Pop() error {
-	frontierIdentifier := GetFrontierIdentifier(m.Frontier())
+	// versions are rebuilt from snapshots taken under the lock, so they never see a partial rollback
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	if m.stopped {
+		return errors.Errorf("can't rollback stopped db")
+	}
+	frontierIdentifier := GetFrontierIdentifier(NewLevelDBWrapper(m.ldb).Subset(frontierByte))
+	if frontierIdentifier.Height <= m.pruned {
+		return errors.Errorf("can't rollback pruned identifier %v", frontierIdentifier)
+	}
 	rollbackPatch := m.getRollback(frontierIdentifier.Height)
 
 	if err := ApplyPatch(NewLevelDBWrapper(m.ldb).Subset(frontierByte), rollbackPatch); err != nil {
@@ -389,7 +549,38 @@ func (m *ldbManager) Pop() error {
 
 	return nil
 }
+func (m *ldbManager) Prune(height uint64, keys [][]byte) error {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	if m.stopped {
+		return errors.Errorf("can't prune stopped db")
+	}
+	frontierIdentifier := GetFrontierIdentifier(NewLevelDBWrapper(m.ldb).Subset(frontierByte))
+	if height <= m.pruned || height >= frontierIdentifier.Height {
+		return errors.Errorf("can't prune height %v. pruned height is %v and frontier is %v", height, m.pruned, frontierIdentifier)
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Delete(common.JoinBytes(patchByte, common.Uint64ToBytes(height)))
+	batch.Delete(common.JoinBytes(rollbackByte, common.Uint64ToBytes(height)))
+	for _, key := range keys {
+		batch.Delete(common.JoinBytes(frontierByte, key))
+	}
+	batch.Put(prunedByte, common.Uint64ToBytes(height))
+	if err := m.ldb.Write(batch, nil); err != nil {
+		return err
+	}
+	m.pruned = height
+	return nil
+}
+func (m *ldbManager) PrunedHeight() uint64 {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	return m.pruned
+}
 func (m *ldbManager) Stop() error {
+	m.reads.Lock()
+	defer m.reads.Unlock()
 	m.changes.Lock()
 	defer m.changes.Unlock()
 	if err := m.ldb.Close(); err != nil {
@@ -399,6 +590,7 @@ func (m *ldbManager) Stop() error {
 	m.ldb = nil
 	m.l1Cache = nil
 	m.l2Cache = nil
+	m.checkpoints = nil
 	return nil
 }
 func (m *ldbManager) Location() string {
@@ -260,3 +260,68 @@ d5104dc76695721d - b80704bb7b4d7c03
 dc2864602be7fb85 - d38967f931a50490
 f25f4b21eef64b43 - 9c0a8a2bfc0914df`)
 }
+
+// newOverwriteTransaction overwrites a few of 16 keys, so the rollbacks restore values and not only delete keys
+func newOverwriteTransaction(db DB, branch uint64) *mockTransaction {
+	frontier := GetFrontierIdentifier(db)
+	ab := &mockCommit{
+		prevHash: frontier.Hash,
+		height:   frontier.Height + 1,
+	}
+	for i := uint64(0); i < 3; i += 1 {
+		common.DealWithErr(db.Put(common.Uint64ToBytes((ab.height*(i+1))%16), common.Uint64ToBytes(ab.height+branch)))
+	}
+
+	changes, _ := db.Changes()
+	ab.changesHash = PatchHash(changes)
+	ab.hash = types.NewHash(common.JoinBytes(ab.changesHash.Bytes(), common.Uint64ToBytes(ab.height)))
+	return &mockTransaction{
+		patch:  changes,
+		commit: ab,
+	}
+}
+
+func TestVersionedDBCheckpoints(t *testing.T) {
+	m := NewLevelDBManager(t.TempDir())
+	defer m.Stop()
+
+	checked := []uint64{1, 500, checkpointInterval - 1, checkpointInterval, checkpointInterval + 1, 2 * checkpointInterval}
+	versions := make(map[types.HashHeight]string)
+	addVersions := func(count int, branch uint64) {
+		for i := 0; i < count; i += 1 {
+			common.DealWithErr(m.Add(newOverwriteTransaction(m.Frontier(), branch)))
+			frontier := m.Frontier()
+			identifier := GetFrontierIdentifier(frontier)
+			for _, height := range checked {
+				if identifier.Height == height {
+					versions[identifier] = DebugDB(frontier)
+				}
+			}
+		}
+	}
+	checkVersions := func() {
+		for identifier, expected := range versions {
+			common.ExpectString(t, DebugDB(m.Get(identifier)), expected)
+		}
+	}
+
+	addVersions(2*checkpointInterval+50, 0)
+	checkVersions()
+
+	// the cached versions and checkpoints are moved to the new frontier
+	addVersions(checkpointInterval/2, 0)
+	checkVersions()
+
+	// the checkpoints of a rolled back chain are rebuilt
+	for i := 0; i < checkpointInterval+200; i += 1 {
+		common.DealWithErr(m.Pop())
+	}
+	for identifier := range versions {
+		if identifier.Height > checkpointInterval+350 {
+			delete(versions, identifier)
+		}
+	}
+	checked = append(checked, checkpointInterval+355)
+	addVersions(10, 1)
+	checkVersions()
+}
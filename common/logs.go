@@ -18,6 +18,10 @@ var (
 	NodeLogger       = log15.New("module", "node")
 	P2PLogger        = log15.New("module", "p2p")
 	PillarLogger     = log15.New("module", "pillar")
+	RelayerLogger    = log15.New("module", "relayer")
+	StratumLogger    = log15.New("module", "stratum")
+	IndexerLogger    = log15.New("module", "indexer")
+	PrunerLogger     = log15.New("module", "pruner")
 	ProtocolLogger   = log15.New("module", "handler")
 	FetcherLogger    = ProtocolLogger.New("submodule", "fetcher")
 	DownloaderLogger = ProtocolLogger.New("submodule", "downloader")
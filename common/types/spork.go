@@ -5,12 +5,16 @@ var (
 	HtlcSpork               = NewImplementedSpork("ceb7e3808ef17ea910adda2f3ab547be4cdfb54de8400ce3683258d06be1354b")
 	BridgeAndLiquiditySpork = NewImplementedSpork("ddd43466769461c5b5d109c639da0f50a7eeb96ad6e7274b1928a35c431d7b1b")
 	MergeMiningSpork        = NewImplementedSpork("add43466769461c5b5d109c639da0f50a7eeb96ad6e7274b1928a35c431d7b1b")
+	HtlcHashTypesSpork      = NewImplementedSpork("bdd43466769461c5b5d109c639da0f50a7eeb96ad6e7274b1928a35c431d7b1b")
+	BridgeExtensionsSpork   = NewImplementedSpork("edd43466769461c5b5d109c639da0f50a7eeb96ad6e7274b1928a35c431d7b1b")
 
 	ImplementedSporksMap = map[Hash]bool{
 		AcceleratorSpork.SporkId:        true,
 		HtlcSpork.SporkId:               true,
 		BridgeAndLiquiditySpork.SporkId: true,
 		MergeMiningSpork.SporkId:        true,
+		HtlcHashTypesSpork.SporkId:      true,
+		BridgeExtensionsSpork.SporkId:   true,
 	}
 )
 
@@ -158,6 +158,9 @@ func (em *electionManager) generateProducers(proofBlock *nom.Momentum) (*storage
 	}
 
 	// get delegations
+	if store == nil {
+		return nil, errors.Errorf("can't compute producers, state at proof momentum %v is not available", hashH)
+	}
 	delegationsDetailed, err := store.ComputePillarDelegations()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,16 @@
+package history
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidMagic       = errors.Errorf("file is not a znnd chain export")
+	ErrUnsupportedVersion = errors.Errorf("unsupported chain export version")
+	ErrRecordTooBig       = errors.Errorf("chain export record is too big")
+	ErrMalformedRecord    = errors.Errorf("malformed chain export record")
+	ErrTruncated          = errors.Errorf("chain export is truncated")
+
+	ErrInvalidRange     = errors.Errorf("invalid momentum range")
+	ErrGenesisMismatch  = errors.Errorf("chain export was created from a different chain")
+	ErrUnexpectedHeight = errors.Errorf("chain export momentums are not consecutive")
+	ErrDivergentChain   = errors.Errorf("chain export doesn't match the momentums of the local chain")
+)
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"io"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+)
+
+// Export writes the momentums between from and to, inclusive, together with their account-blocks.
+// A to of 0 exports up to the frontier momentum.
+func Export(w io.Writer, ch chain.Chain, from, to uint64, compress bool, progress Progress) (*Header, error) {
+	momentumStore := ch.GetFrontierMomentumStore()
+	frontier := momentumStore.Identifier()
+	if to == 0 {
+		to = frontier.Height
+	}
+	if from == 0 || from > to || to > frontier.Height {
+		return nil, ErrInvalidRange
+	}
+
+	header := &Header{
+		Version:         Version,
+		ChainIdentifier: ch.ChainIdentifier(),
+		GenesisHash:     ch.GetGenesisMomentum().Hash,
+		From:            from,
+		To:              to,
+	}
+	hw, err := newWriter(w, compress)
+	if err != nil {
+		return nil, err
+	}
+	if err := hw.writeHeader(header); err != nil {
+		return nil, err
+	}
+
+	for height := from; height <= to; height += 1 {
+		momentum, err := momentumStore.GetMomentumByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		blocks := make([]*nom.AccountBlock, len(momentum.Content))
+		for i, header := range momentum.Content {
+			if blocks[i], err = momentumStore.GetAccountBlock(*header); err != nil {
+				return nil, err
+			}
+			if blocks[i] == nil {
+				return nil, store.ErrDataPruned
+			}
+		}
+		if err := hw.writeMomentum(&nom.DetailedMomentum{
+			Momentum:      momentum,
+			AccountBlocks: blocks,
+		}); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(height, to)
+		}
+	}
+
+	if err := hw.close(); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
@@ -0,0 +1,224 @@
+package history
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// A chain export, optionally compressed with gzip, starts with magic and the version, followed by length-prefixed
+// records:
+//
+//	record   := length (uvarint) | payload
+//	momentum := length (uvarint) | momentum proto | count (uvarint) | (length (uvarint) | account-block proto)*
+//
+// The first record is the json header, followed by one record for every momentum, in order, and an empty record
+// which marks the end of the export.
+const (
+	Version = 1
+
+	// maxRecordSize bounds the size of the records read from an export
+	maxRecordSize = 256 * 1024 * 1024
+)
+
+var (
+	magic     = []byte("ZNNCHAIN")
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+type Header struct {
+	Version         uint64     `json:"version"`
+	ChainIdentifier uint64     `json:"chainIdentifier"`
+	GenesisHash     types.Hash `json:"genesisHash"`
+	From            uint64     `json:"from"`
+	To              uint64     `json:"to"`
+}
+
+// Progress is called after height was exported or imported, out of the last height of the export
+type Progress func(height, last uint64)
+
+type writer struct {
+	w  *bufio.Writer
+	gz *gzip.Writer
+}
+
+func newWriter(w io.Writer, compress bool) (*writer, error) {
+	hw := &writer{}
+	if compress {
+		hw.gz = gzip.NewWriter(w)
+		w = hw.gz
+	}
+	hw.w = bufio.NewWriter(w)
+	if _, err := hw.w.Write(magic); err != nil {
+		return nil, err
+	}
+	if _, err := hw.w.Write(common.Uint64ToBytes(Version)); err != nil {
+		return nil, err
+	}
+	return hw, nil
+}
+
+func (w *writer) writeRecord(payload []byte) error {
+	if _, err := w.w.Write(binary.AppendUvarint(nil, uint64(len(payload)))); err != nil {
+		return err
+	}
+	_, err := w.w.Write(payload)
+	return err
+}
+
+func (w *writer) writeHeader(header *Header) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	return w.writeRecord(data)
+}
+
+func (w *writer) writeMomentum(detailed *nom.DetailedMomentum) error {
+	data, err := detailed.Momentum.Serialize()
+	if err != nil {
+		return err
+	}
+	payload := binary.AppendUvarint(nil, uint64(len(data)))
+	payload = append(payload, data...)
+	payload = binary.AppendUvarint(payload, uint64(len(detailed.AccountBlocks)))
+	for _, block := range detailed.AccountBlocks {
+		data, err := block.Serialize()
+		if err != nil {
+			return err
+		}
+		payload = binary.AppendUvarint(payload, uint64(len(data)))
+		payload = append(payload, data...)
+	}
+	return w.writeRecord(payload)
+}
+
+// close writes the end of the export and flushes it
+func (w *writer) close() error {
+	if err := w.writeRecord(nil); err != nil {
+		return err
+	}
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	return nil
+}
+
+type reader struct {
+	r *bufio.Reader
+}
+
+// newReader detects if the export is compressed and checks the magic and the version
+func newReader(r io.Reader) (*reader, error) {
+	br := bufio.NewReader(r)
+	if prefix, err := br.Peek(len(gzipMagic)); err == nil && string(prefix) == string(gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(gz)
+	}
+
+	prefix := make([]byte, len(magic)+8)
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		return nil, ErrInvalidMagic
+	}
+	if string(prefix[:len(magic)]) != string(magic) {
+		return nil, ErrInvalidMagic
+	}
+	if common.BytesToUint64(prefix[len(magic):]) != Version {
+		return nil, ErrUnsupportedVersion
+	}
+	return &reader{r: br}, nil
+}
+
+// readRecord returns the payload of the next record, which is empty at the end of the export
+func (r *reader) readRecord() ([]byte, error) {
+	length, err := binary.ReadUvarint(r.r)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrTruncated
+	} else if err != nil {
+		return nil, err
+	}
+	if length > maxRecordSize {
+		return nil, ErrRecordTooBig
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r.r, payload); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrTruncated
+	} else if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (r *reader) readHeader() (*Header, error) {
+	payload, err := r.readRecord()
+	if err != nil {
+		return nil, err
+	}
+	header := new(Header)
+	if err := json.Unmarshal(payload, header); err != nil {
+		return nil, ErrMalformedRecord
+	}
+	return header, nil
+}
+
+// readMomentum returns the next momentum of the export, or nil at the end of the export
+func (r *reader) readMomentum() (*nom.DetailedMomentum, error) {
+	payload, err := r.readRecord()
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 {
+		return nil, nil
+	}
+
+	data, payload, err := readBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	momentum, err := nom.DeserializeMomentum(data)
+	if err != nil {
+		return nil, ErrMalformedRecord
+	}
+	count, n := binary.Uvarint(payload)
+	if n <= 0 || count > uint64(len(payload)) {
+		return nil, ErrMalformedRecord
+	}
+	payload = payload[n:]
+	blocks := make([]*nom.AccountBlock, count)
+	for i := range blocks {
+		if data, payload, err = readBytes(payload); err != nil {
+			return nil, err
+		}
+		if blocks[i], err = nom.DeserializeAccountBlock(data); err != nil {
+			return nil, ErrMalformedRecord
+		}
+	}
+	if len(payload) != 0 {
+		return nil, ErrMalformedRecord
+	}
+	return &nom.DetailedMomentum{
+		Momentum:      momentum,
+		AccountBlocks: blocks,
+	}, nil
+}
+
+// readBytes splits a uvarint length-prefixed value from the start of data
+func readBytes(data []byte) ([]byte, []byte, error) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < length {
+		return nil, nil, ErrMalformedRecord
+	}
+	return data[n : n+int(length)], data[n+int(length):], nil
+}
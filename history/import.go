@@ -0,0 +1,93 @@
+package history
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/protocol"
+)
+
+const (
+	// importBatchSize is the number of momentums inserted at once
+	importBatchSize = 100
+)
+
+// Import inserts the momentums read from r through the chain bridge, which verifies and applies them as it does for
+// momentums received from peers. Momentums up to the frontier of the chain are skipped, so an interrupted import
+// resumes from the frontier when the same export is imported again. The momentum of the export at the height of
+// the frontier must be the frontier, otherwise the export is of a different chain.
+// Returns the header of the export and the number of inserted momentums.
+func Import(r io.Reader, ch chain.Chain, bridge protocol.ChainBridge, progress Progress) (*Header, uint64, error) {
+	hr, err := newReader(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	header, err := hr.readHeader()
+	if err != nil {
+		return nil, 0, err
+	}
+	if header.Version != Version {
+		return nil, 0, ErrUnsupportedVersion
+	}
+	if header.ChainIdentifier != ch.ChainIdentifier() || header.GenesisHash != ch.GetGenesisMomentum().Hash {
+		return nil, 0, ErrGenesisMismatch
+	}
+
+	resume := ch.GetFrontierMomentumStore().Identifier()
+	expected := header.From
+	inserted := uint64(0)
+	batch := make([]*nom.DetailedMomentum, 0, importBatchSize)
+	insert := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := bridge.InsertChain(batch); err != nil {
+			first := batch[0].Momentum.Height
+			last := batch[len(batch)-1].Momentum.Height
+			return errors.Errorf("failed to insert momentums %v to %v: %v", first, last, err)
+		}
+		inserted += uint64(len(batch))
+		if progress != nil {
+			progress(batch[len(batch)-1].Momentum.Height, header.To)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		detailed, err := hr.readMomentum()
+		if err != nil {
+			return nil, 0, err
+		}
+		if detailed == nil {
+			break
+		}
+		if detailed.Momentum.Height != expected {
+			return nil, 0, ErrUnexpectedHeight
+		}
+		expected += 1
+		if detailed.Momentum.Height == resume.Height && detailed.Momentum.Hash != resume.Hash {
+			return nil, 0, ErrDivergentChain
+		}
+		if detailed.Momentum.Height <= resume.Height {
+			continue
+		}
+
+		batch = append(batch, detailed)
+		if len(batch) == importBatchSize {
+			if err := insert(); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	if expected != header.To+1 {
+		return nil, 0, ErrTruncated
+	}
+	if err := insert(); err != nil {
+		return nil, 0, err
+	}
+	return header, inserted, nil
+}
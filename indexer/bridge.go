@@ -0,0 +1,424 @@
+package indexer
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+const (
+	RequestStatusUnsigned = "unsigned"
+	RequestStatusSigned   = "signed"
+	RequestStatusPending  = "pending"
+	RequestStatusRedeemed = "redeemed"
+	RequestStatusRevoked  = "revoked"
+
+	bridgeWrapKind   byte = 0
+	bridgeUnwrapKind byte = 1
+
+	bridgeTokenPairStatsSize = 8 + 8 + 4*32
+)
+
+var (
+	bridgeStatusBytes = map[string]byte{
+		RequestStatusUnsigned: 0,
+		RequestStatusSigned:   1,
+		RequestStatusPending:  2,
+		RequestStatusRedeemed: 3,
+		RequestStatusRevoked:  4,
+	}
+)
+
+// BridgeRequestFilter selects wrap and unwrap requests, every field left empty matches all requests.
+// Wrap requests can be unsigned or signed, unwrap requests can be pending, redeemed or revoked.
+// The momentum range is inclusive, ToMomentumHeight 0 meaning no upper bound.
+type BridgeRequestFilter struct {
+	NetworkClass       *uint32                   `json:"networkClass"`
+	ChainId            *uint32                   `json:"chainId"`
+	TokenStandard      *types.ZenonTokenStandard `json:"tokenStandard"`
+	TokenAddress       *string                   `json:"tokenAddress"`
+	Status             string                    `json:"status"`
+	FromMomentumHeight uint64                    `json:"fromMomentumHeight"`
+	ToMomentumHeight   uint64                    `json:"toMomentumHeight"`
+}
+
+// Validate checks the status against the kind of the selected requests
+func (f *BridgeRequestFilter) Validate(wrap bool) error {
+	switch f.Status {
+	case "":
+		return nil
+	case RequestStatusUnsigned, RequestStatusSigned:
+		if wrap {
+			return nil
+		}
+	case RequestStatusPending, RequestStatusRedeemed, RequestStatusRevoked:
+		if !wrap {
+			return nil
+		}
+	}
+	return ErrInvalidRequestStatus
+}
+func (f *BridgeRequestFilter) hasPair() bool {
+	return f.NetworkClass != nil && f.ChainId != nil && f.TokenStandard != nil
+}
+func (f *BridgeRequestFilter) matches(request *bridgeRequest) bool {
+	if f.NetworkClass != nil && *f.NetworkClass != request.networkClass {
+		return false
+	}
+	if f.ChainId != nil && *f.ChainId != request.chainId {
+		return false
+	}
+	if f.TokenStandard != nil && *f.TokenStandard != request.tokenStandard {
+		return false
+	}
+	if f.TokenAddress != nil && *f.TokenAddress != request.tokenAddress {
+		return false
+	}
+	if f.Status != "" && f.Status != request.status {
+		return false
+	}
+	return true
+}
+
+// BridgeTokenPairStats aggregates the requests of a token pair, revoked unwrap requests are not counted
+type BridgeTokenPairStats struct {
+	WrapCount   uint64
+	UnwrapCount uint64
+	Wrapped     *big.Int
+	Fees        *big.Int
+	Unwrapped   *big.Int
+	Redeemed    *big.Int
+}
+
+func newBridgeTokenPairStats() *BridgeTokenPairStats {
+	return &BridgeTokenPairStats{
+		Wrapped:   big.NewInt(0),
+		Fees:      big.NewInt(0),
+		Unwrapped: big.NewInt(0),
+		Redeemed:  big.NewInt(0),
+	}
+}
+
+// apply adds the request to the stats, or removes it if sign is negative
+func (s *BridgeTokenPairStats) apply(request *bridgeRequest, sign int64) {
+	amount := new(big.Int).Mul(request.amount, big.NewInt(sign))
+	if request.kind == bridgeWrapKind {
+		s.WrapCount = uint64(int64(s.WrapCount) + sign)
+		s.Wrapped.Add(s.Wrapped, amount)
+		s.Fees.Add(s.Fees, new(big.Int).Mul(request.fee, big.NewInt(sign)))
+		return
+	}
+	if request.status == RequestStatusRevoked {
+		return
+	}
+	s.UnwrapCount = uint64(int64(s.UnwrapCount) + sign)
+	s.Unwrapped.Add(s.Unwrapped, amount)
+	if request.status == RequestStatusRedeemed {
+		s.Redeemed.Add(s.Redeemed, amount)
+	}
+}
+func (s *BridgeTokenPairStats) marshal() []byte {
+	return common.JoinBytes(
+		common.Uint64ToBytes(s.WrapCount),
+		common.Uint64ToBytes(s.UnwrapCount),
+		common.BigIntToBytes(s.Wrapped),
+		common.BigIntToBytes(s.Fees),
+		common.BigIntToBytes(s.Unwrapped),
+		common.BigIntToBytes(s.Redeemed),
+	)
+}
+func unmarshalBridgeTokenPairStats(data []byte) (*BridgeTokenPairStats, error) {
+	if len(data) != bridgeTokenPairStatsSize {
+		return nil, ErrInvalidIndexEntry
+	}
+	return &BridgeTokenPairStats{
+		WrapCount:   common.BytesToUint64(data[0:8]),
+		UnwrapCount: common.BytesToUint64(data[8:16]),
+		Wrapped:     common.BytesToBigInt(data[16:48]),
+		Fees:        common.BytesToBigInt(data[48:80]),
+		Unwrapped:   common.BytesToBigInt(data[80:112]),
+		Redeemed:    common.BytesToBigInt(data[112:144]),
+	}, nil
+}
+
+type bridgePairKey struct {
+	networkClass  uint32
+	chainId       uint32
+	tokenStandard types.ZenonTokenStandard
+}
+
+// bridgeRequest holds the fields of a wrap or unwrap request used by the indexes
+type bridgeRequest struct {
+	kind          byte
+	networkClass  uint32
+	chainId       uint32
+	tokenStandard types.ZenonTokenStandard
+	tokenAddress  string
+	height        uint64
+	status        string
+	amount        *big.Int
+	fee           *big.Int
+}
+
+func parseBridgeRequest(storageKey, value []byte) (*bridgeRequest, error) {
+	if definition.IsWrapTokenRequestKey(storageKey) {
+		request, err := definition.ParseWrapTokenRequest(value, storageKey)
+		if err != nil {
+			return nil, err
+		}
+		status := RequestStatusSigned
+		if request.Signature == "" {
+			status = RequestStatusUnsigned
+		}
+		return &bridgeRequest{
+			kind:          bridgeWrapKind,
+			networkClass:  request.NetworkClass,
+			chainId:       request.ChainId,
+			tokenStandard: request.TokenStandard,
+			tokenAddress:  request.TokenAddress,
+			height:        request.CreationMomentumHeight,
+			status:        status,
+			amount:        request.Amount,
+			fee:           request.Fee,
+		}, nil
+	}
+
+	request, err := definition.ParseUnwrapTokenRequest(value, storageKey)
+	if err != nil {
+		return nil, err
+	}
+	status := RequestStatusPending
+	if request.Revoked != 0 {
+		status = RequestStatusRevoked
+	} else if request.Redeemed != 0 {
+		status = RequestStatusRedeemed
+	}
+	return &bridgeRequest{
+		kind:          bridgeUnwrapKind,
+		networkClass:  request.NetworkClass,
+		chainId:       request.ChainId,
+		tokenStandard: request.TokenStandard,
+		tokenAddress:  request.TokenAddress,
+		height:        request.RegistrationMomentumHeight,
+		status:        status,
+		amount:        request.Amount,
+		fee:           big.NewInt(0),
+	}, nil
+}
+
+func (r *bridgeRequest) pairKey() bridgePairKey {
+	return bridgePairKey{networkClass: r.networkClass, chainId: r.chainId, tokenStandard: r.tokenStandard}
+}
+
+// indexKeys are the keys of the request in the height, token pair and status indexes.
+// All of them end with the height and the storage key, so the requests are sorted by height in each index.
+func (r *bridgeRequest) indexKeys(storageKey []byte) [][]byte {
+	height := common.Uint64ToBytes(r.height)
+	return [][]byte{
+		common.JoinBytes(getBridgeHeightPrefix(r.kind), height, storageKey),
+		common.JoinBytes(getBridgePairPrefix(r.kind, r.networkClass, r.chainId, r.tokenStandard), height, storageKey),
+		common.JoinBytes(getBridgeStatusPrefix(r.kind, r.status), height, storageKey),
+	}
+}
+
+func getBridgeHeightPrefix(kind byte) []byte {
+	return common.JoinBytes(bridgeHeightKeyPrefix, []byte{kind})
+}
+func getBridgePairPrefix(kind byte, networkClass, chainId uint32, zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(bridgePairKeyPrefix, []byte{kind}, common.Uint32ToBytes(networkClass), common.Uint32ToBytes(chainId), zts.Bytes())
+}
+func getBridgeStatusPrefix(kind byte, status string) []byte {
+	return common.JoinBytes(bridgeStatusKeyPrefix, []byte{kind, bridgeStatusBytes[status]})
+}
+func getBridgeStatsKey(key bridgePairKey) []byte {
+	return common.JoinBytes(bridgeStatsKeyPrefix, common.Uint32ToBytes(key.networkClass), common.Uint32ToBytes(key.chainId), key.tokenStandard.Bytes())
+}
+
+// bridgeTotals keeps the per token pair stats up to date with the requests changed by a momentum
+type bridgeTotals struct {
+	ldb   *leveldb.DB
+	stats map[bridgePairKey]*BridgeTokenPairStats
+}
+
+func (bt *bridgeTotals) getStats(key bridgePairKey) (*BridgeTokenPairStats, error) {
+	if stats, ok := bt.stats[key]; ok {
+		return stats, nil
+	}
+	stats, err := getBridgeTokenPairStats(bt.ldb, key)
+	if err != nil {
+		return nil, err
+	}
+	bt.stats[key] = stats
+	return stats, nil
+}
+func (bt *bridgeTotals) apply(storageKey, value []byte, sign int64) error {
+	request, err := parseBridgeRequest(storageKey, value)
+	if err != nil {
+		return err
+	}
+	stats, err := bt.getStats(request.pairKey())
+	if err != nil {
+		return err
+	}
+	stats.apply(request, sign)
+	return nil
+}
+func (bt *bridgeTotals) flush(batch *leveldb.Batch) {
+	for key, stats := range bt.stats {
+		if stats.WrapCount == 0 && stats.UnwrapCount == 0 {
+			batch.Delete(getBridgeStatsKey(key))
+		} else {
+			batch.Put(getBridgeStatsKey(key), stats.marshal())
+		}
+	}
+}
+
+// bridgeIndex indexes the wrap and unwrap requests of the bridge by height, token pair and status
+var bridgeIndex = &storageIndex{
+	contract:    types.BridgeContract,
+	entryPrefix: bridgeRequestKeyPrefix,
+	undoPrefix:  bridgeUndoKeyPrefix,
+	isEntryKey: func(storageKey []byte) bool {
+		return definition.IsWrapTokenRequestKey(storageKey) || definition.IsUnwrapTokenRequestKey(storageKey)
+	},
+	indexKeys: func(storageKey, value []byte) ([][]byte, error) {
+		request, err := parseBridgeRequest(storageKey, value)
+		if err != nil {
+			return nil, err
+		}
+		return request.indexKeys(storageKey), nil
+	},
+	newTotals: func(ldb *leveldb.DB) storageTotals {
+		return &bridgeTotals{
+			ldb:   ldb,
+			stats: make(map[bridgePairKey]*BridgeTokenPairStats),
+		}
+	},
+}
+
+func getBridgeTokenPairStats(ldb *leveldb.DB, key bridgePairKey) (*BridgeTokenPairStats, error) {
+	data, err := ldb.Get(getBridgeStatsKey(key), nil)
+	if err == leveldb.ErrNotFound {
+		return newBridgeTokenPairStats(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	return unmarshalBridgeTokenPairStats(data)
+}
+
+// getBridgeRequests iterates the requests of a kind which match the filter, newest first, and calls add with the
+// storage key and the value of the ones in the page. It returns the number of matches.
+// The most selective index is used, the requests are only decoded if the filter has fields the index doesn't cover.
+func (idx *indexer) getBridgeRequests(kind byte, filter *BridgeRequestFilter, skip, count int, add func(storageKey, value []byte) error) (int, error) {
+	var prefix []byte
+	exact := false
+	switch {
+	case filter.hasPair():
+		prefix = getBridgePairPrefix(kind, *filter.NetworkClass, *filter.ChainId, *filter.TokenStandard)
+		exact = filter.TokenAddress == nil && filter.Status == ""
+	case filter.Status != "":
+		prefix = getBridgeStatusPrefix(kind, filter.Status)
+		exact = filter.NetworkClass == nil && filter.ChainId == nil && filter.TokenStandard == nil && filter.TokenAddress == nil
+	default:
+		prefix = getBridgeHeightPrefix(kind)
+		exact = filter.NetworkClass == nil && filter.ChainId == nil && filter.TokenStandard == nil && filter.TokenAddress == nil
+	}
+
+	keyRange := util.BytesPrefix(prefix)
+	if filter.FromMomentumHeight != 0 {
+		keyRange.Start = common.JoinBytes(prefix, common.Uint64ToBytes(filter.FromMomentumHeight))
+	}
+	if filter.ToMomentumHeight != 0 && filter.ToMomentumHeight != math.MaxUint64 {
+		keyRange.Limit = common.JoinBytes(prefix, common.Uint64ToBytes(filter.ToMomentumHeight+1))
+	}
+
+	iterator := idx.ldb.NewIterator(keyRange, nil)
+	defer iterator.Release()
+	total := 0
+	for ok := iterator.Last(); ok; ok = iterator.Prev() {
+		storageKey := iterator.Key()[len(prefix)+8:]
+		if !exact {
+			request, err := parseBridgeRequest(storageKey, iterator.Value())
+			if err != nil {
+				return 0, err
+			}
+			if !filter.matches(request) {
+				continue
+			}
+		}
+		if total >= skip && total < skip+count {
+			if err := add(bytes.Clone(storageKey), bytes.Clone(iterator.Value())); err != nil {
+				return 0, err
+			}
+		}
+		total += 1
+	}
+	if err := iterator.Error(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (idx *indexer) GetWrapTokenRequests(filter *BridgeRequestFilter, skip, count int) ([]*definition.WrapTokenRequest, int, error) {
+	if !idx.config.Bridge {
+		return nil, 0, ErrIndexDisabled
+	}
+	if filter == nil {
+		filter = &BridgeRequestFilter{}
+	}
+	if err := filter.Validate(true); err != nil {
+		return nil, 0, err
+	}
+
+	list := make([]*definition.WrapTokenRequest, 0, count)
+	total, err := idx.getBridgeRequests(bridgeWrapKind, filter, skip, count, func(storageKey, value []byte) error {
+		request, err := definition.ParseWrapTokenRequest(value, storageKey)
+		if err != nil {
+			return err
+		}
+		list = append(list, request)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+func (idx *indexer) GetUnwrapTokenRequests(filter *BridgeRequestFilter, skip, count int) ([]*definition.UnwrapTokenRequest, int, error) {
+	if !idx.config.Bridge {
+		return nil, 0, ErrIndexDisabled
+	}
+	if filter == nil {
+		filter = &BridgeRequestFilter{}
+	}
+	if err := filter.Validate(false); err != nil {
+		return nil, 0, err
+	}
+
+	list := make([]*definition.UnwrapTokenRequest, 0, count)
+	total, err := idx.getBridgeRequests(bridgeUnwrapKind, filter, skip, count, func(storageKey, value []byte) error {
+		request, err := definition.ParseUnwrapTokenRequest(value, storageKey)
+		if err != nil {
+			return err
+		}
+		list = append(list, request)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+func (idx *indexer) GetBridgeTokenPairStats(networkClass, chainId uint32, zts types.ZenonTokenStandard) (*BridgeTokenPairStats, error) {
+	if !idx.config.Bridge {
+		return nil, ErrIndexDisabled
+	}
+	return getBridgeTokenPairStats(idx.ldb, bridgePairKey{networkClass: networkClass, chainId: chainId, tokenStandard: zts})
+}
@@ -0,0 +1,12 @@
+package indexer
+
+type Config struct {
+	// Transfers enables the per-address transfer history
+	Transfers bool
+	// Holders enables the per-token balances of the holders
+	Holders bool
+	// Bridge enables the bridge requests by token pair and status, and the per token pair totals
+	Bridge bool
+	// Htlcs enables the active htlcs by participant, hash lock and expiration time
+	Htlcs bool
+}
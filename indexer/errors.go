@@ -0,0 +1,14 @@
+package indexer
+
+import "github.com/pkg/errors"
+
+var (
+	ErrIndexDisabled        = errors.Errorf("index is not enabled by the node config")
+	ErrInvalidCursor        = errors.Errorf("invalid cursor")
+	ErrInvalidDirection     = errors.Errorf("invalid direction, expected 'in' or 'out'")
+	ErrInvalidRequestStatus = errors.Errorf("invalid request status")
+
+	ErrRebuildOnPrunedNode  = errors.Errorf("indexes can't be rebuilt on a pruned node, revert the indexer config or resync the node without pruning")
+	ErrMissingMomentumPatch = errors.Errorf("momentum patch is missing from the chain")
+	ErrInvalidIndexEntry    = errors.Errorf("invalid index entry")
+)
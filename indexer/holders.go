@@ -0,0 +1,243 @@
+package indexer
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	holderUndoEntrySize = types.AddressSize + types.ZenonTokenStandardSize + 32
+)
+
+type TokenHolder struct {
+	Address types.Address
+	Balance *big.Int
+}
+
+// TokenHolders are the number of addresses with a non-zero balance of a token and the sum of their balances
+type TokenHolders struct {
+	Count uint64
+	Total *big.Int
+}
+
+type holdersDelta struct {
+	count int64
+	total *big.Int
+}
+
+type holderKey struct {
+	address types.Address
+	zts     types.ZenonTokenStandard
+}
+
+func getHolderBalanceKey(zts types.ZenonTokenStandard, address types.Address) []byte {
+	return common.JoinBytes(holderBalanceKeyPrefix, zts.Bytes(), address.Bytes())
+}
+
+// getHolderRankKey sorts the holders of a token by balance, the balance is padded so the keys compare like the balances
+func getHolderRankKey(zts types.ZenonTokenStandard, balance *big.Int, address types.Address) []byte {
+	return common.JoinBytes(holderRankKeyPrefix, zts.Bytes(), common.BigIntToBytes(balance), address.Bytes())
+}
+func getTokenHoldersKey(zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(tokenHoldersKeyPrefix, zts.Bytes())
+}
+func getHolderUndoKey(height uint64) []byte {
+	return common.JoinBytes(holderUndoKeyPrefix, common.Uint64ToBytes(height))
+}
+
+// balanceChanges collects the account balances set by the patch of a momentum
+type balanceChanges map[holderKey]*big.Int
+
+func (bc balanceChanges) Put(key []byte, value []byte) {
+	if address, zts, ok := momentum.ParseAccountBalanceKey(key); ok {
+		bc[holderKey{address: address, zts: zts}] = common.BytesToBigInt(value)
+	}
+}
+func (bc balanceChanges) Delete(key []byte) {
+	if address, zts, ok := momentum.ParseAccountBalanceKey(key); ok {
+		bc[holderKey{address: address, zts: zts}] = big.NewInt(0)
+	}
+}
+
+// holdersWriter applies balance changes to the holder index of a batch and keeps the per token totals up to date
+type holdersWriter struct {
+	ldb    *leveldb.DB
+	batch  *leveldb.Batch
+	tokens map[types.ZenonTokenStandard]*holdersDelta
+}
+
+func (hw *holdersWriter) getBalance(key holderKey) (*big.Int, error) {
+	data, err := hw.ldb.Get(getHolderBalanceKey(key.zts, key.address), nil)
+	if err == leveldb.ErrNotFound {
+		return big.NewInt(0), nil
+	} else if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+func (hw *holdersWriter) setBalance(key holderKey, previous, current *big.Int) {
+	delta, ok := hw.tokens[key.zts]
+	if !ok {
+		delta = &holdersDelta{total: big.NewInt(0)}
+		hw.tokens[key.zts] = delta
+	}
+	if previous.Sign() != 0 {
+		hw.batch.Delete(getHolderRankKey(key.zts, previous, key.address))
+		delta.count -= 1
+	}
+	if current.Sign() != 0 {
+		hw.batch.Put(getHolderBalanceKey(key.zts, key.address), current.Bytes())
+		hw.batch.Put(getHolderRankKey(key.zts, current, key.address), []byte{})
+		delta.count += 1
+	} else {
+		hw.batch.Delete(getHolderBalanceKey(key.zts, key.address))
+	}
+	delta.total.Add(delta.total, current)
+	delta.total.Sub(delta.total, previous)
+}
+
+// flush adds the count and total changes of each token to the stored ones
+func (hw *holdersWriter) flush() error {
+	for zts, delta := range hw.tokens {
+		stats, err := getTokenHolders(hw.ldb, zts)
+		if err != nil {
+			return err
+		}
+		stats.Count = uint64(int64(stats.Count) + delta.count)
+		stats.Total.Add(stats.Total, delta.total)
+		if stats.Count == 0 {
+			hw.batch.Delete(getTokenHoldersKey(zts))
+		} else {
+			hw.batch.Put(getTokenHoldersKey(zts), common.JoinBytes(common.Uint64ToBytes(stats.Count), stats.Total.Bytes()))
+		}
+	}
+	return nil
+}
+
+// indexHolders updates the balances changed by the patch of the momentum.
+// The previous balances are saved for each momentum, so the changes can be reverted if the momentum is deleted.
+func (idx *indexer) indexHolders(batch *leveldb.Batch, detailed *nom.DetailedMomentum, rollback bool) error {
+	writer := &holdersWriter{
+		ldb:    idx.ldb,
+		batch:  batch,
+		tokens: make(map[types.ZenonTokenStandard]*holdersDelta),
+	}
+	undoKey := getHolderUndoKey(detailed.Momentum.Height)
+
+	if rollback {
+		undo, err := idx.ldb.Get(undoKey, nil)
+		if err == leveldb.ErrNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		for offset := 0; offset+holderUndoEntrySize <= len(undo); offset += holderUndoEntrySize {
+			key := holderKey{}
+			if err := key.address.SetBytes(undo[offset : offset+types.AddressSize]); err != nil {
+				return err
+			}
+			if err := key.zts.SetBytes(undo[offset+types.AddressSize : offset+types.AddressSize+types.ZenonTokenStandardSize]); err != nil {
+				return err
+			}
+			current, err := writer.getBalance(key)
+			if err != nil {
+				return err
+			}
+			writer.setBalance(key, current, common.BytesToBigInt(undo[offset+types.AddressSize+types.ZenonTokenStandardSize:offset+holderUndoEntrySize]))
+		}
+		batch.Delete(undoKey)
+		return writer.flush()
+	}
+
+	balances := make(balanceChanges)
+	patch := idx.chain.GetMomentumPatch(detailed.Momentum.Identifier())
+	if patch == nil {
+		return ErrMissingMomentumPatch
+	}
+	if err := patch.Replay(balances); err != nil {
+		return err
+	}
+
+	keys := make([]holderKey, 0, len(balances))
+	for key := range balances {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if c := bytes.Compare(keys[i].address.Bytes(), keys[j].address.Bytes()); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(keys[i].zts.Bytes(), keys[j].zts.Bytes()) < 0
+	})
+
+	undo := make([]byte, 0, len(keys)*holderUndoEntrySize)
+	for _, key := range keys {
+		previous, err := writer.getBalance(key)
+		if err != nil {
+			return err
+		}
+		if previous.Cmp(balances[key]) == 0 {
+			continue
+		}
+		undo = append(undo, common.JoinBytes(key.address.Bytes(), key.zts.Bytes(), common.BigIntToBytes(previous))...)
+		writer.setBalance(key, previous, balances[key])
+	}
+	if len(undo) != 0 {
+		batch.Put(undoKey, undo)
+	}
+	return writer.flush()
+}
+
+func getTokenHolders(ldb *leveldb.DB, zts types.ZenonTokenStandard) (*TokenHolders, error) {
+	data, err := ldb.Get(getTokenHoldersKey(zts), nil)
+	if err == leveldb.ErrNotFound {
+		return &TokenHolders{Total: big.NewInt(0)}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &TokenHolders{
+		Count: common.BytesToUint64(data[:8]),
+		Total: new(big.Int).SetBytes(data[8:]),
+	}, nil
+}
+
+func (idx *indexer) GetTokenHolders(zts types.ZenonTokenStandard) (*TokenHolders, error) {
+	if !idx.config.Holders {
+		return nil, ErrIndexDisabled
+	}
+	return getTokenHolders(idx.ldb, zts)
+}
+func (idx *indexer) GetTopTokenHolders(zts types.ZenonTokenStandard, skip, count int) ([]*TokenHolder, error) {
+	if !idx.config.Holders {
+		return nil, ErrIndexDisabled
+	}
+
+	prefix := common.JoinBytes(holderRankKeyPrefix, zts.Bytes())
+	iterator := idx.ldb.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iterator.Release()
+	list := make([]*TokenHolder, 0, count)
+	for ok := iterator.Last(); ok && len(list) < count; ok = iterator.Prev() {
+		if skip > 0 {
+			skip -= 1
+			continue
+		}
+		key := iterator.Key()[len(prefix):]
+		holder := &TokenHolder{Balance: new(big.Int).SetBytes(key[:32])}
+		if err := holder.Address.SetBytes(key[32:]); err != nil {
+			return nil, err
+		}
+		list = append(list, holder)
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
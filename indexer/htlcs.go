@@ -0,0 +1,107 @@
+package indexer
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+func getHtlcTimeLockedPrefix(address types.Address) []byte {
+	return common.JoinBytes(htlcTimeLockedKeyPrefix, address.Bytes())
+}
+func getHtlcHashLockedPrefix(address types.Address) []byte {
+	return common.JoinBytes(htlcHashLockedKeyPrefix, address.Bytes())
+}
+
+// getHtlcHashLockPrefix starts with the size of the hash lock, so a hash lock isn't a prefix of a longer one
+func getHtlcHashLockPrefix(hashLock []byte) []byte {
+	return common.JoinBytes(htlcHashLockKeyPrefix, []byte{byte(len(hashLock))}, hashLock)
+}
+
+// htlcIndex indexes the active htlcs by time locked address, hash locked address, hash lock and expiration time.
+// All the keys end with the expiration time and the storage key, so the htlcs which expire first come first.
+var htlcIndex = &storageIndex{
+	contract:    types.HtlcContract,
+	entryPrefix: htlcEntryKeyPrefix,
+	undoPrefix:  htlcUndoKeyPrefix,
+	isEntryKey:  definition.IsHtlcInfoKey,
+	indexKeys: func(storageKey, value []byte) ([][]byte, error) {
+		// the hash lock is unpacked in place, so the value is copied
+		htlc, err := definition.ParseHtlcInfo(storageKey, bytes.Clone(value))
+		if err != nil {
+			return nil, err
+		}
+		expiration := common.Uint64ToBytes(uint64(htlc.ExpirationTime))
+		return [][]byte{
+			common.JoinBytes(getHtlcTimeLockedPrefix(htlc.TimeLocked), expiration, storageKey),
+			common.JoinBytes(getHtlcHashLockedPrefix(htlc.HashLocked), expiration, storageKey),
+			common.JoinBytes(getHtlcHashLockPrefix(htlc.HashLock), expiration, storageKey),
+			common.JoinBytes(htlcExpirationKeyPrefix, expiration, storageKey),
+		}, nil
+	},
+}
+
+// getHtlcs returns up to count htlcs of the key range, after skipping the first skip ones, and the number of htlcs in the range
+func (idx *indexer) getHtlcs(prefix []byte, keyRange *util.Range, skip, count int) ([]*definition.HtlcInfo, int, error) {
+	iterator := idx.ldb.NewIterator(keyRange, nil)
+	defer iterator.Release()
+	list := make([]*definition.HtlcInfo, 0)
+	total := 0
+	for iterator.Next() {
+		if total >= skip && total-skip < count {
+			storageKey := iterator.Key()[len(prefix)+8:]
+			htlc, err := definition.ParseHtlcInfo(bytes.Clone(storageKey), bytes.Clone(iterator.Value()))
+			if err != nil {
+				return nil, 0, err
+			}
+			list = append(list, htlc)
+		}
+		total += 1
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (idx *indexer) GetHtlcsByTimeLocked(address types.Address, skip, count int) ([]*definition.HtlcInfo, int, error) {
+	if !idx.config.Htlcs {
+		return nil, 0, ErrIndexDisabled
+	}
+	prefix := getHtlcTimeLockedPrefix(address)
+	return idx.getHtlcs(prefix, util.BytesPrefix(prefix), skip, count)
+}
+func (idx *indexer) GetHtlcsByHashLocked(address types.Address, skip, count int) ([]*definition.HtlcInfo, int, error) {
+	if !idx.config.Htlcs {
+		return nil, 0, ErrIndexDisabled
+	}
+	prefix := getHtlcHashLockedPrefix(address)
+	return idx.getHtlcs(prefix, util.BytesPrefix(prefix), skip, count)
+}
+func (idx *indexer) GetHtlcsByHashLock(hashLock []byte) ([]*definition.HtlcInfo, error) {
+	if !idx.config.Htlcs {
+		return nil, ErrIndexDisabled
+	}
+	if len(hashLock) > math.MaxUint8 {
+		return make([]*definition.HtlcInfo, 0), nil
+	}
+	prefix := getHtlcHashLockPrefix(hashLock)
+	list, _, err := idx.getHtlcs(prefix, util.BytesPrefix(prefix), 0, math.MaxInt)
+	return list, err
+}
+func (idx *indexer) GetExpiringHtlcs(before int64, skip, count int) ([]*definition.HtlcInfo, int, error) {
+	if !idx.config.Htlcs {
+		return nil, 0, ErrIndexDisabled
+	}
+	if before <= 0 {
+		return make([]*definition.HtlcInfo, 0), 0, nil
+	}
+	keyRange := util.BytesPrefix(htlcExpirationKeyPrefix)
+	keyRange.Limit = common.JoinBytes(htlcExpirationKeyPrefix, common.Uint64ToBytes(uint64(before)))
+	return idx.getHtlcs(htlcExpirationKeyPrefix, keyRange, skip, count)
+}
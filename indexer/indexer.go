@@ -0,0 +1,321 @@
+package indexer
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+)
+
+const (
+	// logCatchUpEvery is the number of momentums indexed between two progress logs while catching up
+	logCatchUpEvery = 10000
+	// resetBatchSize is the number of keys deleted in a batch when the indexes are rebuilt
+	resetBatchSize = 10000
+	// retryInterval is the time waited before replaying the momentums missed after a failed write
+	retryInterval = 10 * time.Second
+)
+
+var (
+	indexedHeightKey        = []byte{0}
+	transferKeyPrefix       = []byte{1}
+	holderBalanceKeyPrefix  = []byte{2}
+	holderRankKeyPrefix     = []byte{3}
+	tokenHoldersKeyPrefix   = []byte{4}
+	holderUndoKeyPrefix     = []byte{5}
+	enabledIndexesKey       = []byte{6}
+	bridgeRequestKeyPrefix  = []byte{7}
+	bridgeHeightKeyPrefix   = []byte{8}
+	bridgePairKeyPrefix     = []byte{9}
+	bridgeStatusKeyPrefix   = []byte{10}
+	bridgeStatsKeyPrefix    = []byte{11}
+	bridgeUndoKeyPrefix     = []byte{12}
+	htlcEntryKeyPrefix      = []byte{13}
+	htlcTimeLockedKeyPrefix = []byte{14}
+	htlcHashLockedKeyPrefix = []byte{15}
+	htlcHashLockKeyPrefix   = []byte{16}
+	htlcExpirationKeyPrefix = []byte{17}
+	htlcUndoKeyPrefix       = []byte{18}
+)
+
+// indexer updates its indexes for every momentum inserted or deleted from the chain.
+// Each momentum is written in a single batch, together with the indexed height, so the indexes
+// always reflect a prefix of the chain. On start, the momentums missing from the indexes are replayed.
+// If a write fails, the indexes stop following the chain and the replay is retried in the background.
+type indexer struct {
+	log      common.Logger
+	changes  sync.Mutex
+	closed   chan struct{}
+	children sync.WaitGroup
+
+	config        *Config
+	chain         chain.Chain
+	ldb           *leveldb.DB
+	indexedHeight uint64
+	// rollbacks are the momentums deleted from the chain which are still in the indexes, highest first
+	rollbacks []*nom.DetailedMomentum
+	// err is the reason of the last failed write, nil once the indexes follow the chain again
+	err      error
+	retrying bool
+}
+
+func NewIndexer(config *Config, chain chain.Chain, ldb *leveldb.DB) Manager {
+	return &indexer{
+		log:    common.IndexerLogger,
+		config: config,
+		chain:  chain,
+		ldb:    ldb,
+	}
+}
+
+// enabledIndexes encodes the indexes enabled by the config, the database is rebuilt if they change
+func (idx *indexer) enabledIndexes() []byte {
+	enabled := byte(0)
+	if idx.config.Transfers {
+		enabled |= 1
+	}
+	if idx.config.Holders {
+		enabled |= 2
+	}
+	if idx.config.Bridge {
+		enabled |= 4
+	}
+	if idx.config.Htlcs {
+		enabled |= 8
+	}
+	return []byte{enabled}
+}
+
+func (idx *indexer) Init() error {
+	enabled, err := idx.ldb.Get(enabledIndexesKey, nil)
+	if err == leveldb.ErrNotFound {
+		enabled = nil
+	} else if err != nil {
+		return err
+	}
+	// indexes are rebuilt from genesis, which is no longer available once the chain is pruned
+	prunedHeight := idx.chain.GetPrunedHeight()
+	if !bytes.Equal(enabled, idx.enabledIndexes()) {
+		if prunedHeight != 0 {
+			return ErrRebuildOnPrunedNode
+		}
+		if enabled != nil {
+			idx.log.Info("enabled indexes changed, rebuilding indexes")
+		}
+		return idx.reset()
+	}
+
+	data, err := idx.ldb.Get(indexedHeightKey, nil)
+	if err == nil {
+		idx.indexedHeight = common.BytesToUint64(data)
+	} else if err != leveldb.ErrNotFound {
+		return err
+	}
+	if idx.indexedHeight < prunedHeight {
+		return ErrRebuildOnPrunedNode
+	}
+	return nil
+}
+
+// reset deletes all indexes, they are rebuilt from the chain on start
+func (idx *indexer) reset() error {
+	iterator := idx.ldb.NewIterator(nil, nil)
+	defer iterator.Release()
+	batch := new(leveldb.Batch)
+	for iterator.Next() {
+		batch.Delete(bytes.Clone(iterator.Key()))
+		if batch.Len() == resetBatchSize {
+			if err := idx.ldb.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+	batch.Put(enabledIndexesKey, idx.enabledIndexes())
+	idx.indexedHeight = 0
+	return idx.ldb.Write(batch, nil)
+}
+func (idx *indexer) Start() error {
+	idx.log.Info("starting ...", "transfers", idx.config.Transfers, "holders", idx.config.Holders, "bridge", idx.config.Bridge, "htlcs", idx.config.Htlcs, "indexed-height", idx.IndexedHeight())
+	defer idx.log.Info("started")
+
+	idx.closed = make(chan struct{})
+	// momentums inserted while catching up are skipped by the listener and replayed by catchUp
+	idx.chain.Register(idx)
+	return idx.catchUp()
+}
+func (idx *indexer) Stop() error {
+	idx.log.Info("stopping ...")
+	defer idx.log.Info("stopped")
+
+	idx.chain.UnRegister(idx)
+	close(idx.closed)
+	idx.children.Wait()
+	return idx.ldb.Close()
+}
+
+func (idx *indexer) IndexedHeight() uint64 {
+	idx.changes.Lock()
+	defer idx.changes.Unlock()
+	return idx.indexedHeight
+}
+
+func (idx *indexer) Err() error {
+	idx.changes.Lock()
+	defer idx.changes.Unlock()
+	return idx.err
+}
+
+func (idx *indexer) catchUp() error {
+	for {
+		idx.changes.Lock()
+		if err := idx.rollBack(); err != nil {
+			idx.changes.Unlock()
+			return err
+		}
+		momentumStore := idx.chain.GetFrontierMomentumStore()
+		frontier, err := momentumStore.GetFrontierMomentum()
+		if err != nil {
+			idx.changes.Unlock()
+			return err
+		}
+		if idx.indexedHeight >= frontier.Height {
+			idx.changes.Unlock()
+			return nil
+		}
+		momentum, err := momentumStore.GetMomentumByHeight(idx.indexedHeight + 1)
+		if err != nil {
+			idx.changes.Unlock()
+			return err
+		}
+		detailed, err := momentumStore.PrefetchMomentum(momentum)
+		if err != nil {
+			idx.changes.Unlock()
+			return err
+		}
+		err = idx.write(detailed, false)
+		idx.changes.Unlock()
+		if err != nil {
+			return err
+		}
+		if momentum.Height%logCatchUpEvery == 0 {
+			idx.log.Info("catching up", "indexed-height", momentum.Height, "frontier-height", frontier.Height)
+		}
+	}
+}
+
+// write adds the momentum to the indexes, or removes it if rollback is set
+func (idx *indexer) write(detailed *nom.DetailedMomentum, rollback bool) error {
+	batch := new(leveldb.Batch)
+	if idx.config.Transfers {
+		indexTransfers(batch, detailed, rollback)
+	}
+	if idx.config.Holders {
+		if err := idx.indexHolders(batch, detailed, rollback); err != nil {
+			return err
+		}
+	}
+	if idx.config.Bridge {
+		if err := idx.indexStorage(bridgeIndex, batch, detailed, rollback); err != nil {
+			return err
+		}
+	}
+	if idx.config.Htlcs {
+		if err := idx.indexStorage(htlcIndex, batch, detailed, rollback); err != nil {
+			return err
+		}
+	}
+
+	height := detailed.Momentum.Height
+	if rollback {
+		height -= 1
+	}
+	batch.Put(indexedHeightKey, common.Uint64ToBytes(height))
+	if err := idx.ldb.Write(batch, nil); err != nil {
+		return err
+	}
+	idx.indexedHeight = height
+	return nil
+}
+
+// rollBack removes the pending rollbacks from the indexes
+func (idx *indexer) rollBack() error {
+	for len(idx.rollbacks) != 0 {
+		if err := idx.write(idx.rollbacks[0], true); err != nil {
+			return err
+		}
+		idx.rollbacks = idx.rollbacks[1:]
+	}
+	return nil
+}
+
+// stall records the failed write and schedules a catch up, the momentums inserted until then are skipped
+func (idx *indexer) stall(err error) {
+	idx.err = err
+	if idx.retrying {
+		return
+	}
+	idx.retrying = true
+	idx.children.Add(1)
+	go idx.retry()
+}
+
+func (idx *indexer) retry() {
+	defer idx.children.Done()
+	defer common.RecoverStack()
+
+	for {
+		select {
+		case <-idx.closed:
+			return
+		case <-time.After(retryInterval):
+		}
+
+		err := idx.catchUp()
+		idx.changes.Lock()
+		idx.err = err
+		if err == nil {
+			idx.retrying = false
+			idx.changes.Unlock()
+			idx.log.Info("indexes caught up", "indexed-height", idx.IndexedHeight())
+			return
+		}
+		idx.changes.Unlock()
+		idx.log.Error("failed to catch up", "reason", err)
+	}
+}
+
+func (idx *indexer) InsertMomentum(detailed *nom.DetailedMomentum) {
+	idx.changes.Lock()
+	defer idx.changes.Unlock()
+
+	if len(idx.rollbacks) != 0 || detailed.Momentum.Height != idx.indexedHeight+1 {
+		return
+	}
+	if err := idx.write(detailed, false); err != nil {
+		idx.log.Error("failed to index momentum", "reason", err, "identifier", detailed.Momentum.Identifier())
+		idx.stall(err)
+	}
+}
+func (idx *indexer) DeleteMomentum(detailed *nom.DetailedMomentum) {
+	idx.changes.Lock()
+	defer idx.changes.Unlock()
+
+	if detailed.Momentum.Height > idx.indexedHeight {
+		return
+	}
+	// the momentum can't be read from the chain anymore, so it's kept until it's removed from the indexes
+	idx.rollbacks = append(idx.rollbacks, detailed)
+	if err := idx.rollBack(); err != nil {
+		idx.log.Error("failed to remove momentum from indexes", "reason", err, "identifier", detailed.Momentum.Identifier())
+		idx.stall(err)
+	}
+}
@@ -0,0 +1,47 @@
+package indexer
+
+import (
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+// Manager maintains optional secondary indexes over the confirmed account-blocks.
+// The indexes are kept in a separate database and aren't part of the consensus state.
+type Manager interface {
+	Init() error
+	Start() error
+	Stop() error
+
+	// IndexedHeight is the height of the last momentum included in the indexes
+	IndexedHeight() uint64
+	// Err returns the reason the indexes stopped following the chain, nil while they are up to date.
+	// The missed momentums are replayed in the background until the indexes catch up.
+	Err() error
+
+	// GetTransfers returns up to count transfers of address which match the filter, newest first.
+	// The cursor of a page is the Cursor of its last transfer, nil for the first page.
+	GetTransfers(address types.Address, filter *TransferFilter, cursor []byte, count int) ([]*Transfer, error)
+
+	// GetTokenHolders returns the number of holders of a token and their total balance
+	GetTokenHolders(zts types.ZenonTokenStandard) (*TokenHolders, error)
+	// GetTopTokenHolders returns up to count holders of a token sorted by balance, largest first, after skipping the first skip holders
+	GetTopTokenHolders(zts types.ZenonTokenStandard, skip, count int) ([]*TokenHolder, error)
+
+	// GetWrapTokenRequests returns up to count wrap requests which match the filter, newest first, after skipping the first skip ones.
+	// The number of matching requests is returned as well.
+	GetWrapTokenRequests(filter *BridgeRequestFilter, skip, count int) ([]*definition.WrapTokenRequest, int, error)
+	// GetUnwrapTokenRequests is GetWrapTokenRequests for unwrap requests
+	GetUnwrapTokenRequests(filter *BridgeRequestFilter, skip, count int) ([]*definition.UnwrapTokenRequest, int, error)
+	// GetBridgeTokenPairStats returns the totals of the requests of a token pair
+	GetBridgeTokenPairStats(networkClass, chainId uint32, zts types.ZenonTokenStandard) (*BridgeTokenPairStats, error)
+
+	// GetHtlcsByTimeLocked returns up to count active htlcs created by address, the ones which expire first first,
+	// after skipping the first skip ones. The number of active htlcs created by address is returned as well.
+	GetHtlcsByTimeLocked(address types.Address, skip, count int) ([]*definition.HtlcInfo, int, error)
+	// GetHtlcsByHashLocked is GetHtlcsByTimeLocked for the htlcs which address can unlock
+	GetHtlcsByHashLocked(address types.Address, skip, count int) ([]*definition.HtlcInfo, int, error)
+	// GetHtlcsByHashLock returns all active htlcs locked with hashLock, the ones which expire first first
+	GetHtlcsByHashLock(hashLock []byte) ([]*definition.HtlcInfo, error)
+	// GetExpiringHtlcs is GetHtlcsByTimeLocked for the htlcs which expire before the unix timestamp
+	GetExpiringHtlcs(before int64, skip, count int) ([]*definition.HtlcInfo, int, error)
+}
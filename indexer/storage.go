@@ -0,0 +1,246 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// storageIndex maintains secondary indexes over the storage entries of an embedded contract, read from the momentum patches.
+// The last value of each entry is kept, so its index keys can be removed when it changes, and the previous values
+// are saved for each momentum, so the changes can be reverted if the momentum is deleted.
+type storageIndex struct {
+	contract    types.Address
+	entryPrefix []byte
+	undoPrefix  []byte
+	// isEntryKey selects the storage keys of the indexed entries
+	isEntryKey func(storageKey []byte) bool
+	// indexKeys returns the index keys of an entry, the value of the entry is stored under each of them
+	indexKeys func(storageKey, value []byte) ([][]byte, error)
+	// newTotals is optional, it returns the totals kept up to date with the entries changed by a momentum
+	newTotals func(ldb *leveldb.DB) storageTotals
+}
+
+type storageTotals interface {
+	// apply adds the entry to the totals, or removes it if sign is negative
+	apply(storageKey, value []byte, sign int64) error
+	// flush writes the updated totals to the batch
+	flush(batch *leveldb.Batch)
+}
+
+func (si *storageIndex) getEntryKey(storageKey []byte) []byte {
+	return common.JoinBytes(si.entryPrefix, storageKey)
+}
+func (si *storageIndex) getUndoKey(height uint64) []byte {
+	return common.JoinBytes(si.undoPrefix, common.Uint64ToBytes(height))
+}
+
+// storageChanges collects the entries set by the patch of a momentum, deleted entries are nil
+type storageChanges struct {
+	index   *storageIndex
+	entries map[string][]byte
+}
+
+func (sc *storageChanges) parseKey(key []byte) ([]byte, bool) {
+	address, storageKey, ok := momentum.ParseAccountStorageKey(key)
+	if !ok || address != sc.index.contract || !sc.index.isEntryKey(storageKey) {
+		return nil, false
+	}
+	return storageKey, true
+}
+func (sc *storageChanges) Put(key []byte, value []byte) {
+	if storageKey, ok := sc.parseKey(key); ok {
+		if len(value) == 0 {
+			sc.entries[string(storageKey)] = nil
+		} else {
+			sc.entries[string(storageKey)] = bytes.Clone(value)
+		}
+	}
+}
+func (sc *storageChanges) Delete(key []byte) {
+	if storageKey, ok := sc.parseKey(key); ok {
+		sc.entries[string(storageKey)] = nil
+	}
+}
+
+// storageWriter applies entry changes to the indexes of a batch
+type storageWriter struct {
+	index  *storageIndex
+	ldb    *leveldb.DB
+	batch  *leveldb.Batch
+	totals storageTotals
+}
+
+func (sw *storageWriter) getEntry(storageKey []byte) ([]byte, error) {
+	data, err := sw.ldb.Get(sw.index.getEntryKey(storageKey), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return data, err
+}
+
+// setEntry replaces the previous value of an entry with the current one, nil meaning the entry doesn't exist
+func (sw *storageWriter) setEntry(storageKey, previous, current []byte) error {
+	if previous != nil {
+		keys, err := sw.index.indexKeys(storageKey, previous)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			sw.batch.Delete(key)
+		}
+		if sw.totals != nil {
+			if err := sw.totals.apply(storageKey, previous, -1); err != nil {
+				return err
+			}
+		}
+	}
+	if current == nil {
+		sw.batch.Delete(sw.index.getEntryKey(storageKey))
+		return nil
+	}
+
+	keys, err := sw.index.indexKeys(storageKey, current)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		sw.batch.Put(key, current)
+	}
+	if sw.totals != nil {
+		if err := sw.totals.apply(storageKey, current, 1); err != nil {
+			return err
+		}
+	}
+	sw.batch.Put(sw.index.getEntryKey(storageKey), current)
+	return nil
+}
+func (sw *storageWriter) flush() {
+	if sw.totals != nil {
+		sw.totals.flush(sw.batch)
+	}
+}
+
+// marshalStorageUndo appends the previous value of an entry to the undo of a momentum, as
+// the length of the storage key, the storage key, the length of the value and the value
+func marshalStorageUndo(undo []byte, storageKey, previous []byte) []byte {
+	return append(undo, common.JoinBytes(
+		common.Uint32ToBytes(uint32(len(storageKey))), storageKey,
+		common.Uint32ToBytes(uint32(len(previous))), previous,
+	)...)
+}
+
+// unmarshalStorageUndo returns the storage keys and the previous values saved by marshalStorageUndo
+func unmarshalStorageUndo(undo []byte) ([][]byte, [][]byte, error) {
+	keys := make([][]byte, 0)
+	values := make([][]byte, 0)
+	for offset := 0; offset < len(undo); {
+		if offset+4 > len(undo) {
+			return nil, nil, ErrInvalidIndexEntry
+		}
+		keySize := int(binary.BigEndian.Uint32(undo[offset:]))
+		offset += 4
+		if offset+keySize+4 > len(undo) {
+			return nil, nil, ErrInvalidIndexEntry
+		}
+		keys = append(keys, undo[offset:offset+keySize])
+		offset += keySize
+		valueSize := int(binary.BigEndian.Uint32(undo[offset:]))
+		offset += 4
+		if offset+valueSize > len(undo) {
+			return nil, nil, ErrInvalidIndexEntry
+		}
+		if valueSize == 0 {
+			values = append(values, nil)
+		} else {
+			values = append(values, undo[offset:offset+valueSize])
+		}
+		offset += valueSize
+	}
+	return keys, values, nil
+}
+
+// indexStorage updates the entries of the index changed by the patch of the momentum, or reverts them if rollback is set
+func (idx *indexer) indexStorage(index *storageIndex, batch *leveldb.Batch, detailed *nom.DetailedMomentum, rollback bool) error {
+	writer := &storageWriter{
+		index: index,
+		ldb:   idx.ldb,
+		batch: batch,
+	}
+	if index.newTotals != nil {
+		writer.totals = index.newTotals(idx.ldb)
+	}
+	undoKey := index.getUndoKey(detailed.Momentum.Height)
+
+	if rollback {
+		undo, err := idx.ldb.Get(undoKey, nil)
+		if err == leveldb.ErrNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		keys, values, err := unmarshalStorageUndo(undo)
+		if err != nil {
+			return err
+		}
+		for i := range keys {
+			current, err := writer.getEntry(keys[i])
+			if err != nil {
+				return err
+			}
+			if err := writer.setEntry(keys[i], current, values[i]); err != nil {
+				return err
+			}
+		}
+		batch.Delete(undoKey)
+		writer.flush()
+		return nil
+	}
+
+	changes := &storageChanges{
+		index:   index,
+		entries: make(map[string][]byte),
+	}
+	patch := idx.chain.GetMomentumPatch(detailed.Momentum.Identifier())
+	if patch == nil {
+		return ErrMissingMomentumPatch
+	}
+	if err := patch.Replay(changes); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(changes.entries))
+	for key := range changes.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	undo := make([]byte, 0)
+	for _, key := range keys {
+		storageKey := []byte(key)
+		previous, err := writer.getEntry(storageKey)
+		if err != nil {
+			return err
+		}
+		current := changes.entries[key]
+		if bytes.Equal(previous, current) {
+			continue
+		}
+		undo = marshalStorageUndo(undo, storageKey, previous)
+		if err := writer.setEntry(storageKey, previous, current); err != nil {
+			return err
+		}
+	}
+	if len(undo) != 0 {
+		batch.Put(undoKey, undo)
+	}
+	writer.flush()
+	return nil
+}
@@ -0,0 +1,187 @@
+package indexer
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+
+	directionOutByte byte = 0
+	directionInByte  byte = 1
+
+	// a cursor is the transfer key without the prefix and the address: momentum height, index in momentum and direction
+	transferCursorSize = 8 + 4 + 1
+)
+
+// Transfer is a send-block with a non-zero amount, seen from one of its two sides.
+// Contract send-blocks, like the payouts of embedded contracts, are transfers as well.
+type Transfer struct {
+	Address           types.Address
+	Direction         string
+	Counterparty      types.Address
+	BlockHash         types.Hash
+	TokenStandard     types.ZenonTokenStandard
+	Amount            *big.Int
+	MomentumHeight    uint64
+	MomentumTimestamp int64
+	Cursor            []byte
+}
+
+// TransferFilter restricts the returned transfers, zero values match everything.
+// Heights and timestamps are inclusive.
+type TransferFilter struct {
+	TokenStandard *types.ZenonTokenStandard `json:"tokenStandard"`
+	Direction     string                    `json:"direction"`
+	Counterparty  *types.Address            `json:"counterparty"`
+	FromHeight    uint64                    `json:"fromHeight"`
+	ToHeight      uint64                    `json:"toHeight"`
+	FromTime      int64                     `json:"fromTime"`
+	ToTime        int64                     `json:"toTime"`
+}
+
+func (f *TransferFilter) Validate() error {
+	if f.Direction != "" && f.Direction != DirectionIn && f.Direction != DirectionOut {
+		return ErrInvalidDirection
+	}
+	return nil
+}
+func (f *TransferFilter) matches(transfer *Transfer) bool {
+	if f.TokenStandard != nil && *f.TokenStandard != transfer.TokenStandard {
+		return false
+	}
+	if f.Direction != "" && f.Direction != transfer.Direction {
+		return false
+	}
+	if f.Counterparty != nil && *f.Counterparty != transfer.Counterparty {
+		return false
+	}
+	if f.ToTime != 0 && transfer.MomentumTimestamp > f.ToTime {
+		return false
+	}
+	return true
+}
+
+func getTransferKey(address types.Address, height uint64, index uint32, direction byte) []byte {
+	return common.JoinBytes(transferKeyPrefix, address.Bytes(), common.Uint64ToBytes(height), common.Uint32ToBytes(index), []byte{direction})
+}
+
+func indexTransfers(batch *leveldb.Batch, detailed *nom.DetailedMomentum, rollback bool) {
+	momentum := detailed.Momentum
+	for index, block := range detailed.AccountBlocks {
+		if !block.IsSendBlock() || block.Amount == nil || block.Amount.Sign() == 0 {
+			continue
+		}
+		outKey := getTransferKey(block.Address, momentum.Height, uint32(index), directionOutByte)
+		inKey := getTransferKey(block.ToAddress, momentum.Height, uint32(index), directionInByte)
+		if rollback {
+			batch.Delete(outKey)
+			batch.Delete(inKey)
+			continue
+		}
+		batch.Put(outKey, marshalTransferValue(block, block.ToAddress, momentum))
+		batch.Put(inKey, marshalTransferValue(block, block.Address, momentum))
+	}
+}
+
+func marshalTransferValue(block *nom.AccountBlock, counterparty types.Address, momentum *nom.Momentum) []byte {
+	return common.JoinBytes(
+		block.Hash.Bytes(),
+		counterparty.Bytes(),
+		block.TokenStandard.Bytes(),
+		common.Uint64ToBytes(uint64(momentum.Timestamp.Unix())),
+		block.Amount.Bytes(),
+	)
+}
+func unmarshalTransfer(key, value []byte) (*Transfer, error) {
+	transfer := new(Transfer)
+	offset := len(transferKeyPrefix)
+	if err := transfer.Address.SetBytes(key[offset : offset+types.AddressSize]); err != nil {
+		return nil, err
+	}
+	offset += types.AddressSize
+	transfer.Cursor = bytes.Clone(key[offset:])
+	transfer.MomentumHeight = common.BytesToUint64(key[offset : offset+8])
+	transfer.Direction = DirectionOut
+	if key[len(key)-1] == directionInByte {
+		transfer.Direction = DirectionIn
+	}
+
+	offset = 0
+	if err := transfer.BlockHash.SetBytes(value[offset : offset+types.HashSize]); err != nil {
+		return nil, err
+	}
+	offset += types.HashSize
+	if err := transfer.Counterparty.SetBytes(value[offset : offset+types.AddressSize]); err != nil {
+		return nil, err
+	}
+	offset += types.AddressSize
+	if err := transfer.TokenStandard.SetBytes(value[offset : offset+types.ZenonTokenStandardSize]); err != nil {
+		return nil, err
+	}
+	offset += types.ZenonTokenStandardSize
+	transfer.MomentumTimestamp = int64(common.BytesToUint64(value[offset : offset+8]))
+	transfer.Amount = new(big.Int).SetBytes(value[offset+8:])
+	return transfer, nil
+}
+
+func (idx *indexer) GetTransfers(address types.Address, filter *TransferFilter, cursor []byte, count int) ([]*Transfer, error) {
+	if !idx.config.Transfers {
+		return nil, ErrIndexDisabled
+	}
+	if filter == nil {
+		filter = &TransferFilter{}
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	prefix := common.JoinBytes(transferKeyPrefix, address.Bytes())
+	keyRange := util.BytesPrefix(prefix)
+	if filter.FromHeight != 0 {
+		keyRange.Start = common.JoinBytes(prefix, common.Uint64ToBytes(filter.FromHeight))
+	}
+	if filter.ToHeight != 0 && filter.ToHeight != math.MaxUint64 {
+		keyRange.Limit = common.JoinBytes(prefix, common.Uint64ToBytes(filter.ToHeight+1))
+	}
+	// transfers are returned newest first, so the next page ends right before the cursor
+	if cursor != nil {
+		if len(cursor) != transferCursorSize {
+			return nil, ErrInvalidCursor
+		}
+		if limit := common.JoinBytes(prefix, cursor); bytes.Compare(limit, keyRange.Limit) < 0 {
+			keyRange.Limit = limit
+		}
+	}
+
+	iterator := idx.ldb.NewIterator(keyRange, nil)
+	defer iterator.Release()
+	list := make([]*Transfer, 0, count)
+	for ok := iterator.Last(); ok && len(list) < count; ok = iterator.Prev() {
+		transfer, err := unmarshalTransfer(iterator.Key(), iterator.Value())
+		if err != nil {
+			return nil, err
+		}
+		// timestamps don't decrease with the height, so there are no older matches
+		if filter.FromTime != 0 && transfer.MomentumTimestamp < filter.FromTime {
+			break
+		}
+		if filter.matches(transfer) {
+			list = append(list, transfer)
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
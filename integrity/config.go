@@ -0,0 +1,43 @@
+package integrity
+
+const (
+	DefaultSampleCount = 16
+	DefaultSampleSize  = 100
+)
+
+type Config struct {
+	// From is the first verified momentum, 0 for the genesis or the first verifiable momentum of a pruned node
+	From uint64
+	// To is the last verified momentum, 0 for the frontier momentum
+	To uint64
+
+	// Fast verifies SampleCount ranges of SampleSize momentums spread over the momentum range, instead of all of them.
+	// The last range always ends at To, since that's where a crash leaves the db inconsistent.
+	Fast        bool
+	SampleCount uint64
+	SampleSize  uint64
+}
+
+func (c *Config) setDefaults() {
+	if c.SampleCount == 0 {
+		c.SampleCount = DefaultSampleCount
+	}
+	if c.SampleSize == 0 {
+		c.SampleSize = DefaultSampleSize
+	}
+}
+
+// ranges returns the inclusive ranges of momentums between from and to which are verified
+func (c *Config) ranges(from, to uint64) [][2]uint64 {
+	total := to - from + 1
+	if !c.Fast || total <= c.SampleCount*c.SampleSize {
+		return [][2]uint64{{from, to}}
+	}
+	step := total / c.SampleCount
+	ranges := make([][2]uint64, 0, c.SampleCount)
+	for i := uint64(0); i+1 < c.SampleCount; i += 1 {
+		start := from + i*step
+		ranges = append(ranges, [2]uint64{start, start + c.SampleSize - 1})
+	}
+	return append(ranges, [2]uint64{to - c.SampleSize + 1, to})
+}
@@ -0,0 +1,22 @@
+package integrity
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidRange     = errors.Errorf("invalid momentum range")
+	ErrStateUnavailable = errors.Errorf("state before the momentum range is not available, it is pruned")
+
+	ErrGenesisMismatch            = errors.Errorf("genesis momentum doesn't match the configured genesis")
+	ErrMomentumMissing            = errors.Errorf("momentum is missing")
+	ErrAccountBlockMissing        = errors.Errorf("account-block of momentum content is missing")
+	ErrAccountBlockHashInvalid    = errors.Errorf("account-block hash is invalid")
+	ErrPatchMissing               = errors.Errorf("momentum patch is missing")
+	ErrPatchInvalid               = errors.Errorf("momentum patch is invalid")
+	ErrRollbackMissing            = errors.Errorf("momentum rollback is missing")
+	ErrRollbackInvalid            = errors.Errorf("momentum rollback is invalid")
+	ErrFrontierMismatch           = errors.Errorf("momentum frontier doesn't match after applying the patch")
+	ErrConfirmationHeightMismatch = errors.Errorf("account-block confirmation height mismatch")
+	ErrAccountFrontierMismatch    = errors.Errorf("account frontier doesn't match momentum content")
+	ErrMailboxMismatch            = errors.Errorf("mailbox entry doesn't match the receive-block")
+	ErrSequencerMismatch          = errors.Errorf("sequencer entries don't match the send-blocks")
+)
@@ -0,0 +1,34 @@
+package integrity
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// Repair pops the momentums from the frontier down to the last consistent momentum before the divergence and returns
+// its identifier. The momentums are popped from the db manager since their content may be unreadable, so the chain
+// must not have any listener which depends on deleted momentums, i.e. the node must not be running.
+func Repair(chainManager db.Manager, genesis store.Genesis, divergence *Divergence) (*types.HashHeight, error) {
+	if divergence.Momentum.Height <= 1 {
+		return nil, errors.Errorf("can't repair the db, the genesis momentum is inconsistent")
+	}
+	// like the online rollback, the momentums aren't rolled back to a pruned momentum
+	height := divergence.Momentum.Height - 1
+	if height <= chainManager.PrunedHeight() {
+		return nil, errors.Errorf("can't repair the db, momentums up to height %v are pruned", chainManager.PrunedHeight())
+	}
+
+	for {
+		frontier := momentum.NewStore(genesis, chainManager.Frontier()).Identifier()
+		if frontier.Height <= height {
+			return &frontier, nil
+		}
+		if err := chainManager.Pop(); err != nil {
+			return nil, errors.Errorf("can't repair the db, rollback of %v failed: %v", frontier, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package integrity
+
+import (
+	"fmt"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// confirmedBlocks returns the account-blocks confirmed by the momentum, in the order in which they are confirmed
+func confirmedBlocks(detailed *nom.DetailedMomentum) []*nom.AccountBlock {
+	blocks := make([]*nom.AccountBlock, 0, len(detailed.AccountBlocks))
+	for _, block := range detailed.AccountBlocks {
+		blocks = append(blocks, block)
+		blocks = append(blocks, block.DescendantBlocks...)
+	}
+	return blocks
+}
+
+// getSequencerSizes returns the size of the sequencers of the embedded contracts which receive send-blocks in the momentum
+func getSequencerSizes(previous store.Momentum, detailed *nom.DetailedMomentum) map[types.Address]uint64 {
+	sizes := make(map[types.Address]uint64)
+	for _, block := range confirmedBlocks(detailed) {
+		if block.IsSendBlock() && types.IsEmbeddedAddress(block.ToAddress) {
+			if _, ok := sizes[block.ToAddress]; !ok {
+				sizes[block.ToAddress] = previous.GetAccountMailbox(block.ToAddress).SequencerSize()
+			}
+		}
+	}
+	return sizes
+}
+
+// checkState checks that ms, the state after the momentum, is consistent with the account-blocks of the momentum.
+// Returns the reason of the divergence, if any.
+func checkState(ms store.Momentum, detailed *nom.DetailedMomentum, sequencers map[types.Address]uint64) (reason error, err error) {
+	height := detailed.Momentum.Height
+	heads := make(map[types.Address]types.HashHeight)
+	sends := make(map[types.Address][]types.AccountHeader)
+
+	for _, block := range confirmedBlocks(detailed) {
+		if block.ComputeHash() != block.Hash {
+			return fmt.Errorf("%w - account-block %v", ErrAccountBlockHashInvalid, block.Header()), nil
+		}
+		if head, ok := heads[block.Address]; !ok || head.Height < block.Height {
+			heads[block.Address] = block.Identifier()
+		}
+
+		if confirmed, err := ms.GetBlockConfirmationHeight(block.Hash); err != nil {
+			return nil, err
+		} else if confirmed != height {
+			return fmt.Errorf("%w - account-block %v expected %v but got %v", ErrConfirmationHeightMismatch, block.Header(), height, confirmed), nil
+		}
+
+		if block.IsSendBlock() {
+			if types.IsEmbeddedAddress(block.ToAddress) {
+				sends[block.ToAddress] = append(sends[block.ToAddress], block.Header())
+			}
+		} else if block.BlockType != nom.BlockTypeGenesisReceive {
+			send, err := ms.GetAccountBlockByHash(block.FromBlockHash)
+			if err == store.ErrDataPruned || (err == nil && send == nil) {
+				return fmt.Errorf("%w - can't find send-block %v of %v", ErrMailboxMismatch, block.FromBlockHash, block.Header()), nil
+			} else if err != nil {
+				return nil, err
+			}
+			if confirmed, err := ms.GetBlockConfirmationHeight(send.Hash); err != nil {
+				return nil, err
+			} else if confirmed == 0 || confirmed > height {
+				return fmt.Errorf("%w - send-block %v is confirmed at %v after the receive-block", ErrConfirmationHeightMismatch, send.Header(), confirmed), nil
+			}
+			receive := ms.GetAccountMailbox(send.Address).GetBlockWhichReceives(send.Hash)
+			if receive == nil || *receive != block.Header() {
+				return fmt.Errorf("%w - send-block %v expected to be received by %v", ErrMailboxMismatch, send.Header(), block.Header()), nil
+			}
+		}
+	}
+
+	for address, head := range heads {
+		if frontier := ms.GetAccountStore(address).Identifier(); frontier != head {
+			return fmt.Errorf("%w - account %v expected %v but got %v", ErrAccountFrontierMismatch, address, head, frontier), nil
+		}
+	}
+
+	for address, headers := range sends {
+		mailbox := ms.GetAccountMailbox(address)
+		previous := sequencers[address]
+		if size := mailbox.SequencerSize(); size != previous+uint64(len(headers)) {
+			return fmt.Errorf("%w - contract %v expected %v entries but got %v", ErrSequencerMismatch, address, previous+uint64(len(headers)), size), nil
+		}
+		for i, header := range headers {
+			if entry := mailbox.SequencerByHeight(previous + uint64(i) + 1); entry == nil || *entry != header {
+				return fmt.Errorf("%w - contract %v expected %v at %v", ErrSequencerMismatch, address, header, previous+uint64(i)+1), nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,245 @@
+package integrity
+
+import (
+	"fmt"
+
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/verifier"
+)
+
+// Progress is called after the momentum at height was verified, out of the last height to verify
+type Progress func(height, last uint64)
+
+// Divergence is the first momentum at which the db is inconsistent
+type Divergence struct {
+	Momentum types.HashHeight
+	Reason   error
+}
+
+type Report struct {
+	From uint64
+	To   uint64
+	// Verified is the number of verified momentums, which is smaller than the range in fast mode or on divergence
+	Verified   uint64
+	Divergence *Divergence
+}
+
+type integrityVerifier struct {
+	chainManager db.Manager
+	genesis      store.Genesis
+	verifier     verifier.MomentumVerifier
+	frontier     store.Momentum
+	report       *Report
+	progress     Progress
+}
+
+// Verify walks the momentums of the chain db and stops at the first divergence.
+//
+// The state before the first verified momentum is rebuilt by applying the rollbacks of the momentums on top of the
+// frontier state, and the stored patches of the following momentums are applied on top of it in order. Every verified
+// momentum is checked with the rules of the verifier, with the changes recomputed from its stored patch, against the
+// state of its previous momentum. After applying its patch, the state must have the momentum as frontier, confirm the
+// account-blocks of its content and have the account frontiers, mailboxes and sequencers updated accordingly. The last
+// momentum of the chain is checked against the frontier state of the db instead.
+//
+// Rebuilding the state keeps the changes from the first verified momentum to the frontier in memory, so old momentums
+// are best verified in ranges.
+func Verify(chainManager db.Manager, genesis store.Genesis, momentumVerifier verifier.MomentumVerifier, config *Config, progress Progress) (*Report, error) {
+	config.setDefaults()
+	frontier := momentum.NewStore(genesis, chainManager.Frontier())
+	frontierHeight := frontier.Identifier().Height
+
+	from := config.From
+	if from == 0 {
+		from = 1
+		if pruned := chainManager.PrunedHeight(); pruned != 0 {
+			from = pruned + 2
+		}
+	}
+	to := config.To
+	if to == 0 {
+		to = frontierHeight
+	}
+	if from > to || to > frontierHeight {
+		return nil, ErrInvalidRange
+	}
+
+	v := &integrityVerifier{
+		chainManager: chainManager,
+		genesis:      genesis,
+		verifier:     momentumVerifier,
+		frontier:     frontier,
+		report:       &Report{From: from, To: to},
+		progress:     progress,
+	}
+	if err := v.verify(config.ranges(from, to)); err != nil {
+		return nil, err
+	}
+	return v.report, nil
+}
+
+func (v *integrityVerifier) diverge(identifier types.HashHeight, reason error) {
+	v.report.Divergence = &Divergence{
+		Momentum: identifier,
+		Reason:   reason,
+	}
+}
+
+func (v *integrityVerifier) verify(ranges [][2]uint64) error {
+	from := ranges[0][0]
+	if from == 1 {
+		genesis, err := v.frontier.GetMomentumByHeight(1)
+		if err != nil {
+			return err
+		}
+		if genesis == nil || genesis.Hash != v.genesis.GetGenesisMomentum().Hash {
+			v.diverge(types.HashHeight{Height: 1}, ErrGenesisMismatch)
+			return nil
+		}
+		v.report.Verified += 1
+		from = 2
+	}
+	if from > v.report.To {
+		return nil
+	}
+	if from-1 <= v.chainManager.PrunedHeight() {
+		return ErrStateUnavailable
+	}
+
+	state, err := v.rollbackTo(from - 1)
+	if err != nil || state == nil {
+		return err
+	}
+
+	current := 0
+	for height := from; height <= v.report.To; height += 1 {
+		for current < len(ranges) && ranges[current][1] < height {
+			current += 1
+		}
+		m, err := v.frontier.GetMomentumByHeight(height)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			v.diverge(types.HashHeight{Height: height}, ErrMomentumMissing)
+			return nil
+		}
+
+		var reason error
+		if ranges[current][0] <= height {
+			reason, err = v.verifyMomentum(state, m)
+		} else {
+			reason, err = v.applyMomentum(state, m)
+		}
+		if err != nil {
+			return err
+		} else if reason != nil {
+			v.diverge(m.Identifier(), reason)
+			return nil
+		}
+
+		if ranges[current][0] <= height {
+			v.report.Verified += 1
+			if v.progress != nil {
+				v.progress(height, v.report.To)
+			}
+		}
+	}
+	return nil
+}
+
+// rollbackTo returns the state at height, rebuilt from the frontier state and the rollbacks of the momentums after it.
+// Returns nil on divergence.
+func (v *integrityVerifier) rollbackTo(height uint64) (db.DB, error) {
+	state := v.chainManager.Frontier().Snapshot()
+	for current := v.frontier.Identifier().Height; current > height; current -= 1 {
+		m, err := v.frontier.GetMomentumByHeight(current)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			v.diverge(types.HashHeight{Height: current}, ErrMomentumMissing)
+			return nil, nil
+		}
+		rollback := v.chainManager.GetRollback(m.Identifier())
+		if rollback == nil {
+			v.diverge(m.Identifier(), ErrRollbackMissing)
+			return nil, nil
+		}
+		if err := state.Apply(rollback); err != nil {
+			v.diverge(m.Identifier(), fmt.Errorf("%w - %v", ErrRollbackInvalid, err))
+			return nil, nil
+		}
+	}
+
+	previous, err := v.frontier.GetMomentumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if rolledBack := momentum.NewStore(v.genesis, state).Identifier(); previous == nil || rolledBack != previous.Identifier() {
+		v.diverge(types.HashHeight{Height: height}, fmt.Errorf("%w - rollbacks restore %v instead", ErrRollbackInvalid, rolledBack))
+		return nil, nil
+	}
+	return state, nil
+}
+
+// applyMomentum applies the patch of m on state, without verifying m.
+// Returns the reason of the divergence, if any.
+func (v *integrityVerifier) applyMomentum(state db.DB, m *nom.Momentum) (reason error, err error) {
+	patch := v.chainManager.GetPatch(m.Identifier())
+	if patch == nil {
+		return ErrPatchMissing, nil
+	}
+	if err := state.Apply(patch); err != nil {
+		return fmt.Errorf("%w - %v", ErrPatchInvalid, err), nil
+	}
+	if replayed := momentum.NewStore(v.genesis, state).Identifier(); replayed != m.Identifier() {
+		return fmt.Errorf("%w - expected %v but got %v", ErrFrontierMismatch, m.Identifier(), replayed), nil
+	}
+	return nil, nil
+}
+
+// verifyMomentum verifies m against the state of its previous momentum and applies its patch on state.
+// Returns the reason of the divergence, if any.
+func (v *integrityVerifier) verifyMomentum(state db.DB, m *nom.Momentum) (reason error, err error) {
+	previous := momentum.NewStore(v.genesis, state)
+	detailed, err := v.frontier.PrefetchMomentum(m)
+	if err == store.ErrDataPruned {
+		return ErrAccountBlockMissing, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := v.verifier.MomentumAt(detailed, previous); err != nil {
+		return err, nil
+	}
+
+	patch := v.chainManager.GetPatch(m.Identifier())
+	if patch == nil {
+		return ErrPatchMissing, nil
+	}
+	changes, err := db.GetTransactionChanges(patch, 1)
+	if err != nil {
+		return fmt.Errorf("%w - %v", ErrPatchInvalid, err), nil
+	}
+	if err := v.verifier.MomentumTransaction(&nom.MomentumTransaction{
+		Momentum: m,
+		Changes:  changes,
+	}); err != nil {
+		return err, nil
+	}
+
+	sequencers := getSequencerSizes(previous, detailed)
+	if reason, err := v.applyMomentum(state, m); reason != nil || err != nil {
+		return reason, err
+	}
+
+	// the frontier momentum is checked against the actual state of the db
+	if m.Identifier() == v.frontier.Identifier() {
+		return checkState(v.frontier, detailed, sequencers)
+	}
+	return checkState(momentum.NewStore(v.genesis, state), detailed, sequencers)
+}
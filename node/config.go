@@ -4,14 +4,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/zenon-network/go-zenon/chain/genesis"
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/metadata"
 	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/pruner"
+	"github.com/zenon-network/go-zenon/relayer"
+	"github.com/zenon-network/go-zenon/stratum"
 	"github.com/zenon-network/go-zenon/wallet"
 	"github.com/zenon-network/go-zenon/zenon"
 )
@@ -22,6 +27,51 @@ type ProducerConfig struct {
 	KeyFilePath string
 	Password    string
 }
+type RelayerConfig struct {
+	Address     string
+	Index       uint32
+	KeyFilePath string
+	Password    string
+
+	BitcoinEndpoint string // JSON-RPC or REST endpoint of a bitcoind-compatible node
+	BitcoinUser     string
+	BitcoinPassword string
+	UseREST         bool
+
+	PollIntervalSec      int64
+	BatchSize            uint32
+	SubmissionTimeoutSec int64
+	MaxBackoffSec        int64
+}
+type StratumConfig struct {
+	Address     string
+	Index       uint32
+	KeyFilePath string
+	Password    string
+
+	ListenAddress     string // Stratum server, e.g. 0.0.0.0:3333
+	HTTPListenAddress string // JSON-RPC fallback, disabled if empty
+
+	ShareChainId       uint8
+	RefreshIntervalSec int64
+}
+type IndexerConfig struct {
+	// EnableTransfers maintains the transfer history used by ledger.getTransfers
+	EnableTransfers bool
+	// EnableTokenHolders maintains the token balances used by embedded.token.getHolders
+	EnableTokenHolders bool
+	// EnableBridgeRequests maintains the bridge requests and totals used by embedded.bridge.getWrapTokenRequestsByFilter,
+	// getUnwrapTokenRequestsByFilter and getTokenPairStats
+	EnableBridgeRequests bool
+	// EnableHtlcs maintains the htlcs by participant, hash lock and expiration used by the embedded.htlc queries
+	EnableHtlcs bool
+}
+type PruningConfig struct {
+	// Enabled deletes the account-blocks and momentum patches which are no longer required, in the background
+	Enabled bool
+	// Retention is the number of recent momentums kept in full, defaults to pruner.DefaultRetention
+	Retention uint64
+}
 type RPCConfig struct {
 	EnableHTTP bool
 	EnableWS   bool
@@ -58,7 +108,14 @@ type Config struct {
 
 	LogLevel string // "debug", "dbug" | "info" | "warn" | "error", "error" | "crit"
 
+	// ArchiveMode serves account and embedded contract state at any momentum, not only at recent ones
+	ArchiveMode bool
+
 	Producer *ProducerConfig
+	Relayer  *RelayerConfig
+	Stratum  *StratumConfig
+	Indexer  IndexerConfig
+	Pruning  PruningConfig
 	RPC      RPCConfig
 	Net      NetConfig
 }
@@ -102,13 +159,31 @@ func (c *Config) makeZenonConfig(walletManager *wallet.Manager) (*zenon.Config,
 	if err != nil {
 		return nil, err
 	}
+	relayerKeyPair, err := c.parseRelayer(walletManager)
+	if err != nil {
+		return nil, err
+	}
+	stratumKeyPair, err := c.parseStratum(walletManager)
+	if err != nil {
+		return nil, err
+	}
+	if c.ArchiveMode && c.Pruning.Enabled {
+		return nil, ErrArchiveWithPruning
+	}
 
 	return &zenon.Config{
 		MinPeers:          c.Net.MinPeers,
 		MinConnectedPeers: c.Net.MinConnectedPeers,
 		ProducingKeyPair:  pillarCoinbase,
+		RelayerKeyPair:    relayerKeyPair,
+		Relayer:           c.makeRelayerConfig(),
+		StratumKeyPair:    stratumKeyPair,
+		Stratum:           c.makeStratumConfig(),
+		Indexer:           c.makeIndexerConfig(),
+		Pruner:            c.makePrunerConfig(),
 		GenesisConfig:     c.makeGenesisConfig(),
 		DataDir:           c.DataPath,
+		Archive:           c.ArchiveMode,
 	}, nil
 }
 func (c *Config) makeGenesisConfig() (genesisConfig store.Genesis) {
@@ -144,41 +219,102 @@ func (c *Config) parseProducer(walletManager *wallet.Manager) (*wallet.KeyPair,
 	if c.Producer == nil {
 		return nil, nil
 	}
+	return deriveKeyPair(walletManager, "producer", c.Producer.KeyFilePath, c.Producer.Password, c.Producer.Address, c.Producer.Index)
+}
+func (c *Config) parseRelayer(walletManager *wallet.Manager) (*wallet.KeyPair, error) {
+	if c.Relayer == nil {
+		return nil, nil
+	}
+	return deriveKeyPair(walletManager, "relayer", c.Relayer.KeyFilePath, c.Relayer.Password, c.Relayer.Address, c.Relayer.Index)
+}
+func (c *Config) makeRelayerConfig() *relayer.Config {
+	if c.Relayer == nil {
+		return nil
+	}
+	return &relayer.Config{
+		Endpoint:          c.Relayer.BitcoinEndpoint,
+		User:              c.Relayer.BitcoinUser,
+		Password:          c.Relayer.BitcoinPassword,
+		UseREST:           c.Relayer.UseREST,
+		PollInterval:      time.Duration(c.Relayer.PollIntervalSec) * time.Second,
+		BatchSize:         c.Relayer.BatchSize,
+		SubmissionTimeout: time.Duration(c.Relayer.SubmissionTimeoutSec) * time.Second,
+		MaxBackoff:        time.Duration(c.Relayer.MaxBackoffSec) * time.Second,
+	}
+}
+func (c *Config) parseStratum(walletManager *wallet.Manager) (*wallet.KeyPair, error) {
+	if c.Stratum == nil {
+		return nil, nil
+	}
+	return deriveKeyPair(walletManager, "stratum", c.Stratum.KeyFilePath, c.Stratum.Password, c.Stratum.Address, c.Stratum.Index)
+}
+func (c *Config) makeStratumConfig() *stratum.Config {
+	if c.Stratum == nil {
+		return nil
+	}
+	return &stratum.Config{
+		ListenAddress:     c.Stratum.ListenAddress,
+		HTTPListenAddress: c.Stratum.HTTPListenAddress,
+		ShareChainId:      c.Stratum.ShareChainId,
+		RefreshInterval:   time.Duration(c.Stratum.RefreshIntervalSec) * time.Second,
+	}
+}
+func (c *Config) makeIndexerConfig() *indexer.Config {
+	if !c.Indexer.EnableTransfers && !c.Indexer.EnableTokenHolders && !c.Indexer.EnableBridgeRequests && !c.Indexer.EnableHtlcs {
+		return nil
+	}
+	return &indexer.Config{
+		Transfers: c.Indexer.EnableTransfers,
+		Holders:   c.Indexer.EnableTokenHolders,
+		Bridge:    c.Indexer.EnableBridgeRequests,
+		Htlcs:     c.Indexer.EnableHtlcs,
+	}
+}
+func (c *Config) makePrunerConfig() *pruner.Config {
+	if !c.Pruning.Enabled {
+		return nil
+	}
+	return &pruner.Config{
+		Retention: c.Pruning.Retention,
+	}
+}
 
+// deriveKeyPair unlocks the keyFile and derives the key pair of the given role, which must match the configured address
+func deriveKeyPair(walletManager *wallet.Manager, role, keyFilePath, password, addressStr string, index uint32) (*wallet.KeyPair, error) {
 	// Unlock in wallet
-	if _, err := walletManager.GetKeyFile(c.Producer.KeyFilePath); err != nil {
-		log.Error("unable to get keyFile", "keyFilePath", c.Producer.KeyFilePath, "reason", err)
+	if _, err := walletManager.GetKeyFile(keyFilePath); err != nil {
+		log.Error("unable to get keyFile", "keyFilePath", keyFilePath, "reason", err)
 		return nil, err
 	}
-	if err := walletManager.Unlock(c.Producer.KeyFilePath, c.Producer.Password); err != nil {
-		log.Error("unable to unlock keyFile", "keyFilePath", c.Producer.KeyFilePath, "reason", err)
+	if err := walletManager.Unlock(keyFilePath, password); err != nil {
+		log.Error("unable to unlock keyFile", "keyFilePath", keyFilePath, "reason", err)
 		return nil, err
 	}
 
 	// check address field is set & parse it
-	if c.Producer.Address == "" {
-		return nil, fmt.Errorf("unable to parse producer address. Reason:missing")
+	if addressStr == "" {
+		return nil, fmt.Errorf("unable to parse %v address. Reason:missing", role)
 	}
-	address, err := types.ParseAddress(c.Producer.Address)
+	address, err := types.ParseAddress(addressStr)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse producer address. Reason:%w", err)
+		return nil, fmt.Errorf("unable to parse %v address. Reason:%w", role, err)
 	}
 
 	// get keyStore which should already be unlocked
-	keyStore, err := walletManager.GetKeyStore(c.Producer.KeyFilePath)
+	keyStore, err := walletManager.GetKeyStore(keyFilePath)
 	if err != nil {
 		return nil, err
 	}
 
-	// derive coinbase
-	_, keyPair, err := keyStore.DeriveForIndexPath(c.Producer.Index)
+	// derive key pair
+	_, keyPair, err := keyStore.DeriveForIndexPath(index)
 	if err != nil {
 		return nil, err
 	}
 
 	// make sure address matches
 	if keyPair.Address != address {
-		return nil, errors.Errorf("producer address doesn't match. Expected %v but got %v", address, keyPair.Address)
+		return nil, errors.Errorf("%v address doesn't match. Expected %v but got %v", role, address, keyPair.Address)
 	}
 
 	return keyPair, nil
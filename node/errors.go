@@ -10,6 +10,8 @@ var (
 	ErrDataDirUsed     = errors.New("dataDir already used by another process")
 	ErrNodeStopped     = errors.New("node not started")
 	datadirInUseErrnos = map[uint]bool{11: true, 32: true, 35: true}
+
+	ErrArchiveWithPruning = errors.New("archive mode and pruning can't be enabled together")
 )
 
 func convertFileLockError(err error) error {
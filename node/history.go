@@ -0,0 +1,84 @@
+package node
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/history"
+	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/verifier"
+	"github.com/zenon-network/go-zenon/vm"
+)
+
+// ExportChain writes the momentums between from and to, together with their account-blocks, to path.
+// The node must not be running since the chain db is opened directly.
+func ExportChain(cfg *Config, path string, from, to uint64, compress bool, progress history.Progress) (*history.Header, error) {
+	fileLock, err := lockDataDir(cfg.DataPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fileLock.Release()
+
+	nomDir := filepath.Join(cfg.DataPath, "nom")
+	if _, err := os.Stat(nomDir); err != nil {
+		return nil, errors.Errorf("can't find the chain db in %v", cfg.DataPath)
+	}
+	ch := chain.NewChain(db.NewLevelDBManager(nomDir), cfg.makeGenesisConfig())
+	defer ch.Stop()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+
+	header, err := history.Export(w, ch, from, to, compress, progress)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		return nil, err
+	}
+	log.Info("exported chain", "path", path, "from", header.From, "to", header.To)
+	return header, nil
+}
+
+// ImportChain verifies and inserts the momentums exported to path, in the same way momentums received from peers
+// are inserted. Momentums which are already part of the chain are skipped, so an interrupted import can be resumed.
+// Returns the header of the export and the number of inserted momentums.
+func ImportChain(cfg *Config, path string, progress history.Progress) (*history.Header, uint64, error) {
+	fileLock, err := lockDataDir(cfg.DataPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer fileLock.Release()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	oc, err := openChain(cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer oc.close()
+
+	bridge := protocol.NewChainBridge(oc.chain, oc.consensus, verifier.NewVerifier(oc.chain, oc.consensus), vm.NewSupervisor(oc.chain, oc.consensus))
+	header, inserted, err := history.Import(bufio.NewReader(file), oc.chain, bridge, progress)
+	if err != nil {
+		return nil, 0, err
+	}
+	log.Info("imported chain", "path", path, "from", header.From, "to", header.To, "inserted", inserted)
+	return header, inserted, nil
+}
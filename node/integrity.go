@@ -0,0 +1,54 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/integrity"
+	"github.com/zenon-network/go-zenon/verifier"
+)
+
+// VerifyDB checks the consistency of the chain db, see integrity.Verify.
+// If repair is set and a divergence is found, the chain is rolled back to the last consistent momentum, from which
+// the node syncs again, and its identifier is returned.
+func VerifyDB(cfg *Config, config *integrity.Config, repair bool, progress integrity.Progress) (*integrity.Report, *types.HashHeight, error) {
+	fileLock, err := lockDataDir(cfg.DataPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer fileLock.Release()
+
+	if _, err := os.Stat(filepath.Join(cfg.DataPath, "nom")); err != nil {
+		return nil, nil, errors.Errorf("can't find the chain db in %v", cfg.DataPath)
+	}
+	oc, err := openChain(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer oc.close()
+
+	report, err := integrity.Verify(oc.chainManager, oc.chain, verifier.NewVerifier(oc.chain, oc.consensus), config, progress)
+	if err != nil {
+		return nil, nil, err
+	}
+	if report.Divergence == nil {
+		log.Info("db is consistent", "from", report.From, "to", report.To, "verified", report.Verified)
+		return report, nil, nil
+	}
+	log.Error("db is inconsistent", "momentum", report.Divergence.Momentum, "reason", report.Divergence.Reason)
+	if !repair {
+		return report, nil, nil
+	}
+
+	insert := oc.chain.AcquireInsert("repair inconsistent db")
+	defer insert.Unlock()
+	identifier, err := integrity.Repair(oc.chainManager, oc.chain, report.Divergence)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Info("repaired db", "frontier", identifier)
+	return report, identifier, nil
+}
@@ -0,0 +1,63 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/prometheus/tsdb/fileutil"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/consensus"
+)
+
+// lockDataDir prevents the use of the data dir by a running node while the databases are opened directly
+func lockDataDir(dataPath string) (fileutil.Releaser, error) {
+	if err := os.MkdirAll(dataPath, 0700); err != nil {
+		return nil, err
+	}
+	fileLock, _, err := fileutil.Flock(filepath.Join(dataPath, ".lock"))
+	if err != nil {
+		return nil, convertFileLockError(err)
+	}
+	return fileLock, nil
+}
+
+// offlineChain is the chain and consensus of a node which isn't running, used by the db commands
+type offlineChain struct {
+	chainManager db.Manager
+	chain        chain.Chain
+	consensus    consensus.Consensus
+	consensusDB  interface{ Close() error }
+}
+
+func openChain(cfg *Config) (*offlineChain, error) {
+	chainManager := db.NewLevelDBManager(filepath.Join(cfg.DataPath, "nom"))
+	ch := chain.NewChain(chainManager, cfg.makeGenesisConfig())
+	consensusDB, consensusLevelDB := db.NewLevelDB(filepath.Join(cfg.DataPath, "consensus"))
+	cs := consensus.NewConsensus(consensusDB, ch, false)
+	oc := &offlineChain{
+		chainManager: chainManager,
+		chain:        ch,
+		consensus:    cs,
+		consensusDB:  consensusLevelDB,
+	}
+	if err := ch.Init(); err != nil {
+		oc.close()
+		return nil, err
+	}
+	if err := cs.Init(); err != nil {
+		oc.close()
+		return nil, err
+	}
+	common.DealWithErr(ch.Start())
+	common.DealWithErr(cs.Start())
+	return oc, nil
+}
+
+func (oc *offlineChain) close() {
+	common.DealWithErr(oc.consensus.Stop())
+	common.DealWithErr(oc.chain.Stop())
+	common.DealWithErr(oc.consensusDB.Close())
+}
@@ -0,0 +1,89 @@
+package node
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/snapshot"
+)
+
+// ExportSnapshot writes the snapshot of the momentum at height to path.
+// The node must not be running since the databases are opened directly.
+func ExportSnapshot(cfg *Config, path string, height, window uint64) (*snapshot.Header, types.Hash, error) {
+	fileLock, err := lockDataDir(cfg.DataPath)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	defer fileLock.Release()
+
+	nomDir := filepath.Join(cfg.DataPath, "nom")
+	if _, err := os.Stat(nomDir); err != nil {
+		return nil, types.ZeroHash, errors.Errorf("can't find the chain db in %v", cfg.DataPath)
+	}
+	chainManager := db.NewLevelDBManager(nomDir)
+	defer chainManager.Stop()
+	consensusDB, consensusLevelDB := db.NewLevelDB(filepath.Join(cfg.DataPath, "consensus"))
+	defer consensusLevelDB.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+
+	header, root, err := snapshot.Export(w, chainManager, consensusDB, cfg.makeGenesisConfig(), height, window)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, types.ZeroHash, err
+	}
+	if err := file.Sync(); err != nil {
+		return nil, types.ZeroHash, err
+	}
+	log.Info("exported snapshot", "path", path, "momentum", header.Momentum, "base", header.Base, "root", root)
+	return header, root, nil
+}
+
+// ImportSnapshot restores the snapshot at path into the data dir, which must not contain a chain yet.
+// The root of the snapshot must match trusted. After the import, the node syncs forward from the momentum of the snapshot.
+func ImportSnapshot(cfg *Config, path string, trusted types.Hash) (*snapshot.Header, types.Hash, error) {
+	fileLock, err := lockDataDir(cfg.DataPath)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	defer fileLock.Release()
+
+	nomDir := filepath.Join(cfg.DataPath, "nom")
+	consensusDir := filepath.Join(cfg.DataPath, "consensus")
+	for _, dir := range []string{nomDir, consensusDir} {
+		if _, err := os.Stat(dir); err == nil {
+			return nil, types.ZeroHash, errors.Errorf("can't import snapshot, %v already exists", dir)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	defer file.Close()
+
+	consensusDB, consensusLevelDB := db.NewLevelDB(consensusDir)
+	header, root, err := snapshot.Import(bufio.NewReader(file), nomDir, consensusDB, cfg.makeGenesisConfig(), trusted)
+	common.DealWithErr(consensusLevelDB.Close())
+	if err != nil {
+		// leave the data dir as it was, so the import can be retried
+		common.DealWithErr(os.RemoveAll(nomDir))
+		common.DealWithErr(os.RemoveAll(consensusDir))
+		return nil, types.ZeroHash, err
+	}
+	log.Info("imported snapshot", "path", path, "momentum", header.Momentum, "base", header.Base, "root", root)
+	return header, root, nil
+}
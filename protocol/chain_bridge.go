@@ -7,6 +7,7 @@ import (
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
+	chainstore "github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
@@ -73,23 +74,29 @@ func (c chainBridge) GetBlockHashesFromHash(hash types.Hash, amount uint64) ([]t
 	}
 	return hashes, nil
 }
-func (c chainBridge) GetBlock(hash types.Hash) *nom.DetailedMomentum {
+func (c chainBridge) GetBlock(hash types.Hash) (*nom.DetailedMomentum, error) {
 	store := c.chain.GetFrontierMomentumStore()
-	momentum, _ := store.GetMomentumByHash(hash)
-	if momentum == nil {
-		return nil
+	momentum, err := store.GetMomentumByHash(hash)
+	if err != nil || momentum == nil {
+		return nil, err
 	}
 	prefetched := make([]*nom.AccountBlock, len(momentum.Content))
 
 	for i := range prefetched {
-		block, _ := store.GetAccountBlock(*momentum.Content[i])
+		block, err := store.GetAccountBlock(*momentum.Content[i])
+		if err != nil && err != chainstore.ErrDataPruned {
+			return nil, err
+		}
+		if block == nil {
+			return nil, chainstore.ErrDataPruned
+		}
 		prefetched[i] = block
 	}
 
 	return &nom.DetailedMomentum{
 		Momentum:      momentum,
 		AccountBlocks: prefetched,
-	}
+	}, nil
 }
 func (c chainBridge) CurrentBlock() *nom.Momentum {
 	store := c.chain.GetFrontierMomentumStore()
@@ -325,12 +325,6 @@ func (q *queue) Deliver(id string, blocks []*nom.DetailedMomentum) (err error) {
 	}
 	delete(q.pendPool, id)
 
-	// If no blocks were retrieved, mark them as unavailable for the origin peer
-	if len(blocks) == 0 {
-		for hash, _ := range request.Hashes {
-			request.Peer.ignored.Insert(hash)
-		}
-	}
 	// Iterate over the downloaded blocks and add each of them
 	errs := make([]error, 0)
 	for _, detailed := range blocks {
@@ -355,8 +349,10 @@ func (q *queue) Deliver(id string, blocks []*nom.DetailedMomentum) (err error) {
 		delete(q.hashPool, hash)
 		q.blockPool[hash] = block.Height
 	}
-	// Return all failed or missing fetches to the queue
+	// Return all failed or missing fetches to the queue. Since requests never exceed what a
+	// peer serves at once, missing blocks are unavailable at the origin peer (e.g. pruned).
 	for hash, index := range request.Hashes {
+		request.Peer.ignored.Insert(hash)
 		q.hashQueue.Push(hash, float32(index))
 	}
 	// If none of the blocks were good, it's a stale delivery
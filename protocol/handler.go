@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 
 	"github.com/zenon-network/go-zenon/chain/nom"
+	chainstore "github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/p2p"
 	"github.com/zenon-network/go-zenon/protocol/downloader"
@@ -90,9 +91,13 @@ func NewProtocolManager(minPeers int, networkId uint64, bridge ChainBridge) *Pro
 		}
 	}
 	// Construct the different synchronisation mechanisms
+	getBlock := func(hash types.Hash) *nom.DetailedMomentum {
+		block, _ := manager.chainman.GetBlock(hash)
+		return block
+	}
 	manager.downloader = downloader.New(
 		manager.chainman.HasBlock,
-		manager.chainman.GetBlock,
+		getBlock,
 		manager.chainman.CurrentBlock,
 		manager.chainman.InsertChain,
 		manager.removePeer)
@@ -106,7 +111,7 @@ func NewProtocolManager(minPeers int, networkId uint64, bridge ChainBridge) *Pro
 		return momentum.Height
 	}
 	manager.fetcher = fetcher.New(
-		manager.chainman.GetBlock,
+		getBlock,
 		validator,
 		manager.BroadcastMomentum,
 		heighter,
@@ -300,6 +305,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			hash   types.Hash
 			hashes []types.Hash
 			blocks []*nom.DetailedMomentum
+			pruned int
 		)
 		for {
 			err := msgStream.Decode(&hash)
@@ -310,14 +316,23 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			hashes = append(hashes, hash)
 
-			// Retrieve the requested block, stopping if enough was found
-			if block := pm.chainman.GetBlock(hash); block != nil {
+			// Retrieve the requested block, stopping if enough was found. Pruned blocks are left
+			// out of the reply, which makes the peer stop requesting them from us.
+			block, err := pm.chainman.GetBlock(hash)
+			if err == chainstore.ErrDataPruned {
+				pruned += 1
+			} else if err != nil {
+				log.Warn("failed to retrieve requested block", "hash", hash, "reason", err)
+			} else if block != nil {
 				blocks = append(blocks, block)
 				if len(blocks) >= downloader.MaxBlockFetch {
 					break
 				}
 			}
 		}
+		if pruned != 0 {
+			log.Info("not serving pruned blocks", "peer-id", p.id, "num-pruned", pruned)
+		}
 
 		if len(blocks) == 0 && len(hashes) > 0 {
 			list := "["
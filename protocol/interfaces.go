@@ -36,7 +36,8 @@ type txPool interface {
 type chainManager interface {
 	HasBlock(hash types.Hash) bool
 	GetBlockHashesFromHash(hash types.Hash, amount uint64) ([]types.Hash, error)
-	GetBlock(hash types.Hash) (block *nom.DetailedMomentum)
+	// GetBlock returns chainstore.ErrDataPruned if the momentum is known but its content is pruned.
+	GetBlock(hash types.Hash) (*nom.DetailedMomentum, error)
 	GetBlockByNumber(num uint64) (*nom.Momentum, error)
 	CurrentBlock() *nom.Momentum
 	Status() (td uint64, currentBlock types.Hash, genesisBlock types.Hash)
@@ -0,0 +1,29 @@
+package pruner
+
+import (
+	"time"
+)
+
+const (
+	// DefaultRetention keeps about a day of momentums in full
+	DefaultRetention = 8640
+	// MinimumRetention covers the rollbacks done while syncing and the momentums whose state is served over RPC
+	MinimumRetention = 360
+	DefaultInterval  = time.Minute
+)
+
+type Config struct {
+	// Retention is the number of recent momentums whose account-blocks and patches are kept
+	Retention uint64
+	// Interval between two pruning rounds
+	Interval time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.Retention == 0 {
+		c.Retention = DefaultRetention
+	}
+	if c.Interval == 0 {
+		c.Interval = DefaultInterval
+	}
+}
@@ -0,0 +1,7 @@
+package pruner
+
+import "github.com/pkg/errors"
+
+var (
+	ErrRetentionTooSmall = errors.Errorf("pruning retention is smaller than the minimum of %v momentums", MinimumRetention)
+)
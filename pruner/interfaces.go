@@ -0,0 +1,11 @@
+package pruner
+
+type Manager interface {
+	Init() error
+	Start() error
+	Stop() error
+
+	// Prune is used by the testing environment to run a single pruning round
+	// without waiting for the interval.
+	Prune() error
+}
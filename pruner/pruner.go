@@ -0,0 +1,122 @@
+package pruner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/indexer"
+)
+
+const (
+	// pruneBatchSize is the number of momentums pruned between two checks for stop
+	pruneBatchSize = 1000
+)
+
+// pruner deletes, in the background, the data of the momentums older than the retention.
+// Momentum headers are kept since consensus needs them, together with the account-blocks
+// still required by the verifier, see momentum.GetPrunableKeys.
+type pruner struct {
+	log      common.Logger
+	closed   chan struct{}
+	working  sync.Mutex
+	children sync.WaitGroup
+
+	config *Config
+	chain  chain.Chain
+	// indexer is optional, momentums which aren't indexed yet are not pruned
+	indexer indexer.Manager
+}
+
+func NewPruner(config *Config, chain chain.Chain, indexer indexer.Manager) Manager {
+	config.setDefaults()
+	return &pruner{
+		log:     common.PrunerLogger,
+		config:  config,
+		chain:   chain,
+		indexer: indexer,
+	}
+}
+
+func (p *pruner) Init() error {
+	if p.config.Retention < MinimumRetention {
+		return ErrRetentionTooSmall
+	}
+	return nil
+}
+func (p *pruner) Start() error {
+	p.log.Info("starting ...", "retention", p.config.Retention, "pruned-height", p.chain.GetPrunedHeight())
+	defer p.log.Info("started")
+
+	p.closed = make(chan struct{})
+	p.children.Add(1)
+	go p.loop()
+
+	return nil
+}
+func (p *pruner) Stop() error {
+	p.log.Info("stopping ...")
+	defer p.log.Info("stopped")
+
+	close(p.closed)
+	p.children.Wait()
+
+	return nil
+}
+
+func (p *pruner) loop() {
+	defer p.children.Done()
+	defer common.RecoverStack()
+
+	for {
+		if err := p.Prune(); err != nil {
+			p.log.Error("failed to prune momentums", "reason", err)
+		}
+
+		select {
+		case <-p.closed:
+			return
+		case <-time.After(p.config.Interval):
+		}
+	}
+}
+
+func (p *pruner) Prune() error {
+	p.working.Lock()
+	defer p.working.Unlock()
+
+	frontier, err := p.chain.GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return err
+	}
+	if frontier.Height <= p.config.Retention {
+		return nil
+	}
+	target := frontier.Height - p.config.Retention
+	if p.indexer != nil && p.indexer.IndexedHeight() < target {
+		target = p.indexer.IndexedHeight()
+		if err := p.indexer.Err(); err != nil {
+			p.log.Warn("pruning is held back by the stalled indexer", "reason", err, "indexed-height", target)
+		}
+	}
+
+	for pruned := p.chain.GetPrunedHeight(); pruned < target; {
+		select {
+		case <-p.closed:
+			return nil
+		default:
+		}
+
+		next := pruned + pruneBatchSize
+		if next > target {
+			next = target
+		}
+		if err := p.chain.PruneTo(next); err != nil {
+			return err
+		}
+		pruned = next
+		p.log.Info("pruned momentums", "pruned-height", next, "frontier-height", frontier.Height)
+	}
+	return nil
+}
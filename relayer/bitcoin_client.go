@@ -0,0 +1,227 @@
+package relayer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	bitcoinRequestTimeout = time.Second * 15
+	// blockHeaderLength is the size of a serialized bitcoin block header
+	blockHeaderLength = 80
+)
+
+// NewBitcoinClient returns a client for the JSON-RPC or the REST interface of a bitcoind-compatible node
+func NewBitcoinClient(config *Config) BitcoinClient {
+	httpClient := &http.Client{Timeout: bitcoinRequestTimeout}
+	endpoint := strings.TrimSuffix(config.Endpoint, "/")
+	if config.UseREST {
+		return &restClient{
+			endpoint: endpoint,
+			client:   httpClient,
+		}
+	}
+	return &rpcClient{
+		endpoint: endpoint,
+		user:     config.User,
+		password: config.Password,
+		client:   httpClient,
+	}
+}
+
+func parseBlockHeader(data []byte) (*wire.BlockHeader, error) {
+	if len(data) != blockHeaderLength {
+		return nil, ErrInvalidBitcoinHeader
+	}
+	header := new(wire.BlockHeader)
+	if err := header.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, ErrInvalidBitcoinHeader
+	}
+	return header, nil
+}
+
+// rpcClient queries the JSON-RPC interface of the node
+type rpcClient struct {
+	endpoint string
+	user     string
+	password string
+	client   *http.Client
+	id       uint64
+}
+
+type rpcRequest struct {
+	JsonRpc string        `json:"jsonrpc"`
+	Id      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func (c *rpcClient) call(result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(&rpcRequest{
+		JsonRpc: "1.0",
+		Id:      atomic.AddUint64(&c.id, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if c.user != "" || c.password != "" {
+		request.SetBasicAuth(c.user, c.password)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// bitcoind answers failed calls with a non-200 status and the error in the body
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	rpcResult := new(rpcResponse)
+	if err := json.Unmarshal(data, rpcResult); err != nil {
+		return errors.Errorf("%v: unexpected response from bitcoin node, status %v", method, response.Status)
+	}
+	if rpcResult.Error != nil {
+		return errors.Errorf("%v: bitcoin node returned error %v: %v", method, rpcResult.Error.Code, rpcResult.Error.Message)
+	}
+	return json.Unmarshal(rpcResult.Result, result)
+}
+
+func (c *rpcClient) GetBlockCount() (uint32, error) {
+	var count uint32
+	if err := c.call(&count, "getblockcount"); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+func (c *rpcClient) GetBlockHash(height uint32) (types.Hash, error) {
+	var hash string
+	if err := c.call(&hash, "getblockhash", height); err != nil {
+		return types.ZeroHash, err
+	}
+	return types.HexToHash(hash)
+}
+func (c *rpcClient) GetBlockHeaders(height uint32, count uint32) ([]*wire.BlockHeader, error) {
+	headers := make([]*wire.BlockHeader, 0, count)
+	for i := uint32(0); i < count; i += 1 {
+		hash, err := c.GetBlockHash(height + i)
+		if err != nil {
+			return nil, err
+		}
+		var headerHex string
+		if err := c.call(&headerHex, "getblockheader", hash.String(), false); err != nil {
+			return nil, err
+		}
+		data, err := hex.DecodeString(headerHex)
+		if err != nil {
+			return nil, ErrInvalidBitcoinHeader
+		}
+		header, err := parseBlockHeader(data)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+// restClient queries the unauthenticated REST interface of the node, enabled with -rest
+type restClient struct {
+	endpoint string
+	client   *http.Client
+}
+
+func (c *restClient) get(path string) ([]byte, error) {
+	response, err := c.client.Get(c.endpoint + path)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("%v: bitcoin node returned status %v: %v", path, response.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+func (c *restClient) GetBlockCount() (uint32, error) {
+	data, err := c.get("/rest/chaininfo.json")
+	if err != nil {
+		return 0, err
+	}
+	chainInfo := new(struct {
+		Blocks uint32 `json:"blocks"`
+	})
+	if err := json.Unmarshal(data, chainInfo); err != nil {
+		return 0, err
+	}
+	return chainInfo.Blocks, nil
+}
+func (c *restClient) GetBlockHash(height uint32) (types.Hash, error) {
+	data, err := c.get(fmt.Sprintf("/rest/blockhashbyheight/%d.hex", height))
+	if err != nil {
+		return types.ZeroHash, err
+	}
+	return types.HexToHash(strings.TrimSpace(string(data)))
+}
+func (c *restClient) GetBlockHeaders(height uint32, count uint32) ([]*wire.BlockHeader, error) {
+	hash, err := c.GetBlockHash(height)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.get(fmt.Sprintf("/rest/headers/%v.bin?count=%d", hash.String(), count))
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%blockHeaderLength != 0 {
+		return nil, ErrInvalidBitcoinHeader
+	}
+	headers := make([]*wire.BlockHeader, 0, len(data)/blockHeaderLength)
+	for i := 0; i < len(data); i += blockHeaderLength {
+		header, err := parseBlockHeader(data[i : i+blockHeaderLength])
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
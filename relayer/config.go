@@ -0,0 +1,43 @@
+package relayer
+
+import (
+	"time"
+)
+
+const (
+	DefaultPollInterval      = time.Second * 30
+	DefaultBatchSize         = 10
+	DefaultSubmissionTimeout = time.Minute * 5
+	DefaultMaxBackoff        = time.Minute * 10
+)
+
+type Config struct {
+	// Endpoint of the bitcoind-compatible node, e.g. http://127.0.0.1:8332
+	Endpoint string
+	User     string
+	Password string
+	// UseREST queries the REST interface of the node instead of the JSON-RPC one
+	UseREST bool
+
+	PollInterval time.Duration
+	// BatchSize is the maximum number of headers submitted in a relay round
+	BatchSize uint32
+	// SubmissionTimeout is how long to wait for submitted headers to be added to the header chain
+	SubmissionTimeout time.Duration
+	MaxBackoff        time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.PollInterval == 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+	if c.SubmissionTimeout == 0 {
+		c.SubmissionTimeout = DefaultSubmissionTimeout
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+}
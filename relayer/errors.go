@@ -0,0 +1,10 @@
+package relayer
+
+import "github.com/pkg/errors"
+
+var (
+	ErrSyncNotDone          = errors.Errorf("sync is not done")
+	ErrNoCommonAncestor     = errors.Errorf("no common ancestor with the bitcoin node within the max reorg depth")
+	ErrSubmissionsRejected  = errors.Errorf("submitted headers were not added to the header chain")
+	ErrInvalidBitcoinHeader = errors.Errorf("invalid block header received from the bitcoin node")
+)
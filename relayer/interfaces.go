@@ -0,0 +1,25 @@
+package relayer
+
+import (
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+type Manager interface {
+	Init() error
+	Start() error
+	Stop() error
+
+	// Relay is used by the testing environment to run a single relay round
+	// without waiting for the poll interval.
+	Relay() error
+}
+
+// BitcoinClient is the subset of a bitcoind-compatible node used to fetch the headers of its best chain
+type BitcoinClient interface {
+	GetBlockCount() (uint32, error)
+	GetBlockHash(height uint32) (types.Hash, error)
+	// GetBlockHeaders returns count consecutive headers of the best chain starting at height
+	GetBlockHeaders(height uint32, count uint32) ([]*wire.BlockHeader, error)
+}
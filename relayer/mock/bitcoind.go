@@ -0,0 +1,194 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// MockBitcoind is a bitcoind-compatible node which serves canned headers over JSON-RPC and REST.
+type MockBitcoind interface {
+	Endpoint() string
+	// SetHeaders replaces the best chain with headers, the first one being at height
+	SetHeaders(height uint32, headers []*wire.BlockHeader)
+	Close()
+}
+
+type mockBitcoind struct {
+	server  *httptest.Server
+	changes sync.Mutex
+	height  uint32
+	headers []*wire.BlockHeader
+}
+
+func NewMockBitcoind(height uint32, headers []*wire.BlockHeader) MockBitcoind {
+	b := &mockBitcoind{
+		height:  height,
+		headers: headers,
+	}
+	b.server = httptest.NewServer(http.HandlerFunc(b.serve))
+	return b
+}
+
+func (b *mockBitcoind) Endpoint() string {
+	return b.server.URL
+}
+func (b *mockBitcoind) SetHeaders(height uint32, headers []*wire.BlockHeader) {
+	b.changes.Lock()
+	defer b.changes.Unlock()
+	b.height = height
+	b.headers = headers
+}
+func (b *mockBitcoind) Close() {
+	b.server.Close()
+}
+
+func (b *mockBitcoind) count() uint32 {
+	return b.height + uint32(len(b.headers)) - 1
+}
+func (b *mockBitcoind) byHeight(height uint32) *wire.BlockHeader {
+	if height < b.height || height > b.count() {
+		return nil
+	}
+	return b.headers[height-b.height]
+}
+func (b *mockBitcoind) heightByHash(hash string) (uint32, bool) {
+	for index, header := range b.headers {
+		if header.BlockHash().String() == hash {
+			return b.height + uint32(index), true
+		}
+	}
+	return 0, false
+}
+
+func (b *mockBitcoind) serve(w http.ResponseWriter, r *http.Request) {
+	b.changes.Lock()
+	defer b.changes.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, "/rest/") {
+		b.serveREST(w, r)
+	} else {
+		b.serveRPC(w, r)
+	}
+}
+
+func (b *mockBitcoind) serveRPC(w http.ResponseWriter, r *http.Request) {
+	request := new(struct {
+		Id     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	})
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result interface{}
+	switch request.Method {
+	case "getblockcount":
+		result = b.count()
+	case "getblockhash":
+		var height uint32
+		if len(request.Params) != 1 || json.Unmarshal(request.Params[0], &height) != nil {
+			writeRPCError(w, request.Id, -1, "invalid params")
+			return
+		}
+		header := b.byHeight(height)
+		if header == nil {
+			writeRPCError(w, request.Id, -8, "Block height out of range")
+			return
+		}
+		result = header.BlockHash().String()
+	case "getblockheader":
+		var hash string
+		if len(request.Params) == 0 || json.Unmarshal(request.Params[0], &hash) != nil {
+			writeRPCError(w, request.Id, -1, "invalid params")
+			return
+		}
+		height, ok := b.heightByHash(hash)
+		if !ok {
+			writeRPCError(w, request.Id, -5, "Block not found")
+			return
+		}
+		result = hex.EncodeToString(serialize(b.byHeight(height)))
+	default:
+		writeRPCError(w, request.Id, -32601, "Method not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": result,
+		"error":  nil,
+		"id":     request.Id,
+	})
+}
+
+func writeRPCError(w http.ResponseWriter, id uint64, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"result": nil,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+		"id": id,
+	})
+}
+
+func (b *mockBitcoind) serveREST(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/rest/")
+	switch {
+	case path == "chaininfo.json":
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"chain":  "main",
+			"blocks": b.count(),
+		})
+	case strings.HasPrefix(path, "blockhashbyheight/") && strings.HasSuffix(path, ".hex"):
+		height, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(path, "blockhashbyheight/"), ".hex"), 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid height", http.StatusBadRequest)
+			return
+		}
+		header := b.byHeight(uint32(height))
+		if header == nil {
+			http.Error(w, "Block height out of range", http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintln(w, header.BlockHash().String())
+	case strings.HasPrefix(path, "headers/") && strings.HasSuffix(path, ".bin"):
+		height, ok := b.heightByHash(strings.TrimSuffix(strings.TrimPrefix(path, "headers/"), ".bin"))
+		if !ok {
+			http.Error(w, "Block not found", http.StatusNotFound)
+			return
+		}
+		count, err := strconv.ParseUint(r.URL.Query().Get("count"), 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid count", http.StatusBadRequest)
+			return
+		}
+		for i := uint32(0); i < uint32(count) && height+i <= b.count(); i += 1 {
+			_, _ = w.Write(serialize(b.byHeight(height + i)))
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func serialize(header *wire.BlockHeader) []byte {
+	buffer := new(bytes.Buffer)
+	if err := header.Serialize(buffer); err != nil {
+		panic(err)
+	}
+	return buffer.Bytes()
+}
@@ -0,0 +1,242 @@
+package relayer
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+// relayer keeps the header chain of the merge mining contract in sync with the best chain of a bitcoin node.
+// Every round it looks for the last header both chains agree on and submits the following headers, in batches.
+// Submitted headers are expected to be part of the header chain before SubmissionTimeout, otherwise
+// the relayer considers them rejected and backs off.
+type relayer struct {
+	log      common.Logger
+	closed   chan struct{}
+	working  sync.Mutex
+	children sync.WaitGroup
+
+	config  *Config
+	keyPair *wallet.KeyPair
+	client  BitcoinClient
+
+	// last header of the pending batch and the time until it is expected to be added
+	pending         *types.Hash
+	pendingDeadline time.Time
+	backoff         time.Duration
+
+	// modules
+	chain       chain.Chain
+	supervisor  *vm.Supervisor
+	broadcaster protocol.Broadcaster
+}
+
+func NewRelayer(config *Config, keyPair *wallet.KeyPair, chain chain.Chain, consensus consensus.Consensus, broadcaster protocol.Broadcaster) Manager {
+	return NewRelayerWithClient(config, keyPair, NewBitcoinClient(config), chain, consensus, broadcaster)
+}
+
+func NewRelayerWithClient(config *Config, keyPair *wallet.KeyPair, client BitcoinClient, chain chain.Chain, consensus consensus.Consensus, broadcaster protocol.Broadcaster) Manager {
+	config.setDefaults()
+	return &relayer{
+		log:         common.RelayerLogger.New("address", keyPair.Address),
+		config:      config,
+		keyPair:     keyPair,
+		client:      client,
+		chain:       chain,
+		supervisor:  vm.NewSupervisor(chain, consensus),
+		broadcaster: broadcaster,
+	}
+}
+
+func (r *relayer) Init() error {
+	return nil
+}
+func (r *relayer) Start() error {
+	r.log.Info("starting ...", "endpoint", r.config.Endpoint, "rest", r.config.UseREST)
+	defer r.log.Info("started")
+
+	r.closed = make(chan struct{})
+	r.children.Add(1)
+	go r.loop()
+
+	return nil
+}
+func (r *relayer) Stop() error {
+	r.log.Info("stopping ...")
+	defer r.log.Info("stopped")
+
+	close(r.closed)
+	r.children.Wait()
+
+	return nil
+}
+
+func (r *relayer) loop() {
+	defer r.children.Done()
+	defer common.RecoverStack()
+
+	wait := r.config.PollInterval
+	for {
+		select {
+		case <-r.closed:
+			return
+		case <-time.After(wait):
+		}
+
+		if err := r.Relay(); err != nil && err != ErrSyncNotDone {
+			r.backoff = nextBackoff(r.backoff, r.config.PollInterval, r.config.MaxBackoff)
+			r.log.Error("failed to relay bitcoin headers", "reason", err, "backoff", r.backoff)
+			wait = r.backoff
+		} else {
+			r.backoff = 0
+			wait = r.config.PollInterval
+		}
+	}
+}
+
+// nextBackoff doubles the previous backoff, starting from the poll interval, without exceeding maxBackoff
+func nextBackoff(backoff, pollInterval, maxBackoff time.Duration) time.Duration {
+	if backoff == 0 {
+		backoff = pollInterval
+	} else {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+func (r *relayer) Relay() error {
+	r.working.Lock()
+	defer r.working.Unlock()
+
+	if r.broadcaster.SyncInfo().State != protocol.SyncDone {
+		return ErrSyncNotDone
+	}
+
+	momentumStore := r.chain.GetFrontierMomentumStore()
+	context := vm_context.NewAccountContext(momentumStore, r.chain.GetFrontierAccountStore(types.MergeMiningContract), nil)
+	_, headerChainInfo, err := implementation.CanPerformActionMergeMining(context)
+	if err != nil {
+		r.log.Debug("header chain is not initialized", "reason", err)
+		return nil
+	}
+
+	if r.pending != nil {
+		if _, err := definition.GetBlockHeaderVariable(context.Storage(), *r.pending); err == nil {
+			r.pending = nil
+		} else if err != constants.ErrDataNonExistent {
+			return err
+		} else if common.Clock.Now().Before(r.pendingDeadline) {
+			r.log.Debug("waiting for submitted headers", "last", r.pending)
+			return nil
+		} else {
+			r.pending = nil
+			return ErrSubmissionsRejected
+		}
+	}
+
+	count, err := r.client.GetBlockCount()
+	if err != nil {
+		return err
+	}
+	ancestor, err := r.findCommonAncestor(context, headerChainInfo.TipHeight, count)
+	if err != nil {
+		return err
+	}
+	if ancestor >= count {
+		r.log.Debug("header chain is up to date", "tip-height", headerChainInfo.TipHeight)
+		return nil
+	}
+
+	batchSize := r.config.BatchSize
+	if count-ancestor < batchSize {
+		batchSize = count - ancestor
+	}
+	headers, err := r.client.GetBlockHeaders(ancestor+1, batchSize)
+	if err != nil {
+		return err
+	}
+	return r.submit(context, ancestor, headers)
+}
+
+// findCommonAncestor returns the height of the last header the bitcoin node and the main chain of the contract agree on.
+// Only reorgs the contract still accepts are followed.
+func (r *relayer) findCommonAncestor(context vm_context.AccountVmContext, tipHeight, count uint32) (uint32, error) {
+	height := tipHeight
+	if count < height {
+		height = count
+	}
+	for depth := uint32(0); depth < constants.MaxReorgDepth; depth += 1 {
+		mainHash, err := definition.GetMainChainHash(context.Storage(), height-depth)
+		if err == constants.ErrDataNonExistent {
+			return 0, ErrNoCommonAncestor
+		} else if err != nil {
+			return 0, err
+		}
+		hash, err := r.client.GetBlockHash(height - depth)
+		if err != nil {
+			return 0, err
+		}
+		if hash == *mainHash {
+			return height - depth, nil
+		}
+	}
+	return 0, ErrNoCommonAncestor
+}
+
+func (r *relayer) submit(context vm_context.AccountVmContext, ancestor uint32, headers []*wire.BlockHeader) error {
+	var last *types.Hash
+	for index, header := range headers {
+		hash := types.HexToHashPanic(header.BlockHash().String())
+
+		// headers of a fork we already relayed are still known by the contract
+		if _, err := definition.GetBlockHeaderVariable(context.Storage(), hash); err == nil {
+			continue
+		}
+
+		block, err := r.supervisor.GenerateFromTemplate(&nom.AccountBlock{
+			BlockType:     nom.BlockTypeUserSend,
+			Address:       r.keyPair.Address,
+			ToAddress:     types.MergeMiningContract,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        big.NewInt(0),
+			Data: definition.ABIMergeMining.PackMethodPanic(definition.AddBitcoinBlockHeaderMethodName,
+				header.Version,
+				types.HexToHashPanic(header.PrevBlock.String()),
+				types.HexToHashPanic(header.MerkleRoot.String()),
+				uint32(header.Timestamp.Unix()),
+				header.Bits,
+				header.Nonce,
+			),
+		}, r.keyPair.Signer)
+		if err != nil {
+			return err
+		}
+		r.broadcaster.CreateAccountBlock(block)
+		r.log.Info("submitted bitcoin header", "height", ancestor+uint32(index)+1, "hash", hash, "identifier", block.Block.Header())
+		last = &hash
+	}
+
+	if last != nil {
+		r.pending = last
+		r.pendingDeadline = common.Clock.Now().Add(r.config.SubmissionTimeout)
+	}
+	return nil
+}
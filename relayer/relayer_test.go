@@ -0,0 +1,104 @@
+package relayer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/relayer/mock"
+)
+
+func newHeader(version int32, prevBlock, merkleRoot string, timestamp int64, bits, nonce uint32) *wire.BlockHeader {
+	prev, err := chainhash.NewHashFromStr(prevBlock)
+	common.DealWithErr(err)
+	merkle, err := chainhash.NewHashFromStr(merkleRoot)
+	common.DealWithErr(err)
+	return &wire.BlockHeader{
+		Version:    version,
+		PrevBlock:  *prev,
+		MerkleRoot: *merkle,
+		Timestamp:  time.Unix(timestamp, 0),
+		Bits:       bits,
+		Nonce:      nonce,
+	}
+}
+
+// cannedHeaders returns the bitcoin mainnet headers at heights 838288 and 838289
+func cannedHeaders() []*wire.BlockHeader {
+	return []*wire.BlockHeader{
+		newHeader(644612096,
+			"0000000000000000000090937d63bfb7b27a1cf1073d6bd309195c62753c87b3",
+			"a76c7189bc34c9614d8848cc4074037db2f7cab329f646a23ad27a5628a573b1",
+			1712573645, 386097875, 1731415048),
+		newHeader(551550976,
+			"00000000000000000001052825fecaf9987861781cb11af3639603a381db34e4",
+			"7fad6c9b3fd2af85f631b5c0e13d6653a9e5ff268c60a78138a98da5e4cddf69",
+			1712575802, 386097875, 2118989352),
+	}
+}
+
+func TestBitcoinClient(t *testing.T) {
+	bitcoind := mock.NewMockBitcoind(838288, cannedHeaders())
+	defer bitcoind.Close()
+
+	for _, useREST := range []bool{false, true} {
+		client := NewBitcoinClient(&Config{
+			Endpoint: bitcoind.Endpoint(),
+			User:     "user",
+			Password: "password",
+			UseREST:  useREST,
+		})
+
+		count, err := client.GetBlockCount()
+		common.FailIfErr(t, err)
+		common.ExpectUint64(t, uint64(count), 838289)
+
+		hash, err := client.GetBlockHash(838289)
+		common.FailIfErr(t, err)
+		common.ExpectString(t, hash.String(), "000000000000000000000142eb893b9b422b5cf60ab352c9a8a2166f0c33c3d2")
+
+		headers, err := client.GetBlockHeaders(838288, 2)
+		common.FailIfErr(t, err)
+		common.ExpectUint64(t, uint64(len(headers)), 2)
+		common.ExpectString(t, headers[0].BlockHash().String(), "00000000000000000001052825fecaf9987861781cb11af3639603a381db34e4")
+		common.ExpectString(t, headers[1].BlockHash().String(), "000000000000000000000142eb893b9b422b5cf60ab352c9a8a2166f0c33c3d2")
+		common.ExpectUint64(t, uint64(headers[1].Nonce), 2118989352)
+
+		_, err = client.GetBlockHash(838290)
+		common.ExpectTrue(t, err != nil)
+	}
+}
+
+func TestBitcoinClient_Unreachable(t *testing.T) {
+	bitcoind := mock.NewMockBitcoind(838288, cannedHeaders())
+	endpoint := bitcoind.Endpoint()
+	bitcoind.Close()
+
+	for _, useREST := range []bool{false, true} {
+		client := NewBitcoinClient(&Config{
+			Endpoint: endpoint,
+			UseREST:  useREST,
+		})
+		_, err := client.GetBlockCount()
+		common.ExpectTrue(t, err != nil)
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	backoff := time.Duration(0)
+	expected := []time.Duration{
+		time.Second * 30,
+		time.Minute,
+		time.Minute * 2,
+		time.Minute * 4,
+		time.Minute * 5,
+		time.Minute * 5,
+	}
+	for _, e := range expected {
+		backoff = nextBackoff(backoff, time.Second*30, time.Minute*5)
+		common.ExpectUint64(t, uint64(backoff), uint64(e))
+	}
+}
@@ -19,16 +19,31 @@ import (
 
 type AcceleratorApi struct {
 	chain chain.Chain
+	z     zenon.Zenon
 	log   log15.Logger
 }
 
 func NewAcceleratorApi(z zenon.Zenon) *AcceleratorApi {
 	return &AcceleratorApi{
 		chain: z.Chain(),
+		z:     z,
 		log:   common.RPCLogger.New("module", "embedded_accelerator_api"),
 	}
 }
 
+func (a *AcceleratorApi) At(ref *api.MomentumRef) (*AcceleratorApi, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	return &historical, nil
+}
+
 func (a *AcceleratorApi) toProject(context vm_context.AccountVmContext, abiProject *definition.Project) *Project {
 	project := &Project{
 		Id:                  abiProject.Id,
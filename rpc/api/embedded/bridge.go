@@ -7,12 +7,14 @@ import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+	"math/big"
 	"reflect"
 	"sort"
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/vm/vm_context"
@@ -21,16 +23,31 @@ import (
 
 type BridgeApi struct {
 	chain chain.Chain
+	z     zenon.Zenon
 	log   log15.Logger
 }
 
 func NewBridgeApi(z zenon.Zenon) *BridgeApi {
 	return &BridgeApi{
 		chain: z.Chain(),
+		z:     z,
 		log:   common.RPCLogger.New("module", "embedded_bridge_api"),
 	}
 }
 
+func (a *BridgeApi) At(ref *api.MomentumRef) (*BridgeApi, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	return &historical, nil
+}
+
 func (a *BridgeApi) GetBridgeInfo() (*definition.BridgeInfoVariable, error) {
 	_, context, err := api.GetFrontierContext(a.chain, types.BridgeContract)
 	if err != nil {
@@ -85,7 +102,7 @@ func (a *BridgeApi) GetTimeChallengesInfo() (*TimeChallengesList, error) {
 	}
 
 	ans := make([]*definition.TimeChallengeInfo, 0)
-	methods := []string{"NominateGuardians", "ChangeTssECDSAPubKey", "ChangeAdministrator", "SetTokenPair"}
+	methods := []string{"NominateGuardians", "ChangeTssECDSAPubKey", "ChangeAdministrator", "SetTokenPair", "SetTokenPairLimits", "SetFeeDistribution"}
 
 	for _, m := range methods {
 		timeC, err := definition.GetTimeChallengeInfoVariable(context.Storage(), m)
@@ -676,3 +693,252 @@ func (a *BridgeApi) GetFeeTokenPair(zts types.ZenonTokenStandard) (*definition.Z
 	}
 	return definition.GetZtsFeesInfoVariable(context.Storage(), zts)
 }
+
+func (a *BridgeApi) GetFeeDistribution() (*definition.FeeDistribution, error) {
+	_, context, err := api.GetFrontierContext(a.chain, types.BridgeContract)
+	if err != nil {
+		return nil, err
+	}
+	return definition.GetFeeDistribution(context.Storage())
+}
+
+type FeeDistributionRecordList struct {
+	Count int                                 `json:"count"`
+	List  []*definition.FeeDistributionRecord `json:"list"`
+}
+
+// GetFeeDistributionHistory returns the fee distributions of all tokens, the most recent first
+func (a *BridgeApi) GetFeeDistributionHistory(pageIndex, pageSize uint32) (*FeeDistributionRecordList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	_, context, err := api.GetFrontierContext(a.chain, types.BridgeContract)
+	if err != nil {
+		return nil, err
+	}
+	records, err := definition.GetFeeDistributionRecords(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+	start, end := api.GetRange(pageIndex, pageSize, uint32(len(records)))
+	return &FeeDistributionRecordList{
+		Count: len(records),
+		List:  records[start:end],
+	}, nil
+}
+
+type TokenPairLimits struct {
+	NetworkClass    uint32                   `json:"networkClass"`
+	ChainId         uint32                   `json:"chainId"`
+	TokenStandard   types.ZenonTokenStandard `json:"tokenStandard"`
+	WindowSize      uint64                   `json:"windowSize"`
+	MaxWrapAmount   string                   `json:"maxWrapAmount"`
+	MaxRedeemAmount string                   `json:"maxRedeemAmount"`
+	WrapVolume      string                   `json:"wrapVolume"`
+	RedeemVolume    string                   `json:"redeemVolume"`
+	// nil if there is no limit
+	RemainingWrapAmount   *string `json:"remainingWrapAmount"`
+	RemainingRedeemAmount *string `json:"remainingRedeemAmount"`
+}
+
+func remainingToString(remaining *big.Int) *string {
+	if remaining == nil {
+		return nil
+	}
+	value := remaining.String()
+	return &value
+}
+
+// GetTokenPairLimits returns the limits of the token pair and the capacity left in the current rolling window
+func (a *BridgeApi) GetTokenPairLimits(networkClass, chainId uint32, zts types.ZenonTokenStandard) (*TokenPairLimits, error) {
+	momentum, context, err := api.GetFrontierContext(a.chain, types.BridgeContract)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := definition.GetTokenPairLimits(context.Storage(), networkClass, chainId, zts)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, redeemed := implementation.TokenPairVolumes(limits, momentum.Height)
+	return &TokenPairLimits{
+		NetworkClass:          networkClass,
+		ChainId:               chainId,
+		TokenStandard:         zts,
+		WindowSize:            limits.WindowSize,
+		MaxWrapAmount:         limits.MaxWrapAmount.String(),
+		MaxRedeemAmount:       limits.MaxRedeemAmount.String(),
+		WrapVolume:            wrapped.String(),
+		RedeemVolume:          redeemed.String(),
+		RemainingWrapAmount:   remainingToString(implementation.RemainingCapacity(limits.MaxWrapAmount, wrapped)),
+		RemainingRedeemAmount: remainingToString(implementation.RemainingCapacity(limits.MaxRedeemAmount, redeemed)),
+	}, nil
+}
+
+// GetWrapTokenRequestsByFilter returns the wrap requests selected by filter, the most recent first.
+// Requires the bridge index to be enabled in the node config.
+func (a *BridgeApi) GetWrapTokenRequestsByFilter(filter *indexer.BridgeRequestFilter, pageIndex, pageSize uint32) (*WrapTokenRequestList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	idx, err := api.IndexerOf(a.z, a.chain)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, count, err := idx.GetWrapTokenRequests(filter, int(pageIndex)*int(pageSize), int(pageSize))
+	if err != nil {
+		return nil, err
+	}
+	_, context, err := api.GetFrontierContext(a.chain, types.BridgeContract)
+	if err != nil {
+		return nil, err
+	}
+	momentum, err := context.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	orchestratorInfo, err := definition.GetOrchestratorInfoVariable(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WrapTokenRequestList{
+		Count: count,
+		List:  make([]*WrapTokenRequest, 0),
+	}
+	for _, request := range requests {
+		token, err := a.getToken(request.TokenStandard)
+		if err != nil {
+			continue
+		}
+		confirmationsToFinality, err := a.getConfirmationsToFinality(*request, orchestratorInfo.ConfirmationsToFinality, *momentum)
+		if err != nil {
+			continue
+		}
+		result.List = append(result.List, &WrapTokenRequest{request, token, confirmationsToFinality})
+	}
+	return result, nil
+}
+
+// GetUnwrapTokenRequestsByFilter returns the unwrap requests selected by filter, the most recent first.
+// Requires the bridge index to be enabled in the node config.
+func (a *BridgeApi) GetUnwrapTokenRequestsByFilter(filter *indexer.BridgeRequestFilter, pageIndex, pageSize uint32) (*UnwrapTokenRequestList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	idx, err := api.IndexerOf(a.z, a.chain)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, count, err := idx.GetUnwrapTokenRequests(filter, int(pageIndex)*int(pageSize), int(pageSize))
+	if err != nil {
+		return nil, err
+	}
+	_, context, err := api.GetFrontierContext(a.chain, types.BridgeContract)
+	if err != nil {
+		return nil, err
+	}
+	momentum, err := context.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UnwrapTokenRequestList{
+		Count: count,
+		List:  make([]*UnwrapTokenRequest, 0),
+	}
+	for _, request := range requests {
+		token, err := a.getToken(request.TokenStandard)
+		if err != nil {
+			continue
+		}
+		tokenPair, err := implementation.CheckNetworkAndPairExist(context, request.NetworkClass, request.ChainId, request.TokenAddress)
+		if err != nil {
+			return nil, err
+		}
+		if tokenPair == nil {
+			return nil, errors.New("token pair not found")
+		}
+		redeemableIn := a.getRedeemableIn(*request, *tokenPair, *momentum)
+		result.List = append(result.List, &UnwrapTokenRequest{request, token, redeemableIn})
+	}
+	return result, nil
+}
+
+// TokenPairStats aggregates the requests of a token pair. Revoked unwrap requests are not counted.
+// Outstanding is the supply which must be backed: for a token not owned by the bridge, the amount minted on the
+// other chain and backed by the balance of the bridge, for an owned token, the amount minted on NoM and backed by
+// the contract on the other chain. It is negative when more tokens came back than the requests account for, for
+// example tokens which were issued on NoM before being bridged.
+type TokenPairStats struct {
+	NetworkClass   uint32                   `json:"networkClass"`
+	ChainId        uint32                   `json:"chainId"`
+	TokenStandard  types.ZenonTokenStandard `json:"tokenStandard"`
+	TokenAddress   string                   `json:"tokenAddress"`
+	Owned          bool                     `json:"owned"`
+	WrapCount      uint64                   `json:"wrapCount"`
+	UnwrapCount    uint64                   `json:"unwrapCount"`
+	TotalWrapped   string                   `json:"totalWrapped"`
+	TotalFees      string                   `json:"totalFees"`
+	TotalUnwrapped string                   `json:"totalUnwrapped"`
+	TotalRedeemed  string                   `json:"totalRedeemed"`
+	Outstanding    string                   `json:"outstanding"`
+}
+
+type TokenPairStatsList struct {
+	Count int               `json:"count"`
+	List  []*TokenPairStats `json:"list"`
+}
+
+// GetTokenPairStats returns the wrapped and unwrapped volumes and the outstanding supply of every token pair.
+// Requires the bridge index to be enabled in the node config.
+func (a *BridgeApi) GetTokenPairStats() (*TokenPairStatsList, error) {
+	idx, err := api.IndexerOf(a.z, a.chain)
+	if err != nil {
+		return nil, err
+	}
+	_, context, err := api.GetFrontierContext(a.chain, types.BridgeContract)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := definition.GetNetworkList(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TokenPairStatsList{
+		List: make([]*TokenPairStats, 0),
+	}
+	for _, network := range networks {
+		for _, pair := range network.TokenPairs {
+			stats, err := idx.GetBridgeTokenPairStats(network.NetworkClass, network.Id, pair.TokenStandard)
+			if err != nil {
+				return nil, err
+			}
+			outstanding := new(big.Int).Sub(stats.Wrapped, stats.Fees)
+			if pair.Owned {
+				outstanding.Sub(stats.Unwrapped, outstanding)
+			} else {
+				outstanding.Sub(outstanding, stats.Unwrapped)
+			}
+			result.List = append(result.List, &TokenPairStats{
+				NetworkClass:   network.NetworkClass,
+				ChainId:        network.Id,
+				TokenStandard:  pair.TokenStandard,
+				TokenAddress:   pair.TokenAddress,
+				Owned:          pair.Owned,
+				WrapCount:      stats.WrapCount,
+				UnwrapCount:    stats.UnwrapCount,
+				TotalWrapped:   stats.Wrapped.String(),
+				TotalFees:      stats.Fees.String(),
+				TotalUnwrapped: stats.Unwrapped.String(),
+				TotalRedeemed:  stats.Redeemed.String(),
+				Outstanding:    outstanding.String(),
+			})
+		}
+	}
+	result.Count = len(result.List)
+	return result, nil
+}
@@ -16,6 +16,13 @@ type ConsensusCache interface {
 	Get() (weights map[string]*big.Int, currentStats *api.EpochStats)
 }
 
+// noConsensusCache is used when reading historical state, weights and stats are left out
+type noConsensusCache struct{}
+
+func (noConsensusCache) Get() (map[string]*big.Int, *api.EpochStats) {
+	return nil, nil
+}
+
 type consensusCache struct {
 	testing   bool
 	log       common.Logger
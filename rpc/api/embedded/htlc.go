@@ -29,6 +29,19 @@ func NewHtlcApi(z zenon.Zenon) *HtlcApi {
 	}
 }
 
+func (a *HtlcApi) At(ref *api.MomentumRef) (*HtlcApi, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	return &historical, nil
+}
+
 func (a *HtlcApi) GetById(id types.Hash) (*definition.HtlcInfo, error) {
 
 	_, context, err := api.GetFrontierContext(a.chain, types.HtlcContract)
@@ -51,3 +64,110 @@ func (a *HtlcApi) GetProxyUnlockStatus(address types.Address) (bool, error) {
 	}
 	return implementation.GetHtlcProxyUnlockStatus(context, address)
 }
+
+type HtlcHashType struct {
+	HashType   uint8  `json:"hashType"`
+	Name       string `json:"name"`
+	DigestSize uint8  `json:"digestSize"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// GetHashTypes returns every hash type known by the htlc contract and whether new htlcs can use it
+func (a *HtlcApi) GetHashTypes() ([]*HtlcHashType, error) {
+	_, context, err := api.GetFrontierContext(a.chain, types.HtlcContract)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := implementation.HtlcHashTypes
+	if context.IsHtlcHashTypesSporkEnforced() {
+		enabled = implementation.HtlcExtendedHashTypes
+	}
+
+	hashTypes := make([]*HtlcHashType, 0, len(definition.HashTypeDigestSizes))
+	for hashType := uint8(0); int(hashType) < len(definition.HashTypeDigestSizes); hashType++ {
+		hashTypes = append(hashTypes, &HtlcHashType{
+			HashType:   hashType,
+			Name:       definition.HashTypeNames[hashType],
+			DigestSize: definition.HashTypeDigestSizes[hashType],
+			Enabled:    enabled[hashType],
+		})
+	}
+	return hashTypes, nil
+}
+
+type HtlcInfoList struct {
+	Count int                    `json:"count"`
+	List  []*definition.HtlcInfo `json:"list"`
+}
+
+// GetHtlcsByTimeLocked returns the active htlcs created by address, which can reclaim them after expiration.
+// The ones which expire first are returned first. Requires the htlc index to be enabled in the node config.
+func (a *HtlcApi) GetHtlcsByTimeLocked(address types.Address, pageIndex, pageSize uint32) (*HtlcInfoList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	idx, err := api.IndexerOf(a.z, a.chain)
+	if err != nil {
+		return nil, err
+	}
+	list, count, err := idx.GetHtlcsByTimeLocked(address, int(pageIndex)*int(pageSize), int(pageSize))
+	if err != nil {
+		return nil, err
+	}
+	return &HtlcInfoList{
+		Count: count,
+		List:  list,
+	}, nil
+}
+
+// GetHtlcsByHashLocked returns the active htlcs which address can unlock with the preimage.
+// The ones which expire first are returned first. Requires the htlc index to be enabled in the node config.
+func (a *HtlcApi) GetHtlcsByHashLocked(address types.Address, pageIndex, pageSize uint32) (*HtlcInfoList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	idx, err := api.IndexerOf(a.z, a.chain)
+	if err != nil {
+		return nil, err
+	}
+	list, count, err := idx.GetHtlcsByHashLocked(address, int(pageIndex)*int(pageSize), int(pageSize))
+	if err != nil {
+		return nil, err
+	}
+	return &HtlcInfoList{
+		Count: count,
+		List:  list,
+	}, nil
+}
+
+// GetHtlcByHashLock returns the active htlcs locked with hashLock.
+// Anyone can create an htlc with a known hash lock, so callers should check the participants and amount of each one.
+// Requires the htlc index to be enabled in the node config.
+func (a *HtlcApi) GetHtlcByHashLock(hashLock []byte) ([]*definition.HtlcInfo, error) {
+	idx, err := api.IndexerOf(a.z, a.chain)
+	if err != nil {
+		return nil, err
+	}
+	return idx.GetHtlcsByHashLock(hashLock)
+}
+
+// GetExpiringHtlcs returns the active htlcs which expire before the given unix timestamp,
+// including expired htlcs which haven't been reclaimed yet. Requires the htlc index to be enabled in the node config.
+func (a *HtlcApi) GetExpiringHtlcs(before int64, pageIndex, pageSize uint32) (*HtlcInfoList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	idx, err := api.IndexerOf(a.z, a.chain)
+	if err != nil {
+		return nil, err
+	}
+	list, count, err := idx.GetExpiringHtlcs(before, int(pageIndex)*int(pageSize), int(pageSize))
+	if err != nil {
+		return nil, err
+	}
+	return &HtlcInfoList{
+		Count: count,
+		List:  list,
+	}, nil
+}
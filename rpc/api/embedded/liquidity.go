@@ -15,16 +15,31 @@ import (
 
 type LiquidityApi struct {
 	chain chain.Chain
+	z     zenon.Zenon
 	log   log15.Logger
 }
 
 func NewLiquidityApi(z zenon.Zenon) *LiquidityApi {
 	return &LiquidityApi{
 		chain: z.Chain(),
+		z:     z,
 		log:   common.RPCLogger.New("module", "embedded_liquidity_api"),
 	}
 }
 
+func (a *LiquidityApi) At(ref *api.MomentumRef) (*LiquidityApi, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	return &historical, nil
+}
+
 func (a *LiquidityApi) GetLiquidityInfo() (*definition.LiquidityInfo, error) {
 	_, context, err := api.GetFrontierContext(a.chain, types.LiquidityContract)
 	if err != nil {
@@ -6,22 +6,39 @@ import (
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
 type MergeMiningApi struct {
 	chain chain.Chain
+	z     zenon.Zenon
 	log   log15.Logger
 }
 
 func NewMergeMiningApi(z zenon.Zenon) *MergeMiningApi {
 	return &MergeMiningApi{
 		chain: z.Chain(),
+		z:     z,
 		log:   common.RPCLogger.New("module", "embedded_merge_mining_api"),
 	}
 }
 
+func (a *MergeMiningApi) At(ref *api.MomentumRef) (*MergeMiningApi, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	return &historical, nil
+}
+
 func (a *MergeMiningApi) GetMergeMiningInfo() (*definition.MergeMiningInfoVariable, error) {
 	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
 	if err != nil {
@@ -40,6 +57,16 @@ func (a *MergeMiningApi) GetHeaderChainInfo() (*definition.HeaderChainInfoVariab
 	return definition.GetHeaderChainInfoVariableVariable(context.Storage())
 }
 
+// GetPowParams returns the proof of work rules the header chain follows
+func (a *MergeMiningApi) GetPowParams() (*constants.PowParams, error) {
+	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
+	if err != nil {
+		return nil, err
+	}
+
+	return implementation.GetPowParams(context.Storage()), nil
+}
+
 func (a *MergeMiningApi) GetShareChainInfo(id uint8) (*definition.ShareChainInfoVariable, error) {
 	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
 	if err != nil {
@@ -58,6 +85,163 @@ func (a *MergeMiningApi) GetBlockHeader(hash types.Hash) (*definition.BlockHeade
 	return definition.GetBlockHeaderVariable(context.Storage(), hash)
 }
 
+func (a *MergeMiningApi) GetMainChainHeaderByHeight(height uint32) (*definition.BlockHeaderVariable, error) {
+	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
+	if err != nil {
+		return nil, err
+	}
+
+	return definition.GetMainChainHeaderByHeight(context.Storage(), height)
+}
+
+// GetHeaderConfirmations returns how deep a block header is in the best chain, 0 if it is on a stale fork
+func (a *MergeMiningApi) GetHeaderConfirmations(hash types.Hash) (uint32, error) {
+	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
+	if err != nil {
+		return 0, err
+	}
+
+	return implementation.GetHeaderConfirmations(context.Storage(), hash)
+}
+
+type BlockHeaderList struct {
+	Count int                               `json:"count"`
+	List  []*definition.BlockHeaderVariable `json:"list"`
+}
+
+// GetMainChainHeaders returns the block headers of the best chain, tip first
+func (a *MergeMiningApi) GetMainChainHeaders(pageIndex, pageSize uint32) (*BlockHeaderList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
+	if err != nil {
+		return nil, err
+	}
+
+	headerChainInfo, err := definition.GetHeaderChainInfoVariableVariable(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+	firstHeight, err := definition.GetMainChainFirstHeight(context.Storage())
+	if err == constants.ErrDataNonExistent {
+		return &BlockHeaderList{
+			Count: 0,
+			List:  make([]*definition.BlockHeaderVariable, 0),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	count := headerChainInfo.TipHeight - firstHeight + 1
+	start, end := api.GetRange(pageIndex, pageSize, count)
+	list := make([]*definition.BlockHeaderVariable, 0, end-start)
+	for index := start; index < end; index += 1 {
+		header, err := definition.GetMainChainHeaderByHeight(context.Storage(), headerChainInfo.TipHeight-index)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, header)
+	}
+
+	return &BlockHeaderList{
+		Count: int(count),
+		List:  list,
+	}, nil
+}
+
+func (a *MergeMiningApi) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
+	return getUncollectedReward(a.chain, types.MergeMiningContract, address)
+}
+func (a *MergeMiningApi) GetFrontierRewardByPage(address types.Address, pageIndex, pageSize uint32) (*RewardHistoryList, error) {
+	return getFrontierRewardByPage(a.chain, types.MergeMiningContract, address, pageIndex, pageSize)
+}
+
+type SharesInfoList struct {
+	Count int                      `json:"count"`
+	List  []*definition.SharesInfo `json:"list"`
+}
+
+// GetSharesByAddress returns the shares accounted to an address for each epoch, latest epoch first
+func (a *MergeMiningApi) GetSharesByAddress(address types.Address, pageIndex, pageSize uint32) (*SharesInfoList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := definition.GetSharesInfoListByAddress(context.Storage(), address)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+
+	start, end := api.GetRange(pageIndex, pageSize, uint32(len(list)))
+	return &SharesInfoList{
+		Count: len(list),
+		List:  list[start:end],
+	}, nil
+}
+
+type TransactionVerification struct {
+	TxId          types.Hash `json:"txId"`
+	BlockHash     types.Hash `json:"blockHash"`
+	BlockHeight   uint32     `json:"blockHeight"`
+	Index         uint32     `json:"index"`
+	Confirmations uint32     `json:"confirmations"`
+	// Confirmed is true if the block has enough confirmations for the transaction to be verified by the contract
+	Confirmed bool `json:"confirmed"`
+	// Recorded is true if the transaction was already verified by the contract
+	Recorded bool `json:"recorded"`
+}
+
+// VerifyTransaction checks the inclusion of a Bitcoin transaction in a stored block header without recording it
+func (a *MergeMiningApi) VerifyTransaction(transaction []byte, merkleBranch []types.Hash, index uint32, blockHash types.Hash) (*TransactionVerification, error) {
+	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
+	if err != nil {
+		return nil, err
+	}
+
+	verified, confirmations, err := implementation.CheckTransactionProof(context.Storage(), &definition.TransactionProof{
+		Transaction:  transaction,
+		MerkleBranch: merkleBranch,
+		Index:        index,
+		BlockHash:    blockHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = definition.GetVerifiedTransaction(context.Storage(), verified.TxId)
+	if err != nil && err != constants.ErrDataNonExistent {
+		return nil, err
+	}
+
+	return &TransactionVerification{
+		TxId:          verified.TxId,
+		BlockHash:     verified.BlockHash,
+		BlockHeight:   verified.BlockHeight,
+		Index:         verified.Index,
+		Confirmations: confirmations,
+		Confirmed:     confirmations >= constants.TransactionConfirmations,
+		Recorded:      err == nil,
+	}, nil
+}
+
+func (a *MergeMiningApi) GetVerifiedTransaction(txId types.Hash) (*definition.VerifiedTransaction, error) {
+	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
+	if err != nil {
+		return nil, err
+	}
+
+	return definition.GetVerifiedTransaction(context.Storage(), txId)
+}
+
 func (a *MergeMiningApi) GetSecurityInfo() (*definition.SecurityInfoVariable, error) {
 	_, context, err := api.GetFrontierContext(a.chain, types.MergeMiningContract)
 	if err != nil {
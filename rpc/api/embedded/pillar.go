@@ -20,6 +20,7 @@ import (
 type PillarApi struct {
 	log            log15.Logger
 	chain          chain.Chain
+	z              zenon.Zenon
 	consensusCache ConsensusCache
 }
 
@@ -27,10 +28,28 @@ func NewPillarApi(z zenon.Zenon, testing bool) *PillarApi {
 	return &PillarApi{
 		log:            common.RPCLogger.New("module", "embedded_pillar_api"),
 		chain:          z.Chain(),
+		z:              z,
 		consensusCache: NewConsensusCache(z, testing),
 	}
 }
 
+func (a *PillarApi) At(ref *api.MomentumRef) (*PillarApi, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	// weights and production stats are only known for the frontier
+	if ref != nil {
+		historical.consensusCache = noConsensusCache{}
+	}
+	return &historical, nil
+}
+
 var (
 	PillarActive   uint8 = 1
 	PillarInActive uint8 = 2
@@ -35,6 +35,19 @@ func NewPlasmaApi(z zenon.Zenon) *PlasmaApi {
 	}
 }
 
+func (a *PlasmaApi) At(ref *api.MomentumRef) (*PlasmaApi, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	return &historical, nil
+}
+
 type PlasmaInfo struct {
 	CurrentPlasma uint64   `json:"currentPlasma"`
 	MaxPlasma     uint64   `json:"maxPlasma"`
@@ -14,6 +14,7 @@ import (
 
 type SentinelApi struct {
 	chain chain.Chain
+	z     zenon.Zenon
 	log   log15.Logger
 }
 
@@ -32,10 +33,24 @@ type SentinelInfoList struct {
 func NewSentinelApi(z zenon.Zenon) *SentinelApi {
 	return &SentinelApi{
 		chain: z.Chain(),
+		z:     z,
 		log:   common.RPCLogger.New("module", "embedded_sentinel_api"),
 	}
 }
 
+func (api *SentinelApi) At(ref *rpcapi.MomentumRef) (*SentinelApi, error) {
+	if ref == nil {
+		return api, nil
+	}
+	c, err := rpcapi.ChainAt(api.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *api
+	historical.chain = c
+	return &historical, nil
+}
+
 func (api *SentinelApi) toSentinelInfo(sentinel *definition.SentinelInfo) *SentinelInfo {
 	m, _, err := rpcapi.GetFrontierContext(api.chain, types.SentinelContract)
 	if err != nil {
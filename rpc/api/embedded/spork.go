@@ -28,6 +28,19 @@ func NewSporkApi(z zenon.Zenon) *SporkApi {
 	}
 }
 
+func (a *SporkApi) At(ref *api.MomentumRef) (*SporkApi, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	return &historical, nil
+}
+
 type SporkList struct {
 	Count uint32              `json:"count"`
 	List  []*definition.Spork `json:"list"`
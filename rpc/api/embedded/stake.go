@@ -32,6 +32,19 @@ func NewStakeApi(z zenon.Zenon) *StakeApi {
 	}
 }
 
+func (a *StakeApi) At(ref *api.MomentumRef) (*StakeApi, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	return &historical, nil
+}
+
 // === Shared RPCs ===
 
 func (a *StakeApi) GetUncollectedReward(address types.Address) (*definition.RewardDeposit, error) {
@@ -20,6 +20,7 @@ import (
 
 type SwapApi struct {
 	chain     chain.Chain
+	z         zenon.Zenon
 	consensus consensus.Consensus
 	log       log15.Logger
 }
@@ -27,11 +28,25 @@ type SwapApi struct {
 func NewSwapApi(z zenon.Zenon) *SwapApi {
 	return &SwapApi{
 		chain:     z.Chain(),
+		z:         z,
 		consensus: z.Consensus(),
 		log:       common.RPCLogger.New("module", "rpc_api/embedded_swap_api"),
 	}
 }
 
+func (p *SwapApi) At(ref *api.MomentumRef) (*SwapApi, error) {
+	if ref == nil {
+		return p, nil
+	}
+	c, err := api.ChainAt(p.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *p
+	historical.chain = c
+	return &historical, nil
+}
+
 type SwapAssetEntry struct {
 	KeyIdHash string   `json:"keyIdHash"`
 	Znn       *big.Int `json:"znn"`
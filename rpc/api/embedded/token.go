@@ -1,12 +1,15 @@
 package embedded
 
 import (
+	"math/big"
+
 	"github.com/inconshreveable/log15"
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
@@ -29,6 +32,19 @@ func NewTokenApi(z zenon.Zenon) *TokenAPI {
 	}
 }
 
+func (a *TokenAPI) At(ref *api.MomentumRef) (*TokenAPI, error) {
+	if ref == nil {
+		return a, nil
+	}
+	c, err := api.ChainAt(a.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *a
+	historical.chain = c
+	return &historical, nil
+}
+
 type TokenList struct {
 	Count int          `json:"count"`
 	List  []*api.Token `json:"list"`
@@ -99,3 +115,102 @@ func (a *TokenAPI) GetByZts(zts types.ZenonTokenStandard) (*api.Token, error) {
 	}
 	return nil, nil
 }
+
+type TokenHolder struct {
+	Address types.Address `json:"address"`
+	Balance string        `json:"balance"`
+}
+
+// TokenHolderList is a page of the holders of a token, sorted by balance, largest first.
+// IndexedHeight is the height of the last momentum included in the token holder index.
+type TokenHolderList struct {
+	Count         uint64         `json:"count"`
+	List          []*TokenHolder `json:"list"`
+	IndexedHeight uint64         `json:"indexedHeight"`
+}
+
+// TokenDistribution is the share of the held supply of a token which belongs to its top holders.
+// HeldSupply is the sum of the balances of all holders, TopShare is TopBalance / HeldSupply.
+type TokenDistribution struct {
+	HolderCount   uint64  `json:"holderCount"`
+	HeldSupply    string  `json:"heldSupply"`
+	Top           uint32  `json:"top"`
+	TopBalance    string  `json:"topBalance"`
+	TopShare      float64 `json:"topShare"`
+	IndexedHeight uint64  `json:"indexedHeight"`
+}
+
+func tokenHoldersToRpc(holders []*indexer.TokenHolder) []*TokenHolder {
+	list := make([]*TokenHolder, len(holders))
+	for index, holder := range holders {
+		list[index] = &TokenHolder{
+			Address: holder.Address,
+			Balance: holder.Balance.String(),
+		}
+	}
+	return list
+}
+
+// GetHolders requires the token holder index to be enabled in the node config
+func (a *TokenAPI) GetHolders(zts types.ZenonTokenStandard, pageIndex, pageSize uint32) (*TokenHolderList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	idx, err := api.IndexerOf(a.z, a.chain)
+	if err != nil {
+		return nil, err
+	}
+
+	indexedHeight := idx.IndexedHeight()
+	holders, err := idx.GetTokenHolders(zts)
+	if err != nil {
+		return nil, err
+	}
+	list, err := idx.GetTopTokenHolders(zts, int(pageIndex)*int(pageSize), int(pageSize))
+	if err != nil {
+		return nil, err
+	}
+	return &TokenHolderList{
+		Count:         holders.Count,
+		List:          tokenHoldersToRpc(list),
+		IndexedHeight: indexedHeight,
+	}, nil
+}
+
+// GetDistribution returns the concentration of a token in its top holders.
+// Requires the token holder index to be enabled in the node config.
+func (a *TokenAPI) GetDistribution(zts types.ZenonTokenStandard, top uint32) (*TokenDistribution, error) {
+	if top > api.RpcMaxCountSize {
+		return nil, api.ErrCountParamTooBig
+	}
+	idx, err := api.IndexerOf(a.z, a.chain)
+	if err != nil {
+		return nil, err
+	}
+
+	indexedHeight := idx.IndexedHeight()
+	holders, err := idx.GetTokenHolders(zts)
+	if err != nil {
+		return nil, err
+	}
+	list, err := idx.GetTopTokenHolders(zts, 0, int(top))
+	if err != nil {
+		return nil, err
+	}
+	topBalance := big.NewInt(0)
+	for _, holder := range list {
+		topBalance.Add(topBalance, holder.Balance)
+	}
+	topShare := float64(0)
+	if holders.Total.Sign() != 0 {
+		topShare, _ = new(big.Rat).SetFrac(topBalance, holders.Total).Float64()
+	}
+	return &TokenDistribution{
+		HolderCount:   holders.Count,
+		HeldSupply:    holders.Total.String(),
+		Top:           top,
+		TopBalance:    topBalance.String(),
+		TopShare:      topShare,
+		IndexedHeight: indexedHeight,
+	}, nil
+}
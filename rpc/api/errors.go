@@ -10,4 +10,8 @@ var (
 	ErrCountParamTooBig     = common.NewErrorWCode(-32000, "count parameter is too big")
 	ErrHeightParamIsZero    = common.NewErrorWCode(-32000, "height parameter must be strictly greater than zero")
 	ErrParamIsNull          = common.NewErrorWCode(-32000, "parameter must not be null")
+
+	ErrMomentumNotFound           = common.NewErrorWCode(-32000, "momentum not found")
+	ErrHistoricalStateUnavailable = common.NewErrorWCode(-32000, "state at momentum is not available, it is too old for a non-archive node")
+	ErrFrontierOnly               = common.NewErrorWCode(-32000, "method can only be called at the frontier")
 )
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/indexer"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+const (
+	// HistoricalStateWindow is the number of momentums behind the frontier whose state is served by non-archive nodes.
+	// Older states are rebuilt by applying the rollback of every momentum down to the requested one, which is
+	// only allowed on archive nodes.
+	HistoricalStateWindow = 360
+)
+
+// MomentumRef selects the momentum at which state is read, either by height or by hash.
+// In JSON, a number is a height and a string is a hash.
+type MomentumRef struct {
+	Height uint64
+	Hash   *types.Hash
+}
+
+func (r *MomentumRef) UnmarshalJSON(data []byte) error {
+	if len(data) != 0 && data[0] == '"' {
+		hash := new(types.Hash)
+		if err := json.Unmarshal(data, hash); err != nil {
+			return err
+		}
+		r.Hash = hash
+		return nil
+	}
+	height, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return err
+	}
+	r.Height = height
+	return nil
+}
+func (r *MomentumRef) MarshalJSON() ([]byte, error) {
+	if r.Hash != nil {
+		return json.Marshal(r.Hash)
+	}
+	return json.Marshal(r.Height)
+}
+
+// ChainAt returns a read-only view of the chain as it was right after the momentum referenced by at.
+// The view has no uncommitted account-blocks. A nil reference returns the chain itself.
+//
+// The apis which can read at a past momentum implement At on top of it, the rpc server then
+// accepts the reference as an optional trailing argument of all their methods.
+func ChainAt(z zenon.Zenon, at *MomentumRef) (chain.Chain, error) {
+	c := z.Chain()
+	if at == nil {
+		return c, nil
+	}
+
+	frontierStore := c.GetFrontierMomentumStore()
+	frontier, err := frontierStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	var momentum *nom.Momentum
+	if at.Hash != nil {
+		momentum, err = frontierStore.GetMomentumByHash(*at.Hash)
+	} else {
+		if at.Height == 0 {
+			return nil, ErrHeightParamIsZero
+		}
+		momentum, err = frontierStore.GetMomentumByHeight(at.Height)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if momentum == nil {
+		return nil, ErrMomentumNotFound
+	}
+	if momentum.Height == frontier.Height {
+		return &historicalChain{Chain: c, momentumStore: frontierStore}, nil
+	}
+
+	if momentum.Height <= c.GetPrunedHeight() {
+		return nil, store.ErrDataPruned
+	}
+	archive := z.Config() != nil && z.Config().Archive
+	if !archive && frontier.Height-momentum.Height > HistoricalStateWindow {
+		return nil, ErrHistoricalStateUnavailable
+	}
+
+	momentumStore := c.GetMomentumStore(momentum.Identifier())
+	if momentumStore == nil {
+		return nil, ErrHistoricalStateUnavailable
+	}
+	return &historicalChain{Chain: c, momentumStore: momentumStore}, nil
+}
+
+// historicalChain answers frontier queries with the state of a committed momentum.
+// Account stores are read from the momentum store, so they only contain confirmed account-blocks.
+type historicalChain struct {
+	chain.Chain
+	momentumStore store.Momentum
+}
+
+func (c *historicalChain) GetFrontierMomentumStore() store.Momentum {
+	return c.momentumStore
+}
+func (c *historicalChain) GetFrontierAccountStore(address types.Address) store.Account {
+	return c.momentumStore.GetAccountStore(address)
+}
+func (c *historicalChain) GetNewMomentumContent() []*nom.AccountBlock {
+	return []*nom.AccountBlock{}
+}
+func (c *historicalChain) GetAllUncommittedAccountBlocks() []*nom.AccountBlock {
+	return []*nom.AccountBlock{}
+}
+func (c *historicalChain) GetUncommittedAccountBlocksByAddress(types.Address) []*nom.AccountBlock {
+	return []*nom.AccountBlock{}
+}
+
+// IndexerOf returns the indexer of the node for an api which reads from c.
+// The indexes only follow the frontier, so they can't serve reads at a past momentum.
+func IndexerOf(z zenon.Zenon, c chain.Chain) (indexer.Manager, error) {
+	if _, ok := c.(*historicalChain); ok {
+		return nil, ErrFrontierOnly
+	}
+	idx := z.Indexer()
+	if idx == nil {
+		return nil, indexer.ErrIndexDisabled
+	}
+	return idx, nil
+}
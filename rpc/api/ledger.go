@@ -8,6 +8,7 @@ import (
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/vm"
@@ -40,11 +41,29 @@ func (l LedgerApi) String() string {
 	return "LedgerApi"
 }
 
+// At returns a copy of the api which reads the state right after the referenced momentum.
+// Account-blocks can't be published or simulated on it.
+func (l *LedgerApi) At(ref *MomentumRef) (*LedgerApi, error) {
+	if ref == nil {
+		return l, nil
+	}
+	c, err := ChainAt(l.z, ref)
+	if err != nil {
+		return nil, err
+	}
+	historical := *l
+	historical.chain = c
+	return &historical, nil
+}
+
 func (l *LedgerApi) PublishRawTransaction(block *AccountBlock) error {
 	defer common.RecoverStack()
 	if block == nil {
 		return ErrParamIsNull
 	}
+	if _, ok := l.chain.(*historicalChain); ok {
+		return ErrFrontierOnly
+	}
 
 	if block.ChainIdentifier != 0 && block.ChainIdentifier != l.chain.ChainIdentifier() {
 		return errors.Errorf("the block has a different network Id (%d) from the node (%d)", block.ChainIdentifier, l.chain.ChainIdentifier())
@@ -73,6 +92,41 @@ func (l *LedgerApi) PublishRawTransaction(block *AccountBlock) error {
 	return nil
 }
 
+// SimulateAccountBlock applies an unsigned send-block template on top of the frontier without publishing it.
+// For calls to embedded contracts it also runs the contract, so wallets can preview the result of the call.
+func (l *LedgerApi) SimulateAccountBlock(block *AccountBlock) (*AccountBlockSimulation, error) {
+	if block == nil {
+		return nil, ErrParamIsNull
+	}
+	if _, ok := l.chain.(*historicalChain); ok {
+		return nil, ErrFrontierOnly
+	}
+
+	if block.ChainIdentifier != 0 && block.ChainIdentifier != l.chain.ChainIdentifier() {
+		return nil, errors.Errorf("the block has a different network Id (%d) from the node (%d)", block.ChainIdentifier, l.chain.ChainIdentifier())
+	}
+
+	lb, err := block.ToLedgerBlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTokenIdValid(l.chain, &lb.TokenStandard); err != nil {
+		return nil, err
+	}
+
+	supervisor := vm.NewSupervisor(l.z.Chain(), l.z.Consensus())
+	simulation, err := supervisor.SimulateAccountBlock(lb)
+	if err != nil {
+		return nil, err
+	}
+	return simulationToRpc(simulation)
+}
+
+// GetReceipt returns the outcome of a send-block, or nil if the send-block isn't confirmed by a momentum yet
+func (l *LedgerApi) GetReceipt(sendHash types.Hash) (*chain.Receipt, error) {
+	return chain.GetReceipt(l.chain, sendHash)
+}
+
 // Unconfirmed AccountBlocks
 func (l *LedgerApi) GetUnconfirmedBlocksByAddress(address types.Address, pageIndex, pageSize uint32) (*AccountBlockList, error) {
 	if pageSize > RpcMaxPageSize {
@@ -145,6 +199,11 @@ func (l *LedgerApi) GetAccountBlocksByHeight(address types.Address, height, coun
 		l.log.Error("GetAccountBlocksByHeight failed", "reason", err, "method-called", "GetAccountBlocksByHeight")
 		return nil, err
 	}
+	for i, block := range accountBlocks {
+		if block == nil && height+uint64(i) <= frontier.Height {
+			return nil, store.ErrDataPruned
+		}
+	}
 
 	list, err := ledgerAccountBlocksToRpc(l.chain, accountBlocks)
 	if err != nil {
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/hex"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm"
+)
+
+// PatchEntry is a key of the account storage written by a block, Value is empty for deleted keys
+type PatchEntry struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Deleted bool   `json:"deleted"`
+}
+
+type patchEntries struct {
+	list []*PatchEntry
+}
+
+func (pe *patchEntries) Put(key []byte, value []byte) {
+	pe.list = append(pe.list, &PatchEntry{Key: hex.EncodeToString(key), Value: hex.EncodeToString(value)})
+}
+func (pe *patchEntries) Delete(key []byte) {
+	pe.list = append(pe.list, &PatchEntry{Key: hex.EncodeToString(key), Deleted: true})
+}
+
+func patchToRpc(patch db.Patch) ([]*PatchEntry, error) {
+	entries := &patchEntries{list: make([]*PatchEntry, 0)}
+	if patch == nil {
+		return entries.list, nil
+	}
+	if err := patch.Replay(entries); err != nil {
+		return nil, err
+	}
+	return entries.list, nil
+}
+
+type BalanceDelta struct {
+	Address       types.Address            `json:"address"`
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	Delta         string                   `json:"delta"`
+}
+
+// AccountBlockSimulation is the result of ledger.simulateAccountBlock.
+// SendError is set if the send-block would be rejected, the other fields are only set for accepted send-blocks.
+// Receive is set for calls to embedded contracts, ReceiveStatus and MethodError tell whether the call would succeed.
+type AccountBlockSimulation struct {
+	Block          *nom.AccountBlock `json:"block"`
+	Changes        []*PatchEntry     `json:"changes"`
+	RequiredPlasma uint64            `json:"requiredPlasma"`
+	SendError      string            `json:"sendError"`
+
+	Receive        *nom.AccountBlock `json:"receive"`
+	ReceiveChanges []*PatchEntry     `json:"receiveChanges"`
+	ReceiveStatus  uint64            `json:"receiveStatus"`
+	MethodError    string            `json:"methodError"`
+
+	BalanceDeltas []*BalanceDelta `json:"balanceDeltas"`
+}
+
+func simulationToRpc(simulation *vm.Simulation) (*AccountBlockSimulation, error) {
+	result := &AccountBlockSimulation{
+		Block:          simulation.Block,
+		RequiredPlasma: simulation.RequiredPlasma,
+		Receive:        simulation.Receive,
+		ReceiveStatus:  simulation.ReceiveStatus,
+		BalanceDeltas:  make([]*BalanceDelta, 0, len(simulation.BalanceDeltas)),
+	}
+	if simulation.SendError != nil {
+		result.SendError = simulation.SendError.Error()
+	}
+	if simulation.MethodError != nil {
+		result.MethodError = simulation.MethodError.Error()
+	}
+
+	var err error
+	if result.Changes, err = patchToRpc(simulation.Changes); err != nil {
+		return nil, err
+	}
+	if result.ReceiveChanges, err = patchToRpc(simulation.ReceiveChanges); err != nil {
+		return nil, err
+	}
+	for _, delta := range simulation.BalanceDeltas {
+		result.BalanceDeltas = append(result.BalanceDeltas, &BalanceDelta{
+			Address:       delta.Address,
+			TokenStandard: delta.TokenStandard,
+			Delta:         delta.Delta.String(),
+		})
+	}
+	return result, nil
+}
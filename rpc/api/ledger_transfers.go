@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/hex"
+
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/indexer"
+)
+
+type Transfer struct {
+	Direction         string                   `json:"direction"`
+	Counterparty      types.Address            `json:"counterparty"`
+	BlockHash         types.Hash               `json:"blockHash"`
+	TokenStandard     types.ZenonTokenStandard `json:"tokenStandard"`
+	Amount            string                   `json:"amount"`
+	MomentumHeight    uint64                   `json:"momentumHeight"`
+	MomentumTimestamp int64                    `json:"momentumTimestamp"`
+	Cursor            string                   `json:"cursor"`
+}
+
+// TransferList is a page of ledger.getTransfers. NextCursor is empty if there are no more transfers to query.
+// IndexedHeight is the height of the last momentum included in the transfer history.
+type TransferList struct {
+	List          []*Transfer `json:"list"`
+	NextCursor    string      `json:"nextCursor"`
+	IndexedHeight uint64      `json:"indexedHeight"`
+}
+
+// GetTransfers returns the transfer history of an address which matches the filter, newest first.
+// The first page is queried with an empty cursor, the following ones with the NextCursor of the previous page.
+// Requires the transfers index to be enabled in the node config.
+func (l *LedgerApi) GetTransfers(address types.Address, filter *indexer.TransferFilter, cursor string, pageSize uint32) (*TransferList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	idx, err := IndexerOf(l.z, l.chain)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursorBytes []byte
+	if cursor != "" {
+		var err error
+		if cursorBytes, err = hex.DecodeString(cursor); err != nil {
+			return nil, indexer.ErrInvalidCursor
+		}
+	}
+	indexedHeight := idx.IndexedHeight()
+	transfers, err := idx.GetTransfers(address, filter, cursorBytes, int(pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TransferList{
+		List:          make([]*Transfer, len(transfers)),
+		IndexedHeight: indexedHeight,
+	}
+	for index, transfer := range transfers {
+		result.List[index] = &Transfer{
+			Direction:         transfer.Direction,
+			Counterparty:      transfer.Counterparty,
+			BlockHash:         transfer.BlockHash,
+			TokenStandard:     transfer.TokenStandard,
+			Amount:            transfer.Amount.String(),
+			MomentumHeight:    transfer.MomentumHeight,
+			MomentumTimestamp: transfer.MomentumTimestamp,
+			Cursor:            hex.EncodeToString(transfer.Cursor),
+		}
+	}
+	// a full page might be followed by more transfers
+	if len(transfers) != 0 && len(transfers) == int(pageSize) {
+		result.NextCursor = result.List[len(result.List)-1].Cursor
+	}
+	return result, nil
+}
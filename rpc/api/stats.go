@@ -9,6 +9,7 @@ import (
 	"github.com/shirou/gopsutil/mem"
 
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/metadata"
 	"github.com/zenon-network/go-zenon/p2p"
 	"github.com/zenon-network/go-zenon/p2p/discover"
@@ -125,3 +126,23 @@ func (api *StatsApi) NetworkInfo() (*NetworkInfoResponse, error) {
 func (api *StatsApi) SyncInfo() (*protocol.SyncInfo, error) {
 	return api.z.Broadcaster().SyncInfo(), nil
 }
+
+type IndexerInfoResponse struct {
+	IndexedHeight uint64 `json:"indexedHeight"`
+	// Error is the reason the indexes stopped following the chain, empty while they are up to date
+	Error string `json:"error"`
+}
+
+func (api *StatsApi) IndexerInfo() (*IndexerInfoResponse, error) {
+	idx := api.z.Indexer()
+	if idx == nil {
+		return nil, indexer.ErrIndexDisabled
+	}
+	info := &IndexerInfoResponse{
+		IndexedHeight: idx.IndexedHeight(),
+	}
+	if err := idx.Err(); err != nil {
+		info.Error = err.Error()
+	}
+	return info, nil
+}
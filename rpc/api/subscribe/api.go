@@ -16,6 +16,9 @@ import (
 const (
 	acChanSize    = 100
 	mChanSize     = 100
+	bChanSize     = 100
+	hChanSize     = 100
+	rChanSize     = 100
 	installSize   = 100
 	uninstallSize = 100
 )
@@ -66,6 +69,9 @@ type Server struct {
 	uninstallCh   chan *Subscription // remove subscription
 	acCh          chan []*AccountBlock
 	mCh           chan *Momentum
+	bCh           chan *nom.DetailedMomentum
+	hCh           chan *nom.DetailedMomentum
+	rCh           chan []types.Hash
 	stopped       chan struct{}
 	subscriptions map[SubscriptionType]map[rpc.ID]*Subscription
 
@@ -86,6 +92,9 @@ func GetSubscribeServer(chain chain.Chain) *Server {
 
 			acCh:          make(chan []*AccountBlock, acChanSize),
 			mCh:           make(chan *Momentum, mChanSize),
+			bCh:           make(chan *nom.DetailedMomentum, bChanSize),
+			hCh:           make(chan *nom.DetailedMomentum, hChanSize),
+			rCh:           make(chan []types.Hash, rChanSize),
 			uninstallCh:   make(chan *Subscription, uninstallSize),
 			stopped:       make(chan struct{}),
 			subscriptions: make(map[SubscriptionType]map[rpc.ID]*Subscription),
@@ -157,6 +166,35 @@ func (s *Server) InsertMomentum(detailed *nom.DetailedMomentum) {
 	default:
 		s.log.Error("can't insert account-blocks for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
 	}
+
+	if hasContractReceiveBlocks(detailed, types.BridgeContract) {
+		select {
+		case s.bCh <- detailed:
+		default:
+			s.log.Error("can't insert bridge events for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
+		}
+	}
+	if hasContractReceiveBlocks(detailed, types.HtlcContract) {
+		select {
+		case s.hCh <- detailed:
+		default:
+			s.log.Error("can't insert htlc preimages for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
+		}
+	}
+
+	received := make([]types.Hash, 0)
+	for _, block := range detailed.AccountBlocks {
+		if !block.IsSendBlock() {
+			received = append(received, block.FromBlockHash)
+		}
+	}
+	if len(received) != 0 {
+		select {
+		case s.rCh <- received:
+		default:
+			s.log.Error("can't insert receipts for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
+		}
+	}
 	return
 }
 func (s *Server) DeleteMomentum(*nom.DetailedMomentum) {
@@ -181,6 +219,12 @@ func (s *Server) work() {
 			s.broadcastMomentums(momentums)
 		case blocks := <-s.acCh:
 			s.broadcastBlocks(blocks)
+		case detailed := <-s.bCh:
+			s.broadcastBridgeEvents(detailed)
+		case detailed := <-s.hCh:
+			s.broadcastHtlcPreimages(detailed)
+		case received := <-s.rCh:
+			s.broadcastReceipts(received)
 		}
 	}
 }
@@ -192,6 +236,10 @@ type BroadcastStats struct {
 
 func (s *Server) install(subscription *Subscription) {
 	s.log.Info("install", "id", subscription.rpc.ID)
+	// the receipt might be final already, in which case there is nothing to wait for
+	if subscription.options.subscriptionType == ReceiptSubscription && s.notifyFinalReceipt(subscription, &BroadcastStats{}) {
+		return
+	}
 	s.subscriptions[subscription.options.subscriptionType][subscription.rpc.ID] = subscription
 }
 func (s *Server) uninstall(subscription *Subscription) {
@@ -259,6 +307,89 @@ func (s *Server) broadcastBlocks(blocks []*AccountBlock) {
 	s.log.Info("finish broadcasting account-blocks", "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
 }
 
+func (s *Server) broadcastBridgeEvents(detailed *nom.DetailedMomentum) {
+	// decoding requires reading the bridge state, skip it if nobody listens
+	if len(s.subscriptions[BridgeEventsSubscription]) == 0 {
+		return
+	}
+	startTime := common.Clock.Now()
+	stats := &BroadcastStats{}
+
+	events, err := BridgeEvents(s.chain, detailed)
+	if err != nil {
+		s.log.Error("can't decode bridge events", "reason", err, "momentum-identifier", detailed.Momentum.Identifier())
+		return
+	}
+	if len(events) == 0 {
+		return
+	}
+	for _, f := range s.subscriptions[BridgeEventsSubscription] {
+		s.broadcast(f, events, stats)
+	}
+
+	s.log.Info("finish broadcasting bridge events", "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
+}
+
+func (s *Server) broadcastHtlcPreimages(detailed *nom.DetailedMomentum) {
+	// decoding requires reading the htlc state, skip it if nobody listens
+	if len(s.subscriptions[HtlcPreimagesSubscription]) == 0 {
+		return
+	}
+	startTime := common.Clock.Now()
+	stats := &BroadcastStats{}
+
+	preimages, err := HtlcPreimages(s.chain, detailed)
+	if err != nil {
+		s.log.Error("can't decode htlc preimages", "reason", err, "momentum-identifier", detailed.Momentum.Identifier())
+		return
+	}
+	if len(preimages) == 0 {
+		return
+	}
+	for _, f := range s.subscriptions[HtlcPreimagesSubscription] {
+		s.broadcast(f, preimages, stats)
+	}
+
+	s.log.Info("finish broadcasting htlc preimages", "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
+}
+
+// notifyFinalReceipt notifies the subscription if its receipt is final, returns true if it did
+func (s *Server) notifyFinalReceipt(subscription *Subscription, stats *BroadcastStats) bool {
+	receipt, err := chain.GetReceipt(s.chain, subscription.options.hash)
+	if err != nil {
+		s.log.Error("can't get receipt", "reason", err, "send-block-hash", subscription.options.hash)
+		return false
+	}
+	if receipt == nil || !receipt.IsFinal() {
+		return false
+	}
+	s.broadcast(subscription, receipt, stats)
+	return true
+}
+func (s *Server) broadcastReceipts(received []types.Hash) {
+	if len(s.subscriptions[ReceiptSubscription]) == 0 {
+		return
+	}
+	startTime := common.Clock.Now()
+	stats := &BroadcastStats{}
+
+	receivedSet := make(map[types.Hash]bool, len(received))
+	for _, hash := range received {
+		receivedSet[hash] = true
+	}
+	for _, f := range s.subscriptions[ReceiptSubscription] {
+		if !receivedSet[f.options.hash] {
+			continue
+		}
+		// a receipt is notified only once, when it becomes final
+		if s.notifyFinalReceipt(f, stats) {
+			s.uninstall(f)
+		}
+	}
+
+	s.log.Info("finish broadcasting receipts", "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
+}
+
 func (s *Api) subscribe(ctx context.Context, options *subscriptionOptions) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -285,3 +416,15 @@ func (s *Api) UnreceivedAccountBlocksByAddress(ctx context.Context, address type
 	s.log.Info("new subscription", "type", "UnreceivedAccountBlocksByAddress")
 	return s.subscribe(ctx, NewToUnreceivedBlocksSubscription(address))
 }
+func (s *Api) BridgeEvents(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "BridgeEvents")
+	return s.subscribe(ctx, NewBridgeEventsSubscription())
+}
+func (s *Api) HtlcPreimages(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "HtlcPreimages")
+	return s.subscribe(ctx, NewHtlcPreimagesSubscription())
+}
+func (s *Api) Receipt(ctx context.Context, sendHash types.Hash) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "Receipt")
+	return s.subscribe(ctx, NewReceiptSubscription(sendHash))
+}
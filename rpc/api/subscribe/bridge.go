@@ -0,0 +1,190 @@
+package subscribe
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+const (
+	WrapRequestCreated    = "wrapRequestCreated"
+	WrapRequestSigned     = "wrapRequestSigned"
+	UnwrapRequestCreated  = "unwrapRequestCreated"
+	UnwrapRequestRedeemed = "unwrapRequestRedeemed"
+	UnwrapRequestRevoked  = "unwrapRequestRevoked"
+	BridgeHalted          = "bridgeHalted"
+	BridgeUnhalted        = "bridgeUnhalted"
+)
+
+var (
+	errMissingMomentumStore = errors.New("momentum store not found")
+)
+
+// BridgeEvent is a change of a bridge request, or of the bridge itself, caused by the account-blocks of a momentum.
+// Only the field matching Type is set. SendBlockHash is the block which called the bridge, zero for halt events
+// since the bridge can halt itself when a limit is crossed.
+type BridgeEvent struct {
+	Type           string                         `json:"type"`
+	MomentumHeight uint64                         `json:"momentumHeight"`
+	MomentumHash   types.Hash                     `json:"momentumHash"`
+	SendBlockHash  types.Hash                     `json:"sendBlockHash"`
+	WrapRequest    *definition.WrapTokenRequest   `json:"wrapRequest"`
+	UnwrapRequest  *definition.UnwrapTokenRequest `json:"unwrapRequest"`
+	BridgeInfo     *definition.BridgeInfoVariable `json:"bridgeInfo"`
+}
+
+// hasContractReceiveBlocks returns true if the contract received at least one account-block in the momentum
+func hasContractReceiveBlocks(detailed *nom.DetailedMomentum, contract types.Address) bool {
+	for _, block := range detailed.AccountBlocks {
+		if block.Address == contract && block.BlockType == nom.BlockTypeContractReceive {
+			return true
+		}
+	}
+	return false
+}
+
+// BridgeEvents decodes the bridge events of a momentum which is already inserted in the chain.
+// Receive blocks don't tell whether the call succeeded, so the bridge state before and after the momentum is compared.
+func BridgeEvents(c chain.Chain, detailed *nom.DetailedMomentum) ([]*BridgeEvent, error) {
+	events := make([]*BridgeEvent, 0)
+	if !hasContractReceiveBlocks(detailed, types.BridgeContract) {
+		return events, nil
+	}
+
+	momentum := detailed.Momentum
+	afterStore := c.GetMomentumStore(momentum.Identifier())
+	beforeStore := c.GetMomentumStore(types.HashHeight{Hash: momentum.PreviousHash, Height: momentum.Height - 1})
+	if afterStore == nil || beforeStore == nil {
+		return nil, errMissingMomentumStore
+	}
+	before := vm_context.NewAccountContext(beforeStore, beforeStore.GetAccountStore(types.BridgeContract), nil).Storage()
+	after := vm_context.NewAccountContext(afterStore, afterStore.GetAccountStore(types.BridgeContract), nil).Storage()
+
+	newEvent := func(eventType string, sendBlockHash types.Hash) *BridgeEvent {
+		event := &BridgeEvent{
+			Type:           eventType,
+			MomentumHeight: momentum.Height,
+			MomentumHash:   momentum.Hash,
+			SendBlockHash:  sendBlockHash,
+		}
+		events = append(events, event)
+		return event
+	}
+
+	for _, block := range detailed.AccountBlocks {
+		if block.Address != types.BridgeContract || block.BlockType != nom.BlockTypeContractReceive {
+			continue
+		}
+		sendBlock, err := afterStore.GetAccountBlockByHash(block.FromBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if sendBlock == nil {
+			continue
+		}
+		method, err := definition.ABIBridge.MethodById(sendBlock.Data)
+		if err != nil {
+			continue
+		}
+
+		switch method.Name {
+		case definition.WrapTokenMethodName:
+			if request, err := definition.GetWrapTokenRequestById(after, sendBlock.Hash); err == nil {
+				newEvent(WrapRequestCreated, sendBlock.Hash).WrapRequest = request
+			}
+		case definition.UpdateWrapRequestMethodName:
+			param := new(definition.UpdateWrapRequestParam)
+			if err := definition.ABIBridge.UnpackMethod(param, method.Name, sendBlock.Data); err != nil {
+				continue
+			}
+			request, err := definition.GetWrapTokenRequestById(after, param.Id)
+			if err != nil || request.Signature != param.Signature {
+				continue
+			}
+			if previous, err := definition.GetWrapTokenRequestById(before, param.Id); err == nil && previous.Signature == param.Signature {
+				continue
+			}
+			newEvent(WrapRequestSigned, sendBlock.Hash).WrapRequest = request
+		case definition.UnwrapTokenMethodName, definition.UnwrapBitcoinTokenMethodName:
+			txHash, logIndex, ok := unwrapRequestOf(method.Name, sendBlock.Data)
+			if !ok {
+				continue
+			}
+			if _, err := definition.GetUnwrapTokenRequestByTxHashAndLog(before, txHash, logIndex); err == nil {
+				continue
+			}
+			if request, err := definition.GetUnwrapTokenRequestByTxHashAndLog(after, txHash, logIndex); err == nil {
+				newEvent(UnwrapRequestCreated, sendBlock.Hash).UnwrapRequest = request
+			}
+		case definition.RedeemUnwrapMethodName, definition.RevokeUnwrapRequestMethodName:
+			txHash, logIndex, ok := unwrapRequestOf(method.Name, sendBlock.Data)
+			if !ok {
+				continue
+			}
+			previous, err := definition.GetUnwrapTokenRequestByTxHashAndLog(before, txHash, logIndex)
+			if err != nil {
+				continue
+			}
+			request, err := definition.GetUnwrapTokenRequestByTxHashAndLog(after, txHash, logIndex)
+			if err != nil {
+				continue
+			}
+			if method.Name == definition.RedeemUnwrapMethodName && previous.Redeemed == 0 && request.Redeemed != 0 {
+				newEvent(UnwrapRequestRedeemed, sendBlock.Hash).UnwrapRequest = request
+			} else if method.Name == definition.RevokeUnwrapRequestMethodName && previous.Revoked == 0 && request.Revoked != 0 {
+				newEvent(UnwrapRequestRevoked, sendBlock.Hash).UnwrapRequest = request
+			}
+		}
+	}
+
+	previousInfo, err := definition.GetBridgeInfoVariable(before)
+	if err != nil {
+		return nil, err
+	}
+	bridgeInfo, err := definition.GetBridgeInfoVariable(after)
+	if err != nil {
+		return nil, err
+	}
+	if !previousInfo.Halted && bridgeInfo.Halted {
+		newEvent(BridgeHalted, types.ZeroHash).BridgeInfo = bridgeInfo
+	} else if previousInfo.Halted && !bridgeInfo.Halted {
+		newEvent(BridgeUnhalted, types.ZeroHash).BridgeInfo = bridgeInfo
+	}
+
+	return events, nil
+}
+
+// unwrapRequestOf returns the transaction hash and log index which identify the unwrap request of a bridge call
+func unwrapRequestOf(methodName string, data []byte) (types.Hash, uint32, bool) {
+	switch methodName {
+	case definition.UnwrapTokenMethodName:
+		param := new(definition.UnwrapTokenParam)
+		if err := definition.ABIBridge.UnpackMethod(param, methodName, data); err != nil {
+			return types.ZeroHash, 0, false
+		}
+		return param.TransactionHash, param.LogIndex, true
+	case definition.UnwrapBitcoinTokenMethodName:
+		param := new(definition.UnwrapBitcoinTokenParam)
+		if err := definition.ABIBridge.UnpackMethod(param, methodName, data); err != nil {
+			return types.ZeroHash, 0, false
+		}
+		return param.TxId, param.Vout, true
+	case definition.RedeemUnwrapMethodName:
+		param := new(definition.RedeemParam)
+		if err := definition.ABIBridge.UnpackMethod(param, methodName, data); err != nil {
+			return types.ZeroHash, 0, false
+		}
+		return param.TransactionHash, param.LogIndex, true
+	case definition.RevokeUnwrapRequestMethodName:
+		param := new(definition.RevokeUnwrapParam)
+		if err := definition.ABIBridge.UnpackMethod(param, methodName, data); err != nil {
+			return types.ZeroHash, 0, false
+		}
+		return param.TransactionHash, param.LogIndex, true
+	}
+	return types.ZeroHash, 0, false
+}
@@ -0,0 +1,82 @@
+package subscribe
+
+import (
+	"bytes"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+// HtlcPreimage is a preimage revealed by a successful htlc unlock.
+// Htlc is the unlocked htlc as it was before being deleted by the unlock.
+type HtlcPreimage struct {
+	MomentumHeight uint64               `json:"momentumHeight"`
+	MomentumHash   types.Hash           `json:"momentumHash"`
+	SendBlockHash  types.Hash           `json:"sendBlockHash"`
+	Htlc           *definition.HtlcInfo `json:"htlc"`
+	Preimage       []byte               `json:"preimage"`
+}
+
+// HtlcPreimages decodes the preimages revealed by the htlc unlocks of a momentum which is already inserted in the chain.
+// An unlock succeeded if the htlc existed before the momentum, is deleted after it and the preimage matches its hash lock.
+func HtlcPreimages(c chain.Chain, detailed *nom.DetailedMomentum) ([]*HtlcPreimage, error) {
+	preimages := make([]*HtlcPreimage, 0)
+	if !hasContractReceiveBlocks(detailed, types.HtlcContract) {
+		return preimages, nil
+	}
+
+	momentum := detailed.Momentum
+	afterStore := c.GetMomentumStore(momentum.Identifier())
+	beforeStore := c.GetMomentumStore(types.HashHeight{Hash: momentum.PreviousHash, Height: momentum.Height - 1})
+	if afterStore == nil || beforeStore == nil {
+		return nil, errMissingMomentumStore
+	}
+	before := vm_context.NewAccountContext(beforeStore, beforeStore.GetAccountStore(types.HtlcContract), nil).Storage()
+	after := vm_context.NewAccountContext(afterStore, afterStore.GetAccountStore(types.HtlcContract), nil).Storage()
+
+	for _, block := range detailed.AccountBlocks {
+		if block.Address != types.HtlcContract || block.BlockType != nom.BlockTypeContractReceive {
+			continue
+		}
+		sendBlock, err := afterStore.GetAccountBlockByHash(block.FromBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if sendBlock == nil {
+			continue
+		}
+		method, err := definition.ABIHtlc.MethodById(sendBlock.Data)
+		if err != nil || method.Name != definition.UnlockHtlcMethodName {
+			continue
+		}
+		param := new(definition.UnlockHtlcParam)
+		if err := definition.ABIHtlc.UnpackMethod(param, method.Name, sendBlock.Data); err != nil {
+			continue
+		}
+
+		htlc, err := definition.GetHtlcInfo(before, param.Id)
+		if err != nil {
+			continue
+		}
+		if _, err := definition.GetHtlcInfo(after, param.Id); err == nil {
+			continue
+		}
+		if !bytes.Equal(implementation.HashPreimage(htlc.HashType, param.Preimage), htlc.HashLock) {
+			continue
+		}
+
+		preimages = append(preimages, &HtlcPreimage{
+			MomentumHeight: momentum.Height,
+			MomentumHash:   momentum.Hash,
+			SendBlockHash:  sendBlock.Hash,
+			Htlc:           htlc,
+			Preimage:       param.Preimage,
+		})
+	}
+
+	return preimages, nil
+}
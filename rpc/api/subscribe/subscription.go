@@ -18,6 +18,9 @@ const (
 	AccountBlocksSubscriptionByAddress
 	UnreceivedAccountBlocksSubscriptionByAddress
 	MomentumsSubscription
+	BridgeEventsSubscription
+	HtlcPreimagesSubscription
+	ReceiptSubscription
 	LastSubscriptionType
 )
 
@@ -25,6 +28,7 @@ type subscriptionOptions struct {
 	subscriptionType SubscriptionType
 	createTime       time.Time
 	address          types.Address
+	hash             types.Hash
 }
 
 func newSubscription(subscriptionType SubscriptionType) *subscriptionOptions {
@@ -49,6 +53,17 @@ func NewToUnreceivedBlocksSubscription(addr types.Address) *subscriptionOptions
 func NewMomentumsSubscription() *subscriptionOptions {
 	return newSubscription(MomentumsSubscription)
 }
+func NewBridgeEventsSubscription() *subscriptionOptions {
+	return newSubscription(BridgeEventsSubscription)
+}
+func NewHtlcPreimagesSubscription() *subscriptionOptions {
+	return newSubscription(HtlcPreimagesSubscription)
+}
+func NewReceiptSubscription(sendHash types.Hash) *subscriptionOptions {
+	sub := newSubscription(ReceiptSubscription)
+	sub.hash = sendHash
+	return sub
+}
 
 type Subscription struct {
 	log      log15.Logger
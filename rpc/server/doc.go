@@ -49,6 +49,14 @@ will be pointing to the given third argument. Since the optional argument is the
 argument the RPC package will also accept 2 integers as arguments. It will pass the mod
 argument as nil to the RPC method.
 
+Services which can serve their calls at a past momentum implement an At method, which takes
+a momentum reference and returns the receiver which reads the state at that momentum.
+
+ func (s *CalcService) At(ref *MomentumRef) (*CalcService, error)
+
+All the other methods of such a service accept the reference as an optional trailing argument.
+When it is given, the method is called on the receiver returned by At.
+
 The server offers the ServeCodec method which accepts a ServerCodec instance. It will read
 requests from the codec, process the request and sends the response back to the client
 using the codec. The server can execute requests concurrently. Responses can be sent back
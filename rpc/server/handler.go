@@ -333,7 +333,7 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
-	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
+	args, err := parsePositionalArguments(msg.Params, callb.paramTypes())
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
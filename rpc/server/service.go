@@ -36,6 +36,10 @@ var (
 	stringType       = reflect.TypeOf("")
 )
 
+// historicalMethodName is the method of the services which can serve their calls at a past momentum.
+// It takes a momentum reference and returns the receiver which reads the state at that momentum.
+const historicalMethodName = "At"
+
 type serviceRegistry struct {
 	mu       sync.Mutex
 	services map[string]service
@@ -56,6 +60,7 @@ type callback struct {
 	hasCtx      bool           // method's first argument is a context (not included in argTypes)
 	errPos      int            // err return idx, of -1 when method cannot return error
 	isSubscribe bool           // true if this is a subscription callback
+	at          reflect.Value  // At method of the receiver, set if the callback accepts a trailing momentum reference
 }
 
 func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
@@ -116,22 +121,46 @@ func (r *serviceRegistry) subscription(service, name string) *callback {
 // collection of callbacks. See server documentation for a summary of these criteria.
 func suitableCallbacks(receiver reflect.Value) map[string]*callback {
 	typ := receiver.Type()
+	at := historicalMethod(receiver)
 	callbacks := make(map[string]*callback)
 	for m := 0; m < typ.NumMethod(); m++ {
 		method := typ.Method(m)
 		if method.PkgPath != "" {
 			continue // method not exported
 		}
+		if at.IsValid() && method.Name == historicalMethodName {
+			continue
+		}
 		cb := newCallback(receiver, method.Func)
 		if cb == nil {
 			continue // function invalid
 		}
+		if !cb.isSubscribe {
+			cb.at = at
+		}
 		name := formatName(method.Name)
 		callbacks[name] = cb
 	}
 	return callbacks
 }
 
+// historicalMethod returns the At method of the receiver, if it has the signature
+// func (r R) At(ref *Ref) (R, error)
+func historicalMethod(receiver reflect.Value) reflect.Value {
+	method := receiver.MethodByName(historicalMethodName)
+	if !method.IsValid() {
+		return reflect.Value{}
+	}
+	fntype := method.Type()
+	if fntype.NumIn() != 1 || fntype.In(0).Kind() != reflect.Ptr {
+		return reflect.Value{}
+	}
+	if fntype.NumOut() != 2 || fntype.Out(0) != receiver.Type() || !isErrorType(fntype.Out(1)) {
+		return reflect.Value{}
+	}
+	return method
+}
+
 // newCallback turns fn (a function) into a callback object. It returns nil if the function
 // is unsuitable as an RPC callback.
 func newCallback(receiver, fn reflect.Value) *callback {
@@ -181,12 +210,36 @@ func (c *callback) makeArgTypes() {
 	}
 }
 
+// paramTypes returns the types of the arguments of a call, including the trailing momentum reference
+func (c *callback) paramTypes() []reflect.Type {
+	if !c.at.IsValid() {
+		return c.argTypes
+	}
+	types := make([]reflect.Type, 0, len(c.argTypes)+1)
+	types = append(types, c.argTypes...)
+	return append(types, c.at.Type().In(0))
+}
+
 // call invokes the callback.
 func (c *callback) call(ctx context.Context, method string, args []reflect.Value) (res interface{}, errRes error) {
+	rcvr := c.rcvr
+	if c.at.IsValid() {
+		// the call is made on the receiver which reads the state at the referenced momentum
+		ref := args[len(args)-1]
+		args = args[:len(args)-1]
+		if !ref.IsNil() {
+			results := c.at.Call([]reflect.Value{ref})
+			if !results[1].IsNil() {
+				return nil, results[1].Interface().(error)
+			}
+			rcvr = results[0]
+		}
+	}
+
 	// Create the argument slice.
 	fullargs := make([]reflect.Value, 0, 2+len(args))
-	if c.rcvr.IsValid() {
-		fullargs = append(fullargs, c.rcvr)
+	if rcvr.IsValid() {
+		fullargs = append(fullargs, rcvr)
 	}
 	if c.hasCtx {
 		fullargs = append(fullargs, reflect.ValueOf(ctx))
@@ -0,0 +1,21 @@
+package snapshot
+
+import "github.com/pkg/errors"
+
+var (
+	ErrInvalidMagic       = errors.Errorf("file is not a znnd snapshot")
+	ErrUnsupportedVersion = errors.Errorf("unsupported snapshot version")
+	ErrChunkTooBig        = errors.Errorf("snapshot chunk is too big")
+	ErrInvalidChecksum    = errors.Errorf("snapshot chunk checksum mismatch")
+	ErrInvalidRoot        = errors.Errorf("snapshot root mismatch")
+	ErrUnexpectedChunk    = errors.Errorf("unexpected snapshot chunk")
+	ErrMalformedChunk     = errors.Errorf("malformed snapshot chunk")
+
+	ErrMomentumNotFound   = errors.Errorf("momentum not found")
+	ErrStateUnavailable   = errors.Errorf("state at momentum is not available, it is pruned or too far from the frontier")
+	ErrGenesisMismatch    = errors.Errorf("snapshot was exported from a different chain")
+	ErrUntrustedSnapshot  = errors.Errorf("snapshot root doesn't match the trusted root")
+	ErrInvalidMomentum    = errors.Errorf("snapshot momentum is invalid")
+	ErrUnknownProducer    = errors.Errorf("snapshot momentum is produced by a pillar which didn't produce in the epoch before the base")
+	ErrIncompleteSnapshot = errors.Errorf("snapshot is incomplete")
+)
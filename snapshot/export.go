@@ -0,0 +1,150 @@
+package snapshot
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/consensus/storage"
+)
+
+// Export writes the snapshot of the momentum at height, or of the frontier momentum if height is 0.
+// The state and the consensus db are exported at window momentums before height and the following momentums
+// are included together with their changes, so they can be verified and replayed on import.
+func Export(w io.Writer, chainManager db.Manager, consensusDB db.DB, genesis store.Genesis, height, window uint64) (*Header, types.Hash, error) {
+	frontierStore := momentum.NewStore(genesis, chainManager.Frontier())
+	if height == 0 {
+		height = frontierStore.Identifier().Height
+	}
+	last, err := frontierStore.GetMomentumByHeight(height)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	if last == nil {
+		return nil, types.ZeroHash, ErrMomentumNotFound
+	}
+	baseHeight := uint64(1)
+	if height > window+1 {
+		baseHeight = height - window
+	}
+	base, err := frontierStore.GetMomentumByHeight(baseHeight)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	baseDB := chainManager.Get(base.Identifier())
+	if baseDB == nil {
+		return nil, types.ZeroHash, ErrStateUnavailable
+	}
+
+	header := &Header{
+		Version:         Version,
+		ChainIdentifier: genesis.ChainIdentifier(),
+		GenesisHash:     genesis.GetGenesisMomentum().Hash,
+		Momentum:        last.Identifier(),
+		Base:            base.Identifier(),
+	}
+	sw, err := newWriter(w)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	if err := sw.writeChunk(chunkHeader, data); err != nil {
+		return nil, types.ZeroHash, err
+	}
+
+	if err := writeEntries(sw, chunkState, baseDB, nil); err != nil {
+		return nil, types.ZeroHash, err
+	}
+	if err := writeEntries(sw, chunkConsensus, consensusDB, consensusEntriesAt(genesis, momentum.NewStore(genesis, baseDB), base)); err != nil {
+		return nil, types.ZeroHash, err
+	}
+
+	for i := baseHeight + 1; i <= height; i += 1 {
+		m, err := frontierStore.GetMomentumByHeight(i)
+		if err != nil {
+			return nil, types.ZeroHash, err
+		}
+		patch := chainManager.GetPatch(m.Identifier())
+		if patch == nil {
+			return nil, types.ZeroHash, ErrStateUnavailable
+		}
+		changes, err := db.GetTransactionChanges(patch, 1)
+		if err != nil {
+			return nil, types.ZeroHash, err
+		}
+		data, err := m.Serialize()
+		if err != nil {
+			return nil, types.ZeroHash, err
+		}
+		payload := binary.AppendUvarint(nil, uint64(len(data)))
+		payload = append(payload, data...)
+		payload = append(payload, changes.Dump()...)
+		if err := sw.writeChunk(chunkMomentum, payload); err != nil {
+			return nil, types.ZeroHash, err
+		}
+	}
+
+	root, err := sw.close()
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	return header, root, nil
+}
+
+// consensusEntriesAt selects the entries of the consensus db which were computed from the momentums up to base.
+// The consensus db isn't versioned, so the entries computed from later momentums are dropped and computed
+// again once the momentums after base are replayed.
+func consensusEntriesAt(genesis store.Genesis, baseStore store.Momentum, base *nom.Momentum) func(key []byte) (bool, error) {
+	genesisTime := *genesis.GetGenesisMomentum().Timestamp
+	periodTick := consensus.NewConsensusContext(genesisTime).ToTick(*base.Timestamp)
+	epochTick := common.NewTicker(genesisTime, consensus.EpochDuration).ToTick(*base.Timestamp)
+	return func(key []byte) (bool, error) {
+		switch {
+		case len(key) == 9 && key[0] == storage.PrefixPeriodPoint:
+			return binary.BigEndian.Uint64(key[1:]) < periodTick, nil
+		case len(key) == 9 && key[0] == storage.PrefixEpochPoint:
+			return binary.BigEndian.Uint64(key[1:]) < epochTick, nil
+		case len(key) == 1+types.HashSize && key[0] == storage.PrefixElectionResult:
+			proof, err := baseStore.GetMomentumByHash(types.BytesToHashPanic(key[1:]))
+			if err != nil {
+				return false, err
+			}
+			return proof != nil, nil
+		default:
+			return false, nil
+		}
+	}
+}
+
+// writeEntries writes the entries of source selected by keep, or all of them if keep is nil
+func writeEntries(sw *writer, kind byte, source db.DB, keep func(key []byte) (bool, error)) error {
+	ew := &entriesWriter{w: sw, kind: kind}
+	iterator := source.NewIterator([]byte{})
+	defer iterator.Release()
+	for iterator.Next() {
+		if keep != nil {
+			if ok, err := keep(iterator.Key()); err != nil {
+				return err
+			} else if !ok {
+				continue
+			}
+		}
+		if err := ew.add(iterator.Key(), iterator.Value()); err != nil {
+			return err
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+	return ew.flush()
+}
@@ -0,0 +1,241 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// A snapshot starts with magic and the version, followed by chunks:
+//
+//	chunk    := kind (1 byte) | length (4 bytes) | payload | checksum (32 bytes)
+//	checksum := hash(kind | payload)
+//
+// The chunks are, in order, one header, the entries of the momentum store and of the consensus db at the base
+// momentum, one chunk for every momentum after the base, and one trailer. The trailer holds the root of the
+// snapshot, which is the hash of the checksums of all previous chunks.
+const (
+	Version = 1
+
+	// DefaultWindow is the number of momentums before the snapshot height which are replayed on import
+	DefaultWindow = 360
+	// prefixBatchSize is the number of momentums before the base read at once on import
+	prefixBatchSize = 1000
+
+	// chunkSize is the payload size after which an entries chunk is closed
+	chunkSize = 4 * 1024 * 1024
+	// maxChunkSize bounds the payload of the chunks read from a snapshot
+	maxChunkSize = 256 * 1024 * 1024
+)
+
+const (
+	chunkHeader    byte = 1
+	chunkState     byte = 2
+	chunkConsensus byte = 3
+	chunkMomentum  byte = 4
+	chunkTrailer   byte = 5
+)
+
+var (
+	magic = []byte("ZNNSNAP\x00")
+)
+
+type Header struct {
+	Version         uint64     `json:"version"`
+	ChainIdentifier uint64     `json:"chainIdentifier"`
+	GenesisHash     types.Hash `json:"genesisHash"`
+	// Momentum is the last momentum of the snapshot, the node continues to sync from it
+	Momentum types.HashHeight `json:"momentum"`
+	// Base is the momentum of the exported state, the momentums after it are verified and replayed on import
+	Base types.HashHeight `json:"base"`
+}
+
+type writer struct {
+	w         *bufio.Writer
+	checksums []byte
+}
+
+func newWriter(w io.Writer) (*writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(magic); err != nil {
+		return nil, err
+	}
+	if _, err := bw.Write(common.Uint64ToBytes(Version)); err != nil {
+		return nil, err
+	}
+	return &writer{w: bw}, nil
+}
+
+func (w *writer) writeChunk(kind byte, payload []byte) error {
+	checksum := types.NewHash(common.JoinBytes([]byte{kind}, payload))
+	length := make([]byte, 4)
+	binary.BigEndian.PutUint32(length, uint32(len(payload)))
+	for _, data := range [][]byte{{kind}, length, payload, checksum.Bytes()} {
+		if _, err := w.w.Write(data); err != nil {
+			return err
+		}
+	}
+	w.checksums = append(w.checksums, checksum.Bytes()...)
+	return nil
+}
+
+// close writes the trailer and returns the root of the snapshot
+func (w *writer) close() (types.Hash, error) {
+	root := types.NewHash(w.checksums)
+	count := uint64(len(w.checksums) / types.HashSize)
+	if err := w.writeChunk(chunkTrailer, common.JoinBytes(root.Bytes(), common.Uint64ToBytes(count))); err != nil {
+		return types.ZeroHash, err
+	}
+	return root, w.w.Flush()
+}
+
+// entriesWriter packs key-value entries into chunks of kind
+type entriesWriter struct {
+	w       *writer
+	kind    byte
+	payload []byte
+}
+
+func (ew *entriesWriter) add(key, value []byte) error {
+	ew.payload = binary.AppendUvarint(ew.payload, uint64(len(key)))
+	ew.payload = append(ew.payload, key...)
+	ew.payload = binary.AppendUvarint(ew.payload, uint64(len(value)))
+	ew.payload = append(ew.payload, value...)
+	if len(ew.payload) < chunkSize {
+		return nil
+	}
+	return ew.flush()
+}
+func (ew *entriesWriter) flush() error {
+	if len(ew.payload) == 0 {
+		return nil
+	}
+	if err := ew.w.writeChunk(ew.kind, ew.payload); err != nil {
+		return err
+	}
+	ew.payload = ew.payload[:0]
+	return nil
+}
+
+type reader struct {
+	r         *bufio.Reader
+	checksums []byte
+
+	// peeked chunk, returned by the next call to next
+	kind    byte
+	payload []byte
+	peeked  bool
+}
+
+func newReader(r io.Reader) (*reader, error) {
+	br := bufio.NewReader(r)
+	prefix := make([]byte, len(magic)+8)
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		return nil, ErrInvalidMagic
+	}
+	if !bytes.Equal(prefix[:len(magic)], magic) {
+		return nil, ErrInvalidMagic
+	}
+	if common.BytesToUint64(prefix[len(magic):]) != Version {
+		return nil, ErrUnsupportedVersion
+	}
+	return &reader{r: br}, nil
+}
+
+// peek returns the kind of the next chunk without consuming it
+func (r *reader) peek() (byte, error) {
+	if !r.peeked {
+		kind, payload, err := r.readChunk()
+		if err != nil {
+			return 0, err
+		}
+		r.kind, r.payload, r.peeked = kind, payload, true
+	}
+	return r.kind, nil
+}
+
+// next returns the next chunk, which must be of kind
+func (r *reader) next(kind byte) ([]byte, error) {
+	if current, err := r.peek(); err != nil {
+		return nil, err
+	} else if current != kind {
+		return nil, ErrUnexpectedChunk
+	}
+	r.peeked = false
+	return r.payload, nil
+}
+
+func (r *reader) readChunk() (byte, []byte, error) {
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(r.r, prefix); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 0, nil, ErrIncompleteSnapshot
+	} else if err != nil {
+		return 0, nil, err
+	}
+	kind := prefix[0]
+	length := binary.BigEndian.Uint32(prefix[1:])
+	if length > maxChunkSize {
+		return 0, nil, ErrChunkTooBig
+	}
+	data := make([]byte, int(length)+types.HashSize)
+	if _, err := io.ReadFull(r.r, data); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 0, nil, ErrIncompleteSnapshot
+	} else if err != nil {
+		return 0, nil, err
+	}
+	payload := data[:length]
+	checksum := types.NewHash(common.JoinBytes([]byte{kind}, payload))
+	if !bytes.Equal(checksum.Bytes(), data[length:]) {
+		return 0, nil, ErrInvalidChecksum
+	}
+	if kind != chunkTrailer {
+		r.checksums = append(r.checksums, checksum.Bytes()...)
+	}
+	return kind, payload, nil
+}
+
+// close reads the trailer and checks the root of the snapshot
+func (r *reader) close() (types.Hash, error) {
+	payload, err := r.next(chunkTrailer)
+	if err != nil {
+		return types.ZeroHash, err
+	}
+	if len(payload) != types.HashSize+8 {
+		return types.ZeroHash, ErrMalformedChunk
+	}
+	root := types.NewHash(r.checksums)
+	if !bytes.Equal(root.Bytes(), payload[:types.HashSize]) || common.BytesToUint64(payload[types.HashSize:]) != uint64(len(r.checksums)/types.HashSize) {
+		return types.ZeroHash, ErrInvalidRoot
+	}
+	return root, nil
+}
+
+// forEachEntry calls f for every key-value entry of an entries chunk
+func forEachEntry(payload []byte, f func(key, value []byte) error) error {
+	for len(payload) != 0 {
+		key, rest, err := readBytes(payload)
+		if err != nil {
+			return err
+		}
+		value, rest, err := readBytes(rest)
+		if err != nil {
+			return err
+		}
+		if err := f(key, value); err != nil {
+			return err
+		}
+		payload = rest
+	}
+	return nil
+}
+func readBytes(data []byte) ([]byte, []byte, error) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < length {
+		return nil, nil, ErrMalformedChunk
+	}
+	return data[n : n+int(length)], data[n+int(length):], nil
+}
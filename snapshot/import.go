@@ -0,0 +1,218 @@
+package snapshot
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+// Import restores the snapshot read from r into the empty chain db at chainDir and the empty consensus db.
+// The root of the snapshot must match trusted, which has to come from a trusted source, like the node which exported it.
+//
+// Momentums don't commit to the whole state, so the exported state and consensus db can't be checked against signed
+// data and are only bound to the snapshot by its root. The momentums up to the base must chain back to the genesis,
+// and every momentum after the base is checked against its hash, changes-hash and signature before it is applied.
+// Its producer must have produced one of the momentums of the epoch before the base, since the elections would be
+// computed from the unverified state. The last momentum must match the header of the snapshot. The checksums of
+// the chunks are checked while reading.
+func Import(r io.Reader, chainDir string, consensusDB db.DB, genesis store.Genesis, trusted types.Hash) (*Header, types.Hash, error) {
+	sr, err := newReader(r)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	header, err := readHeader(sr, genesis)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+
+	restorer, err := db.NewLevelDBRestorer(chainDir)
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	if err := readEntries(sr, chunkState, restorer.Put); err != nil {
+		restorer.Close()
+		return nil, types.ZeroHash, err
+	}
+	if err := restorer.Close(); err != nil {
+		return nil, types.ZeroHash, err
+	}
+	if err := readEntries(sr, chunkConsensus, consensusDB.Put); err != nil {
+		return nil, types.ZeroHash, err
+	}
+
+	chainManager := db.NewLevelDBManager(chainDir)
+	defer chainManager.Stop()
+	if err := replayMomentums(sr, chainManager, genesis, header); err != nil {
+		return nil, types.ZeroHash, err
+	}
+
+	root, err := sr.close()
+	if err != nil {
+		return nil, types.ZeroHash, err
+	}
+	if root != trusted {
+		return nil, types.ZeroHash, ErrUntrustedSnapshot
+	}
+	return header, root, nil
+}
+
+func readHeader(sr *reader, genesis store.Genesis) (*Header, error) {
+	payload, err := sr.next(chunkHeader)
+	if err != nil {
+		return nil, err
+	}
+	header := new(Header)
+	if err := json.Unmarshal(payload, header); err != nil {
+		return nil, ErrMalformedChunk
+	}
+	if header.Version != Version {
+		return nil, ErrUnsupportedVersion
+	}
+	if header.ChainIdentifier != genesis.ChainIdentifier() || header.GenesisHash != genesis.GetGenesisMomentum().Hash {
+		return nil, ErrGenesisMismatch
+	}
+	return header, nil
+}
+
+func readEntries(sr *reader, kind byte, put func(key, value []byte) error) error {
+	for {
+		if current, err := sr.peek(); err != nil {
+			return err
+		} else if current != kind {
+			return nil
+		}
+		payload, err := sr.next(kind)
+		if err != nil {
+			return err
+		}
+		if err := forEachEntry(payload, put); err != nil {
+			return err
+		}
+	}
+}
+
+// verifyPrefix checks that the momentums up to the base chain back to the genesis and returns the producers of the
+// momentums of the last epoch. Unlike the elections, they don't depend on the state of the snapshot.
+func verifyPrefix(momentumStore store.Momentum, genesis store.Genesis, base types.HashHeight) (map[types.Address]struct{}, error) {
+	producers := make(map[types.Address]struct{})
+	producerWindow := uint64(constants.MomentumsPerEpoch)
+	previous := genesis.GetGenesisMomentum()
+	for previous.Height < base.Height {
+		count := base.Height - previous.Height
+		if count > prefixBatchSize {
+			count = prefixBatchSize
+		}
+		momentums, err := momentumStore.GetMomentumsByHeight(previous.Height+1, true, count)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(momentums)) != count {
+			return nil, ErrIncompleteSnapshot
+		}
+		for _, m := range momentums {
+			if m.Previous() != previous.Identifier() || m.ComputeHash() != m.Hash {
+				return nil, ErrInvalidMomentum
+			}
+			if m.Height+producerWindow > base.Height {
+				if isVerified, err := wallet.VerifySignature(m.PublicKey, m.Hash.Bytes(), m.Signature); err != nil || !isVerified {
+					return nil, ErrInvalidMomentum
+				}
+				producers[m.Producer()] = struct{}{}
+			}
+			previous = m
+		}
+	}
+	if previous.Identifier() != base {
+		return nil, ErrInvalidMomentum
+	}
+	return producers, nil
+}
+
+// replayMomentums verifies and applies the momentums after the base of the snapshot
+func replayMomentums(sr *reader, chainManager db.Manager, genesis store.Genesis, header *Header) error {
+	momentumStore := momentum.NewStore(genesis, chainManager.Frontier())
+	producers, err := verifyPrefix(momentumStore, genesis, header.Base)
+	if err != nil {
+		return err
+	}
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		return err
+	}
+	if frontier.Identifier() != header.Base {
+		return ErrInvalidMomentum
+	}
+
+	for {
+		if current, err := sr.peek(); err != nil {
+			return err
+		} else if current != chunkMomentum {
+			break
+		}
+		payload, err := sr.next(chunkMomentum)
+		if err != nil {
+			return err
+		}
+		transaction, err := parseMomentumTransaction(payload)
+		if err != nil {
+			return err
+		}
+		if err := verifyMomentumTransaction(transaction, frontier, producers); err != nil {
+			return err
+		}
+		frontier = transaction.Momentum
+		if err := chainManager.Add(transaction); err != nil {
+			return err
+		}
+	}
+
+	if frontier.Identifier() != header.Momentum {
+		return ErrInvalidMomentum
+	}
+	return nil
+}
+
+func parseMomentumTransaction(payload []byte) (*nom.MomentumTransaction, error) {
+	length, n := binary.Uvarint(payload)
+	if n <= 0 || uint64(len(payload)-n) < length {
+		return nil, ErrMalformedChunk
+	}
+	m, err := nom.DeserializeMomentum(payload[n : n+int(length)])
+	if err != nil {
+		return nil, ErrMalformedChunk
+	}
+	changes, err := db.NewPatchFromDump(payload[n+int(length):])
+	if err != nil {
+		return nil, ErrMalformedChunk
+	}
+	return &nom.MomentumTransaction{
+		Momentum: m,
+		Changes:  changes,
+	}, nil
+}
+
+func verifyMomentumTransaction(transaction *nom.MomentumTransaction, previous *nom.Momentum, producers map[types.Address]struct{}) error {
+	m := transaction.Momentum
+	if m.Previous() != previous.Identifier() || m.ComputeHash() != m.Hash {
+		return ErrInvalidMomentum
+	}
+	if db.PatchHash(transaction.Changes) != m.ChangesHash {
+		return ErrInvalidMomentum
+	}
+	if isVerified, err := wallet.VerifySignature(m.PublicKey, m.Hash.Bytes(), m.Signature); err != nil || !isVerified {
+		return ErrInvalidMomentum
+	}
+	// the signature only proves the momentum was signed by its producer, which must be a known one
+	if _, ok := producers[m.Producer()]; !ok {
+		return ErrUnknownProducer
+	}
+	return nil
+}
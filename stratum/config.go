@@ -0,0 +1,35 @@
+package stratum
+
+import (
+	"time"
+)
+
+const (
+	DefaultListenAddress   = "127.0.0.1:3333"
+	DefaultRefreshInterval = time.Second * 10
+	DefaultShareChainId    = 1
+)
+
+type Config struct {
+	// ListenAddress of the Stratum server, e.g. 0.0.0.0:3333
+	ListenAddress string
+	// HTTPListenAddress of the JSON-RPC fallback, disabled if empty
+	HTTPListenAddress string
+
+	// ShareChainId is the share chain the templates are served for
+	ShareChainId uint8
+	// RefreshInterval is how often the header chain is checked for a new tip
+	RefreshInterval time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.ListenAddress == "" {
+		c.ListenAddress = DefaultListenAddress
+	}
+	if c.ShareChainId == 0 {
+		c.ShareChainId = DefaultShareChainId
+	}
+	if c.RefreshInterval == 0 {
+		c.RefreshInterval = DefaultRefreshInterval
+	}
+}
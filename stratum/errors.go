@@ -0,0 +1,15 @@
+package stratum
+
+import "github.com/pkg/errors"
+
+var (
+	ErrSyncNotDone        = errors.Errorf("sync is not done")
+	ErrNoTemplate         = errors.Errorf("no work template available")
+	ErrUnknownJob         = errors.Errorf("job not found")
+	ErrDuplicateShare     = errors.Errorf("duplicate share")
+	ErrLowDifficultyShare = errors.Errorf("low difficulty share")
+	ErrInvalidSolution    = errors.Errorf("invalid solution")
+	ErrUnauthorized       = errors.Errorf("unauthorized worker")
+	ErrNotSubscribed      = errors.Errorf("not subscribed")
+	ErrUnknownMethod      = errors.Errorf("method not found")
+)
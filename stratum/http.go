@@ -0,0 +1,75 @@
+package stratum
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	maxHTTPRequestSize = 1024 * 16
+
+	errorCodeMethodNotFound = -32601
+	errorCodeInvalidParams  = -32602
+	errorCodeServer         = -32000
+)
+
+type jsonRequest struct {
+	JsonRpc string            `json:"jsonrpc"`
+	Id      interface{}       `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonResponse struct {
+	JsonRpc string      `json:"jsonrpc"`
+	Id      interface{} `json:"id"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *jsonError  `json:"error,omitempty"`
+}
+
+// serveHTTP is the JSON-RPC fallback for miners which can't keep a Stratum connection open.
+// Supports mining.getTemplate() and mining.submitSolution(solution).
+func (s *server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	request := new(jsonRequest)
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxHTTPRequestSize)).Decode(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := &jsonResponse{
+		JsonRpc: "2.0",
+		Id:      request.Id,
+	}
+	switch request.Method {
+	case "mining.getTemplate":
+		if template, err := s.GetTemplate(); err != nil {
+			response.Error = &jsonError{errorCodeServer, err.Error()}
+		} else {
+			response.Result = template
+		}
+	case "mining.submitSolution":
+		solution := new(Solution)
+		if len(request.Params) != 1 || json.Unmarshal(request.Params[0], solution) != nil {
+			response.Error = &jsonError{errorCodeInvalidParams, ErrInvalidSolution.Error()}
+		} else if hash, err := s.SubmitSolution(solution); err != nil {
+			response.Error = &jsonError{errorCodeServer, err.Error()}
+		} else {
+			response.Result = hash
+		}
+	default:
+		response.Error = &jsonError{errorCodeMethodNotFound, ErrUnknownMethod.Error()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,25 @@
+package stratum
+
+import (
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+type Manager interface {
+	Init() error
+	Start() error
+	Stop() error
+
+	// Endpoint is the address the Stratum server listens on
+	Endpoint() string
+	// HTTPEndpoint is the URL of the JSON-RPC fallback, empty if disabled
+	HTTPEndpoint() string
+
+	// Refresh is used by the testing environment to rebuild the work template
+	// without waiting for the refresh interval.
+	Refresh() error
+	// GetTemplate returns the current work template, with a new extranonce1
+	GetTemplate() (*Template, error)
+	// SubmitSolution builds the AddShare account block of a solved template and broadcasts it.
+	// Returns the hash of the share.
+	SubmitSolution(solution *Solution) (types.Hash, error)
+}
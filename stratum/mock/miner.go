@@ -0,0 +1,388 @@
+package mock
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/stratum"
+)
+
+const (
+	responseTimeout = time.Second * 10
+	// bitcoin difficulty 1 target, which Stratum difficulties are relative to
+	diff1Bits = 0x1d00ffff
+	// Stratum error code of low difficulty shares, the target derived from the difficulty is not exact
+	errorCodeLowDifficulty = 23
+)
+
+// MockMiner is a CPU miner which connects to a Stratum server and solves its work
+type MockMiner interface {
+	// Mine solves the work notified by the server until count shares are accepted
+	Mine(count int) error
+	Close()
+}
+
+type work struct {
+	jobId     string
+	prevBlock chainhash.Hash
+	coinbase1 []byte
+	coinbase2 []byte
+	branch    []chainhash.Hash
+	version   uint32
+	bits      uint32
+	timestamp uint32
+}
+
+type response struct {
+	Id     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  []interface{}   `json:"error"`
+}
+
+type mockMiner struct {
+	conn      net.Conn
+	worker    string
+	writing   sync.Mutex
+	changes   sync.Mutex
+	responses chan *response
+	notified  chan struct{}
+
+	nextId      uint64
+	extranonce1 []byte
+	extranonce2 uint32
+	// guarded by changes
+	target *big.Int
+	work   *work
+}
+
+func NewMockMiner(endpoint, worker string) (MockMiner, error) {
+	conn, err := net.Dial("tcp", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	m := &mockMiner{
+		conn:      conn,
+		worker:    worker,
+		responses: make(chan *response, 16),
+		notified:  make(chan struct{}, 1),
+	}
+	go m.read()
+
+	subscription := make([]json.RawMessage, 0)
+	if err := m.call(&subscription, "mining.subscribe", "mock-miner"); err != nil {
+		m.Close()
+		return nil, err
+	}
+	if len(subscription) < 2 {
+		m.Close()
+		return nil, errors.Errorf("unexpected subscription %v", subscription)
+	}
+	var extranonce1 string
+	if err := json.Unmarshal(subscription[1], &extranonce1); err != nil {
+		m.Close()
+		return nil, err
+	}
+	if m.extranonce1, err = hex.DecodeString(extranonce1); err != nil {
+		m.Close()
+		return nil, err
+	}
+
+	var authorized bool
+	if err := m.call(&authorized, "mining.authorize", worker, "x"); err != nil || !authorized {
+		m.Close()
+		return nil, errors.Errorf("worker %v not authorized: %v", worker, err)
+	}
+	return m, nil
+}
+
+func (m *mockMiner) Close() {
+	_ = m.conn.Close()
+}
+
+func (m *mockMiner) read() {
+	defer close(m.responses)
+	scanner := bufio.NewScanner(m.conn)
+	for scanner.Scan() {
+		message := new(struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		})
+		if err := json.Unmarshal(scanner.Bytes(), message); err != nil {
+			return
+		}
+		if message.Method == "" {
+			r := new(response)
+			if err := json.Unmarshal(scanner.Bytes(), r); err != nil {
+				return
+			}
+			m.responses <- r
+			continue
+		}
+		if err := m.handleNotification(message.Method, message.Params); err != nil {
+			return
+		}
+	}
+}
+
+func (m *mockMiner) handleNotification(method string, params []json.RawMessage) error {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+
+	switch method {
+	case "mining.set_difficulty":
+		var difficulty float64
+		if len(params) != 1 || json.Unmarshal(params[0], &difficulty) != nil {
+			return errors.Errorf("invalid difficulty")
+		}
+		diff1Target := new(big.Float).SetInt(blockchain.CompactToBig(diff1Bits))
+		m.target, _ = new(big.Float).Quo(diff1Target, big.NewFloat(difficulty)).Int(nil)
+	case "mining.notify":
+		w, err := parseWork(params)
+		if err != nil {
+			return err
+		}
+		m.work = w
+		select {
+		case m.notified <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func parseWork(params []json.RawMessage) (*work, error) {
+	if len(params) < 8 {
+		return nil, errors.Errorf("invalid notification")
+	}
+	values := make([]string, 8)
+	var branch []string
+	for i := range values {
+		var value interface{} = &values[i]
+		if i == 4 {
+			value = &branch
+		}
+		if err := json.Unmarshal(params[i], value); err != nil {
+			return nil, err
+		}
+	}
+
+	w := &work{jobId: values[0]}
+	prevBlock, err := hex.DecodeString(values[1])
+	if err != nil || len(prevBlock) != chainhash.HashSize {
+		return nil, errors.Errorf("invalid prev block")
+	}
+	// every 4-byte word of the serialized hash is reversed
+	for i := 0; i < chainhash.HashSize; i += 4 {
+		for j := 0; j < 4; j += 1 {
+			w.prevBlock[i+j] = prevBlock[i+3-j]
+		}
+	}
+	if w.coinbase1, err = hex.DecodeString(values[2]); err != nil {
+		return nil, err
+	}
+	if w.coinbase2, err = hex.DecodeString(values[3]); err != nil {
+		return nil, err
+	}
+	for _, node := range branch {
+		data, err := hex.DecodeString(node)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := chainhash.NewHash(data)
+		if err != nil {
+			return nil, err
+		}
+		w.branch = append(w.branch, *hash)
+	}
+	for i, field := range []*uint32{&w.version, &w.bits, &w.timestamp} {
+		data, err := hex.DecodeString(values[5+i])
+		if err != nil || len(data) != 4 {
+			return nil, errors.Errorf("invalid header field")
+		}
+		*field = binary.BigEndian.Uint32(data)
+	}
+	return w, nil
+}
+
+func (m *mockMiner) call(result interface{}, method string, params ...interface{}) error {
+	m.nextId += 1
+	id := m.nextId
+	data, err := json.Marshal(map[string]interface{}{
+		"id":     id,
+		"method": method,
+		"params": params,
+	})
+	common.DealWithErr(err)
+
+	m.writing.Lock()
+	_, err = m.conn.Write(append(data, '\n'))
+	m.writing.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case r, ok := <-m.responses:
+			if !ok {
+				return errors.Errorf("connection closed")
+			}
+			if r.Id != id {
+				continue
+			}
+			if r.Error != nil {
+				return &stratumError{r.Error}
+			}
+			return json.Unmarshal(r.Result, result)
+		case <-time.After(responseTimeout):
+			return errors.Errorf("%v: no response", method)
+		}
+	}
+}
+
+type stratumError struct {
+	values []interface{}
+}
+
+func (e *stratumError) Error() string {
+	return fmt.Sprintf("stratum error %v", e.values)
+}
+func (e *stratumError) code() int {
+	if len(e.values) == 0 {
+		return 0
+	}
+	code, _ := e.values[0].(float64)
+	return int(code)
+}
+
+func (m *mockMiner) waitForWork() (*work, *big.Int, error) {
+	for {
+		m.changes.Lock()
+		w, target := m.work, m.target
+		m.changes.Unlock()
+		if w != nil && target != nil {
+			return w, target, nil
+		}
+		select {
+		case <-m.notified:
+		case <-time.After(responseTimeout):
+			return nil, nil, errors.Errorf("no work received")
+		}
+	}
+}
+
+func (m *mockMiner) Mine(count int) error {
+	for accepted := 0; accepted < count; {
+		w, target, err := m.waitForWork()
+		if err != nil {
+			return err
+		}
+
+		m.extranonce2 += 1
+		extranonce2 := make([]byte, 4)
+		binary.BigEndian.PutUint32(extranonce2, m.extranonce2)
+		coinbase := common.JoinBytes(w.coinbase1, m.extranonce1, extranonce2, w.coinbase2)
+		nonce, ok := solve(w.prevBlock, merkleRoot(coinbase, w.branch), w.version, w.timestamp, w.bits, target)
+		if !ok {
+			continue
+		}
+
+		var result bool
+		err = m.call(&result, "mining.submit", m.worker, w.jobId, hex.EncodeToString(extranonce2), fmt.Sprintf("%08x", w.timestamp), fmt.Sprintf("%08x", nonce))
+		if stratumErr, ok := err.(*stratumError); ok && stratumErr.code() == errorCodeLowDifficulty {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if result {
+			accepted += 1
+		}
+	}
+	return nil
+}
+
+// SolveTemplate solves a template of the JSON-RPC fallback at the share chain difficulty
+func SolveTemplate(template *stratum.Template) (*stratum.Solution, error) {
+	coinbase1, err := hex.DecodeString(template.Coinbase1)
+	if err != nil {
+		return nil, err
+	}
+	coinbase2, err := hex.DecodeString(template.Coinbase2)
+	if err != nil {
+		return nil, err
+	}
+	extranonce1, err := hex.DecodeString(template.Extranonce1)
+	if err != nil {
+		return nil, err
+	}
+	prevBlock, err := chainhash.NewHashFromStr(template.PrevBlock.String())
+	if err != nil {
+		return nil, err
+	}
+	branch := make([]chainhash.Hash, len(template.MerkleBranch))
+	for i, hash := range template.MerkleBranch {
+		node, err := chainhash.NewHashFromStr(hash.String())
+		if err != nil {
+			return nil, err
+		}
+		branch[i] = *node
+	}
+
+	extranonce2 := make([]byte, template.Extranonce2Size)
+	coinbase := common.JoinBytes(coinbase1, extranonce1, extranonce2, coinbase2)
+	nonce, ok := solve(*prevBlock, merkleRoot(coinbase, branch), uint32(template.Version), template.Timestamp, template.Bits, blockchain.CompactToBig(template.ShareBits))
+	if !ok {
+		return nil, errors.Errorf("no solution found")
+	}
+	return &stratum.Solution{
+		JobId:       template.JobId,
+		Extranonce1: template.Extranonce1,
+		Extranonce2: hex.EncodeToString(extranonce2),
+		Timestamp:   template.Timestamp,
+		Nonce:       nonce,
+	}, nil
+}
+
+// merkleRoot follows the merkle branch of the coinbase, always the left-most transaction
+func merkleRoot(coinbase []byte, branch []chainhash.Hash) chainhash.Hash {
+	root := chainhash.DoubleHashH(coinbase)
+	for _, node := range branch {
+		root = chainhash.DoubleHashH(append(root[:], node[:]...))
+	}
+	return root
+}
+
+// solve searches for a nonce such that the hash of the header is below target
+func solve(prevBlock, merkleRoot chainhash.Hash, version, timestamp, bits uint32, target *big.Int) (uint32, bool) {
+	header := wire.BlockHeader{
+		Version:    int32(version),
+		PrevBlock:  prevBlock,
+		MerkleRoot: merkleRoot,
+		Timestamp:  time.Unix(int64(timestamp), 0),
+		Bits:       bits,
+	}
+	for nonce := uint32(0); ; nonce += 1 {
+		header.Nonce = nonce
+		hash := header.BlockHash()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			return nonce, true
+		}
+		if nonce == math.MaxUint32 {
+			return 0, false
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package stratum
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+// server serves work templates for a share chain of the merge mining contract and submits the solved ones
+// as AddShare account blocks of keyPair. The coinbase of every template commits to the address of keyPair,
+// since only the committed address can send the share.
+type server struct {
+	log        common.Logger
+	closed     chan struct{}
+	children   sync.WaitGroup
+	changes    sync.Mutex
+	submitting sync.Mutex
+
+	config  *Config
+	keyPair *wallet.KeyPair
+
+	listener     net.Listener
+	httpListener net.Listener
+	httpServer   *http.Server
+
+	// guarded by changes
+	jobCounter  uint64
+	extranonce1 uint32
+	current     *job
+	jobs        map[string]*job
+	jobOrder    []string
+	sessions    map[*session]struct{}
+
+	// modules
+	chain       chain.Chain
+	supervisor  *vm.Supervisor
+	broadcaster protocol.Broadcaster
+}
+
+func NewServer(config *Config, keyPair *wallet.KeyPair, chain chain.Chain, consensus consensus.Consensus, broadcaster protocol.Broadcaster) Manager {
+	config.setDefaults()
+	return &server{
+		log:         common.StratumLogger.New("address", keyPair.Address),
+		config:      config,
+		keyPair:     keyPair,
+		jobs:        make(map[string]*job),
+		sessions:    make(map[*session]struct{}),
+		chain:       chain,
+		supervisor:  vm.NewSupervisor(chain, consensus),
+		broadcaster: broadcaster,
+	}
+}
+
+func (s *server) Init() error {
+	return nil
+}
+func (s *server) Start() error {
+	s.log.Info("starting ...", "listen", s.config.ListenAddress, "http", s.config.HTTPListenAddress, "share-chain", s.config.ShareChainId)
+	defer s.log.Info("started")
+
+	listener, err := net.Listen("tcp", s.config.ListenAddress)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	if s.config.HTTPListenAddress != "" {
+		httpListener, err := net.Listen("tcp", s.config.HTTPListenAddress)
+		if err != nil {
+			_ = s.listener.Close()
+			return err
+		}
+		s.httpListener = httpListener
+		s.httpServer = &http.Server{Handler: http.HandlerFunc(s.serveHTTP)}
+	}
+
+	s.closed = make(chan struct{})
+	s.children.Add(2)
+	go s.accept()
+	go s.loop()
+	if s.httpServer != nil {
+		s.children.Add(1)
+		go func() {
+			defer s.children.Done()
+			if err := s.httpServer.Serve(s.httpListener); err != nil && err != http.ErrServerClosed {
+				s.log.Error("json-rpc server stopped", "reason", err)
+			}
+		}()
+	}
+
+	return nil
+}
+func (s *server) Stop() error {
+	s.log.Info("stopping ...")
+	defer s.log.Info("stopped")
+
+	close(s.closed)
+	_ = s.listener.Close()
+	if s.httpServer != nil {
+		_ = s.httpServer.Close()
+	}
+	s.changes.Lock()
+	for session := range s.sessions {
+		session.close()
+	}
+	s.changes.Unlock()
+	s.children.Wait()
+
+	return nil
+}
+
+func (s *server) Endpoint() string {
+	return s.listener.Addr().String()
+}
+func (s *server) HTTPEndpoint() string {
+	if s.httpListener == nil {
+		return ""
+	}
+	return fmt.Sprintf("http://%v", s.httpListener.Addr().String())
+}
+
+func (s *server) loop() {
+	defer s.children.Done()
+	defer common.RecoverStack()
+
+	wait := time.Duration(0)
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-time.After(wait):
+		}
+		wait = s.config.RefreshInterval
+
+		if err := s.Refresh(); err != nil && err != ErrSyncNotDone {
+			s.log.Error("failed to refresh work template", "reason", err)
+		}
+	}
+}
+
+func (s *server) accept() {
+	defer s.children.Done()
+	defer common.RecoverStack()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.closed:
+			default:
+				s.log.Error("stopped accepting stratum connections", "reason", err)
+			}
+			return
+		}
+
+		session := newSession(s, conn)
+		s.changes.Lock()
+		s.sessions[session] = struct{}{}
+		s.changes.Unlock()
+
+		s.children.Add(1)
+		go func() {
+			defer s.children.Done()
+			defer common.RecoverStack()
+			session.serve()
+
+			s.changes.Lock()
+			delete(s.sessions, session)
+			s.changes.Unlock()
+		}()
+	}
+}
+
+func (s *server) frontierContext() vm_context.AccountVmContext {
+	return vm_context.NewAccountContext(s.chain.GetFrontierMomentumStore(), s.chain.GetFrontierAccountStore(types.MergeMiningContract), nil)
+}
+
+func (s *server) Refresh() error {
+	if s.broadcaster.SyncInfo().State != protocol.SyncDone {
+		return ErrSyncNotDone
+	}
+
+	context := s.frontierContext()
+	_, headerChainInfo, err := implementation.CanPerformActionMergeMining(context)
+	if err != nil {
+		return err
+	}
+	shareChainInfo, err := definition.GetShareChainInfoVariableVariable(context.Storage(), s.config.ShareChainId)
+	if err == constants.ErrDataNonExistent {
+		return constants.ErrShareChainNonExistent
+	} else if err != nil {
+		return err
+	}
+	prevBlock, err := definition.GetBlockHeaderVariable(context.Storage(), headerChainInfo.Tip)
+	if err != nil {
+		return err
+	}
+
+	// The contract rejects shares which are not after the median time past of the tip
+	timestamp := uint32(common.Clock.Now().Unix())
+	if medianTime := implementation.CalcPastMedianTime(context.Storage(), prevBlock); timestamp <= medianTime {
+		timestamp = medianTime + 1
+	}
+	bits, err := implementation.CalcNextRequiredDifficulty(context.Storage(), prevBlock, timestamp, implementation.GetPowParams(context.Storage()))
+	if err != nil {
+		return err
+	}
+
+	s.changes.Lock()
+	defer s.changes.Unlock()
+
+	if s.current != nil && s.current.prevBlock.Hash == prevBlock.Hash && s.current.bits == bits && s.current.shareBits == shareChainInfo.Bits {
+		return nil
+	}
+	clean := s.current == nil || s.current.prevBlock.Hash != prevBlock.Hash
+	s.jobCounter += 1
+	j := newJob(fmt.Sprintf("%x", s.jobCounter), s.config.ShareChainId, s.keyPair.Address, prevBlock, bits, shareChainInfo.Bits, timestamp)
+	s.current = j
+	s.jobs[j.id] = j
+	s.jobOrder = append(s.jobOrder, j.id)
+	if len(s.jobOrder) > maxJobs {
+		delete(s.jobs, s.jobOrder[0])
+		s.jobOrder = s.jobOrder[1:]
+	}
+	s.log.Info("new work template", "job", j.id, "height", prevBlock.Height+1, "prev-block", prevBlock.Hash, "bits", bits, "share-bits", shareChainInfo.Bits)
+
+	for session := range s.sessions {
+		if session.subscribed {
+			session.notify(j, clean)
+		}
+	}
+	return nil
+}
+
+// nextExtranonce1 returns a new extranonce1, so that every miner searches a distinct space. Requires changes.
+func (s *server) nextExtranonce1() []byte {
+	s.extranonce1 += 1
+	extranonce1 := make([]byte, extranonce1Size)
+	binary.BigEndian.PutUint32(extranonce1, s.extranonce1)
+	return extranonce1
+}
+
+func (s *server) GetTemplate() (*Template, error) {
+	s.changes.Lock()
+	defer s.changes.Unlock()
+
+	if s.current == nil {
+		return nil, ErrNoTemplate
+	}
+	return s.current.template(s.nextExtranonce1()), nil
+}
+
+func (s *server) SubmitSolution(solution *Solution) (types.Hash, error) {
+	extranonce1, err := hex.DecodeString(solution.Extranonce1)
+	if err != nil {
+		return types.ZeroHash, ErrInvalidSolution
+	}
+	extranonce2, err := hex.DecodeString(solution.Extranonce2)
+	if err != nil {
+		return types.ZeroHash, ErrInvalidSolution
+	}
+	return s.submit(solution.JobId, extranonce1, extranonce2, solution.Timestamp, solution.Nonce)
+}
+
+// submit checks the solution the same way the contract does and sends the AddShare account block
+func (s *server) submit(jobId string, extranonce1, extranonce2 []byte, timestamp, nonce uint32) (types.Hash, error) {
+	s.changes.Lock()
+	j, ok := s.jobs[jobId]
+	s.changes.Unlock()
+	if !ok {
+		return types.ZeroHash, ErrUnknownJob
+	}
+
+	share, err := j.share(extranonce1, extranonce2, timestamp, nonce)
+	if err != nil {
+		return types.ZeroHash, err
+	}
+	context := s.frontierContext()
+	if err := implementation.CheckBlockTimestamp(context, j.prevBlock, share.Timestamp); err != nil {
+		return types.ZeroHash, ErrInvalidSolution
+	}
+	bits, err := implementation.CalcNextRequiredDifficulty(context.Storage(), j.prevBlock, share.Timestamp, implementation.GetPowParams(context.Storage()))
+	if err != nil {
+		return types.ZeroHash, err
+	}
+	header := definition.BlockHeaderVariable{
+		BaseHeader: definition.BaseHeader{
+			Version:    share.Version,
+			PrevBlock:  share.PrevBlock,
+			MerkleRoot: share.MerkleRoot,
+			Timestamp:  share.Timestamp,
+			Bits:       bits,
+			Nonce:      share.Nonce,
+		},
+	}
+	if err := implementation.CheckProofOfWork(header, j.shareBits); err != nil {
+		return types.ZeroHash, ErrLowDifficultyShare
+	}
+	if err := implementation.CheckShareProof(share, s.keyPair.Address); err != nil {
+		return types.ZeroHash, err
+	}
+
+	hash := header.BlockHash()
+	s.changes.Lock()
+	_, duplicate := j.submitted[hash]
+	j.submitted[hash] = struct{}{}
+	s.changes.Unlock()
+	if duplicate {
+		return types.ZeroHash, ErrDuplicateShare
+	}
+
+	// account blocks of the same address have to be generated one after the other
+	s.submitting.Lock()
+	defer s.submitting.Unlock()
+	block, err := s.supervisor.GenerateFromTemplate(&nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       s.keyPair.Address,
+		ToAddress:     types.MergeMiningContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data: definition.ABIMergeMining.PackMethodPanic(definition.AddShareMethodName,
+			share.ShareChainId,
+			share.Version,
+			share.PrevBlock,
+			share.MerkleRoot,
+			share.Timestamp,
+			share.Nonce,
+			share.Coinbase,
+			share.MerkleBranch,
+		),
+	}, s.keyPair.Signer)
+	if err != nil {
+		return types.ZeroHash, err
+	}
+	s.broadcaster.CreateAccountBlock(block)
+	s.log.Info("submitted share", "hash", hash, "job", jobId, "identifier", block.Block.Header())
+	return hash, nil
+}
@@ -0,0 +1,262 @@
+package stratum
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zenon-network/go-zenon/common"
+)
+
+const (
+	maxMessageSize = 1024 * 16
+	writeTimeout   = time.Second * 10
+)
+
+// Stratum error codes
+const (
+	errorCodeOther          = 20
+	errorCodeUnknownJob     = 21
+	errorCodeDuplicateShare = 22
+	errorCodeLowDifficulty  = 23
+	errorCodeUnauthorized   = 24
+	errorCodeNotSubscribed  = 25
+)
+
+type stratumRequest struct {
+	Id     interface{}       `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type stratumResponse struct {
+	Id     interface{}   `json:"id"`
+	Result interface{}   `json:"result"`
+	Error  []interface{} `json:"error"`
+}
+
+type stratumNotification struct {
+	Id     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// session is a Stratum connection of a miner. Requests are line delimited JSON-RPC 1.0 messages.
+type session struct {
+	log     common.Logger
+	server  *server
+	conn    net.Conn
+	writing sync.Mutex
+
+	// guarded by server.changes
+	extranonce1 []byte
+	subscribed  bool
+	// only used by the reading goroutine
+	worker string
+}
+
+func newSession(s *server, conn net.Conn) *session {
+	return &session{
+		log:    s.log.New("remote", conn.RemoteAddr().String()),
+		server: s,
+		conn:   conn,
+	}
+}
+
+func (c *session) close() {
+	_ = c.conn.Close()
+}
+
+func (c *session) serve() {
+	defer c.close()
+	c.log.Debug("stratum connection opened")
+	defer c.log.Debug("stratum connection closed")
+
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 0, 1024), maxMessageSize)
+	for scanner.Scan() {
+		request := new(stratumRequest)
+		if err := json.Unmarshal(scanner.Bytes(), request); err != nil {
+			c.log.Debug("invalid stratum message", "reason", err)
+			return
+		}
+		result, err := c.handle(request)
+		if err != nil {
+			c.write(&stratumResponse{
+				Id:    request.Id,
+				Error: []interface{}{errorCode(err), err.Error(), nil},
+			})
+		} else {
+			c.write(&stratumResponse{
+				Id:     request.Id,
+				Result: result,
+			})
+		}
+
+		// the work follows the response of the subscription
+		if request.Method == "mining.subscribe" {
+			c.server.changes.Lock()
+			current := c.server.current
+			c.server.changes.Unlock()
+			if current != nil {
+				c.notify(current, true)
+			}
+		}
+	}
+}
+
+func errorCode(err error) int {
+	switch err {
+	case ErrUnknownJob:
+		return errorCodeUnknownJob
+	case ErrDuplicateShare:
+		return errorCodeDuplicateShare
+	case ErrLowDifficultyShare:
+		return errorCodeLowDifficulty
+	case ErrUnauthorized:
+		return errorCodeUnauthorized
+	case ErrNotSubscribed:
+		return errorCodeNotSubscribed
+	default:
+		return errorCodeOther
+	}
+}
+
+func (c *session) write(message interface{}) {
+	data, err := json.Marshal(message)
+	common.DealWithErr(err)
+
+	c.writing.Lock()
+	defer c.writing.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		c.log.Debug("failed to write stratum message", "reason", err)
+		c.close()
+	}
+}
+
+func (c *session) handle(request *stratumRequest) (interface{}, error) {
+	switch request.Method {
+	case "mining.subscribe":
+		return c.subscribe(), nil
+	case "mining.authorize":
+		var worker string
+		if len(request.Params) == 0 || json.Unmarshal(request.Params[0], &worker) != nil {
+			return nil, ErrUnauthorized
+		}
+		c.worker = worker
+		c.log.Info("authorized worker", "worker", worker)
+		return true, nil
+	case "mining.extranonce.subscribe":
+		return true, nil
+	case "mining.submit":
+		return c.submit(request.Params)
+	default:
+		return nil, ErrUnknownMethod
+	}
+}
+
+func (c *session) subscribe() interface{} {
+	s := c.server
+	s.changes.Lock()
+	defer s.changes.Unlock()
+
+	if c.extranonce1 == nil {
+		c.extranonce1 = s.nextExtranonce1()
+	}
+	subscriptionId := hex.EncodeToString(c.extranonce1)
+	result := []interface{}{
+		[][]string{
+			{"mining.set_difficulty", subscriptionId},
+			{"mining.notify", subscriptionId},
+		},
+		hex.EncodeToString(c.extranonce1),
+		extranonce2Size,
+	}
+
+	c.subscribed = true
+	return result
+}
+
+// notify sends the job to the miner, preceded by the difficulty of the share chain
+func (c *session) notify(j *job, clean bool) {
+	c.write(&stratumNotification{
+		Method: "mining.set_difficulty",
+		Params: []interface{}{shareDifficulty(j.shareBits)},
+	})
+	c.write(&stratumNotification{
+		Method: "mining.notify",
+		Params: []interface{}{
+			j.id,
+			stratumPrevHash(j.prevBlock.Hash),
+			hex.EncodeToString(j.coinbase1),
+			hex.EncodeToString(j.coinbase2),
+			[]string{},
+			fmt.Sprintf("%08x", uint32(templateVersion)),
+			fmt.Sprintf("%08x", j.bits),
+			fmt.Sprintf("%08x", j.timestamp),
+			clean,
+		},
+	})
+}
+
+// submit handles mining.submit(worker, jobId, extranonce2, ntime, nonce)
+func (c *session) submit(params []json.RawMessage) (interface{}, error) {
+	if c.worker == "" {
+		return nil, ErrUnauthorized
+	}
+	c.server.changes.Lock()
+	subscribed, extranonce1 := c.subscribed, c.extranonce1
+	c.server.changes.Unlock()
+	if !subscribed {
+		return nil, ErrNotSubscribed
+	}
+
+	if len(params) < 5 {
+		return nil, ErrInvalidSolution
+	}
+	values := make([]string, 5)
+	for i := range values {
+		if err := json.Unmarshal(params[i], &values[i]); err != nil {
+			return nil, ErrInvalidSolution
+		}
+	}
+	if values[0] != c.worker {
+		return nil, ErrUnauthorized
+	}
+	extranonce2, err := hex.DecodeString(values[2])
+	if err != nil {
+		return nil, ErrInvalidSolution
+	}
+	timestamp, err := parseHexUint32(values[3])
+	if err != nil {
+		return nil, ErrInvalidSolution
+	}
+	nonce, err := parseHexUint32(values[4])
+	if err != nil {
+		return nil, ErrInvalidSolution
+	}
+
+	hash, err := c.server.submit(values[1], extranonce1, extranonce2, timestamp, nonce)
+	if err != nil {
+		c.log.Debug("rejected share", "worker", c.worker, "job", values[1], "reason", err)
+		return nil, err
+	}
+	c.log.Info("accepted share", "worker", c.worker, "job", values[1], "hash", hash)
+	return true, nil
+}
+
+// parseHexUint32 parses the big-endian hex values of Stratum
+func parseHexUint32(value string) (uint32, error) {
+	data, err := hex.DecodeString(value)
+	if err != nil || len(data) != 4 {
+		return 0, strconv.ErrSyntax
+	}
+	return binary.BigEndian.Uint32(data), nil
+}
@@ -0,0 +1,60 @@
+package stratum
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+func TestBuildCoinbase(t *testing.T) {
+	address := types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz")
+	commitment := definition.GetShareCommitment(1, address)
+	coinbase1, coinbase2 := buildCoinbase(838290, commitment)
+
+	data := common.JoinBytes(coinbase1, []byte{1, 2, 3, 4}, []byte{5, 6, 7, 8}, coinbase2)
+	coinbase := new(wire.MsgTx)
+	common.FailIfErr(t, coinbase.Deserialize(bytes.NewReader(data)))
+	common.ExpectTrue(t, blockchain.IsCoinBaseTx(coinbase))
+
+	script := coinbase.TxIn[0].SignatureScript
+	// BIP34 height, 838290 = 0x0cca92
+	common.ExpectTrue(t, bytes.HasPrefix(script, []byte{0x03, 0x92, 0xca, 0x0c}))
+	common.ExpectTrue(t, bytes.Contains(script, commitment))
+	common.ExpectTrue(t, bytes.HasSuffix(script, []byte{1, 2, 3, 4, 5, 6, 7, 8}))
+}
+
+func TestJobShare(t *testing.T) {
+	address := types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz")
+	prevBlock := &definition.BlockHeaderVariable{
+		Height: 1000,
+		Hash:   types.HexToHashPanic("6e6b8d7ccdd27a85f19fb088dcc9cd7832435294818be1340b9535d904d28a25"),
+	}
+	j := newJob("1", 1, address, prevBlock, 0x207fffff, 0x207fffff, 1712575802)
+
+	share, err := j.share([]byte{0, 0, 0, 1}, []byte{0, 0, 0, 2}, 1712575803, 7)
+	common.FailIfErr(t, err)
+	common.Expect(t, share.PrevBlock, prevBlock.Hash)
+	common.ExpectUint64(t, uint64(share.Nonce), 7)
+	common.ExpectUint64(t, uint64(len(share.MerkleBranch)), 0)
+
+	// the extranonce has a fixed size
+	_, err = j.share([]byte{0, 0, 0, 1}, []byte{0, 0, 2}, 1712575803, 7)
+	common.ExpectError(t, err, ErrInvalidSolution)
+}
+
+func TestStratumPrevHash(t *testing.T) {
+	hash := types.HexToHashPanic("00000000000000000001052825fecaf9987861781cb11af3639603a381db34e4")
+	common.ExpectString(t, stratumPrevHash(hash), "81db34e4639603a31cb11af39878617825fecaf9000105280000000000000000")
+}
+
+func TestShareDifficulty(t *testing.T) {
+	common.ExpectTrue(t, shareDifficulty(0x1d00ffff) == 1)
+	common.ExpectTrue(t, shareDifficulty(0x1c00ffff) == 256)
+	common.ExpectTrue(t, shareDifficulty(0x207fffff) < 1)
+}
@@ -0,0 +1,170 @@
+package stratum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+const (
+	extranonce1Size = 4
+	extranonce2Size = 4
+	// maxJobs is the number of recent jobs solutions are accepted for
+	maxJobs = 8
+	// templateVersion signals BIP9 versionbits with no deployment
+	templateVersion = 0x20000000
+)
+
+// Template is the work served to miners: a bitcoin block on top of the tip of the header chain
+// which only contains a coinbase transaction committing to the share chain and to Address.
+// The coinbase is Coinbase1 + Extranonce1 + extranonce2 + Coinbase2, all hex encoded, as in Stratum.
+type Template struct {
+	JobId           string        `json:"jobId"`
+	ShareChainId    uint8         `json:"shareChainId"`
+	Address         types.Address `json:"address"`
+	Height          uint32        `json:"height"`
+	PrevBlock       types.Hash    `json:"prevBlock"`
+	Version         int32         `json:"version"`
+	Bits            uint32        `json:"bits"`
+	ShareBits       uint32        `json:"shareBits"`
+	Timestamp       uint32        `json:"timestamp"`
+	Commitment      string        `json:"commitment"`
+	Coinbase1       string        `json:"coinbase1"`
+	Coinbase2       string        `json:"coinbase2"`
+	Extranonce1     string        `json:"extranonce1"`
+	Extranonce2Size int           `json:"extranonce2Size"`
+	MerkleBranch    []types.Hash  `json:"merkleBranch"`
+}
+
+// Solution of a template, the extranonces are hex encoded
+type Solution struct {
+	JobId       string `json:"jobId"`
+	Extranonce1 string `json:"extranonce1"`
+	Extranonce2 string `json:"extranonce2"`
+	Timestamp   uint32 `json:"timestamp"`
+	Nonce       uint32 `json:"nonce"`
+}
+
+type job struct {
+	id           string
+	shareChainId uint8
+	address      types.Address
+	prevBlock    *definition.BlockHeaderVariable
+	bits         uint32
+	shareBits    uint32
+	timestamp    uint32
+	coinbase1    []byte
+	coinbase2    []byte
+	// hashes of the shares already submitted for this job
+	submitted map[types.Hash]struct{}
+}
+
+func newJob(id string, shareChainId uint8, address types.Address, prevBlock *definition.BlockHeaderVariable, bits, shareBits, timestamp uint32) *job {
+	coinbase1, coinbase2 := buildCoinbase(prevBlock.Height+1, definition.GetShareCommitment(shareChainId, address))
+	return &job{
+		id:           id,
+		shareChainId: shareChainId,
+		address:      address,
+		prevBlock:    prevBlock,
+		bits:         bits,
+		shareBits:    shareBits,
+		timestamp:    timestamp,
+		coinbase1:    coinbase1,
+		coinbase2:    coinbase2,
+		submitted:    make(map[types.Hash]struct{}),
+	}
+}
+
+// buildCoinbase returns the serialized coinbase transaction around the extranonce.
+// The script starts with the height, as required by BIP34, followed by the commitment and the extranonce.
+func buildCoinbase(height uint32, commitment []byte) ([]byte, []byte) {
+	script, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).
+		AddData(commitment).
+		AddData(make([]byte, extranonce1Size+extranonce2Size)).
+		Script()
+	common.DealWithErr(err)
+
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), script, nil))
+	coinbase.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+	buffer := new(bytes.Buffer)
+	common.DealWithErr(coinbase.Serialize(buffer))
+	serialized := buffer.Bytes()
+
+	// version, input count and previous outpoint precede the script
+	scriptEnd := 4 + 1 + 36 + wire.VarIntSerializeSize(uint64(len(script))) + len(script)
+	return serialized[:scriptEnd-extranonce1Size-extranonce2Size], serialized[scriptEnd:]
+}
+
+func (j *job) template(extranonce1 []byte) *Template {
+	return &Template{
+		JobId:           j.id,
+		ShareChainId:    j.shareChainId,
+		Address:         j.address,
+		Height:          j.prevBlock.Height + 1,
+		PrevBlock:       j.prevBlock.Hash,
+		Version:         templateVersion,
+		Bits:            j.bits,
+		ShareBits:       j.shareBits,
+		Timestamp:       j.timestamp,
+		Commitment:      hex.EncodeToString(definition.GetShareCommitment(j.shareChainId, j.address)),
+		Coinbase1:       hex.EncodeToString(j.coinbase1),
+		Coinbase2:       hex.EncodeToString(j.coinbase2),
+		Extranonce1:     hex.EncodeToString(extranonce1),
+		Extranonce2Size: extranonce2Size,
+		MerkleBranch:    []types.Hash{},
+	}
+}
+
+// share rebuilds the coinbase with the extranonces of the miner and returns the share to submit
+func (j *job) share(extranonce1, extranonce2 []byte, timestamp, nonce uint32) (*definition.Share, error) {
+	if len(extranonce1) != extranonce1Size || len(extranonce2) != extranonce2Size {
+		return nil, ErrInvalidSolution
+	}
+	data := common.JoinBytes(j.coinbase1, extranonce1, extranonce2, j.coinbase2)
+	coinbase := new(wire.MsgTx)
+	if err := coinbase.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, ErrInvalidSolution
+	}
+
+	return &definition.Share{
+		ShareChainId: j.shareChainId,
+		Version:      templateVersion,
+		PrevBlock:    j.prevBlock.Hash,
+		// The coinbase is the only transaction of the block
+		MerkleRoot:   types.HexToHashPanic(coinbase.TxHash().String()),
+		Timestamp:    timestamp,
+		Nonce:        nonce,
+		Coinbase:     data,
+		MerkleBranch: []types.Hash{},
+	}, nil
+}
+
+// stratumPrevHash encodes the hash as Stratum does: the serialized hash with the bytes of every 4-byte word reversed
+func stratumPrevHash(hash types.Hash) string {
+	encoded := make([]byte, types.HashSize)
+	for i := 0; i < types.HashSize; i += 4 {
+		// hash is displayed in reverse order, so the words are taken from the end
+		copy(encoded[i:i+4], hash[types.HashSize-i-4:types.HashSize-i])
+	}
+	return hex.EncodeToString(encoded)
+}
+
+// shareDifficulty returns the Stratum difficulty of the share chain bits, relative to the bitcoin difficulty 1 target
+func shareDifficulty(bits uint32) float64 {
+	diff1Target := new(big.Float).SetInt(blockchain.CompactToBig(constants.MainNetPowParams.PowLimitBits))
+	target := new(big.Float).SetInt(blockchain.CompactToBig(bits))
+	difficulty, _ := new(big.Float).Quo(diff1Target, target).Float64()
+	return difficulty
+}
@@ -19,6 +19,8 @@ import (
 
 type MomentumVerifier interface {
 	Momentum(momentum *nom.DetailedMomentum) error
+	// MomentumAt verifies the momentum against momentumStore, the store of its previous momentum
+	MomentumAt(momentum *nom.DetailedMomentum, momentumStore store.Momentum) error
 	MomentumTransaction(transaction *nom.MomentumTransaction) error
 }
 
@@ -47,7 +49,9 @@ func (mv *momentumVerifier) Momentum(detailed *nom.DetailedMomentum) error {
 	if err != nil {
 		return err
 	}
-
+	return mv.MomentumAt(detailed, momentumStore)
+}
+func (mv *momentumVerifier) MomentumAt(detailed *nom.DetailedMomentum, momentumStore store.Momentum) error {
 	return (&rawMomentumVerifier{
 		momentum:      detailed.Momentum,
 		accountBlocks: detailed.AccountBlocks,
@@ -3,7 +3,6 @@ package constants
 import (
 	"github.com/zenon-network/go-zenon/common/types"
 	"math/big"
-	"time"
 
 	"github.com/zenon-network/go-zenon/common"
 )
@@ -13,6 +12,13 @@ const (
 	SecsInDay = 24 * 60 * 60
 )
 
+// ReceiveResultSuccess and ReceiveResultFail are written in the data of the receive-block of a call to an embedded contract
+const (
+	ReceiveResultInvalid uint64 = iota
+	ReceiveResultSuccess
+	ReceiveResultFail
+)
+
 var (
 	/// === Common ===
 
@@ -96,17 +102,20 @@ var (
 
 	InitialMergeMiningAdministrator = types.ParseAddressPanic("z1qz8q0x3rs36z2kw8eltf8r323hlcn64jnujkuz")
 
-	// bigOne is 1 represented as a big.Int.  It is defined here to avoid
-	// the overhead of creating it multiple times.
-	bigOne = big.NewInt(1)
-
-	// MainPowLimit is the highest proof of work value a Bitcoin block can
-	// have for the main network.  It is the value 2^224 - 1.
-	MainPowLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, 224), bigOne)
-
-	RetargetAdjustmentFactor = int64(4) // 25% less, 400% more
-	TargetTimespan           = time.Hour * 24 * 14
-	MaxRetargetTimespan      = int64(TargetTimespan/time.Second) * RetargetAdjustmentFactor
+	// MedianTimeBlocks is the number of previous blocks used to compute the median time past
+	MedianTimeBlocks = 11
+	// MaxTimeOffsetSeconds is how far in the future, relative to the frontier momentum, a block header timestamp can be
+	MaxTimeOffsetSeconds = int64(2 * 60 * 60)
+	// MaxReorgDepth is how many blocks behind the tip a fork can start, headers of stale forks older than that are pruned
+	MaxReorgDepth = uint32(144)
+	// MaxCoinbaseLength is the maximum size in bytes of a share coinbase transaction
+	MaxCoinbaseLength = 1024 * 8
+	// MaxMerkleBranchLength is the maximum depth of a share or transaction merkle branch
+	MaxMerkleBranchLength = 32
+	// MaxTransactionLength is the maximum size in bytes of a transaction whose inclusion is verified
+	MaxTransactionLength = 1024 * 100
+	// TransactionConfirmations is how many blocks of the main chain, including its own, must confirm a verified transaction
+	TransactionConfirmations = uint32(6)
 
 	/// === Bridge constants ===
 
@@ -124,6 +133,11 @@ var (
 	DecompressedECDSAPubKeyLength = 65
 	CompressedECDSAPubKeyLength   = 33
 	ECDSASignatureLength          = 65
+	SchnorrSignatureLength        = 64
+
+	// FeeDistributionTotalPercentages is what the percentages of a fee distribution must add up to
+	FeeDistributionTotalPercentages = uint32(10000)
+	MaxFeeDistributionDestinations  = 10
 
 	/// === Reward constants ===
 
@@ -168,6 +182,12 @@ var (
 	LiquidityStakeWeights               = []int64{
 		0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12,
 	}
+
+	// MergeMiningZnnRewardPercentage and MergeMiningQsrRewardPercentage are taken out of the liquidity reward
+	// once the merge mining spork is enforced, so the emission stays the same. The reward of an epoch
+	// without shares is paid with the next epoch which has shares
+	MergeMiningZnnRewardPercentage int64 = 3
+	MergeMiningQsrRewardPercentage int64 = 5
 )
 
 func NetworkZnnRewardPerEpoch(epoch uint64) int64 {
@@ -209,6 +229,14 @@ func LiquidityRewardForEpoch(epoch uint64) (*big.Int, *big.Int) {
 	return big.NewInt(znn), big.NewInt(qsr)
 }
 
+// MergeMiningRewardForEpoch returns merge mining Znn and Qsr reward for a specific epoch.
+// It's part of the liquidity reward for the same epoch, see LiquidityRewardForEpoch.
+func MergeMiningRewardForEpoch(epoch uint64) (*big.Int, *big.Int) {
+	znn := (NetworkZnnRewardPerEpoch(epoch) * MergeMiningZnnRewardPercentage) / 100
+	qsr := (NetworkQsrRewardPerEpoch(epoch) * MergeMiningQsrRewardPercentage) / 100
+	return big.NewInt(znn), big.NewInt(qsr)
+}
+
 // StakeQsrRewardPerEpoch returns staking Qsr reward for a specific epoch
 func StakeQsrRewardPerEpoch(epoch uint64) *big.Int {
 	qsr := (NetworkQsrRewardPerEpoch(epoch) * StakingQsrRewardPercentage) / 100
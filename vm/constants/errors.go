@@ -87,28 +87,48 @@ var (
 	ErrInvalidEDDSASignature                = errors.New("invalid ed25519 signature")
 	ErrInvalidEDDSAPubKey                   = errors.New("invalid eddsa public key")
 	ErrInvalidECDSASignature                = errors.New("invalid secp256k1 signature")
+	ErrUnknownBitcoinChain                  = errors.New("unknown bitcoin chain id")
 	ErrInvalidDecompressedECDSAPubKeyLength = errors.New("invalid decompressed secp256k1 public key length")
 	ErrInvalidCompressedECDSAPubKeyLength   = errors.New("invalid compressed secp256k1 public key length")
 	ErrNotAllowedToChangeTss                = errors.New("changing the tss public key is not allowed")
 	ErrInvalidJsonContent                   = errors.New("metadata does not respect the JSON format")
 	ErrInvalidMinAmount                     = errors.New("invalid min amount")
+	ErrTokenPairLimitExceeded               = errors.New("token pair limit exceeded")
 	ErrTimeChallengeNotDue                  = errors.New("time challenge not due")
 	ErrNotEmergency                         = errors.New("bridge not in emergency")
 	ErrInvalidGuardians                     = errors.New("invalid guardians")
 	ErrSecurityNotInitialized               = errors.New("security not initialized")
 	ErrBridgeNotHalted                      = errors.New("bridge not halted")
+	ErrFeeDistributionNotSet                = errors.New("fee distribution not set")
 
 	// Liquidity
 	ErrInvalidPercentages = errors.New("invalid percentages")
 	ErrInvalidRewards     = errors.New("invalid liquidity stake rewards")
 
 	// Merge Mining
-	ErrMergeMiningNotInitialized    = errors.New("merge mining info is not initialized")
-	ErrHeaderChainNotInitialized    = errors.New("header chain info is not initialized")
-	ErrPrevBlockNonExistent         = errors.New("prev block non existend")
-	ErrShareChainNonExistent        = errors.New("share chain non existent")
-	ErrInvalidNonce                 = errors.New("invalid nonce")
-	ErrTargetDifficultyLessThanZero = errors.New("target difficulty must be larger than 0")
-	ErrDifficultyLessThanMin        = errors.New("target difficulty less than min of prev block")
-	ErrPowLimitExceeded             = errors.New("pow limit exceeded")
+	ErrMergeMiningNotInitialized        = errors.New("merge mining info is not initialized")
+	ErrHeaderChainNotInitialized        = errors.New("header chain info is not initialized")
+	ErrPrevBlockNonExistent             = errors.New("prev block non existend")
+	ErrShareChainNonExistent            = errors.New("share chain non existent")
+	ErrInvalidNonce                     = errors.New("invalid nonce")
+	ErrTargetDifficultyLessThanZero     = errors.New("target difficulty must be larger than 0")
+	ErrDifficultyLessThanMin            = errors.New("target difficulty less than min of prev block")
+	ErrPowLimitExceeded                 = errors.New("pow limit exceeded")
+	ErrInvalidDifficultyBits            = errors.New("block difficulty bits do not match the required difficulty")
+	ErrTimestampTooOld                  = errors.New("block timestamp is not after the median time past")
+	ErrTimestampTooFarInFuture          = errors.New("block timestamp too far in the future")
+	ErrRetargetAncestorNonExistent      = errors.New("retarget ancestor non existent")
+	ErrBlockHeaderAlreadyExists         = errors.New("block header already exists")
+	ErrInvalidCoinbase                  = errors.New("invalid coinbase transaction")
+	ErrInvalidShareCommitment           = errors.New("coinbase does not commit to the share chain and address")
+	ErrInvalidMerkleBranch              = errors.New("invalid merkle branch")
+	ErrShareAlreadyExists               = errors.New("share already exists")
+	ErrReorgTooDeep                     = errors.New("block header forks the main chain deeper than the max reorg depth")
+	ErrBlockHeaderNonExistent           = errors.New("block header non existent")
+	ErrInvalidTransaction               = errors.New("invalid bitcoin transaction")
+	ErrInsufficientConfirmations        = errors.New("block header does not have enough confirmations")
+	ErrTransactionAlreadyVerified       = errors.New("transaction already verified")
+	ErrUnknownPowNetwork                = errors.New("unknown proof of work network")
+	ErrHeaderChainInitialized           = errors.New("header chain info is already initialized")
+	ErrInitialHeaderNotRetargetBoundary = errors.New("initial header is not the first block of a retarget period")
 )
@@ -0,0 +1,81 @@
+package constants
+
+import (
+	"math/big"
+	"time"
+)
+
+// PowParams defines the proof of work rules of the Bitcoin network followed by the merge mining header chain
+type PowParams struct {
+	Network string `json:"network"`
+
+	// PowLimit is the highest proof of work target a block can have
+	PowLimit     *big.Int `json:"powLimit"`
+	PowLimitBits uint32   `json:"powLimitBits"`
+
+	TargetTimespan           time.Duration `json:"targetTimespan"`
+	TargetTimePerBlock       time.Duration `json:"targetTimePerBlock"`
+	RetargetAdjustmentFactor int64         `json:"retargetAdjustmentFactor"`
+
+	// ReduceMinDifficulty allows a block to have the minimum difficulty, PowLimitBits,
+	// if it is mined more than MinDiffReductionTime after the previous one
+	ReduceMinDifficulty  bool          `json:"reduceMinDifficulty"`
+	MinDiffReductionTime time.Duration `json:"minDiffReductionTime"`
+	// NoRetargeting keeps the difficulty of the previous block at retarget heights
+	NoRetargeting bool `json:"noRetargeting"`
+}
+
+// BlocksPerRetarget is the number of blocks between each difficulty retarget, 2016 for Bitcoin
+func (p *PowParams) BlocksPerRetarget() uint32 {
+	return uint32(p.TargetTimespan / p.TargetTimePerBlock)
+}
+func (p *PowParams) MinRetargetTimespan() int64 {
+	return int64(p.TargetTimespan/time.Second) / p.RetargetAdjustmentFactor
+}
+func (p *PowParams) MaxRetargetTimespan() int64 {
+	return int64(p.TargetTimespan/time.Second) * p.RetargetAdjustmentFactor
+}
+
+var (
+	// bigOne is 1 represented as a big.Int.  It is defined here to avoid
+	// the overhead of creating it multiple times.
+	bigOne = big.NewInt(1)
+
+	MainNetPowParams = &PowParams{
+		Network:                  "mainnet",
+		PowLimit:                 new(big.Int).Sub(new(big.Int).Lsh(bigOne, 224), bigOne), // 2^224 - 1
+		PowLimitBits:             0x1d00ffff,
+		TargetTimespan:           time.Hour * 24 * 14,
+		TargetTimePerBlock:       time.Minute * 10,
+		RetargetAdjustmentFactor: 4, // 25% less, 400% more
+	}
+	TestNet3PowParams = &PowParams{
+		Network:                  "testnet3",
+		PowLimit:                 new(big.Int).Sub(new(big.Int).Lsh(bigOne, 224), bigOne), // 2^224 - 1
+		PowLimitBits:             0x1d00ffff,
+		TargetTimespan:           time.Hour * 24 * 14,
+		TargetTimePerBlock:       time.Minute * 10,
+		RetargetAdjustmentFactor: 4,
+		ReduceMinDifficulty:      true,
+		MinDiffReductionTime:     time.Minute * 20,
+	}
+	RegTestPowParams = &PowParams{
+		Network:                  "regtest",
+		PowLimit:                 new(big.Int).Sub(new(big.Int).Lsh(bigOne, 255), bigOne), // 2^255 - 1
+		PowLimitBits:             0x207fffff,
+		TargetTimespan:           time.Hour * 24 * 14,
+		TargetTimePerBlock:       time.Minute * 10,
+		RetargetAdjustmentFactor: 4,
+		ReduceMinDifficulty:      true,
+		MinDiffReductionTime:     time.Minute * 20,
+		NoRetargeting:            true,
+	}
+
+	// DefaultPowParams are used by the header chain until the administrator selects another network
+	DefaultPowParams   = MainNetPowParams
+	PowParamsByNetwork = map[string]*PowParams{
+		MainNetPowParams.Network:  MainNetPowParams,
+		TestNet3PowParams.Network: TestNet3PowParams,
+		RegTestPowParams.Network:  RegTestPowParams,
+	}
+)
@@ -66,6 +66,24 @@ const (
 			{"name":"metadata","type":"string"}
 		]},
 
+		{"type":"function","name":"SetTokenPairLimits","inputs":[
+			{"name":"networkClass","type":"uint32"},
+			{"name":"chainId","type":"uint32"},
+			{"name":"tokenStandard","type":"tokenStandard"},
+			{"name":"windowSize","type":"uint64"},
+			{"name":"maxWrapAmount","type":"uint256"},
+			{"name":"maxRedeemAmount","type":"uint256"}
+		]},
+
+		{"type":"function","name":"SetFeeDistribution","inputs":[
+			{"name":"destinations","type":"address[]"},
+			{"name":"percentages","type":"uint32[]"}
+		]},
+
+		{"type":"function","name":"DistributeFees","inputs":[
+			{"name":"tokenStandard","type":"tokenStandard"}
+		]},
+
 		{"type":"function","name":"RemoveTokenPair","inputs":[
 			{"name":"networkClass","type":"uint32"},
 			{"name":"chainId","type":"uint32"},
@@ -117,6 +135,15 @@ const (
 			{"name":"signature","type":"string"}
 		]},
 
+		{"type":"function","name":"UnwrapBitcoinToken","inputs":[
+			{"name":"chainId","type":"uint32"},
+			{"name":"txId","type":"hash"},
+			{"name":"vout","type":"uint32"},
+			{"name":"toAddress","type":"address"},
+			{"name":"amount","type":"uint256"},
+			{"name":"signature","type":"string"}
+		]},
+
 		{"type":"function","name":"RevokeUnwrapRequest","inputs":[
 			{"name":"transactionHash","type":"hash"},
 			{"name":"logIndex","type":"uint32"}
@@ -210,18 +237,45 @@ const (
 
 		{"type":"variable","name":"feeTokenPair","inputs":[
 			{"name":"accumulatedFee","type":"uint256"}
+		]},
+
+		{"type":"variable","name":"tokenPairLimits","inputs":[
+			{"name":"windowSize","type":"uint64"},
+			{"name":"maxWrapAmount","type":"uint256"},
+			{"name":"maxRedeemAmount","type":"uint256"},
+			{"name":"windowStart","type":"uint64"},
+			{"name":"wrapped","type":"uint256"},
+			{"name":"previousWrapped","type":"uint256"},
+			{"name":"redeemed","type":"uint256"},
+			{"name":"previousRedeemed","type":"uint256"}
+		]},
+
+		{"type":"variable","name":"feeDistribution","inputs":[
+			{"name":"destinations","type":"address[]"},
+			{"name":"percentages","type":"uint32[]"}
+		]},
+
+		{"type":"variable","name":"feeDistributionRecord","inputs":[
+			{"name":"tokenStandard","type":"tokenStandard"},
+			{"name":"momentumHeight","type":"uint64"},
+			{"name":"destinations","type":"address[]"},
+			{"name":"amounts","type":"uint256[]"}
 		]}
 	]`
 
 	WrapTokenMethodName            = "WrapToken"
 	UpdateWrapRequestMethodName    = "UpdateWrapRequest"
 	UnwrapTokenMethodName          = "UnwrapToken"
+	UnwrapBitcoinTokenMethodName   = "UnwrapBitcoinToken"
 	RevokeUnwrapRequestMethodName  = "RevokeUnwrapRequest"
 	RedeemUnwrapMethodName         = "Redeem"
 	SetNetworkMethodName           = "SetNetwork"
 	RemoveNetworkMethodName        = "RemoveNetwork"
 	SetTokenPairMethod             = "SetTokenPair"
 	RemoveTokenPairMethodName      = "RemoveTokenPair"
+	SetTokenPairLimitsMethodName   = "SetTokenPairLimits"
+	SetFeeDistributionMethodName   = "SetFeeDistribution"
+	DistributeFeesMethodName       = "DistributeFees"
 	HaltMethodName                 = "Halt"
 	UnhaltMethodName               = "Unhalt"
 	SetAllowKeygenMethodName       = "SetAllowKeyGen"
@@ -240,6 +294,10 @@ const (
 	networkInfoVariableName      = "networkInfo"
 	feeTokenPairVariableName     = "feeTokenPair"
 	tokenPairVariableName        = "tokenPair"
+	tokenPairLimitsVariableName  = "tokenPairLimits"
+
+	feeDistributionVariableName       = "feeDistribution"
+	feeDistributionRecordVariableName = "feeDistributionRecord"
 )
 
 var (
@@ -252,9 +310,16 @@ var (
 	NetworkInfoKeyPrefix        = []byte{5}
 	RequestPairKeyPrefix        = []byte{6}
 	FeeTokenPairKeyPrefix       = []byte{7}
+	TokenPairLimitsKeyPrefix    = []byte{8}
+	FeeDistributionKeyPrefix    = []byte{9}
+	feeDistributionRecordPrefix = []byte{10}
 
 	NoMClass = uint32(1)
 	EvmClass = uint32(2)
+	BtcClass = uint32(3)
+
+	// BtcTokenAddress is the token address of native BTC, the only token of the Bitcoin network class
+	BtcTokenAddress = "btc"
 
 	Uint256Ty, _ = eabi.NewType("uint256", "uint256", nil)
 	AddressTy, _ = eabi.NewType("address", "address", nil)
@@ -499,6 +564,231 @@ func GetZtsFeesInfoVariable(context db.DB, tokenStandard types.ZenonTokenStandar
 	}
 }
 
+// TokenPairLimits caps the volume wrapped and redeemed for a token pair over a rolling window of WindowSize momentums.
+// A zero max amount means no limit. The volumes of the current and previous windows are kept to estimate the rolling volume.
+type TokenPairLimits struct {
+	NetworkClass     uint32                   `json:"networkClass"`
+	ChainId          uint32                   `json:"chainId"`
+	TokenStandard    types.ZenonTokenStandard `json:"tokenStandard"`
+	WindowSize       uint64                   `json:"windowSize"`
+	MaxWrapAmount    *big.Int                 `json:"maxWrapAmount"`
+	MaxRedeemAmount  *big.Int                 `json:"maxRedeemAmount"`
+	WindowStart      uint64                   `json:"windowStart"`
+	Wrapped          *big.Int                 `json:"wrapped"`
+	PreviousWrapped  *big.Int                 `json:"previousWrapped"`
+	Redeemed         *big.Int                 `json:"redeemed"`
+	PreviousRedeemed *big.Int                 `json:"previousRedeemed"`
+}
+
+func (l *TokenPairLimits) Save(context db.DB) error {
+	data, err := ABIBridge.PackVariable(
+		tokenPairLimitsVariableName,
+		l.WindowSize,
+		l.MaxWrapAmount,
+		l.MaxRedeemAmount,
+		l.WindowStart,
+		l.Wrapped,
+		l.PreviousWrapped,
+		l.Redeemed,
+		l.PreviousRedeemed,
+	)
+	if err != nil {
+		return err
+	}
+	return context.Put(l.Key(), data)
+}
+func (l *TokenPairLimits) Key() []byte {
+	return getTokenPairLimitsKey(l.NetworkClass, l.ChainId, l.TokenStandard)
+}
+
+func getTokenPairLimitsKey(networkClass, chainId uint32, zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(TokenPairLimitsKeyPrefix, common.Uint32ToBytes(networkClass), common.Uint32ToBytes(chainId), zts.Bytes())
+}
+
+// GetTokenPairLimits returns the limits of the token pair, with a zero WindowSize if none were set
+func GetTokenPairLimits(context db.DB, networkClass, chainId uint32, zts types.ZenonTokenStandard) (*TokenPairLimits, error) {
+	data, err := context.Get(getTokenPairLimitsKey(networkClass, chainId, zts))
+	if err != nil {
+		return nil, err
+	}
+	limits := new(TokenPairLimits)
+	if len(data) == 0 {
+		limits.MaxWrapAmount = big.NewInt(0)
+		limits.MaxRedeemAmount = big.NewInt(0)
+		limits.Wrapped = big.NewInt(0)
+		limits.PreviousWrapped = big.NewInt(0)
+		limits.Redeemed = big.NewInt(0)
+		limits.PreviousRedeemed = big.NewInt(0)
+	} else if err := ABIBridge.UnpackVariable(limits, tokenPairLimitsVariableName, data); err != nil {
+		return nil, err
+	}
+	limits.NetworkClass = networkClass
+	limits.ChainId = chainId
+	limits.TokenStandard = zts
+	return limits, nil
+}
+
+type TokenPairLimitsParam struct {
+	NetworkClass    uint32
+	ChainId         uint32
+	TokenStandard   types.ZenonTokenStandard
+	WindowSize      uint64
+	MaxWrapAmount   *big.Int
+	MaxRedeemAmount *big.Int
+}
+
+func (p *TokenPairLimitsParam) Hash() []byte {
+	return crypto.Hash(
+		common.Uint32ToBytes(p.NetworkClass),
+		common.Uint32ToBytes(p.ChainId),
+		p.TokenStandard.Bytes(),
+		common.Uint64ToBytes(p.WindowSize),
+		common.BigIntToBytes(p.MaxWrapAmount),
+		common.BigIntToBytes(p.MaxRedeemAmount),
+	)
+}
+
+// FeeDistribution splits the accumulated fees of each token between Destinations,
+// each one receiving Percentages[i] out of constants.FeeDistributionTotalPercentages
+type FeeDistribution struct {
+	Destinations []types.Address `json:"destinations"`
+	Percentages  []uint32        `json:"percentages"`
+}
+
+func (f *FeeDistribution) Save(context db.DB) error {
+	data, err := ABIBridge.PackVariable(feeDistributionVariableName, f.Destinations, f.Percentages)
+	if err != nil {
+		return err
+	}
+	return context.Put(FeeDistributionKeyPrefix, data)
+}
+
+// GetFeeDistribution returns the fee distribution, with no destinations if none was set
+func GetFeeDistribution(context db.DB) (*FeeDistribution, error) {
+	data, err := context.Get(FeeDistributionKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	distribution := new(FeeDistribution)
+	if len(data) == 0 {
+		distribution.Destinations = make([]types.Address, 0)
+		distribution.Percentages = make([]uint32, 0)
+	} else if err := ABIBridge.UnpackVariable(distribution, feeDistributionVariableName, data); err != nil {
+		return nil, err
+	}
+	return distribution, nil
+}
+
+type FeeDistributionParam struct {
+	Destinations []types.Address
+	Percentages  []uint32
+}
+
+func (p *FeeDistributionParam) Hash() []byte {
+	args := make([][]byte, 0, 2*len(p.Destinations))
+	for i := range p.Destinations {
+		args = append(args, p.Destinations[i].Bytes())
+	}
+	for i := range p.Percentages {
+		args = append(args, common.Uint32ToBytes(p.Percentages[i]))
+	}
+	return crypto.Hash(args...)
+}
+
+// FeeDistributionRecord is the history entry of one DistributeFees call, Amounts[i] being sent to Destinations[i]
+type FeeDistributionRecord struct {
+	Id             types.Hash               `json:"id"`
+	TokenStandard  types.ZenonTokenStandard `json:"tokenStandard"`
+	MomentumHeight uint64                   `json:"momentumHeight"`
+	Destinations   []types.Address          `json:"destinations"`
+	Amounts        []*big.Int               `json:"amounts"`
+}
+
+type FeeDistributionRecordMarshal struct {
+	Id             types.Hash               `json:"id"`
+	TokenStandard  types.ZenonTokenStandard `json:"tokenStandard"`
+	MomentumHeight uint64                   `json:"momentumHeight"`
+	Destinations   []types.Address          `json:"destinations"`
+	Amounts        []string                 `json:"amounts"`
+}
+
+func (r *FeeDistributionRecord) MarshalJSON() ([]byte, error) {
+	aux := &FeeDistributionRecordMarshal{
+		Id:             r.Id,
+		TokenStandard:  r.TokenStandard,
+		MomentumHeight: r.MomentumHeight,
+		Destinations:   r.Destinations,
+		Amounts:        make([]string, len(r.Amounts)),
+	}
+	for i := range r.Amounts {
+		aux.Amounts[i] = r.Amounts[i].String()
+	}
+	return json.Marshal(aux)
+}
+
+func (r *FeeDistributionRecord) UnmarshalJSON(data []byte) error {
+	aux := new(FeeDistributionRecordMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	r.Id = aux.Id
+	r.TokenStandard = aux.TokenStandard
+	r.MomentumHeight = aux.MomentumHeight
+	r.Destinations = aux.Destinations
+	r.Amounts = make([]*big.Int, len(aux.Amounts))
+	for i := range aux.Amounts {
+		r.Amounts[i] = common.StringToBigInt(aux.Amounts[i])
+	}
+	return nil
+}
+
+func (r *FeeDistributionRecord) Save(context db.DB) error {
+	data, err := ABIBridge.PackVariable(feeDistributionRecordVariableName, r.TokenStandard, r.MomentumHeight, r.Destinations, r.Amounts)
+	if err != nil {
+		return err
+	}
+	return context.Put(r.Key(), data)
+}
+
+// Key orders the records from the newest to the oldest
+func (r *FeeDistributionRecord) Key() []byte {
+	return common.JoinBytes(feeDistributionRecordPrefix, common.Uint64ToBytes(math.MaxUint64-r.MomentumHeight), r.Id.Bytes())
+}
+
+func parseFeeDistributionRecord(data, key []byte) (*FeeDistributionRecord, error) {
+	record := new(FeeDistributionRecord)
+	if err := ABIBridge.UnpackVariable(record, feeDistributionRecordVariableName, data); err != nil {
+		return nil, err
+	}
+	if err := record.Id.SetBytes(key[1+8:]); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetFeeDistributionRecords returns the fee distribution history, the most recent first
+func GetFeeDistributionRecords(context db.DB) ([]*FeeDistributionRecord, error) {
+	iterator := context.NewIterator(feeDistributionRecordPrefix)
+	defer iterator.Release()
+	list := make([]*FeeDistributionRecord, 0)
+
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, iterator.Error()
+			}
+			break
+		}
+		record, err := parseFeeDistributionRecord(iterator.Value(), iterator.Key())
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, record)
+	}
+
+	return list, nil
+}
+
 func GetNetworkInfoKey(networkClass uint32, chainId uint32) []byte {
 	networkIdBytes := make([]byte, 4)
 	binary.BigEndian.PutUint32(networkIdBytes, networkClass)
@@ -733,6 +1023,16 @@ func parseWrapTokenRequest(data, key []byte) (*WrapTokenRequest, error) {
 	}
 }
 
+// IsWrapTokenRequestKey reports whether key is the storage key of a wrap token request
+func IsWrapTokenRequestKey(key []byte) bool {
+	return len(key) > 20 && key[0] == wrapTokenRequestKeyPrefix[0]
+}
+
+// ParseWrapTokenRequest decodes a wrap token request from its storage key and value
+func ParseWrapTokenRequest(data, key []byte) (*WrapTokenRequest, error) {
+	return parseWrapTokenRequest(data, key)
+}
+
 func GetWrapTokenRequestById(context db.DB, Id types.Hash) (*WrapTokenRequest, error) {
 	pair, err := GetRequestPairById(context, Id)
 	if err != nil {
@@ -881,6 +1181,16 @@ func parseUnwrapTokenRequest(data, key []byte) (*UnwrapTokenRequest, error) {
 	}
 }
 
+// IsUnwrapTokenRequestKey reports whether key is the storage key of an unwrap token request
+func IsUnwrapTokenRequestKey(key []byte) bool {
+	return len(key) == len(unwrapTokenRequestKeyPrefix)+types.HashSize+4 && key[0] == unwrapTokenRequestKeyPrefix[0]
+}
+
+// ParseUnwrapTokenRequest decodes an unwrap token request from its storage key and value
+func ParseUnwrapTokenRequest(data, key []byte) (*UnwrapTokenRequest, error) {
+	return parseUnwrapTokenRequest(data, key)
+}
+
 func GetUnwrapTokenRequestByTxHashAndLog(context db.DB, txHash types.Hash, logIndex uint32) (*UnwrapTokenRequest, error) {
 	key := getUnwrapTokenRequestKey(txHash, logIndex)
 	if data, err := context.Get(key); err != nil {
@@ -1064,6 +1374,17 @@ type UnwrapTokenParam struct {
 	Signature       string
 }
 
+// UnwrapBitcoinTokenParam identifies the deposit by the txid and vout of the Bitcoin output.
+// The request is saved as an UnwrapTokenRequest with TransactionHash as the txid and LogIndex as the vout.
+type UnwrapBitcoinTokenParam struct {
+	ChainId   uint32
+	TxId      types.Hash
+	Vout      uint32
+	ToAddress types.Address
+	Amount    *big.Int
+	Signature string
+}
+
 type RevokeUnwrapParam struct {
 	TransactionHash types.Hash
 	LogIndex        uint32
@@ -1,6 +1,7 @@
 package definition
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -73,11 +74,24 @@ const (
 const (
 	HashTypeSHA3 uint8 = iota
 	HashTypeSHA256
+	// HashTypeHASH160 is RIPEMD160(SHA256(preimage)), used by Bitcoin scripts
+	HashTypeHASH160
+	// HashTypeBLAKE2b is the 32 bytes BLAKE2b digest of the preimage
+	HashTypeBLAKE2b
 )
 
 var HashTypeDigestSizes = map[uint8]uint8{
-	HashTypeSHA3:   32,
-	HashTypeSHA256: 32,
+	HashTypeSHA3:    32,
+	HashTypeSHA256:  32,
+	HashTypeHASH160: 20,
+	HashTypeBLAKE2b: 32,
+}
+
+var HashTypeNames = map[uint8]string{
+	HashTypeSHA3:    "SHA3",
+	HashTypeSHA256:  "SHA256",
+	HashTypeHASH160: "HASH160",
+	HashTypeBLAKE2b: "BLAKE2b",
 }
 
 var (
@@ -173,6 +187,17 @@ func parseHtlcInfo(key, data []byte) (*HtlcInfo, error) {
 		return nil, constants.ErrDataNonExistent
 	}
 }
+
+// IsHtlcInfoKey reports whether key is the storage key of an htlc
+func IsHtlcInfoKey(key []byte) bool {
+	return len(key) == len(htlcInfoKeyPrefix)+types.HashSize && isHtlcInfoKey(key)
+}
+
+// ParseHtlcInfo decodes an htlc from its storage key and value
+func ParseHtlcInfo(key, data []byte) (*HtlcInfo, error) {
+	return parseHtlcInfo(key, data)
+}
+
 func GetHtlcInfo(context db.DB, id types.Hash) (*HtlcInfo, error) {
 	key := getHtlcInfoKey(id)
 	if data, err := context.Get(key); err != nil {
@@ -181,6 +206,29 @@ func GetHtlcInfo(context db.DB, id types.Hash) (*HtlcInfo, error) {
 		return parseHtlcInfo(key, data)
 	}
 }
+func GetHtlcInfos(context db.DB) ([]*HtlcInfo, error) {
+	iterator := context.NewIterator(htlcInfoKeyPrefix)
+	defer iterator.Release()
+	list := make([]*HtlcInfo, 0)
+
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, iterator.Error()
+			}
+			break
+		}
+		// the hash lock is unpacked in place, so the value is copied before the iterator reuses it
+		if info, err := parseHtlcInfo(iterator.Key(), bytes.Clone(iterator.Value())); err == nil {
+			list = append(list, info)
+		} else if err == constants.ErrDataNonExistent {
+		} else {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
 
 type HtlcInfoMarshal struct {
 	Id             types.Hash               `json:"id"`
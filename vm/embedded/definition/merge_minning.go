@@ -1,8 +1,11 @@
 package definition
 
 import (
+	"encoding/binary"
+	"encoding/json"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/pkg/errors"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
@@ -48,13 +51,24 @@ const (
 			{"name":"merkleRoot","type":"hash"},
 			{"name":"timestamp","type":"uint32"},
 			{"name":"nonce","type":"uint32"},
-			{"name":"proof","type":"hash"},
-			{"name":"prooff","type":"hash"},
-			{"name":"proofff","type":"hash"},
-			{"name":"prooffff","type":"hash"},
-			{"name":"additionalData","type":"hash"}
+			{"name":"coinbase","type":"bytes"},
+			{"name":"merkleBranch","type":"hash[]"}
 		]},
 
+		{"type":"function","name":"SetPowNetwork","inputs":[
+			{"name":"network","type":"string"}
+		]},
+
+		{"type":"function","name":"VerifyTransaction", "inputs":[
+			{"name":"transaction","type":"bytes"},
+			{"name":"merkleBranch","type":"hash[]"},
+			{"name":"index","type":"uint32"},
+			{"name":"blockHash","type":"hash"}
+		]},
+
+		{"type":"function","name":"CollectReward","inputs":[]},
+		{"type":"function","name":"Update","inputs":[]},
+
 		{"type":"function","name":"Emergency","inputs":[]},
 		{"type":"function","name":"NominateGuardians","inputs":[
 			{"name":"guardians","type":"address[]"}
@@ -92,6 +106,27 @@ const (
 			{"name":"rewardMultiplier","type":"uint32"}
 		]},
 
+		{"type":"variable","name":"sharesInfo","inputs":[
+			{"name":"shareCount","type":"uint64"},
+			{"name":"weightedWork","type":"uint256"}
+		]},
+
+		{"type":"variable","name":"powNetwork","inputs":[
+			{"name":"network","type":"string"}
+		]},
+
+		{"type":"variable","name":"unpaidReward","inputs":[
+			{"name":"znn","type":"uint256"},
+			{"name":"qsr","type":"uint256"}
+		]},
+
+		{"type":"variable","name":"verifiedTransaction","inputs":[
+			{"name":"blockHash","type":"hash"},
+			{"name":"blockHeight","type":"uint32"},
+			{"name":"index","type":"uint32"},
+			{"name":"momentumHeight","type":"uint64"}
+		]},
+
 		{"type":"variable","name":"blockHeader","inputs":[
 			{"name":"version","type":"int32"},
 			{"name":"prevBlock","type":"hash"},
@@ -101,21 +136,6 @@ const (
 			{"name":"nonce","type":"uint32"},
 			{"name":"height","type":"uint32"},
 			{"name":"workSum","type":"uint256"}
-		]},
-
-		{"type":"variable","name":"share","inputs":[
-			{"name":"shareChainId","type":"uint8"},
-			{"name":"witness","type":"bool"},
-			{"name":"version","type":"int32"},
-			{"name":"prevBlock","type":"hash"},
-			{"name":"merkleRoot","type":"hash"},
-			{"name":"timestamp","type":"uint32"},
-			{"name":"nonce","type":"uint32"},
-			{"name":"proof","type":"hash"},
-			{"name":"prooff","type":"hash"},
-			{"name":"proofff","type":"hash"},
-			{"name":"prooffff","type":"hash"},
-			{"name":"additionalData","type":"hash"}
 		]}
 	]`
 
@@ -124,12 +144,17 @@ const (
 	SetMergeMiningMetadataMethodName       = "SetMergeMiningMetadata"
 	SetShareChainMethodName                = "SetShareChain"
 	AddShareMethodName                     = "AddShare"
-
-	mergeMiningInfoVariableName = "mergeMiningInfo"
-	headerChainInfoVariableName = "headerChainInfo"
-	shareChainInfoVariableName  = "shareChainInfo"
-	blockHeaderVariableName     = "blockHeader"
-	shareVariableName           = "share"
+	VerifyTransactionMethodName            = "VerifyTransaction"
+	SetPowNetworkMethodName                = "SetPowNetwork"
+
+	mergeMiningInfoVariableName     = "mergeMiningInfo"
+	headerChainInfoVariableName     = "headerChainInfo"
+	shareChainInfoVariableName      = "shareChainInfo"
+	sharesInfoVariableName          = "sharesInfo"
+	verifiedTransactionVariableName = "verifiedTransaction"
+	powNetworkVariableName          = "powNetwork"
+	unpaidRewardVariableName        = "unpaidReward"
+	blockHeaderVariableName         = "blockHeader"
 )
 
 var (
@@ -140,21 +165,206 @@ var (
 	ShareChainInfoPrefix  = []byte{3}
 	ShareKeyPrefix        = []byte{4}
 	BlockHeaderKeyPrefix  = []byte{5}
+	ShareHashKeyPrefix    = []byte{6}
+	MainChainKeyPrefix    = []byte{7}
+	HeaderHeightKeyPrefix = []byte{8}
+	VerifiedTxKeyPrefix   = []byte{9}
+	PowNetworkKeyPrefix   = []byte{10}
+	UnpaidRewardKeyPrefix = []byte{11}
+	// ShareAddressKeyPrefix indexes the epochs with shares of each address
+	ShareAddressKeyPrefix = []byte{12}
+
+	// ShareCommitmentMagic marks the start of the merge mining commitment in the coinbase script, "znnm"
+	ShareCommitmentMagic = []byte{0x7a, 0x6e, 0x6e, 0x6d}
 )
 
 type Share struct {
-	ShareChainId   uint8      `json:"shareChainId"`
-	Witness        bool       `json:"witness"`
-	Version        int32      `json:"version"`
-	PrevBlock      types.Hash `json:"prevBlock"`
-	MerkleRoot     types.Hash `json:"merkleRoot"`
-	Timestamp      uint32     `json:"timestamp"`
-	Nonce          uint32     `json:"nonce"`
-	Proof          types.Hash `json:"proof"`
-	Prooff         types.Hash `json:"prooff"`
-	Proofff        types.Hash `json:"proofff"`
-	Prooffff       types.Hash `json:"prooffff"`
-	AdditionalData types.Hash `json:"additionalData"`
+	ShareChainId uint8      `json:"shareChainId"`
+	Version      int32      `json:"version"`
+	PrevBlock    types.Hash `json:"prevBlock"`
+	MerkleRoot   types.Hash `json:"merkleRoot"`
+	Timestamp    uint32     `json:"timestamp"`
+	Nonce        uint32     `json:"nonce"`
+	// Serialized Bitcoin coinbase transaction of the share
+	Coinbase []byte `json:"coinbase"`
+	// Merkle branch from the coinbase transaction to the merkle root, leaf level first
+	MerkleBranch []types.Hash `json:"merkleBranch"`
+}
+
+// GetShareCommitment returns the bytes a miner has to include in the coinbase script
+// so that its shares for shareChainId are accounted to address
+func GetShareCommitment(shareChainId uint8, address types.Address) []byte {
+	return common.JoinBytes(ShareCommitmentMagic, []byte{shareChainId}, address.Bytes())
+}
+
+// SharesInfo aggregates the shares accepted for an address during an epoch
+type SharesInfo struct {
+	Address    types.Address `json:"address"`
+	Epoch      uint64        `json:"epoch"`
+	ShareCount uint64        `json:"shareCount"`
+	// Sum of the work of each share weighted by the reward multiplier of its share chain
+	WeightedWork *big.Int `json:"weightedWork"`
+}
+
+type SharesInfoMarshal struct {
+	Address      types.Address `json:"address"`
+	Epoch        uint64        `json:"epoch"`
+	ShareCount   uint64        `json:"shareCount"`
+	WeightedWork string        `json:"weightedWork"`
+}
+
+func (s *SharesInfo) ToSharesInfoMarshal() *SharesInfoMarshal {
+	return &SharesInfoMarshal{
+		Address:      s.Address,
+		Epoch:        s.Epoch,
+		ShareCount:   s.ShareCount,
+		WeightedWork: s.WeightedWork.String(),
+	}
+}
+
+func (s *SharesInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.ToSharesInfoMarshal())
+}
+
+func (s *SharesInfo) UnmarshalJSON(data []byte) error {
+	aux := new(SharesInfoMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	s.Address = aux.Address
+	s.Epoch = aux.Epoch
+	s.ShareCount = aux.ShareCount
+	s.WeightedWork = common.StringToBigInt(aux.WeightedWork)
+	return nil
+}
+
+func (s *SharesInfo) Save(context db.DB) error {
+	data, err := ABIMergeMining.PackVariable(
+		sharesInfoVariableName,
+		s.ShareCount,
+		s.WeightedWork,
+	)
+	if err != nil {
+		return err
+	}
+	if err := context.Put(getSharesInfoAddressKey(s.Address, s.Epoch), []byte{1}); err != nil {
+		return err
+	}
+	return context.Put(
+		getSharesInfoKey(s.Epoch, s.Address),
+		data,
+	)
+}
+
+func getSharesInfoKey(epoch uint64, address types.Address) []byte {
+	return common.JoinBytes(ShareKeyPrefix, common.Uint64ToBytes(epoch), address.Bytes())
+}
+func getSharesInfoEpochPrefix(epoch uint64) []byte {
+	return common.JoinBytes(ShareKeyPrefix, common.Uint64ToBytes(epoch))
+}
+func getSharesInfoAddressKey(address types.Address, epoch uint64) []byte {
+	return common.JoinBytes(ShareAddressKeyPrefix, address.Bytes(), common.Uint64ToBytes(epoch))
+}
+func parseSharesInfo(key []byte, data []byte) (*SharesInfo, error) {
+	if len(data) > 0 {
+		sharesInfo := new(SharesInfo)
+		if err := ABIMergeMining.UnpackVariable(sharesInfo, sharesInfoVariableName, data); err != nil {
+			return nil, err
+		}
+		if len(key) != 1+8+types.AddressSize {
+			return nil, errors.Errorf("invalid key! Not shares info key")
+		}
+		sharesInfo.Epoch = common.BytesToUint64(key[1:9])
+		if err := sharesInfo.Address.SetBytes(key[9:]); err != nil {
+			return nil, err
+		}
+		return sharesInfo, nil
+	} else {
+		return nil, constants.ErrDataNonExistent
+	}
+}
+
+// GetSharesInfo returns the shares of address in epoch, or an empty entry if there are none
+func GetSharesInfo(context db.DB, epoch uint64, address types.Address) (*SharesInfo, error) {
+	key := getSharesInfoKey(epoch, address)
+	if data, err := context.Get(key); err != nil {
+		return nil, err
+	} else {
+		sharesInfo, err := parseSharesInfo(key, data)
+		if err == constants.ErrDataNonExistent {
+			return &SharesInfo{
+				Address:      address,
+				Epoch:        epoch,
+				ShareCount:   0,
+				WeightedWork: big.NewInt(0),
+			}, nil
+		}
+		return sharesInfo, err
+	}
+}
+
+func iterateSharesInfo(context db.DB, prefix []byte, f func(*SharesInfo) error) error {
+	iterator := context.NewIterator(prefix)
+	defer iterator.Release()
+
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return iterator.Error()
+			}
+			break
+		}
+
+		if sharesInfo, err := parseSharesInfo(iterator.Key(), iterator.Value()); err == nil {
+			if err := f(sharesInfo); err != nil {
+				return err
+			}
+		} else if err == constants.ErrDataNonExistent {
+		} else {
+			return err
+		}
+	}
+	return nil
+}
+
+// IterateSharesInfoByEpoch iterates the shares of all addresses for an epoch
+func IterateSharesInfoByEpoch(context db.DB, epoch uint64, f func(*SharesInfo) error) error {
+	return iterateSharesInfo(context, getSharesInfoEpochPrefix(epoch), f)
+}
+
+// GetSharesInfoListByAddress returns the shares of an address for all epochs, ordered by epoch
+func GetSharesInfoListByAddress(context db.DB, address types.Address) ([]*SharesInfo, error) {
+	prefix := common.JoinBytes(ShareAddressKeyPrefix, address.Bytes())
+	iterator := context.NewIterator(prefix)
+	defer iterator.Release()
+
+	list := make([]*SharesInfo, 0)
+	for iterator.Next() {
+		if len(iterator.Key()) != len(prefix)+8 {
+			return nil, errors.Errorf("invalid key! Not shares address key")
+		}
+		sharesInfo, err := GetSharesInfo(context, common.BytesToUint64(iterator.Key()[len(prefix):]), address)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, sharesInfo)
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SaveShareHash marks a share as accounted so that it can't be submitted again
+func SaveShareHash(context db.DB, hash types.Hash) error {
+	return context.Put(common.JoinBytes(ShareHashKeyPrefix, hash.Bytes()), []byte{1})
+}
+func HasShareHash(context db.DB, hash types.Hash) (bool, error) {
+	if data, err := context.Get(common.JoinBytes(ShareHashKeyPrefix, hash.Bytes())); err != nil {
+		return false, err
+	} else {
+		return len(data) > 0, nil
+	}
 }
 
 type MergeMiningInfoVariable struct {
@@ -425,3 +635,217 @@ func GetBlockHeaderVariable(context db.DB, hash types.Hash) (*BlockHeaderVariabl
 		return block, nil
 	}
 }
+
+func getMainChainKey(height uint32) []byte {
+	return common.JoinBytes(MainChainKeyPrefix, common.Uint32ToBytes(height))
+}
+
+// SetMainChainHash stores hash as the block header of the best chain at height
+func SetMainChainHash(context db.DB, height uint32, hash types.Hash) error {
+	return context.Put(getMainChainKey(height), hash.Bytes())
+}
+func DeleteMainChainHash(context db.DB, height uint32) error {
+	return context.Delete(getMainChainKey(height))
+}
+
+// GetMainChainHash returns the hash of the block header of the best chain at height
+func GetMainChainHash(context db.DB, height uint32) (*types.Hash, error) {
+	if data, err := context.Get(getMainChainKey(height)); err != nil {
+		return nil, err
+	} else if len(data) == 0 {
+		return nil, constants.ErrDataNonExistent
+	} else {
+		hash := new(types.Hash)
+		if err := hash.SetBytes(data); err != nil {
+			return nil, err
+		}
+		return hash, nil
+	}
+}
+
+// GetMainChainHeaderByHeight returns the block header of the best chain at height
+func GetMainChainHeaderByHeight(context db.DB, height uint32) (*BlockHeaderVariable, error) {
+	hash, err := GetMainChainHash(context, height)
+	if err != nil {
+		return nil, err
+	}
+	return GetBlockHeaderVariable(context, *hash)
+}
+
+// GetMainChainFirstHeight returns the height of the oldest block header of the best chain
+func GetMainChainFirstHeight(context db.DB) (uint32, error) {
+	iterator := context.NewIterator(MainChainKeyPrefix)
+	defer iterator.Release()
+
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return 0, iterator.Error()
+			}
+			return 0, constants.ErrDataNonExistent
+		}
+		if len(iterator.Value()) != 0 {
+			return binary.BigEndian.Uint32(iterator.Key()[1:]), nil
+		}
+	}
+}
+
+func getHeaderHeightKey(height uint32, hash types.Hash) []byte {
+	return common.JoinBytes(HeaderHeightKeyPrefix, common.Uint32ToBytes(height), hash.Bytes())
+}
+
+// SaveHeaderHeight indexes a block header by its height, regardless of the chain it is on
+func SaveHeaderHeight(context db.DB, height uint32, hash types.Hash) error {
+	return context.Put(getHeaderHeightKey(height, hash), []byte{1})
+}
+func DeleteHeaderHeight(context db.DB, height uint32, hash types.Hash) error {
+	return context.Delete(getHeaderHeightKey(height, hash))
+}
+
+// GetHeaderHashesByHeight returns the hashes of all the stored block headers at height
+func GetHeaderHashesByHeight(context db.DB, height uint32) ([]types.Hash, error) {
+	iterator := context.NewIterator(common.JoinBytes(HeaderHeightKeyPrefix, common.Uint32ToBytes(height)))
+	defer iterator.Release()
+
+	hashes := make([]types.Hash, 0)
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, iterator.Error()
+			}
+			break
+		}
+		if len(iterator.Value()) == 0 {
+			continue
+		}
+		hash := new(types.Hash)
+		if err := hash.SetBytes(iterator.Key()[5:]); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, *hash)
+	}
+	return hashes, nil
+}
+
+func (b *BlockHeaderVariable) Delete(context db.DB) error {
+	return context.Delete(GetBlockHeaderKey(b.Hash))
+}
+
+// TransactionProof is the proof that a Bitcoin transaction is included in a block
+type TransactionProof struct {
+	// Serialized Bitcoin transaction
+	Transaction []byte `json:"transaction"`
+	// Merkle branch from the transaction to the merkle root, leaf level first
+	MerkleBranch []types.Hash `json:"merkleBranch"`
+	// Position of the transaction in the block
+	Index     uint32     `json:"index"`
+	BlockHash types.Hash `json:"blockHash"`
+}
+
+// VerifiedTransaction records a Bitcoin transaction whose inclusion in the header chain was verified
+type VerifiedTransaction struct {
+	TxId           types.Hash `json:"txId"`
+	BlockHash      types.Hash `json:"blockHash"`
+	BlockHeight    uint32     `json:"blockHeight"`
+	Index          uint32     `json:"index"`
+	MomentumHeight uint64     `json:"momentumHeight"`
+}
+
+func (v *VerifiedTransaction) Save(context db.DB) error {
+	data, err := ABIMergeMining.PackVariable(
+		verifiedTransactionVariableName,
+		v.BlockHash,
+		v.BlockHeight,
+		v.Index,
+		v.MomentumHeight,
+	)
+	if err != nil {
+		return err
+	}
+	return context.Put(getVerifiedTransactionKey(v.TxId), data)
+}
+
+func getVerifiedTransactionKey(txId types.Hash) []byte {
+	return common.JoinBytes(VerifiedTxKeyPrefix, txId.Bytes())
+}
+func parseVerifiedTransaction(data []byte) (*VerifiedTransaction, error) {
+	if len(data) > 0 {
+		verified := new(VerifiedTransaction)
+		if err := ABIMergeMining.UnpackVariable(verified, verifiedTransactionVariableName, data); err != nil {
+			return nil, err
+		}
+		return verified, nil
+	} else {
+		return nil, constants.ErrDataNonExistent
+	}
+}
+
+// GetVerifiedTransaction returns the verification record of the Bitcoin transaction with txId
+func GetVerifiedTransaction(context db.DB, txId types.Hash) (*VerifiedTransaction, error) {
+	if data, err := context.Get(getVerifiedTransactionKey(txId)); err != nil {
+		return nil, err
+	} else {
+		verified, err := parseVerifiedTransaction(data)
+		if err != nil {
+			return nil, err
+		}
+		verified.TxId = txId
+		return verified, nil
+	}
+}
+
+// SavePowNetwork stores the Bitcoin network whose proof of work rules the header chain follows
+func SavePowNetwork(context db.DB, network string) error {
+	data, err := ABIMergeMining.PackVariable(powNetworkVariableName, network)
+	if err != nil {
+		return err
+	}
+	return context.Put(PowNetworkKeyPrefix, data)
+}
+
+// GetPowNetwork returns the Bitcoin network selected for the header chain, empty if none was selected
+func GetPowNetwork(context db.DB) (string, error) {
+	data, err := context.Get(PowNetworkKeyPrefix)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", nil
+	}
+	network := new(string)
+	if err := ABIMergeMining.UnpackVariable(network, powNetworkVariableName, data); err != nil {
+		return "", err
+	}
+	return *network, nil
+}
+
+// SaveUnpaidReward stores the merge mining reward of the epochs without shares, which is paid with the next epoch with shares
+func SaveUnpaidReward(context db.DB, znn, qsr *big.Int) error {
+	if znn.Sign() == 0 && qsr.Sign() == 0 {
+		return context.Delete(UnpaidRewardKeyPrefix)
+	}
+	data, err := ABIMergeMining.PackVariable(unpaidRewardVariableName, znn, qsr)
+	if err != nil {
+		return err
+	}
+	return context.Put(UnpaidRewardKeyPrefix, data)
+}
+
+// GetUnpaidReward returns the merge mining reward which wasn't paid yet, zero if there is none
+func GetUnpaidReward(context db.DB) (*big.Int, *big.Int, error) {
+	data, err := context.Get(UnpaidRewardKeyPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) == 0 {
+		return big.NewInt(0), big.NewInt(0), nil
+	}
+	reward := new(struct {
+		Znn *big.Int
+		Qsr *big.Int
+	})
+	if err := ABIMergeMining.UnpackVariable(reward, unpaidRewardVariableName, data); err != nil {
+		return nil, nil, err
+	}
+	return reward.Znn, reward.Qsr, nil
+}
@@ -37,8 +37,35 @@ var (
 	htlcEmbedded               = getHtlc()
 	bridgeAndLiquidityEmbedded = getBridgeAndLiquidity()
 	mergeMiningEmbedded        = getMergeMining()
+	htlcHashTypesEmbedded      = getHtlcHashTypes()
+	bridgeExtensionsEmbedded   = getBridgeExtensions()
 )
 
+// getBridgeExtensions returns the bridge contract with the methods enabled by the bridge extensions spork.
+// Only the bridge contract is replaced, so the spork doesn't depend on the activation order of other features.
+func getBridgeExtensions() *embeddedImplementation {
+	contracts := getBridgeAndLiquidity()
+	contracts[types.BridgeContract].m[cabi.WrapTokenMethodName] = &implementation.WrapTokenMethod{cabi.WrapTokenMethodName, true}
+	contracts[types.BridgeContract].m[cabi.UpdateWrapRequestMethodName] = &implementation.UpdateWrapRequestMethod{cabi.UpdateWrapRequestMethodName, true}
+	contracts[types.BridgeContract].m[cabi.UnwrapTokenMethodName] = &implementation.UnwrapTokenMethod{cabi.UnwrapTokenMethodName, true}
+	contracts[types.BridgeContract].m[cabi.UnwrapBitcoinTokenMethodName] = &implementation.UnwrapBitcoinTokenMethod{cabi.UnwrapBitcoinTokenMethodName}
+	contracts[types.BridgeContract].m[cabi.SetNetworkMethodName] = &implementation.SetNetworkMethod{cabi.SetNetworkMethodName, true}
+	contracts[types.BridgeContract].m[cabi.SetTokenPairMethod] = &implementation.SetTokenPairMethod{cabi.SetTokenPairMethod, true}
+	contracts[types.BridgeContract].m[cabi.RemoveTokenPairMethodName] = &implementation.RemoveTokenPairMethod{cabi.RemoveTokenPairMethodName, true}
+	contracts[types.BridgeContract].m[cabi.RedeemUnwrapMethodName] = &implementation.RedeemMethod{cabi.RedeemUnwrapMethodName, true}
+	contracts[types.BridgeContract].m[cabi.SetTokenPairLimitsMethodName] = &implementation.SetTokenPairLimitsMethod{cabi.SetTokenPairLimitsMethodName}
+	contracts[types.BridgeContract].m[cabi.SetFeeDistributionMethodName] = &implementation.SetFeeDistributionMethod{cabi.SetFeeDistributionMethodName}
+	contracts[types.BridgeContract].m[cabi.DistributeFeesMethodName] = &implementation.DistributeFeesMethod{cabi.DistributeFeesMethodName}
+	return contracts[types.BridgeContract]
+}
+
+// getHtlcHashTypes returns the htlc contract with the hash types enabled by the htlc hash types spork.
+func getHtlcHashTypes() *embeddedImplementation {
+	contracts := getHtlc()
+	contracts[types.HtlcContract].m[cabi.CreateHtlcMethodName] = &implementation.CreateHtlcMethod{cabi.CreateHtlcMethodName, implementation.HtlcExtendedHashTypes}
+	return contracts[types.HtlcContract]
+}
+
 func getMergeMining() map[types.Address]*embeddedImplementation {
 	contracts := getHtlc()
 	contracts[types.MergeMiningContract] = &embeddedImplementation{
@@ -46,6 +73,7 @@ func getMergeMining() map[types.Address]*embeddedImplementation {
 			cabi.AddBitcoinBlockHeaderMethodName:        &implementation.AddBitcoinBlockHeaderMethod{cabi.AddBitcoinBlockHeaderMethodName},
 			cabi.SetInitialBitcoinBlockHeaderMethodName: &implementation.SetInitialBitcoinBlockMethod{cabi.SetInitialBitcoinBlockHeaderMethodName},
 			cabi.SetShareChainMethodName:                &implementation.SetShareChainMethod{cabi.SetShareChainMethodName},
+			cabi.SetPowNetworkMethodName:                &implementation.SetPowNetworkMethod{cabi.SetPowNetworkMethodName},
 			cabi.NominateGuardiansMethodName:            &implementation.NominateGuardiansMergeMiningMethod{cabi.NominateGuardiansMethodName},
 			cabi.ProposeAdministratorMethodName:         &implementation.ProposeAdministratorMergeMiningMethod{cabi.ProposeAdministratorMethodName},
 			cabi.EmergencyMethodName:                    &implementation.EmergencyMergeMiningMethod{cabi.EmergencyMethodName},
@@ -53,6 +81,9 @@ func getMergeMining() map[types.Address]*embeddedImplementation {
 			cabi.ChangeAdministratorMethodName:          &implementation.ChangeAdministratorMergeMiningMethod{cabi.ChangeAdministratorMethodName},
 			cabi.SetMergeMiningMetadataMethodName:       &implementation.SetMergeMiningMetadataMethod{cabi.SetMergeMiningMetadataMethodName},
 			cabi.AddShareMethodName:                     &implementation.AddShareMethod{cabi.AddShareMethodName},
+			cabi.VerifyTransactionMethodName:            &implementation.VerifyTransactionMethod{cabi.VerifyTransactionMethodName},
+			cabi.UpdateMethodName:                       &implementation.UpdateEmbeddedMergeMiningMethod{cabi.UpdateMethodName},
+			cabi.CollectRewardMethodName:                &implementation.CollectRewardMethod{cabi.CollectRewardMethodName, constants.AlphanetPlasmaTable.EmbeddedSimple + constants.AlphanetPlasmaTable.EmbeddedWWithdraw},
 		},
 		cabi.ABIMergeMining,
 	}
@@ -64,7 +95,7 @@ func getHtlc() map[types.Address]*embeddedImplementation {
 	contracts := getBridgeAndLiquidity()
 	contracts[types.HtlcContract] = &embeddedImplementation{
 		map[string]Method{
-			cabi.CreateHtlcMethodName:           &implementation.CreateHtlcMethod{cabi.CreateHtlcMethodName},
+			cabi.CreateHtlcMethodName:           &implementation.CreateHtlcMethod{cabi.CreateHtlcMethodName, implementation.HtlcHashTypes},
 			cabi.ReclaimHtlcMethodName:          &implementation.ReclaimHtlcMethod{cabi.ReclaimHtlcMethodName},
 			cabi.UnlockHtlcMethodName:           &implementation.UnlockHtlcMethod{cabi.UnlockHtlcMethodName},
 			cabi.DenyHtlcProxyUnlockMethodName:  &implementation.DenyHtlcProxyUnlockMethod{cabi.DenyHtlcProxyUnlockMethodName},
@@ -79,15 +110,15 @@ func getBridgeAndLiquidity() map[types.Address]*embeddedImplementation {
 	contracts := getAccelerator()
 	contracts[types.BridgeContract] = &embeddedImplementation{
 		map[string]Method{
-			cabi.WrapTokenMethodName:            &implementation.WrapTokenMethod{cabi.WrapTokenMethodName},
-			cabi.UpdateWrapRequestMethodName:    &implementation.UpdateWrapRequestMethod{cabi.UpdateWrapRequestMethodName},
-			cabi.RedeemUnwrapMethodName:         &implementation.RedeemMethod{cabi.RedeemUnwrapMethodName},
-			cabi.UnwrapTokenMethodName:          &implementation.UnwrapTokenMethod{cabi.UnwrapTokenMethodName},
+			cabi.WrapTokenMethodName:            &implementation.WrapTokenMethod{cabi.WrapTokenMethodName, false},
+			cabi.UpdateWrapRequestMethodName:    &implementation.UpdateWrapRequestMethod{cabi.UpdateWrapRequestMethodName, false},
+			cabi.RedeemUnwrapMethodName:         &implementation.RedeemMethod{cabi.RedeemUnwrapMethodName, false},
+			cabi.UnwrapTokenMethodName:          &implementation.UnwrapTokenMethod{cabi.UnwrapTokenMethodName, false},
 			cabi.RevokeUnwrapRequestMethodName:  &implementation.RevokeUnwrapRequestMethod{cabi.RevokeUnwrapRequestMethodName},
-			cabi.SetNetworkMethodName:           &implementation.SetNetworkMethod{cabi.SetNetworkMethodName},
+			cabi.SetNetworkMethodName:           &implementation.SetNetworkMethod{cabi.SetNetworkMethodName, false},
 			cabi.RemoveNetworkMethodName:        &implementation.RemoveNetworkMethod{cabi.RemoveNetworkMethodName},
-			cabi.SetTokenPairMethod:             &implementation.SetTokenPairMethod{cabi.SetTokenPairMethod},
-			cabi.RemoveTokenPairMethodName:      &implementation.RemoveTokenPairMethod{cabi.RemoveTokenPairMethodName},
+			cabi.SetTokenPairMethod:             &implementation.SetTokenPairMethod{cabi.SetTokenPairMethod, false},
+			cabi.RemoveTokenPairMethodName:      &implementation.RemoveTokenPairMethod{cabi.RemoveTokenPairMethodName, false},
 			cabi.HaltMethodName:                 &implementation.HaltMethod{cabi.HaltMethodName},
 			cabi.NominateGuardiansMethodName:    &implementation.NominateGuardiansMethod{cabi.NominateGuardiansMethodName},
 			cabi.UnhaltMethodName:               &implementation.UnhaltMethod{cabi.UnhaltMethodName},
@@ -253,6 +284,12 @@ func GetEmbeddedMethod(context vm_context.AccountVmContext, address types.Addres
 
 	// contract address must exist in map
 	if p, found := contractsMap[address]; found {
+		// features which only extend one contract are keyed on their own spork
+		if address == types.BridgeContract && context.IsBridgeExtensionsSporkEnforced() {
+			p = bridgeExtensionsEmbedded
+		} else if address == types.HtlcContract && context.IsHtlcHashTypesSporkEnforced() {
+			p = htlcHashTypesEmbedded
+		}
 		// contract must implement the method
 		if method, err := p.abi.MethodById(abiSelector); err == nil {
 			// method must exist in the map
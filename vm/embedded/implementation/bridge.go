@@ -134,6 +134,8 @@ func CheckNetworkAndPairExist(context vm_context.AccountVmContext, networkClass
 
 type WrapTokenMethod struct {
 	MethodName string
+	// Extended is set once the bridge extensions spork is enforced
+	Extended bool
 }
 
 func (p *WrapTokenMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
@@ -147,7 +149,11 @@ func (p *WrapTokenMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrUnpackError
 	}
 
-	if !ecommon.IsHexAddress(param.ToAddress) {
+	if p.Extended {
+		if _, err := NormalizeWrapToAddress(param.NetworkClass, param.ChainId, param.ToAddress); err != nil {
+			return err
+		}
+	} else if !ecommon.IsHexAddress(param.ToAddress) {
 		return constants.ErrForbiddenParam
 	}
 
@@ -155,6 +161,11 @@ func (p *WrapTokenMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrInvalidTokenOrAmount
 	}
 
+	// amounts are paid out in satoshis
+	if p.Extended && param.NetworkClass == definition.BtcClass && !block.Amount.IsUint64() {
+		return constants.ErrInvalidTokenOrAmount
+	}
+
 	block.Data, err = definition.ABIBridge.PackMethod(p.MethodName, param.NetworkClass, param.ChainId, param.ToAddress)
 	return err
 }
@@ -184,13 +195,27 @@ func (p *WrapTokenMethod) ReceiveBlock(context vm_context.AccountVmContext, send
 		return nil, constants.ErrInvalidMinAmount
 	}
 
+	// a wrap over the limit is refunded, anyone can send one so it must not halt the bridge
+	if p.Extended {
+		if ok, err := consumeTokenPairLimit(context, param.NetworkClass, param.ChainId, sendBlock.TokenStandard, sendBlock.Amount, true); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, constants.ErrTokenPairLimitExceeded
+		}
+	}
+
 	frontierMomentum, err := context.GetFrontierMomentum()
 	common.DealWithErr(err)
 	request := new(definition.WrapTokenRequest)
 	request.NetworkClass = param.NetworkClass
 	request.ChainId = param.ChainId
 	request.Id = sendBlock.Hash
-	request.ToAddress = strings.ToLower(param.ToAddress)
+	if p.Extended {
+		request.ToAddress, err = NormalizeWrapToAddress(param.NetworkClass, param.ChainId, param.ToAddress)
+		common.DealWithErr(err)
+	} else {
+		request.ToAddress = strings.ToLower(param.ToAddress)
+	}
 	request.TokenStandard = sendBlock.TokenStandard
 	request.TokenAddress = tokenPair.TokenAddress
 	request.Amount = new(big.Int).Set(sendBlock.Amount)
@@ -269,6 +294,7 @@ func GetWrapTokenRequestMessage(request *definition.WrapTokenRequest, contractAd
 
 type UpdateWrapRequestMethod struct {
 	MethodName string
+	Extended   bool
 }
 
 func (p *UpdateWrapRequestMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
@@ -327,12 +353,22 @@ func (p *UpdateWrapRequestMethod) ReceiveBlock(context vm_context.AccountVmConte
 		return nil, constants.ErrInvalidToken
 	}
 
-	contractAddress := ecommon.HexToAddress(networkInfo.ContractAddress)
-	message, err := GetWrapTokenRequestMessage(request, &contractAddress)
-	if err != nil {
-		return nil, err
+	var message []byte
+	var result bool
+	if p.Extended {
+		message, err = GetWrapTokenRequestMessageByNetwork(request, networkInfo)
+		if err != nil {
+			return nil, err
+		}
+		result, err = CheckSignatureByNetworkClass(message, bridgeInfo.DecompressedTssECDSAPubKey, param.Signature, request.NetworkClass)
+	} else {
+		contractAddress := ecommon.HexToAddress(networkInfo.ContractAddress)
+		message, err = GetWrapTokenRequestMessage(request, &contractAddress)
+		if err != nil {
+			return nil, err
+		}
+		result, err = CheckECDSASignature(message, bridgeInfo.DecompressedTssECDSAPubKey, param.Signature)
 	}
-	result, err := CheckECDSASignature(message, bridgeInfo.DecompressedTssECDSAPubKey, param.Signature)
 	if err != nil || !result {
 		return nil, constants.ErrInvalidECDSASignature
 	}
@@ -366,7 +402,12 @@ func GetUnwrapTokenRequestMessage(param *definition.UnwrapTokenParam) ([]byte, e
 	return HashByNetworkClass(messageBytes, param.NetworkClass)
 }
 
-func checkUnwrapMetadataStatic(param *definition.UnwrapTokenParam) error {
+func checkUnwrapMetadataStatic(param *definition.UnwrapTokenParam, extended bool) error {
+	// deposits on Bitcoin are identified by txid and vout, see UnwrapBitcoinTokenMethod
+	if extended && param.NetworkClass == definition.BtcClass {
+		return constants.ErrForbiddenParam
+	}
+
 	if !ecommon.IsHexAddress(param.TokenAddress) {
 		return constants.ErrInvalidToAddress
 	}
@@ -380,6 +421,7 @@ func checkUnwrapMetadataStatic(param *definition.UnwrapTokenParam) error {
 
 type UnwrapTokenMethod struct {
 	MethodName string
+	Extended   bool
 }
 
 func (p *UnwrapTokenMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
@@ -393,7 +435,7 @@ func (p *UnwrapTokenMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrUnpackError
 	}
 
-	err = checkUnwrapMetadataStatic(param)
+	err = checkUnwrapMetadataStatic(param, p.Extended)
 	if err != nil {
 		return err
 	}
@@ -482,6 +524,7 @@ func (p *UnwrapTokenMethod) ReceiveBlock(context vm_context.AccountVmContext, se
 
 type SetNetworkMethod struct {
 	MethodName string
+	Extended   bool
 }
 
 func (p *SetNetworkMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
@@ -507,7 +550,11 @@ func (p *SetNetworkMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrForbiddenParam
 	}
 
-	if !ecommon.IsHexAddress(param.ContractAddress) {
+	if p.Extended {
+		if err := checkNetworkContractAddress(param.NetworkClass, param.ChainId, param.ContractAddress); err != nil {
+			return err
+		}
+	} else if !ecommon.IsHexAddress(param.ContractAddress) {
 		return constants.ErrInvalidContractAddress
 	}
 
@@ -684,6 +731,7 @@ func IsJSON(s string) bool {
 
 type SetTokenPairMethod struct {
 	MethodName string
+	Extended   bool
 }
 
 func (p *SetTokenPairMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
@@ -706,7 +754,11 @@ func (p *SetTokenPairMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrForbiddenParam
 	}
 
-	if !ecommon.IsHexAddress(param.TokenAddress) {
+	if p.Extended {
+		if err := checkTokenAddress(param.NetworkClass, param.TokenAddress); err != nil {
+			return err
+		}
+	} else if !ecommon.IsHexAddress(param.TokenAddress) {
 		return constants.ErrForbiddenParam
 	}
 
@@ -807,6 +859,7 @@ func (p *SetTokenPairMethod) ReceiveBlock(context vm_context.AccountVmContext, s
 
 type RemoveTokenPairMethod struct {
 	MethodName string
+	Extended   bool
 }
 
 func (p *RemoveTokenPairMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
@@ -824,7 +877,11 @@ func (p *RemoveTokenPairMethod) ValidateSendBlock(block *nom.AccountBlock) error
 		return constants.ErrInvalidTokenOrAmount
 	}
 
-	if !ecommon.IsHexAddress(param.TokenAddress) {
+	if p.Extended {
+		if err := checkTokenAddress(param.NetworkClass, param.TokenAddress); err != nil {
+			return err
+		}
+	} else if !ecommon.IsHexAddress(param.TokenAddress) {
 		return constants.ErrForbiddenParam
 	}
 
@@ -1510,6 +1567,7 @@ func (p *RevokeUnwrapRequestMethod) ReceiveBlock(context vm_context.AccountVmCon
 
 type RedeemMethod struct {
 	MethodName string
+	Extended   bool
 }
 
 func (p *RedeemMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
@@ -1541,7 +1599,8 @@ func (p *RedeemMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlo
 		return nil, err
 	}
 
-	if _, _, err = CanPerformAction(context); err != nil {
+	bridgeInfo, _, err := CanPerformAction(context)
+	if err != nil {
 		return nil, err
 	}
 
@@ -1582,6 +1641,17 @@ func (p *RedeemMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlo
 		return nil, constants.ErrInvalidRedeemPeriod
 	}
 
+	// crossing the redeem limit, which only TSS signed requests can do, halts the bridge.
+	// The request can be redeemed after the bridge is unhalted
+	if p.Extended {
+		if ok, err := consumeTokenPairLimit(context, request.NetworkClass, request.ChainId, network.TokenPairs[foundIndex].TokenStandard, request.Amount, false); err != nil {
+			return nil, err
+		} else if !ok {
+			haltOnLimit(context, bridgeInfo, "redeem limit", network.TokenPairs[foundIndex].TokenStandard, request.Amount)
+			return nil, nil
+		}
+	}
+
 	request.Redeemed = 1
 	common.DealWithErr(request.Save(context.Storage()))
 
@@ -0,0 +1,315 @@
+package implementation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	ecommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+const (
+	// bitcoinMessageTag is the BIP340 tag of the messages signed for the Bitcoin network class
+	bitcoinMessageTag = "zenon/bridge"
+
+	bitcoinWrapMessage   = byte(1)
+	bitcoinUnwrapMessage = byte(2)
+)
+
+var (
+	// bitcoinChains maps the chain ids of the Bitcoin network class to the Bitcoin networks
+	bitcoinChains = map[uint32]*chaincfg.Params{
+		1: &chaincfg.MainNetParams,
+		2: &chaincfg.TestNet3Params,
+		3: &chaincfg.RegressionNetParams,
+		4: &chaincfg.SigNetParams,
+	}
+)
+
+// BitcoinChainParams returns the Bitcoin network of chainId
+func BitcoinChainParams(chainId uint32) (*chaincfg.Params, error) {
+	if params, ok := bitcoinChains[chainId]; ok {
+		return params, nil
+	}
+	return nil, constants.ErrUnknownBitcoinChain
+}
+
+// DecodeBitcoinAddress decodes address and checks that it belongs to the Bitcoin network of chainId
+func DecodeBitcoinAddress(address string, chainId uint32) (btcutil.Address, error) {
+	params, err := BitcoinChainParams(chainId)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := btcutil.DecodeAddress(address, params)
+	if err != nil || !decoded.IsForNet(params) {
+		return nil, constants.ErrInvalidToAddress
+	}
+	return decoded, nil
+}
+
+// NormalizeWrapToAddress checks the destination address of a wrap request and returns it in canonical form.
+// Bitcoin addresses are re-encoded, since base58 addresses are case-sensitive.
+func NormalizeWrapToAddress(networkClass, chainId uint32, toAddress string) (string, error) {
+	if networkClass == definition.BtcClass {
+		address, err := DecodeBitcoinAddress(toAddress, chainId)
+		if err != nil {
+			return "", err
+		}
+		return address.EncodeAddress(), nil
+	}
+	if !ecommon.IsHexAddress(toAddress) {
+		return "", constants.ErrForbiddenParam
+	}
+	return strings.ToLower(toAddress), nil
+}
+
+// checkNetworkContractAddress checks the contract address of a network. For the Bitcoin network class
+// it's the custody address of the TSS key.
+func checkNetworkContractAddress(networkClass, chainId uint32, contractAddress string) error {
+	if networkClass == definition.BtcClass {
+		if _, err := DecodeBitcoinAddress(contractAddress, chainId); err == constants.ErrUnknownBitcoinChain {
+			return err
+		} else if err != nil {
+			return constants.ErrInvalidContractAddress
+		}
+		return nil
+	}
+	if !ecommon.IsHexAddress(contractAddress) {
+		return constants.ErrInvalidContractAddress
+	}
+	return nil
+}
+
+// checkTokenAddress checks the token address of a token pair. The Bitcoin network class only bridges native BTC.
+func checkTokenAddress(networkClass uint32, tokenAddress string) error {
+	if networkClass == definition.BtcClass {
+		if tokenAddress != definition.BtcTokenAddress {
+			return constants.ErrForbiddenParam
+		}
+		return nil
+	}
+	if !ecommon.IsHexAddress(tokenAddress) {
+		return constants.ErrForbiddenParam
+	}
+	return nil
+}
+
+// hashBitcoinMessage returns the BIP340 tagged hash of a message signed for the Bitcoin network class
+func hashBitcoinMessage(data []byte) []byte {
+	return chainhash.TaggedHash([]byte(bitcoinMessageTag), data)[:]
+}
+
+// CheckSignatureByNetworkClass checks the signature of a message in the format of networkClass.
+// The TSS key signs Bitcoin payouts as BIP340 signatures, so those are only accepted for the Bitcoin network class.
+func CheckSignatureByNetworkClass(message []byte, pubKeyStr, signatureStr string, networkClass uint32) (bool, error) {
+	if networkClass == definition.BtcClass {
+		signature, err := base64.StdEncoding.DecodeString(signatureStr)
+		if err != nil {
+			return false, constants.ErrInvalidB64Decode
+		}
+		if len(signature) == constants.SchnorrSignatureLength {
+			pubKey, err := base64.StdEncoding.DecodeString(pubKeyStr)
+			if err != nil {
+				return false, constants.ErrInvalidB64Decode
+			}
+			return checkSchnorrSignature(message, pubKey, signature)
+		}
+	}
+	return CheckECDSASignature(message, pubKeyStr, signatureStr)
+}
+
+// checkSchnorrSignature verifies a BIP340 signature against the x-only form of pubKey
+func checkSchnorrSignature(message, pubKey, signature []byte) (bool, error) {
+	if len(pubKey) != constants.DecompressedECDSAPubKeyLength {
+		return false, constants.ErrInvalidDecompressedECDSAPubKeyLength
+	}
+	key, err := btcec.ParsePubKey(pubKey)
+	if err != nil {
+		return false, err
+	}
+	sig, err := schnorr.ParseSignature(signature)
+	if err != nil {
+		return false, constants.ErrInvalidECDSASignature
+	}
+	if !sig.Verify(message, key) {
+		return false, constants.ErrInvalidECDSASignature
+	}
+	return true, nil
+}
+
+func writeUint32BE(buffer *bytes.Buffer, value uint32) {
+	common.DealWithErr(binary.Write(buffer, binary.BigEndian, value))
+}
+
+// GetBitcoinWrapTokenRequestMessage returns the message the TSS key signs to pay out a wrap request on Bitcoin.
+// The destination is committed to as its output script, and the amount, minus the fee, in satoshis.
+func GetBitcoinWrapTokenRequestMessage(request *definition.WrapTokenRequest, custodyAddress string) ([]byte, error) {
+	address, err := DecodeBitcoinAddress(request.ToAddress, request.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	script, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return nil, constants.ErrInvalidToAddress
+	}
+	amount := new(big.Int).Sub(request.Amount, request.Fee)
+	if amount.Sign() <= 0 || !amount.IsUint64() {
+		return nil, constants.ErrInvalidTokenOrAmount
+	}
+
+	buffer := new(bytes.Buffer)
+	buffer.WriteByte(bitcoinWrapMessage)
+	writeUint32BE(buffer, request.NetworkClass)
+	writeUint32BE(buffer, request.ChainId)
+	// custody address so if we ever rotate it, not a single signature can be reused
+	common.DealWithErr(wire.WriteVarString(buffer, 0, custodyAddress))
+	buffer.Write(request.Id.Bytes())
+	common.DealWithErr(wire.WriteVarBytes(buffer, 0, script))
+	common.DealWithErr(wire.WriteVarString(buffer, 0, request.TokenAddress))
+	common.DealWithErr(binary.Write(buffer, binary.LittleEndian, amount.Uint64()))
+
+	return hashBitcoinMessage(buffer.Bytes()), nil
+}
+
+// GetWrapTokenRequestMessageByNetwork returns the message of the wrap request in the format of its network class
+func GetWrapTokenRequestMessageByNetwork(request *definition.WrapTokenRequest, networkInfo *definition.NetworkInfo) ([]byte, error) {
+	if request.NetworkClass == definition.BtcClass {
+		return GetBitcoinWrapTokenRequestMessage(request, networkInfo.ContractAddress)
+	}
+	contractAddress := ecommon.HexToAddress(networkInfo.ContractAddress)
+	return GetWrapTokenRequestMessage(request, &contractAddress)
+}
+
+// GetBitcoinUnwrapTokenRequestMessage returns the message the TSS key signs for a deposit on Bitcoin.
+// The deposit is committed to as its serialized outpoint, with the txid in internal byte order.
+func GetBitcoinUnwrapTokenRequestMessage(param *definition.UnwrapBitcoinTokenParam) ([]byte, error) {
+	txId, err := chainhash.NewHashFromStr(param.TxId.String())
+	if err != nil {
+		return nil, err
+	}
+	if param.Amount.Sign() <= 0 || !param.Amount.IsUint64() {
+		return nil, constants.ErrInvalidTokenOrAmount
+	}
+
+	buffer := new(bytes.Buffer)
+	buffer.WriteByte(bitcoinUnwrapMessage)
+	writeUint32BE(buffer, definition.BtcClass)
+	writeUint32BE(buffer, param.ChainId)
+	buffer.Write(txId[:])
+	common.DealWithErr(binary.Write(buffer, binary.LittleEndian, param.Vout))
+	buffer.Write(param.ToAddress.Bytes())
+	common.DealWithErr(binary.Write(buffer, binary.LittleEndian, param.Amount.Uint64()))
+
+	return hashBitcoinMessage(buffer.Bytes()), nil
+}
+
+type UnwrapBitcoinTokenMethod struct {
+	MethodName string
+}
+
+func (p *UnwrapBitcoinTokenMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
+	return plasmaTable.EmbeddedSimple, nil
+}
+func (p *UnwrapBitcoinTokenMethod) ValidateSendBlock(block *nom.AccountBlock) error {
+	var err error
+	param := new(definition.UnwrapBitcoinTokenParam)
+
+	if err := definition.ABIBridge.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return constants.ErrUnpackError
+	}
+
+	if _, err := BitcoinChainParams(param.ChainId); err != nil {
+		return err
+	}
+
+	// amounts are in satoshis
+	if param.Amount.Sign() <= 0 || !param.Amount.IsUint64() {
+		return constants.ErrInvalidTokenOrAmount
+	}
+
+	if block.Amount.Sign() != 0 {
+		return constants.ErrInvalidTokenOrAmount
+	}
+
+	block.Data, err = definition.ABIBridge.PackMethod(p.MethodName, param.ChainId, param.TxId, param.Vout, param.ToAddress, param.Amount, param.Signature)
+	return err
+}
+func (p *UnwrapBitcoinTokenMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock *nom.AccountBlock) ([]*nom.AccountBlock, error) {
+	if err := p.ValidateSendBlock(sendBlock); err != nil {
+		return nil, err
+	}
+	bridgeInfo, _, err := CanPerformAction(context)
+	if err != nil {
+		return nil, err
+	}
+
+	param := new(definition.UnwrapBitcoinTokenParam)
+	err = definition.ABIBridge.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := definition.GetUnwrapTokenRequestByTxHashAndLog(context.Storage(), param.TxId, param.Vout)
+	if err == nil {
+		return nil, constants.ErrInvalidTransactionHash
+	} else if err != constants.ErrDataNonExistent {
+		common.DealWithErr(err)
+	}
+
+	tokenPair, err := CheckNetworkAndPairExist(context, definition.BtcClass, param.ChainId, definition.BtcTokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tokenPair.Redeemable {
+		return nil, constants.ErrTokenNotRedeemable
+	}
+
+	message, err := GetBitcoinUnwrapTokenRequestMessage(param)
+	if err != nil {
+		return nil, err
+	}
+	result, err := CheckSignatureByNetworkClass(message, bridgeInfo.DecompressedTssECDSAPubKey, param.Signature, definition.BtcClass)
+	if err != nil || !result {
+		bridgeLog.Error("UnwrapBitcoin-ErrInvalidSignature", "error", err, "result", result, "signature", param.Signature)
+		return nil, constants.ErrInvalidECDSASignature
+	}
+
+	momentum, err := context.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	request = &definition.UnwrapTokenRequest{
+		RegistrationMomentumHeight: momentum.Height,
+		NetworkClass:               definition.BtcClass,
+		ChainId:                    param.ChainId,
+		TransactionHash:            param.TxId,
+		LogIndex:                   param.Vout,
+		ToAddress:                  param.ToAddress,
+		TokenAddress:               definition.BtcTokenAddress,
+		TokenStandard:              tokenPair.TokenStandard,
+		Amount:                     param.Amount,
+		Signature:                  param.Signature,
+		Redeemed:                   0,
+		Revoked:                    0,
+	}
+
+	common.DealWithErr(request.Save(context.Storage()))
+	return nil, nil
+}
@@ -0,0 +1,235 @@
+package implementation
+
+import (
+	"math/big"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+var (
+	// feeDonationContracts are the embedded contracts which can receive fees, through their Donate method
+	feeDonationContracts = map[types.Address]bool{
+		types.LiquidityContract:   true,
+		types.AcceleratorContract: true,
+	}
+)
+
+// checkFeeDistribution checks that there is at least one destination, that no destination is repeated
+// and that the percentages add up to constants.FeeDistributionTotalPercentages
+func checkFeeDistribution(param *definition.FeeDistributionParam) error {
+	if len(param.Destinations) == 0 || len(param.Destinations) > constants.MaxFeeDistributionDestinations {
+		return constants.ErrForbiddenParam
+	}
+	if len(param.Destinations) != len(param.Percentages) {
+		return constants.ErrForbiddenParam
+	}
+
+	total := uint32(0)
+	destinations := make(map[types.Address]bool)
+	for i, destination := range param.Destinations {
+		if destination.IsZero() || destinations[destination] {
+			return constants.ErrForbiddenParam
+		}
+		if types.IsEmbeddedAddress(destination) && !feeDonationContracts[destination] {
+			return constants.ErrForbiddenParam
+		}
+		destinations[destination] = true
+
+		if param.Percentages[i] > constants.FeeDistributionTotalPercentages {
+			return constants.ErrInvalidPercentages
+		}
+		total += param.Percentages[i]
+	}
+	if total != constants.FeeDistributionTotalPercentages {
+		return constants.ErrInvalidPercentages
+	}
+	return nil
+}
+
+// splitFees splits amount by the percentages of distribution, the rounding dust going to the last destination
+func splitFees(distribution *definition.FeeDistribution, amount *big.Int) []*big.Int {
+	amounts := make([]*big.Int, len(distribution.Destinations))
+	left := new(big.Int).Set(amount)
+	for i := range distribution.Destinations {
+		if i == len(distribution.Destinations)-1 {
+			amounts[i] = left
+			break
+		}
+		amounts[i] = new(big.Int).Mul(amount, big.NewInt(int64(distribution.Percentages[i])))
+		amounts[i].Div(amounts[i], big.NewInt(int64(constants.FeeDistributionTotalPercentages)))
+		left.Sub(left, amounts[i])
+	}
+	return amounts
+}
+
+type SetFeeDistributionMethod struct {
+	MethodName string
+}
+
+func (p *SetFeeDistributionMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
+	return plasmaTable.EmbeddedSimple, nil
+}
+func (p *SetFeeDistributionMethod) ValidateSendBlock(block *nom.AccountBlock) error {
+	var err error
+	param := new(definition.FeeDistributionParam)
+
+	if err := definition.ABIBridge.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return constants.ErrUnpackError
+	}
+
+	if block.Amount.Sign() != 0 {
+		return constants.ErrInvalidTokenOrAmount
+	}
+
+	if err := checkFeeDistribution(param); err != nil {
+		return err
+	}
+
+	block.Data, err = definition.ABIBridge.PackMethod(p.MethodName, param.Destinations, param.Percentages)
+	return err
+}
+func (p *SetFeeDistributionMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock *nom.AccountBlock) ([]*nom.AccountBlock, error) {
+	if err := p.ValidateSendBlock(sendBlock); err != nil {
+		return nil, err
+	}
+
+	param := new(definition.FeeDistributionParam)
+	err := definition.ABIBridge.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	bridgeInfo, err := definition.GetBridgeInfoVariable(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	if sendBlock.Address.String() != bridgeInfo.Administrator.String() {
+		return nil, constants.ErrPermissionDenied
+	}
+
+	securityInfo, err := definition.GetSecurityInfoVariable(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	if timeChallengeInfo, errTimeChallenge := TimeChallenge(context, p.MethodName, param.Hash(), securityInfo.SoftDelay); errTimeChallenge != nil {
+		return nil, errTimeChallenge
+	} else {
+		// if paramsHash is not zero it means we had a new challenge and we can't go further to save the change into local db
+		if !timeChallengeInfo.ParamsHash.IsZero() {
+			return nil, nil
+		}
+	}
+
+	distribution := &definition.FeeDistribution{
+		Destinations: param.Destinations,
+		Percentages:  param.Percentages,
+	}
+	common.DealWithErr(distribution.Save(context.Storage()))
+	return nil, nil
+}
+
+// DistributeFeesMethod can be called by anyone to send the fees accumulated for a token to the destinations of the fee distribution
+type DistributeFeesMethod struct {
+	MethodName string
+}
+
+func (p *DistributeFeesMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
+	return plasmaTable.EmbeddedWWithdraw, nil
+}
+func (p *DistributeFeesMethod) ValidateSendBlock(block *nom.AccountBlock) error {
+	var err error
+	zts := new(types.ZenonTokenStandard)
+
+	if err := definition.ABIBridge.UnpackMethod(zts, p.MethodName, block.Data); err != nil {
+		return constants.ErrUnpackError
+	}
+
+	if block.Amount.Sign() != 0 {
+		return constants.ErrInvalidTokenOrAmount
+	}
+
+	block.Data, err = definition.ABIBridge.PackMethod(p.MethodName, *zts)
+	return err
+}
+func (p *DistributeFeesMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock *nom.AccountBlock) ([]*nom.AccountBlock, error) {
+	if err := p.ValidateSendBlock(sendBlock); err != nil {
+		return nil, err
+	}
+	if _, _, err := CanPerformAction(context); err != nil {
+		return nil, err
+	}
+
+	zts := new(types.ZenonTokenStandard)
+	err := definition.ABIBridge.UnpackMethod(zts, p.MethodName, sendBlock.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	distribution, err := definition.GetFeeDistribution(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+	if len(distribution.Destinations) == 0 {
+		return nil, constants.ErrFeeDistributionNotSet
+	}
+
+	ztsFeesInfo, err := definition.GetZtsFeesInfoVariable(context.Storage(), *zts)
+	if err != nil {
+		return nil, err
+	}
+	if ztsFeesInfo.AccumulatedFee.Sign() == 0 {
+		return nil, constants.ErrNothingToWithdraw
+	}
+	balance, err := context.GetBalance(*zts)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(ztsFeesInfo.AccumulatedFee) < 0 {
+		return nil, constants.ErrInsufficientBalance
+	}
+
+	momentum, err := context.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &definition.FeeDistributionRecord{
+		Id:             sendBlock.Hash,
+		TokenStandard:  *zts,
+		MomentumHeight: momentum.Height,
+		Destinations:   distribution.Destinations,
+		Amounts:        splitFees(distribution, ztsFeesInfo.AccumulatedFee),
+	}
+
+	blocks := make([]*nom.AccountBlock, 0, len(record.Destinations))
+	for i, destination := range record.Destinations {
+		if record.Amounts[i].Sign() == 0 {
+			continue
+		}
+		block := &nom.AccountBlock{
+			Address:       types.BridgeContract,
+			ToAddress:     destination,
+			BlockType:     nom.BlockTypeContractSend,
+			Amount:        new(big.Int).Set(record.Amounts[i]),
+			TokenStandard: *zts,
+			Data:          []byte{},
+		}
+		if feeDonationContracts[destination] {
+			block.Data = definition.ABICommon.PackMethodPanic(definition.DonateMethodName)
+		}
+		blocks = append(blocks, block)
+	}
+
+	bridgeLog.Debug("distributed fees", "token-standard", *zts, "amount", ztsFeesInfo.AccumulatedFee, "destinations", len(blocks))
+	ztsFeesInfo.AccumulatedFee = big.NewInt(0)
+	common.DealWithErr(ztsFeesInfo.Save(context.Storage()))
+	common.DealWithErr(record.Save(context.Storage()))
+	return blocks, nil
+}
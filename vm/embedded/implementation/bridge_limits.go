@@ -0,0 +1,201 @@
+package implementation
+
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+// advanceTokenPairWindow moves the window of limits to the one containing height.
+// The current volume becomes the previous one only if the windows are adjacent.
+func advanceTokenPairWindow(limits *definition.TokenPairLimits, height uint64) {
+	start := height - height%limits.WindowSize
+	if start == limits.WindowStart {
+		return
+	}
+	if start == limits.WindowStart+limits.WindowSize {
+		limits.PreviousWrapped = limits.Wrapped
+		limits.PreviousRedeemed = limits.Redeemed
+	} else {
+		limits.PreviousWrapped = big.NewInt(0)
+		limits.PreviousRedeemed = big.NewInt(0)
+	}
+	limits.Wrapped = big.NewInt(0)
+	limits.Redeemed = big.NewInt(0)
+	limits.WindowStart = start
+}
+
+// rollingVolume estimates the volume of the last WindowSize momentums, weighting the previous window
+// by the part of it which is still inside the rolling window. Requires advanceTokenPairWindow.
+func rollingVolume(limits *definition.TokenPairLimits, current, previous *big.Int, height uint64) *big.Int {
+	remaining := limits.WindowSize - (height - limits.WindowStart)
+	volume := new(big.Int).Mul(previous, new(big.Int).SetUint64(remaining))
+	volume.Div(volume, new(big.Int).SetUint64(limits.WindowSize))
+	return volume.Add(volume, current)
+}
+
+// TokenPairVolumes returns the rolling wrap and redeem volumes of limits at height
+func TokenPairVolumes(limits *definition.TokenPairLimits, height uint64) (*big.Int, *big.Int) {
+	if limits.WindowSize == 0 {
+		return big.NewInt(0), big.NewInt(0)
+	}
+	advanceTokenPairWindow(limits, height)
+	return rollingVolume(limits, limits.Wrapped, limits.PreviousWrapped, height), rollingVolume(limits, limits.Redeemed, limits.PreviousRedeemed, height)
+}
+
+// RemainingCapacity returns how much can still be moved under max, or nil if there is no limit
+func RemainingCapacity(max, volume *big.Int) *big.Int {
+	if max.Sign() == 0 {
+		return nil
+	}
+	remaining := new(big.Int).Sub(max, volume)
+	if remaining.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return remaining
+}
+
+// consumeTokenPairLimit adds amount to the wrapped or redeemed volume of the token pair.
+// Returns false, without consuming anything, if amount doesn't fit in the remaining capacity.
+func consumeTokenPairLimit(context vm_context.AccountVmContext, networkClass, chainId uint32, zts types.ZenonTokenStandard, amount *big.Int, wrap bool) (bool, error) {
+	limits, err := definition.GetTokenPairLimits(context.Storage(), networkClass, chainId, zts)
+	if err != nil {
+		return false, err
+	}
+	if limits.WindowSize == 0 {
+		return true, nil
+	}
+	momentum, err := context.GetFrontierMomentum()
+	if err != nil {
+		return false, err
+	}
+
+	wrapped, redeemed := TokenPairVolumes(limits, momentum.Height)
+	max, volume, counter := limits.MaxWrapAmount, wrapped, limits.Wrapped
+	if !wrap {
+		max, volume, counter = limits.MaxRedeemAmount, redeemed, limits.Redeemed
+	}
+	if remaining := RemainingCapacity(max, volume); remaining != nil && amount.Cmp(remaining) > 0 {
+		return false, nil
+	}
+	counter.Add(counter, amount)
+	common.DealWithErr(limits.Save(context.Storage()))
+	return true, nil
+}
+
+// haltOnLimit halts the bridge the same way HaltMethod does, so that it stays halted until the administrator unhalts it
+func haltOnLimit(context vm_context.AccountVmContext, bridgeInfo *definition.BridgeInfoVariable, reason string, zts types.ZenonTokenStandard, amount *big.Int) {
+	bridgeLog.Error("halted bridge", "reason", reason, "token-standard", zts, "amount", amount)
+	bridgeInfo.Halted = true
+	common.DealWithErr(bridgeInfo.Save(context.Storage()))
+}
+
+type SetTokenPairLimitsMethod struct {
+	MethodName string
+}
+
+func (p *SetTokenPairLimitsMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
+	return plasmaTable.EmbeddedSimple, nil
+}
+func (p *SetTokenPairLimitsMethod) ValidateSendBlock(block *nom.AccountBlock) error {
+	var err error
+	param := new(definition.TokenPairLimitsParam)
+
+	if err := definition.ABIBridge.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return constants.ErrUnpackError
+	}
+
+	if block.Amount.Sign() != 0 {
+		return constants.ErrInvalidTokenOrAmount
+	}
+
+	if param.WindowSize == 0 {
+		return constants.ErrForbiddenParam
+	}
+
+	block.Data, err = definition.ABIBridge.PackMethod(p.MethodName, param.NetworkClass, param.ChainId, param.TokenStandard, param.WindowSize, param.MaxWrapAmount, param.MaxRedeemAmount)
+	return err
+}
+func (p *SetTokenPairLimitsMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock *nom.AccountBlock) ([]*nom.AccountBlock, error) {
+	if err := p.ValidateSendBlock(sendBlock); err != nil {
+		return nil, err
+	}
+
+	param := new(definition.TokenPairLimitsParam)
+	err := definition.ABIBridge.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	bridgeInfo, err := definition.GetBridgeInfoVariable(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	if sendBlock.Address.String() != bridgeInfo.Administrator.String() {
+		return nil, constants.ErrPermissionDenied
+	}
+
+	networkInfo, err := definition.GetNetworkInfoVariable(context.Storage(), param.NetworkClass, param.ChainId)
+	if err != nil {
+		return nil, err
+	} else if len(networkInfo.Name) == 0 {
+		return nil, constants.ErrUnknownNetwork
+	}
+
+	found := false
+	for _, pair := range networkInfo.TokenPairs {
+		if reflect.DeepEqual(pair.TokenStandard.Bytes(), param.TokenStandard.Bytes()) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, constants.ErrTokenNotFound
+	}
+
+	securityInfo, err := definition.GetSecurityInfoVariable(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	if timeChallengeInfo, errTimeChallenge := TimeChallenge(context, p.MethodName, param.Hash(), securityInfo.SoftDelay); errTimeChallenge != nil {
+		return nil, errTimeChallenge
+	} else {
+		// if paramsHash is not zero it means we had a new challenge and we can't go further to save the change into local db
+		if !timeChallengeInfo.ParamsHash.IsZero() {
+			return nil, nil
+		}
+	}
+
+	limits, err := definition.GetTokenPairLimits(context.Storage(), param.NetworkClass, param.ChainId, param.TokenStandard)
+	if err != nil {
+		return nil, err
+	}
+	momentum, err := context.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	if limits.WindowSize != param.WindowSize {
+		// the volumes so far are kept as the previous window of the new size, which may overestimate them for a while
+		if limits.WindowSize != 0 {
+			wrapped, redeemed := TokenPairVolumes(limits, momentum.Height)
+			limits.PreviousWrapped, limits.PreviousRedeemed = wrapped, redeemed
+		}
+		limits.WindowSize = param.WindowSize
+		limits.WindowStart = momentum.Height - momentum.Height%limits.WindowSize
+		limits.Wrapped = big.NewInt(0)
+		limits.Redeemed = big.NewInt(0)
+	}
+	limits.MaxWrapAmount = param.MaxWrapAmount
+	limits.MaxRedeemAmount = param.MaxRedeemAmount
+	common.DealWithErr(limits.Save(context.Storage()))
+	return nil, nil
+}
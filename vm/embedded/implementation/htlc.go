@@ -34,9 +34,25 @@ func GetHtlcProxyUnlockStatus(context vm_context.AccountVmContext, address types
 	}
 }
 
-func checkHtlc(param definition.CreateHtlcParam) error {
+var (
+	// HtlcHashTypes are the hash types accepted before the HtlcHashTypesSpork
+	HtlcHashTypes = map[uint8]bool{
+		definition.HashTypeSHA3:   true,
+		definition.HashTypeSHA256: true,
+	}
+	// HtlcExtendedHashTypes are the hash types accepted after the HtlcHashTypesSpork,
+	// so that htlcs can be paired with the scripts of Bitcoin-family chains
+	HtlcExtendedHashTypes = map[uint8]bool{
+		definition.HashTypeSHA3:    true,
+		definition.HashTypeSHA256:  true,
+		definition.HashTypeHASH160: true,
+		definition.HashTypeBLAKE2b: true,
+	}
+)
 
-	if param.HashType != definition.HashTypeSHA3 && param.HashType != definition.HashTypeSHA256 {
+func checkHtlc(param definition.CreateHtlcParam, hashTypes map[uint8]bool) error {
+
+	if !hashTypes[param.HashType] {
 		return constants.ErrInvalidHashType
 	}
 
@@ -47,8 +63,26 @@ func checkHtlc(param definition.CreateHtlcParam) error {
 	return nil
 }
 
+// HashPreimage returns the digest of preimage for hashType
+func HashPreimage(hashType uint8, preimage []byte) []byte {
+	switch hashType {
+	case definition.HashTypeSHA3:
+		return crypto.Hash(preimage)
+	case definition.HashTypeSHA256:
+		return crypto.HashSHA256(preimage)
+	case definition.HashTypeHASH160:
+		return crypto.HashHASH160(preimage)
+	case definition.HashTypeBLAKE2b:
+		return crypto.HashBLAKE2b(preimage)
+	default:
+		// shouldn't get here
+		return nil
+	}
+}
+
 type CreateHtlcMethod struct {
 	MethodName string
+	HashTypes  map[uint8]bool
 }
 
 func (p *CreateHtlcMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
@@ -63,7 +97,7 @@ func (p *CreateHtlcMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrUnpackError
 	}
 
-	if err = checkHtlc(*param); err != nil {
+	if err = checkHtlc(*param, p.HashTypes); err != nil {
 		return err
 	}
 
@@ -248,16 +282,7 @@ func (p *UnlockHtlcMethod) ReceiveBlock(context vm_context.AccountVmContext, sen
 		return nil, constants.ErrInvalidPreimage
 	}
 
-	var hashedPreimage []byte
-	if htlcInfo.HashType == definition.HashTypeSHA3 {
-		hashedPreimage = crypto.Hash(param.Preimage)
-	} else if htlcInfo.HashType == definition.HashTypeSHA256 {
-		hashedPreimage = crypto.HashSHA256(param.Preimage)
-	} else {
-		// shouldn't get here
-	}
-
-	if !bytes.Equal(hashedPreimage, htlcInfo.HashLock) {
+	if !bytes.Equal(HashPreimage(htlcInfo.HashType, param.Preimage), htlcInfo.HashLock) {
 		htlcLog.Debug("invalid unlock - wrong preimage", "id", htlcInfo.Id, "address", sendBlock.Address, "preimage", hex.EncodeToString(param.Preimage))
 		return nil, constants.ErrInvalidPreimage
 	}
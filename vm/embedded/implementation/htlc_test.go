@@ -23,17 +23,31 @@ var (
 
 func TestHtlc_HashType(t *testing.T) {
 	htlc := defaultHtlc
-	common.ExpectError(t, checkHtlc(htlc), nil)
+	common.ExpectError(t, checkHtlc(htlc, HtlcHashTypes), nil)
 	htlc.HashType = 1
-	common.ExpectError(t, checkHtlc(htlc), nil)
+	common.ExpectError(t, checkHtlc(htlc, HtlcHashTypes), nil)
 	htlc.HashType = 2
-	common.ExpectError(t, checkHtlc(htlc), constants.ErrInvalidHashType)
+	common.ExpectError(t, checkHtlc(htlc, HtlcHashTypes), constants.ErrInvalidHashType)
+	htlc.HashType = 4
+	common.ExpectError(t, checkHtlc(htlc, HtlcExtendedHashTypes), constants.ErrInvalidHashType)
+}
+
+func TestHtlc_ExtendedHashType(t *testing.T) {
+	htlc := defaultHtlc
+	htlc.HashType = 2
+	common.ExpectError(t, checkHtlc(htlc, HtlcExtendedHashTypes), constants.ErrInvalidHashDigest)
+	htlc.HashLock = htlc.HashLock[:20]
+	common.ExpectError(t, checkHtlc(htlc, HtlcExtendedHashTypes), nil)
+	htlc = defaultHtlc
+	htlc.HashType = 3
+	common.ExpectError(t, checkHtlc(htlc, HtlcExtendedHashTypes), nil)
+	common.ExpectError(t, checkHtlc(htlc, HtlcHashTypes), constants.ErrInvalidHashType)
 }
 
 func TestHtlc_LockLength(t *testing.T) {
 	htlc := defaultHtlc
 	htlc.HashLock = htlc.HashLock[1:]
-	common.ExpectError(t, checkHtlc(htlc), constants.ErrInvalidHashDigest)
+	common.ExpectError(t, checkHtlc(htlc, HtlcHashTypes), constants.ErrInvalidHashDigest)
 	htlc.HashType = 1
-	common.ExpectError(t, checkHtlc(htlc), constants.ErrInvalidHashDigest)
+	common.ExpectError(t, checkHtlc(htlc, HtlcHashTypes), constants.ErrInvalidHashDigest)
 }
@@ -54,8 +54,20 @@ func (method *UpdateEmbeddedLiquidityMethod) ReceiveBlock(context vm_context.Acc
 	return updateLiquidityRewards(context)
 }
 
+// liquidityRewardForEpoch returns the liquidity reward for epoch. Once the merge mining spork is enforced, the merge
+// mining reward is paid out of it, by the merge mining contract to the miners with shares in epoch.
+func liquidityRewardForEpoch(context vm_context.AccountVmContext, epoch uint64) (*big.Int, *big.Int) {
+	znn, qsr := constants.LiquidityRewardForEpoch(epoch)
+	if context.IsMergeMiningEnforced() {
+		mergeMiningZnn, mergeMiningQsr := constants.MergeMiningRewardForEpoch(epoch)
+		znn.Sub(znn, mergeMiningZnn)
+		qsr.Sub(qsr, mergeMiningQsr)
+	}
+	return znn, qsr
+}
+
 func computeLiquidityRewardsForEpoch(context vm_context.AccountVmContext, epoch uint64) ([]*nom.AccountBlock, error) {
-	totalZnnAmount, totalQsrAmount := constants.LiquidityRewardForEpoch(epoch)
+	totalZnnAmount, totalQsrAmount := liquidityRewardForEpoch(context, epoch)
 
 	liquidityLog.Debug("updating liquidity reward", "epoch", epoch, "znn-amount", totalZnnAmount, "qsr-amount", totalQsrAmount)
 
@@ -543,7 +555,7 @@ func computeLiquidityStakeRewardsForEpoch(context vm_context.AccountVmContext, e
 	if err != nil {
 		return nil, err
 	}
-	totalZnnAmount, totalQsrAmount := constants.LiquidityRewardForEpoch(epoch)
+	totalZnnAmount, totalQsrAmount := liquidityRewardForEpoch(context, epoch)
 	if liquidityInfo.IsHalted {
 		// return blocks that issue tokens to liquidity embedded
 		return []*nom.AccountBlock{
@@ -4,14 +4,16 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/binary"
-	"fmt"
 	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 	"github.com/pkg/errors"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/crypto"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
@@ -22,6 +24,10 @@ import (
 	"time"
 )
 
+var (
+	mergeMiningLog = common.EmbeddedLogger.New("contract", "merge-mining")
+)
+
 func CheckMergeMiningInitialized(context vm_context.AccountVmContext) (*definition.MergeMiningInfoVariable, error) {
 	mergeMiningInfo, err := definition.GetMergeMiningInfoVariableVariable(context.Storage())
 	common.DealWithErr(err)
@@ -107,6 +113,14 @@ func (p *SetInitialBitcoinBlockMethod) ReceiveBlock(context vm_context.AccountVm
 	param := new(definition.BlockHeaderVariable)
 	err = definition.ABIMergeMining.UnpackMethod(param, p.MethodName, sendBlock.Data)
 	common.DealWithErr(err)
+	params := GetPowParams(context.Storage())
+	if err := CheckPowLimit(param.Bits, params); err != nil {
+		return nil, err
+	}
+	// the first retarget needs the timestamp of the first block of the period, so the header chain has to start with it
+	if !params.NoRetargeting && param.Height%params.BlocksPerRetarget() != 0 {
+		return nil, constants.ErrInitialHeaderNotRetargetBoundary
+	}
 	blockHash := param.BaseHeader.BlockHash()
 	if err := param.Hash.SetBytes(blockHash.Bytes()); err != nil {
 		return nil, constants.ErrForbiddenParam
@@ -115,16 +129,74 @@ func (p *SetInitialBitcoinBlockMethod) ReceiveBlock(context vm_context.AccountVm
 	headerChainInfo, err := definition.GetHeaderChainInfoVariableVariable(context.Storage())
 	common.DealWithErr(err)
 	// It means merge mining has not been initialised and the administrator must set the starting block
+	if !(reflect.DeepEqual(headerChainInfo.Tip.Bytes(), types.ZeroHash) || headerChainInfo.TipHeight == 0 || headerChainInfo.TipWorkSum.Cmp(big.NewInt(0)) == 0) {
+		return nil, constants.ErrHeaderChainInitialized
+	}
 	workSum := big.NewInt(0).Set(blockchain.CalcWork(param.Bits))
-	if reflect.DeepEqual(headerChainInfo.Tip.Bytes(), types.ZeroHash) || headerChainInfo.TipHeight == 0 || headerChainInfo.TipWorkSum.Cmp(big.NewInt(0)) == 0 {
-		headerChainInfo.Tip = param.Hash
-		headerChainInfo.TipHeight = param.Height
-		headerChainInfo.TipWorkSum.Set(workSum)
-		common.DealWithErr(headerChainInfo.Save(context.Storage()))
-		param.WorkSum = big.NewInt(0).Set(workSum)
-		common.DealWithErr(param.Save(context.Storage()))
+	headerChainInfo.Tip = param.Hash
+	headerChainInfo.TipHeight = param.Height
+	headerChainInfo.TipWorkSum.Set(workSum)
+	common.DealWithErr(headerChainInfo.Save(context.Storage()))
+	param.WorkSum = big.NewInt(0).Set(workSum)
+	common.DealWithErr(param.Save(context.Storage()))
+	common.DealWithErr(definition.SaveHeaderHeight(context.Storage(), param.Height, param.Hash))
+	common.DealWithErr(definition.SetMainChainHash(context.Storage(), param.Height, param.Hash))
+
+	return nil, nil
+}
+
+// SetPowNetworkMethod selects the Bitcoin network whose proof of work rules the header chain follows.
+// The network can only be changed before the initial block header is set.
+type SetPowNetworkMethod struct {
+	MethodName string
+}
+
+func (p *SetPowNetworkMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
+	return plasmaTable.EmbeddedSimple, nil
+}
+func (p *SetPowNetworkMethod) ValidateSendBlock(block *nom.AccountBlock) error {
+	var err error
+
+	param := new(string)
+	if err := definition.ABIMergeMining.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return constants.ErrUnpackError
+	}
+
+	if block.Amount.Sign() != 0 {
+		return constants.ErrInvalidTokenOrAmount
+	}
+
+	if _, ok := constants.PowParamsByNetwork[*param]; !ok {
+		return constants.ErrUnknownPowNetwork
+	}
+
+	block.Data, err = definition.ABIMergeMining.PackMethod(p.MethodName, param)
+	return err
+}
+func (p *SetPowNetworkMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock *nom.AccountBlock) ([]*nom.AccountBlock, error) {
+	if err := p.ValidateSendBlock(sendBlock); err != nil {
+		return nil, err
 	}
 
+	if _, errSec := CheckSecurityInitialized(context); errSec != nil {
+		return nil, errSec
+	}
+	mergeMiningInfo, err := CheckMergeMiningInitialized(context)
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(sendBlock.Address.Bytes(), mergeMiningInfo.Administrator.Bytes()) {
+		return nil, constants.ErrPermissionDenied
+	}
+
+	if _, err := CheckHeaderChainInitialized(context); err == nil {
+		return nil, constants.ErrHeaderChainInitialized
+	}
+
+	param := new(string)
+	err = definition.ABIMergeMining.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	common.DealWithErr(err)
+	common.DealWithErr(definition.SavePowNetwork(context.Storage(), *param))
 	return nil, nil
 }
 
@@ -200,52 +272,244 @@ func (p *SetShareChainMethod) ReceiveBlock(context vm_context.AccountVmContext,
 	return nil, nil
 }
 
+// CheckProofOfWork verifies that the hash of the header satisfies targetBits.
+// The network limit is checked separately by CheckPowLimit since it depends on the header chain.
 func CheckProofOfWork(header definition.BlockHeaderVariable, targetBits uint32) error {
 	hash := header.BlockHashChain()
-	fmt.Println(hash.String())
 	targetDifficulty := blockchain.CompactToBig(targetBits)
-	fmt.Println(targetDifficulty.String())
 	if targetDifficulty.Sign() <= 0 {
 		return constants.ErrTargetDifficultyLessThanZero
 	}
-	fmt.Println(blockchain.HashToBig(&hash).String())
 	if blockchain.HashToBig(&hash).Cmp(targetDifficulty) > 0 {
 		return constants.ErrInvalidNonce
 	}
-	// The target difficulty must be less than the maximum allowed.
-	if targetDifficulty.Cmp(constants.MainPowLimit) > 0 {
+	return nil
+}
+
+// CheckPowLimit verifies that targetBits is not easier than the proof of work limit of the network
+func CheckPowLimit(targetBits uint32, params *constants.PowParams) error {
+	if blockchain.CompactToBig(targetBits).Cmp(params.PowLimit) > 0 {
 		return constants.ErrPowLimitExceeded
 	}
 	return nil
 }
 
-// CalcEasiestDifficulty calculates the easiest possible difficulty that a block
-// can have given starting difficulty bits and a duration.  It is mainly used to
-// verify that claimed proof of work by a block is sane as compared to a
-// known good checkpoint.
-func CalcEasiestDifficulty(bits uint32, duration time.Duration) uint32 {
-	// Convert types used in the calculations below.
-	durationVal := int64(duration / time.Second)
-	adjustmentFactor := big.NewInt(constants.RetargetAdjustmentFactor)
+// GetPowParams returns the proof of work rules of the network selected for the header chain
+func GetPowParams(context db.DB) *constants.PowParams {
+	network, err := definition.GetPowNetwork(context)
+	common.DealWithErr(err)
+	if params, ok := constants.PowParamsByNetwork[network]; ok {
+		return params
+	}
+	return constants.DefaultPowParams
+}
 
-	// Since easier difficulty equates to higher numbers, the easiest
-	// difficulty for a given duration is the largest value possible given
-	// the number of retargets for the duration and starting difficulty
-	// multiplied by the max adjustment factor.
-	newTarget := blockchain.CompactToBig(bits)
-	for durationVal > 0 && newTarget.Cmp(constants.MainPowLimit) < 0 {
-		newTarget.Mul(newTarget, adjustmentFactor)
-		durationVal -= constants.MaxRetargetTimespan
+// CalcRetargetDifficulty calculates the difficulty of the first block of a new
+// retarget period given the bits of the last block of the previous period and
+// the time, in seconds, it took to mine that period. The adjustment is limited
+// to a factor of RetargetAdjustmentFactor in both directions, same as Bitcoin.
+func CalcRetargetDifficulty(bits uint32, actualTimespan int64, params *constants.PowParams) uint32 {
+	// Limit the amount of adjustment that can occur to the previous difficulty.
+	adjustedTimespan := actualTimespan
+	if actualTimespan < params.MinRetargetTimespan() {
+		adjustedTimespan = params.MinRetargetTimespan()
+	} else if actualTimespan > params.MaxRetargetTimespan() {
+		adjustedTimespan = params.MaxRetargetTimespan()
 	}
 
+	// newTarget = oldTarget * adjustedTimespan / targetTimespan
+	newTarget := blockchain.CompactToBig(bits)
+	newTarget.Mul(newTarget, big.NewInt(adjustedTimespan))
+	newTarget.Div(newTarget, big.NewInt(int64(params.TargetTimespan/time.Second)))
+
 	// Limit new value to the proof of work limit.
-	if newTarget.Cmp(constants.MainPowLimit) > 0 {
-		newTarget.Set(constants.MainPowLimit)
+	if newTarget.Cmp(params.PowLimit) > 0 {
+		newTarget.Set(params.PowLimit)
 	}
 
 	return blockchain.BigToCompact(newTarget)
 }
 
+// getBlockHeaderAncestor walks back the stored headers, starting from header, until it reaches the given height.
+// Returns constants.ErrDataNonExistent if the ancestor is older than the initial block header.
+func getBlockHeaderAncestor(context db.DB, header *definition.BlockHeaderVariable, height uint32) (*definition.BlockHeaderVariable, error) {
+	current := header
+	for current.Height > height {
+		// Once on the main chain, the ancestor can be looked up by height
+		if isOnMainChain(context, current) {
+			return definition.GetMainChainHeaderByHeight(context, height)
+		}
+		prevBlock, err := definition.GetBlockHeaderVariable(context, current.PrevBlock)
+		if err != nil {
+			return nil, err
+		}
+		current = prevBlock
+	}
+	return current, nil
+}
+
+// isOnMainChain reports whether header is part of the best chain
+func isOnMainChain(context db.DB, header *definition.BlockHeaderVariable) bool {
+	hash, err := definition.GetMainChainHash(context, header.Height)
+	if err != nil {
+		if !errors.Is(err, constants.ErrDataNonExistent) {
+			common.DealWithErr(err)
+		}
+		return false
+	}
+	return *hash == header.Hash
+}
+
+// updateMainChain makes tip the last block header of the best chain. The main chain is first rewound above
+// the height of the new tip, then the headers of the new tip are applied down to the common ancestor.
+func updateMainChain(context db.DB, tip *definition.BlockHeaderVariable, oldTipHeight uint32) {
+	for height := oldTipHeight; height > tip.Height; height -= 1 {
+		common.DealWithErr(definition.DeleteMainChainHash(context, height))
+	}
+
+	current := tip
+	for !isOnMainChain(context, current) {
+		common.DealWithErr(definition.SetMainChainHash(context, current.Height, current.Hash))
+		prevBlock, err := definition.GetBlockHeaderVariable(context, current.PrevBlock)
+		if err != nil {
+			if !errors.Is(err, constants.ErrDataNonExistent) {
+				common.DealWithErr(err)
+			}
+			// reached the initial block header
+			break
+		}
+		current = prevBlock
+	}
+}
+
+// pruneStaleForks deletes the block headers in the (fromHeight, toHeight] interval that are not on the best chain.
+// Headers that are MaxReorgDepth blocks behind the tip can't be part of a reorg anymore.
+func pruneStaleForks(context db.DB, fromHeight, toHeight uint32) {
+	for height := fromHeight + 1; height <= toHeight; height += 1 {
+		hashes, err := definition.GetHeaderHashesByHeight(context, height)
+		common.DealWithErr(err)
+		mainChainHash, err := definition.GetMainChainHash(context, height)
+		if err != nil && !errors.Is(err, constants.ErrDataNonExistent) {
+			common.DealWithErr(err)
+		}
+
+		for _, hash := range hashes {
+			if mainChainHash == nil || hash != *mainChainHash {
+				header, err := definition.GetBlockHeaderVariable(context, hash)
+				common.DealWithErr(err)
+				common.DealWithErr(header.Delete(context))
+				mergeMiningLog.Debug("pruned stale block header", "hash", hash, "height", height)
+			}
+			common.DealWithErr(definition.DeleteHeaderHeight(context, height, hash))
+		}
+	}
+}
+
+// GetHeaderConfirmations returns the number of confirmations of a block header, 1 for the tip of the best chain.
+// Headers that are not on the best chain have no confirmations.
+func GetHeaderConfirmations(context db.DB, hash types.Hash) (uint32, error) {
+	header, err := definition.GetBlockHeaderVariable(context, hash)
+	if err != nil {
+		return 0, err
+	}
+	if !isOnMainChain(context, header) {
+		return 0, nil
+	}
+	headerChainInfo, err := definition.GetHeaderChainInfoVariableVariable(context)
+	if err != nil {
+		return 0, err
+	}
+	return headerChainInfo.TipHeight - header.Height + 1, nil
+}
+
+// CalcPastMedianTime returns the median timestamp of the last MedianTimeBlocks headers ending with prevBlock.
+// Close to the initial block header, only the stored headers are taken into account.
+func CalcPastMedianTime(context db.DB, prevBlock *definition.BlockHeaderVariable) uint32 {
+	timestamps := make([]uint32, 0, constants.MedianTimeBlocks)
+	current := prevBlock
+	for len(timestamps) < constants.MedianTimeBlocks {
+		timestamps = append(timestamps, current.Timestamp)
+		ancestor, err := definition.GetBlockHeaderVariable(context, current.PrevBlock)
+		if err != nil {
+			if !errors.Is(err, constants.ErrDataNonExistent) {
+				common.DealWithErr(err)
+			}
+			break
+		}
+		current = ancestor
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+	return timestamps[len(timestamps)/2]
+}
+
+// CheckBlockTimestamp checks the timestamp of a block mined on top of prevBlock, same as Bitcoin does: it has to be
+// after the median time past of prevBlock and at most MaxTimeOffsetSeconds after the frontier momentum.
+func CheckBlockTimestamp(context vm_context.AccountVmContext, prevBlock *definition.BlockHeaderVariable, timestamp uint32) error {
+	momentum, err := context.GetFrontierMomentum()
+	if err != nil {
+		return err
+	}
+	if int64(timestamp) > momentum.Timestamp.Unix()+constants.MaxTimeOffsetSeconds {
+		return constants.ErrTimestampTooFarInFuture
+	}
+	if timestamp <= CalcPastMedianTime(context.Storage(), prevBlock) {
+		return constants.ErrTimestampTooOld
+	}
+	return nil
+}
+
+// CalcNextRequiredDifficulty returns the difficulty bits the block after prevBlock, mined at timestamp, must have.
+// The difficulty only changes every BlocksPerRetarget blocks, based on the timestamps of the
+// first and last block of the previous period.
+func CalcNextRequiredDifficulty(context db.DB, prevBlock *definition.BlockHeaderVariable, timestamp uint32, params *constants.PowParams) (uint32, error) {
+	blocksPerRetarget := params.BlocksPerRetarget()
+	if (prevBlock.Height+1)%blocksPerRetarget != 0 {
+		if params.ReduceMinDifficulty {
+			return calcMinDifficultyBits(context, prevBlock, timestamp, params), nil
+		}
+		return prevBlock.Bits, nil
+	}
+	if params.NoRetargeting {
+		return prevBlock.Bits, nil
+	}
+
+	firstBlock, err := getBlockHeaderAncestor(context, prevBlock, prevBlock.Height+1-blocksPerRetarget)
+	if err != nil {
+		if !errors.Is(err, constants.ErrDataNonExistent) {
+			common.DealWithErr(err)
+		}
+		return 0, constants.ErrRetargetAncestorNonExistent
+	}
+
+	actualTimespan := int64(prevBlock.Timestamp) - int64(firstBlock.Timestamp)
+	return CalcRetargetDifficulty(prevBlock.Bits, actualTimespan, params), nil
+}
+
+// calcMinDifficultyBits applies the testnet rule: a block mined more than MinDiffReductionTime after the previous one
+// can have the minimum difficulty, otherwise it has the difficulty of the last block which was not mined at the minimum one.
+func calcMinDifficultyBits(context db.DB, prevBlock *definition.BlockHeaderVariable, timestamp uint32, params *constants.PowParams) uint32 {
+	if int64(timestamp) > int64(prevBlock.Timestamp)+int64(params.MinDiffReductionTime/time.Second) {
+		return params.PowLimitBits
+	}
+
+	current := prevBlock
+	for current.Height%params.BlocksPerRetarget() != 0 && current.Bits == params.PowLimitBits {
+		previous, err := definition.GetBlockHeaderVariable(context, current.PrevBlock)
+		if err != nil {
+			if !errors.Is(err, constants.ErrDataNonExistent) {
+				common.DealWithErr(err)
+			}
+			// older headers than the initial one are not known
+			break
+		}
+		current = previous
+	}
+	return current.Bits
+}
+
 type AddBitcoinBlockHeaderMethod struct {
 	MethodName string
 }
@@ -295,46 +559,127 @@ func (p *AddBitcoinBlockHeaderMethod) ReceiveBlock(context vm_context.AccountVmC
 		}
 	}
 
-	// ! If the timestamp is in the far future, one could get away with a low difficulty and spam blocks
-
-	// todo better validate timestamp?
-	if param.Timestamp < prevBlock.Timestamp {
-		return nil, constants.ErrForbiddenParam
-	}
-	// todo is this correct?
-	easiestDifficulty := CalcEasiestDifficulty(prevBlock.Bits, time.Duration(int64(param.Timestamp-prevBlock.Timestamp)))
-	if easiestDifficulty > param.Bits {
-		return nil, constants.ErrDifficultyLessThanMin
-	}
-
 	hash := param.BlockHash()
 	if err := param.Hash.SetBytes(hash.Bytes()); err != nil {
 		return nil, constants.ErrForbiddenParam
 	}
 
 	// We do not allow duplicate blocks
-	if _, err = definition.GetBlockHeaderVariable(context.Storage(), hash); err != nil {
-		if !errors.Is(err, constants.ErrDataNonExistent) {
-			common.DealWithErr(err)
-		}
+	if _, err = definition.GetBlockHeaderVariable(context.Storage(), hash); err == nil {
+		return nil, constants.ErrBlockHeaderAlreadyExists
+	} else if !errors.Is(err, constants.ErrDataNonExistent) {
+		common.DealWithErr(err)
 	}
 
-	workSum := big.NewInt(0).Add(prevBlock.WorkSum, blockchain.CalcWork(param.Bits))
-	// We found a block with more accumulated pow then the previous max
-	if workSum.Cmp(headerChainInfo.TipWorkSum) > 0 {
-		headerChainInfo.Tip = hash
-		headerChainInfo.TipHeight = prevBlock.Height + 1
-		headerChainInfo.TipWorkSum.Set(workSum)
-		common.DealWithErr(headerChainInfo.Save(context.Storage()))
+	// Headers that fork the main chain too deep can never become the tip
+	if prevBlock.Height+constants.MaxReorgDepth <= headerChainInfo.TipHeight {
+		return nil, constants.ErrReorgTooDeep
 	}
-	param.WorkSum = big.NewInt(0).Set(workSum)
+
+	// A timestamp far in the future would allow an attacker to lower the difficulty at the next retarget
+	if err := CheckBlockTimestamp(context, prevBlock, param.Timestamp); err != nil {
+		return nil, err
+	}
+
+	requiredBits, err := CalcNextRequiredDifficulty(context.Storage(), prevBlock, param.Timestamp, GetPowParams(context.Storage()))
+	if err != nil {
+		return nil, err
+	}
+	if param.Bits != requiredBits {
+		return nil, constants.ErrInvalidDifficultyBits
+	}
+
+	param.WorkSum = big.NewInt(0).Add(prevBlock.WorkSum, blockchain.CalcWork(param.Bits))
 	param.Height = prevBlock.Height + 1
-	common.DealWithErr(param.Save(context.Storage()))
+	connectBlockHeader(context.Storage(), headerChainInfo, param)
 
 	common.DealWithErr(headerChainInfo.Save(context.Storage()))
 	return nil, nil
 }
 
+// connectBlockHeader stores a validated block header and, if it has more accumulated work than the tip,
+// makes it the new tip of the best chain
+func connectBlockHeader(context db.DB, headerChainInfo *definition.HeaderChainInfoVariable, header *definition.BlockHeaderVariable) {
+	common.DealWithErr(header.Save(context))
+	common.DealWithErr(definition.SaveHeaderHeight(context, header.Height, header.Hash))
+
+	// We found a block with more accumulated pow then the previous max
+	if header.WorkSum.Cmp(headerChainInfo.TipWorkSum) > 0 {
+		oldTipHeight := headerChainInfo.TipHeight
+		headerChainInfo.Tip = header.Hash
+		headerChainInfo.TipHeight = header.Height
+		headerChainInfo.TipWorkSum.Set(header.WorkSum)
+		common.DealWithErr(headerChainInfo.Save(context))
+
+		updateMainChain(context, header, oldTipHeight)
+		if header.Height > oldTipHeight && header.Height > constants.MaxReorgDepth {
+			prunedHeight := uint32(0)
+			if oldTipHeight > constants.MaxReorgDepth {
+				prunedHeight = oldTipHeight - constants.MaxReorgDepth
+			}
+			pruneStaleForks(context, prunedHeight, header.Height-constants.MaxReorgDepth)
+		}
+	}
+}
+
+// toChainHash converts a hash from its displayed (reversed) form, as used by the contract, to a chainhash.Hash
+func toChainHash(hash types.Hash) (*chainhash.Hash, error) {
+	return chainhash.NewHashFromStr(hash.String())
+}
+
+// CheckShareProof verifies that the coinbase transaction of the share commits to the share chain and to address,
+// and that it is the first transaction of the block by following the merkle branch up to the merkle root.
+func CheckShareProof(share *definition.Share, address types.Address) error {
+	reader := bytes.NewReader(share.Coinbase)
+	coinbase := new(wire.MsgTx)
+	if err := coinbase.Deserialize(reader); err != nil || reader.Len() != 0 {
+		return constants.ErrInvalidCoinbase
+	}
+	if !blockchain.IsCoinBaseTx(coinbase) {
+		return constants.ErrInvalidCoinbase
+	}
+
+	commitment := definition.GetShareCommitment(share.ShareChainId, address)
+	if !bytes.Contains(coinbase.TxIn[0].SignatureScript, commitment) {
+		return constants.ErrInvalidShareCommitment
+	}
+
+	// The coinbase is always the left-most leaf
+	return checkMerkleBranch(coinbase.TxHash(), share.MerkleBranch, 0, share.MerkleRoot)
+}
+
+// checkMerkleBranch follows the merkle branch of the leaf at index up to the merkle root.
+// The bits of index tell, level by level, whether the current node is the left or the right child.
+// The last node of a level with an odd number of nodes is paired with itself, as its left child,
+// so a right child equal to its sibling would prove a position that does not exist.
+func checkMerkleBranch(leaf chainhash.Hash, branch []types.Hash, index uint32, root types.Hash) error {
+	if len(branch) > constants.MaxMerkleBranchLength || index>>uint(len(branch)) != 0 {
+		return constants.ErrInvalidMerkleBranch
+	}
+
+	current := leaf
+	for _, hash := range branch {
+		sibling, err := toChainHash(hash)
+		if err != nil {
+			return constants.ErrInvalidMerkleBranch
+		}
+		if index&1 == 0 {
+			current = chainhash.DoubleHashH(append(current[:], sibling[:]...))
+		} else if sibling.IsEqual(&current) {
+			return constants.ErrInvalidMerkleBranch
+		} else {
+			current = chainhash.DoubleHashH(append(sibling[:], current[:]...))
+		}
+		index >>= 1
+	}
+
+	merkleRoot, err := toChainHash(root)
+	if err != nil || !merkleRoot.IsEqual(&current) {
+		return constants.ErrInvalidMerkleBranch
+	}
+	return nil
+}
+
 type AddShareMethod struct {
 	MethodName string
 }
@@ -354,8 +699,18 @@ func (p *AddShareMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrInvalidTokenOrAmount
 	}
 
+	if len(param.Coinbase) > constants.MaxCoinbaseLength {
+		return constants.ErrInvalidCoinbase
+	}
+	if len(param.MerkleBranch) > constants.MaxMerkleBranchLength {
+		return constants.ErrInvalidMerkleBranch
+	}
+	if err := CheckShareProof(param, block.Address); err != nil {
+		return err
+	}
+
 	block.Data, err = definition.ABIMergeMining.PackMethod(p.MethodName, param.ShareChainId, param.Version, param.PrevBlock, param.MerkleRoot, param.Timestamp, param.Nonce,
-		param.Proof, param.Prooff, param.Proofff, param.Prooffff, param.AdditionalData)
+		param.Coinbase, param.MerkleBranch)
 	return err
 }
 func (p *AddShareMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock *nom.AccountBlock) ([]*nom.AccountBlock, error) {
@@ -394,10 +749,16 @@ func (p *AddShareMethod) ReceiveBlock(context vm_context.AccountVmContext, sendB
 		return nil, constants.ErrForbiddenParam
 	}
 
-	// ! If the timestamp is in the far future, one could get away with a low difficulty and spam blocks
-	// todo better validate timestamp?
-	if param.Timestamp < prevBlock.Timestamp {
-		return nil, constants.ErrForbiddenParam
+	// Same rules as for block headers, a timestamp far in the future would allow a share to use a lower difficulty
+	if err := CheckBlockTimestamp(context, prevBlock, param.Timestamp); err != nil {
+		return nil, err
+	}
+
+	// The share is a Bitcoin block candidate on top of prevBlock, so it has the bits required for the next block
+	powParams := GetPowParams(context.Storage())
+	bits, err := CalcNextRequiredDifficulty(context.Storage(), prevBlock, param.Timestamp, powParams)
+	if err != nil {
+		return nil, err
 	}
 
 	shareChainHeader := definition.BlockHeaderVariable{
@@ -406,7 +767,7 @@ func (p *AddShareMethod) ReceiveBlock(context vm_context.AccountVmContext, sendB
 			PrevBlock:  param.PrevBlock,
 			MerkleRoot: param.MerkleRoot,
 			Timestamp:  param.Timestamp,
-			Bits:       prevBlock.Bits,
+			Bits:       bits,
 			Nonce:      param.Nonce,
 		},
 		Height:  0,
@@ -417,11 +778,255 @@ func (p *AddShareMethod) ReceiveBlock(context vm_context.AccountVmContext, sendB
 	if err := CheckProofOfWork(shareChainHeader, shareChainInfo.Bits); err != nil {
 		return nil, err
 	}
+	if err := CheckPowLimit(shareChainInfo.Bits, powParams); err != nil {
+		return nil, err
+	}
+
+	shareHash := shareChainHeader.BlockHash()
+	if exists, err := definition.HasShareHash(context.Storage(), shareHash); err != nil {
+		common.DealWithErr(err)
+	} else if exists {
+		return nil, constants.ErrShareAlreadyExists
+	}
+	common.DealWithErr(definition.SaveShareHash(context.Storage(), shareHash))
 
 	// Add pow to this address
+	momentum, err := context.GetFrontierMomentum()
+	common.DealWithErr(err)
+	epoch := uint64(context.EpochTicker().ToTick(*momentum.Timestamp))
+
+	sharesInfo, err := definition.GetSharesInfo(context.Storage(), epoch, sendBlock.Address)
+	common.DealWithErr(err)
+	weightedWork := GetShareWeightedWork(shareChainInfo)
+	sharesInfo.ShareCount += 1
+	sharesInfo.WeightedWork.Add(sharesInfo.WeightedWork, weightedWork)
+	common.DealWithErr(sharesInfo.Save(context.Storage()))
+
+	mergeMiningLog.Debug("accepted share", "hash", shareHash, "address", sendBlock.Address, "share-chain", param.ShareChainId, "epoch", epoch, "weighted-work", weightedWork)
+	return nil, nil
+}
+
+// GetShareWeightedWork returns the work of a share mined for the given share chain, multiplied by its reward multiplier
+func GetShareWeightedWork(shareChainInfo *definition.ShareChainInfoVariable) *big.Int {
+	work := blockchain.CalcWork(shareChainInfo.Bits)
+	return work.Mul(work, big.NewInt(int64(shareChainInfo.RewardMultiplier)))
+}
+
+// parseTransaction deserializes a Bitcoin transaction and returns its txid.
+// 64 byte transactions are rejected since they can be confused with inner nodes of the merkle tree.
+func parseTransaction(data []byte) (*wire.MsgTx, types.Hash, error) {
+	if len(data) == 64 || len(data) > constants.MaxTransactionLength {
+		return nil, types.ZeroHash, constants.ErrInvalidTransaction
+	}
+	reader := bytes.NewReader(data)
+	transaction := new(wire.MsgTx)
+	if err := transaction.Deserialize(reader); err != nil || reader.Len() != 0 {
+		return nil, types.ZeroHash, constants.ErrInvalidTransaction
+	}
+	return transaction, types.HexToHashPanic(transaction.TxHash().String()), nil
+}
+
+// CheckTransactionProof verifies that the transaction is included in a stored block header
+// and returns the verification record together with the confirmations of the block
+func CheckTransactionProof(context db.DB, proof *definition.TransactionProof) (*definition.VerifiedTransaction, uint32, error) {
+	transaction, txId, err := parseTransaction(proof.Transaction)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	header, err := definition.GetBlockHeaderVariable(context, proof.BlockHash)
+	if err != nil {
+		if !errors.Is(err, constants.ErrDataNonExistent) {
+			return nil, 0, err
+		}
+		return nil, 0, constants.ErrBlockHeaderNonExistent
+	}
+
+	// Witness data is not committed in the merkle root, the txid is
+	if err := checkMerkleBranch(transaction.TxHash(), proof.MerkleBranch, proof.Index, header.MerkleRoot); err != nil {
+		return nil, 0, err
+	}
+
+	confirmations, err := GetHeaderConfirmations(context, proof.BlockHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &definition.VerifiedTransaction{
+		TxId:        txId,
+		BlockHash:   proof.BlockHash,
+		BlockHeight: header.Height,
+		Index:       proof.Index,
+	}, confirmations, nil
+}
+
+type VerifyTransactionMethod struct {
+	MethodName string
+}
+
+func (p *VerifyTransactionMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
+	return plasmaTable.EmbeddedSimple, nil
+}
+func (p *VerifyTransactionMethod) ValidateSendBlock(block *nom.AccountBlock) error {
+	var err error
+	param := new(definition.TransactionProof)
+
+	if err = definition.ABIMergeMining.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return constants.ErrUnpackError
+	}
+
+	if block.Amount.Sign() > 0 {
+		return constants.ErrInvalidTokenOrAmount
+	}
+
+	if _, _, err := parseTransaction(param.Transaction); err != nil {
+		return err
+	}
+	if len(param.MerkleBranch) > constants.MaxMerkleBranchLength {
+		return constants.ErrInvalidMerkleBranch
+	}
+
+	block.Data, err = definition.ABIMergeMining.PackMethod(p.MethodName, param.Transaction, param.MerkleBranch, param.Index, param.BlockHash)
+	return err
+}
+func (p *VerifyTransactionMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock *nom.AccountBlock) ([]*nom.AccountBlock, error) {
+	if err := p.ValidateSendBlock(sendBlock); err != nil {
+		return nil, err
+	}
+
+	if _, _, err := CanPerformActionMergeMining(context); err != nil {
+		return nil, err
+	}
+
+	param := new(definition.TransactionProof)
+	err := definition.ABIMergeMining.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	common.DealWithErr(err)
+
+	verified, confirmations, err := CheckTransactionProof(context.Storage(), param)
+	if err != nil {
+		return nil, err
+	}
+	if confirmations < constants.TransactionConfirmations {
+		return nil, constants.ErrInsufficientConfirmations
+	}
+
+	if _, err := definition.GetVerifiedTransaction(context.Storage(), verified.TxId); err == nil {
+		return nil, constants.ErrTransactionAlreadyVerified
+	} else if !errors.Is(err, constants.ErrDataNonExistent) {
+		common.DealWithErr(err)
+	}
+
+	momentum, err := context.GetFrontierMomentum()
+	common.DealWithErr(err)
+	verified.MomentumHeight = momentum.Height
+	common.DealWithErr(verified.Save(context.Storage()))
+
+	mergeMiningLog.Debug("verified transaction", "tx-id", verified.TxId, "block-hash", verified.BlockHash, "confirmations", confirmations)
 	return nil, nil
 }
 
+type UpdateEmbeddedMergeMiningMethod struct {
+	MethodName string
+}
+
+func (p *UpdateEmbeddedMergeMiningMethod) GetPlasma(plasmaTable *constants.PlasmaTable) (uint64, error) {
+	return plasmaTable.EmbeddedSimple, nil
+}
+func (p *UpdateEmbeddedMergeMiningMethod) ValidateSendBlock(block *nom.AccountBlock) error {
+	var err error
+
+	if err := definition.ABIMergeMining.UnpackEmptyMethod(p.MethodName, block.Data); err != nil {
+		return constants.ErrUnpackError
+	}
+
+	if block.Amount.Sign() != 0 {
+		return constants.ErrInvalidTokenOrAmount
+	}
+
+	block.Data, err = definition.ABIMergeMining.PackMethod(p.MethodName)
+	return err
+}
+func (p *UpdateEmbeddedMergeMiningMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock *nom.AccountBlock) ([]*nom.AccountBlock, error) {
+	if err := p.ValidateSendBlock(sendBlock); err != nil {
+		return nil, err
+	}
+
+	if err := checkAndPerformUpdate(context); err != nil {
+		return nil, err
+	}
+
+	err := updateMergeMiningRewards(context)
+	return nil, err
+}
+
+// computeMergeMiningRewardsForEpoch pays the reward of epoch, together with the reward of the previous epochs without
+// shares, to the addresses with shares in epoch. The reward of an epoch without shares is kept for the next one.
+func computeMergeMiningRewardsForEpoch(context vm_context.AccountVmContext, epoch uint64) error {
+	epochZnn, epochQsr := constants.MergeMiningRewardForEpoch(epoch)
+	totalZnn, totalQsr, err := definition.GetUnpaidReward(context.Storage())
+	if err != nil {
+		return err
+	}
+	totalZnn.Add(totalZnn, epochZnn)
+	totalQsr.Add(totalQsr, epochQsr)
+	cumulatedWork := big.NewInt(0)
+
+	err = definition.IterateSharesInfoByEpoch(context.Storage(), epoch, func(sharesInfo *definition.SharesInfo) error {
+		cumulatedWork.Add(cumulatedWork, sharesInfo.WeightedWork)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	mergeMiningLog.Debug("updating merge mining reward", "epoch", epoch, "znn-total-amount", totalZnn, "qsr-total-amount", totalQsr, "cumulated-work", cumulatedWork)
+	if cumulatedWork.Sign() == 0 {
+		return definition.SaveUnpaidReward(context.Storage(), totalZnn, totalQsr)
+	}
+	if err := definition.SaveUnpaidReward(context.Storage(), big.NewInt(0), big.NewInt(0)); err != nil {
+		return err
+	}
+
+	return definition.IterateSharesInfoByEpoch(context.Storage(), epoch, func(sharesInfo *definition.SharesInfo) error {
+		znnReward := new(big.Int).Mul(totalZnn, sharesInfo.WeightedWork)
+		znnReward.Quo(znnReward, cumulatedWork)
+		qsrReward := new(big.Int).Mul(totalQsr, sharesInfo.WeightedWork)
+		qsrReward.Quo(qsrReward, cumulatedWork)
+
+		address := sharesInfo.Address
+		addReward(context, epoch, definition.RewardDeposit{
+			Address: &address,
+			Znn:     znnReward,
+			Qsr:     qsrReward,
+		})
+
+		mergeMiningLog.Debug("giving rewards", "address", address, "epoch", epoch, "znn-amount", znnReward, "qsr-amount", qsrReward)
+		return nil
+	})
+}
+
+func updateMergeMiningRewards(context vm_context.AccountVmContext) error {
+	lastEpoch, err := definition.GetLastEpochUpdate(context.Storage())
+	if err != nil {
+		return err
+	}
+
+	for numEpochs := 0; ; numEpochs += 1 {
+		if numEpochs >= constants.MaxEpochsPerUpdate {
+			return nil
+		}
+		if err := checkAndPerformUpdateEpoch(context, lastEpoch); err == constants.ErrEpochUpdateTooRecent {
+			mergeMiningLog.Debug("invalid update - rewards not due yet", "epoch", lastEpoch.LastEpoch+1)
+			return nil
+		} else if err != nil {
+			mergeMiningLog.Error("unknown panic", "reason", err)
+			return err
+		}
+		if err := computeMergeMiningRewardsForEpoch(context, uint64(lastEpoch.LastEpoch)); err != nil {
+			return err
+		}
+	}
+}
+
 type NominateGuardiansMergeMiningMethod struct {
 	MethodName string
 }
@@ -0,0 +1,383 @@
+package implementation
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+var (
+	mainNet           = constants.MainNetPowParams
+	targetTimespan    = int64(mainNet.TargetTimespan / time.Second)
+	blocksPerRetarget = mainNet.BlocksPerRetarget()
+)
+
+// saveHeaderChain stores count headers linked through PrevBlock, starting at startHeight,
+// each one mined interval seconds after the previous one. Returns the last header.
+func saveHeaderChain(context db.DB, startHeight uint32, count uint32, bits uint32, interval uint32) *definition.BlockHeaderVariable {
+	var last *definition.BlockHeaderVariable
+	prevBlock := types.ZeroHash
+	for i := uint32(0); i < count; i += 1 {
+		header := &definition.BlockHeaderVariable{
+			BaseHeader: definition.BaseHeader{
+				PrevBlock: prevBlock,
+				Timestamp: 1712573645 + i*interval,
+				Bits:      bits,
+			},
+			Height:  startHeight + i,
+			WorkSum: big.NewInt(0),
+			Hash:    types.NewHash(big.NewInt(int64(startHeight + i + 1)).Bytes()),
+		}
+		common.DealWithErr(header.Save(context))
+		prevBlock = header.Hash
+		last = header
+	}
+	return last
+}
+
+func TestMergeMining_CalcRetargetDifficulty(t *testing.T) {
+	// mined exactly in the target timespan
+	common.ExpectUint64(t, uint64(CalcRetargetDifficulty(0x170331db, targetTimespan, mainNet)), 0x170331db)
+	// mined twice as fast, the target halves
+	common.ExpectUint64(t, uint64(CalcRetargetDifficulty(0x170331db, targetTimespan/2, mainNet)), 0x170198ed)
+	// the adjustment is limited to RetargetAdjustmentFactor
+	common.ExpectUint64(t, uint64(CalcRetargetDifficulty(0x170331db, targetTimespan/100, mainNet)), uint64(CalcRetargetDifficulty(0x170331db, targetTimespan/4, mainNet)))
+	common.ExpectUint64(t, uint64(CalcRetargetDifficulty(0x170331db, targetTimespan*100, mainNet)), uint64(CalcRetargetDifficulty(0x170331db, targetTimespan*4, mainNet)))
+	// the target can't go over the pow limit
+	common.ExpectUint64(t, uint64(CalcRetargetDifficulty(0x1d00ffff, targetTimespan*4, mainNet)), 0x1d00ffff)
+	common.ExpectUint64(t, uint64(CalcRetargetDifficulty(0x207fffff, targetTimespan*4, constants.RegTestPowParams)), 0x207fffff)
+}
+
+func TestMergeMining_CalcNextRequiredDifficulty(t *testing.T) {
+	context := db.DisableNotFound(db.NewMemDB())
+	// a full retarget period mined twice as fast as the target
+	interval := uint32(targetTimespan/int64(blocksPerRetarget)) / 2
+	prevBlock := saveHeaderChain(context, 2*blocksPerRetarget, blocksPerRetarget, 0x170331db, interval)
+
+	bits, err := CalcNextRequiredDifficulty(context, prevBlock, prevBlock.Timestamp+interval, mainNet)
+	common.ExpectError(t, err, nil)
+	expected := CalcRetargetDifficulty(0x170331db, int64((blocksPerRetarget-1)*interval), mainNet)
+	common.ExpectUint64(t, uint64(bits), uint64(expected))
+
+	// regtest never retargets
+	bits, err = CalcNextRequiredDifficulty(context, prevBlock, prevBlock.Timestamp+interval, constants.RegTestPowParams)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(bits), 0x170331db)
+
+	// inside a retarget period the difficulty does not change
+	ancestor, err := getBlockHeaderAncestor(context, prevBlock, prevBlock.Height-1)
+	common.ExpectError(t, err, nil)
+	bits, err = CalcNextRequiredDifficulty(context, ancestor, ancestor.Timestamp+interval, mainNet)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(bits), 0x170331db)
+}
+
+func TestMergeMining_CalcNextRequiredDifficultyMissingAncestor(t *testing.T) {
+	context := db.DisableNotFound(db.NewMemDB())
+	// the header chain was initialized in the middle of a retarget period
+	prevBlock := saveHeaderChain(context, 2*blocksPerRetarget+10, blocksPerRetarget-10, 0x170331db, 600)
+
+	_, err := CalcNextRequiredDifficulty(context, prevBlock, prevBlock.Timestamp+600, mainNet)
+	common.ExpectError(t, err, constants.ErrRetargetAncestorNonExistent)
+}
+
+func TestMergeMining_CalcNextRequiredDifficultyMinDifficulty(t *testing.T) {
+	testNet := constants.TestNet3PowParams
+	minDiffReduction := uint32(testNet.MinDiffReductionTime / time.Second)
+	context := db.DisableNotFound(db.NewMemDB())
+	prevBlock := saveHeaderChain(context, 100, 5, 0x1c0fffff, 600)
+
+	// a block mined long enough after the previous one can have the minimum difficulty
+	bits, err := CalcNextRequiredDifficulty(context, prevBlock, prevBlock.Timestamp+minDiffReduction+1, testNet)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(bits), uint64(testNet.PowLimitBits))
+	bits, err = CalcNextRequiredDifficulty(context, prevBlock, prevBlock.Timestamp+minDiffReduction, testNet)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(bits), 0x1c0fffff)
+
+	// otherwise it has the difficulty of the last block not mined at the minimum difficulty
+	minDifficultyBlock := &definition.BlockHeaderVariable{
+		BaseHeader: definition.BaseHeader{
+			PrevBlock: prevBlock.Hash,
+			Timestamp: prevBlock.Timestamp + minDiffReduction + 1,
+			Bits:      testNet.PowLimitBits,
+		},
+		Height:  prevBlock.Height + 1,
+		WorkSum: big.NewInt(0),
+		Hash:    types.NewHash(big.NewInt(1000).Bytes()),
+	}
+	common.DealWithErr(minDifficultyBlock.Save(context))
+	bits, err = CalcNextRequiredDifficulty(context, minDifficultyBlock, minDifficultyBlock.Timestamp+600, testNet)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(bits), 0x1c0fffff)
+
+	// mainnet has no such rule
+	bits, err = CalcNextRequiredDifficulty(context, prevBlock, prevBlock.Timestamp+minDiffReduction+1, mainNet)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(bits), 0x1c0fffff)
+}
+
+func TestMergeMining_CheckPowLimit(t *testing.T) {
+	common.ExpectError(t, CheckPowLimit(0x1d00ffff, mainNet), nil)
+	common.ExpectError(t, CheckPowLimit(0x207fffff, mainNet), constants.ErrPowLimitExceeded)
+	common.ExpectError(t, CheckPowLimit(0x207fffff, constants.RegTestPowParams), nil)
+}
+
+func TestMergeMining_CalcPastMedianTime(t *testing.T) {
+	context := db.DisableNotFound(db.NewMemDB())
+	prevBlock := saveHeaderChain(context, 100, 20, 0x170331db, 600)
+	// median of the last 11 timestamps
+	common.ExpectUint64(t, uint64(CalcPastMedianTime(context, prevBlock)), uint64(prevBlock.Timestamp-5*600))
+
+	// only the stored headers are used close to the initial block
+	context = db.DisableNotFound(db.NewMemDB())
+	prevBlock = saveHeaderChain(context, 100, 3, 0x170331db, 600)
+	common.ExpectUint64(t, uint64(CalcPastMedianTime(context, prevBlock)), uint64(prevBlock.Timestamp-600))
+}
+
+// initHeaderChain stores the initial block header as the tip of the best chain, the way SetInitialBitcoinBlockHeader does
+func initHeaderChain(context db.DB, height uint32, bits uint32) (*definition.HeaderChainInfoVariable, *definition.BlockHeaderVariable) {
+	header := &definition.BlockHeaderVariable{
+		BaseHeader: definition.BaseHeader{Bits: bits},
+		Height:     height,
+		WorkSum:    blockchain.CalcWork(bits),
+		Hash:       types.NewHash(big.NewInt(int64(height)).Bytes()),
+	}
+	common.DealWithErr(header.Save(context))
+	common.DealWithErr(definition.SaveHeaderHeight(context, header.Height, header.Hash))
+	common.DealWithErr(definition.SetMainChainHash(context, header.Height, header.Hash))
+
+	headerChainInfo := &definition.HeaderChainInfoVariable{
+		Tip:        header.Hash,
+		TipHeight:  header.Height,
+		TipWorkSum: new(big.Int).Set(header.WorkSum),
+	}
+	common.DealWithErr(headerChainInfo.Save(context))
+	return headerChainInfo, header
+}
+
+func forkHeaderHash(fork byte, height uint32) types.Hash {
+	return types.NewHash(append([]byte{fork}, big.NewInt(int64(height)).Bytes()...))
+}
+
+// connectHeaderChain connects count headers on top of prevBlock, fork differentiates the hashes of competing chains
+func connectHeaderChain(context db.DB, headerChainInfo *definition.HeaderChainInfoVariable, prevBlock *definition.BlockHeaderVariable, count uint32, bits uint32, fork byte) *definition.BlockHeaderVariable {
+	for i := uint32(0); i < count; i += 1 {
+		header := &definition.BlockHeaderVariable{
+			BaseHeader: definition.BaseHeader{
+				PrevBlock: prevBlock.Hash,
+				Bits:      bits,
+			},
+			Height:  prevBlock.Height + 1,
+			WorkSum: new(big.Int).Add(prevBlock.WorkSum, blockchain.CalcWork(bits)),
+			Hash:    forkHeaderHash(fork, prevBlock.Height+1),
+		}
+		connectBlockHeader(context, headerChainInfo, header)
+		prevBlock = header
+	}
+	return prevBlock
+}
+
+func expectConfirmations(t *testing.T, context db.DB, hash types.Hash, expected uint32) {
+	confirmations, err := GetHeaderConfirmations(context, hash)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(confirmations), uint64(expected))
+}
+
+func TestMergeMining_MainChainReorg(t *testing.T) {
+	context := db.DisableNotFound(db.NewMemDB())
+	headerChainInfo, initial := initHeaderChain(context, 1000, 0x207fffff)
+
+	mainTip := connectHeaderChain(context, headerChainInfo, initial, 3, 0x207fffff, 1)
+	forkPoint, err := definition.GetMainChainHeaderByHeight(context, 1001)
+	common.ExpectError(t, err, nil)
+	expectConfirmations(t, context, mainTip.Hash, 1)
+	expectConfirmations(t, context, initial.Hash, 4)
+
+	// a fork with the same accumulated work does not replace the tip
+	forkTip := connectHeaderChain(context, headerChainInfo, forkPoint, 2, 0x207fffff, 2)
+	common.ExpectString(t, headerChainInfo.Tip.String(), mainTip.Hash.String())
+	expectConfirmations(t, context, forkTip.Hash, 0)
+
+	// once it has more work, the main chain is reapplied from the fork point
+	forkTip = connectHeaderChain(context, headerChainInfo, forkTip, 1, 0x207fffff, 2)
+	common.ExpectString(t, headerChainInfo.Tip.String(), forkTip.Hash.String())
+	common.ExpectUint64(t, uint64(headerChainInfo.TipHeight), 1004)
+	expectConfirmations(t, context, mainTip.Hash, 0)
+	expectConfirmations(t, context, forkTip.Hash, 1)
+	expectConfirmations(t, context, forkPoint.Hash, 4)
+	for height := uint32(1002); height <= 1004; height += 1 {
+		header, err := definition.GetMainChainHeaderByHeight(context, height)
+		common.ExpectError(t, err, nil)
+		common.ExpectString(t, header.Hash.String(), forkHeaderHash(2, height).String())
+	}
+
+	// a shorter fork with more work rewinds the main chain above its tip
+	heavyTip := connectHeaderChain(context, headerChainInfo, forkPoint, 1, 0x1d00ffff, 3)
+	common.ExpectString(t, headerChainInfo.Tip.String(), heavyTip.Hash.String())
+	common.ExpectUint64(t, uint64(headerChainInfo.TipHeight), 1002)
+	_, err = definition.GetMainChainHash(context, 1003)
+	common.ExpectError(t, err, constants.ErrDataNonExistent)
+	_, err = definition.GetMainChainHash(context, 1004)
+	common.ExpectError(t, err, constants.ErrDataNonExistent)
+	expectConfirmations(t, context, forkTip.Hash, 0)
+	expectConfirmations(t, context, initial.Hash, 3)
+
+	// ancestors on the main chain are looked up by height
+	ancestor, err := getBlockHeaderAncestor(context, heavyTip, 1000)
+	common.ExpectError(t, err, nil)
+	common.ExpectString(t, ancestor.Hash.String(), initial.Hash.String())
+}
+
+func TestMergeMining_PruneStaleForks(t *testing.T) {
+	context := db.DisableNotFound(db.NewMemDB())
+	headerChainInfo, initial := initHeaderChain(context, 1000, 0x207fffff)
+
+	forkPoint := connectHeaderChain(context, headerChainInfo, initial, 1, 0x207fffff, 1)
+	staleTip := connectHeaderChain(context, headerChainInfo, forkPoint, 2, 0x207fffff, 2)
+	mainTip := connectHeaderChain(context, headerChainInfo, forkPoint, 3, 0x207fffff, 1)
+	common.ExpectString(t, headerChainInfo.Tip.String(), mainTip.Hash.String())
+
+	// the stale fork is kept while it could still be part of a reorg
+	mainTip = connectHeaderChain(context, headerChainInfo, mainTip, constants.MaxReorgDepth-2, 0x207fffff, 1)
+	_, err := definition.GetBlockHeaderVariable(context, staleTip.Hash)
+	common.ExpectError(t, err, nil)
+
+	mainTip = connectHeaderChain(context, headerChainInfo, mainTip, 1, 0x207fffff, 1)
+	_, err = definition.GetBlockHeaderVariable(context, staleTip.Hash)
+	common.ExpectError(t, err, constants.ErrDataNonExistent)
+	hashes, err := definition.GetHeaderHashesByHeight(context, staleTip.Height)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(len(hashes)), 0)
+
+	// headers of the main chain are not pruned
+	header, err := definition.GetMainChainHeaderByHeight(context, staleTip.Height)
+	common.ExpectError(t, err, nil)
+	expectConfirmations(t, context, header.Hash, constants.MaxReorgDepth+1)
+}
+
+// newTransaction returns a serialized transaction spending a made up output, value differentiates transactions
+func newTransaction(value int64) []byte {
+	transaction := wire.NewMsgTx(wire.TxVersion)
+	transaction.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), []byte{0x51}, nil))
+	transaction.AddTxOut(wire.NewTxOut(value, []byte{0x51}))
+	var buffer bytes.Buffer
+	common.DealWithErr(transaction.Serialize(&buffer))
+	return buffer.Bytes()
+}
+
+// merkleBranch returns the merkle root of leaves and the merkle branch of the leaf at index
+func merkleBranch(leaves []chainhash.Hash, index uint32) (types.Hash, []types.Hash) {
+	level := append([]chainhash.Hash{}, leaves...)
+	branch := make([]types.Hash, 0)
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		branch = append(branch, types.HexToHashPanic(level[index^1].String()))
+		next := make([]chainhash.Hash, 0, len(level)/2)
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, chainhash.DoubleHashH(append(level[i][:], level[i+1][:]...)))
+		}
+		level = next
+		index >>= 1
+	}
+	return types.HexToHashPanic(level[0].String()), branch
+}
+
+func TestMergeMining_CheckMerkleBranch(t *testing.T) {
+	leaves := make([]chainhash.Hash, 5)
+	for i := range leaves {
+		_, txId, err := parseTransaction(newTransaction(int64(i + 1)))
+		common.FailIfErr(t, err)
+		leaf, err := toChainHash(txId)
+		common.FailIfErr(t, err)
+		leaves[i] = *leaf
+	}
+
+	for index := uint32(0); index < uint32(len(leaves)); index += 1 {
+		root, branch := merkleBranch(leaves, index)
+		common.ExpectError(t, checkMerkleBranch(leaves[index], branch, index, root), nil)
+		// the branch only proves the position it was built for
+		common.ExpectError(t, checkMerkleBranch(leaves[index], branch, index^1, root), constants.ErrInvalidMerkleBranch)
+		// index bits beyond the depth of the tree are not allowed
+		common.ExpectError(t, checkMerkleBranch(leaves[index], branch, index+8, root), constants.ErrInvalidMerkleBranch)
+	}
+
+	// a single transaction is the merkle root
+	common.ExpectError(t, checkMerkleBranch(leaves[0], nil, 0, types.HexToHashPanic(leaves[0].String())), nil)
+
+	_, _, err := parseTransaction(make([]byte, 64))
+	common.ExpectError(t, err, constants.ErrInvalidTransaction)
+	_, _, err = parseTransaction(append(newTransaction(1), 0))
+	common.ExpectError(t, err, constants.ErrInvalidTransaction)
+}
+
+func TestMergeMining_CheckTransactionProof(t *testing.T) {
+	context := db.DisableNotFound(db.NewMemDB())
+	headerChainInfo, initial := initHeaderChain(context, 1000, 0x207fffff)
+
+	transactions := [][]byte{newTransaction(1), newTransaction(2), newTransaction(3)}
+	leaves := make([]chainhash.Hash, len(transactions))
+	for i, transaction := range transactions {
+		_, txId, err := parseTransaction(transaction)
+		common.FailIfErr(t, err)
+		leaves[i] = *blockchainHash(txId)
+	}
+	root, branch := merkleBranch(leaves, 2)
+
+	header := &definition.BlockHeaderVariable{
+		BaseHeader: definition.BaseHeader{
+			PrevBlock:  initial.Hash,
+			MerkleRoot: root,
+			Bits:       0x207fffff,
+		},
+		Height:  initial.Height + 1,
+		WorkSum: new(big.Int).Add(initial.WorkSum, blockchain.CalcWork(0x207fffff)),
+		Hash:    forkHeaderHash(1, initial.Height+1),
+	}
+	connectBlockHeader(context, headerChainInfo, header)
+	connectHeaderChain(context, headerChainInfo, header, 4, 0x207fffff, 1)
+
+	proof := &definition.TransactionProof{
+		Transaction:  transactions[2],
+		MerkleBranch: branch,
+		Index:        2,
+		BlockHash:    header.Hash,
+	}
+	verified, confirmations, err := CheckTransactionProof(context, proof)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, uint64(confirmations), 5)
+	common.ExpectString(t, verified.TxId.String(), leaves[2].String())
+	common.ExpectUint64(t, uint64(verified.BlockHeight), 1001)
+
+	// a stale fork gives no confirmations
+	connectHeaderChain(context, headerChainInfo, initial, 6, 0x207fffff, 2)
+	_, confirmations, err = CheckTransactionProof(context, proof)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, uint64(confirmations), 0)
+
+	proof.Transaction = transactions[1]
+	_, _, err = CheckTransactionProof(context, proof)
+	common.ExpectError(t, err, constants.ErrInvalidMerkleBranch)
+
+	proof.BlockHash = forkHeaderHash(3, 1001)
+	_, _, err = CheckTransactionProof(context, proof)
+	common.ExpectError(t, err, constants.ErrBlockHeaderNonExistent)
+}
+
+func blockchainHash(hash types.Hash) *chainhash.Hash {
+	h, err := toChainHash(hash)
+	common.DealWithErr(err)
+	return h
+}
@@ -0,0 +1,527 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	etypes "github.com/ethereum/go-ethereum/core/types"
+	evm "github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	eabi "github.com/ethereum/go-ethereum/accounts/abi"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// The reference bridge contract deployed on the simulated EVM chain. It keeps the balances of the wrapped tokens
+// itself instead of minting ERC20 tokens, which is enough to check that both sides agree on the messages signed by
+// the TSS. There is no Solidity compiler in the build environment, so the contract is assembled below from the
+// opcodes of go-ethereum and its interface is described by referenceBridgeABI.
+const referenceBridgeABI = `[
+	{"type":"function","name":"redeem","stateMutability":"nonpayable","inputs":[{"name":"id","type":"uint256"},{"name":"to","type":"address"},{"name":"token","type":"address"},{"name":"amount","type":"uint256"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"outputs":[]},
+	{"type":"function","name":"unwrap","stateMutability":"nonpayable","inputs":[{"name":"token","type":"address"},{"name":"amount","type":"uint256"},{"name":"to","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"setTss","stateMutability":"nonpayable","inputs":[{"name":"newTss","type":"address"},{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"outputs":[]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"token","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"tss","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"nonce","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"event","name":"Unwrapped","anonymous":false,"inputs":[{"name":"to","type":"uint256","indexed":false},{"name":"token","type":"address","indexed":false},{"name":"amount","type":"uint256","indexed":false}]}
+]`
+
+// Storage layout of the reference bridge contract
+const (
+	referenceTssSlot    = 0 // address of the TSS
+	referenceNonceSlot  = 1 // number of TSS rotations
+	referenceRedeemSlot = 2 // keccak256(id, 2) is set once a wrap request is redeemed
+)
+
+var (
+	// The chain id of the go-ethereum simulated backend
+	simulatedChainId = uint32(1337)
+	// "\x19Ethereum Signed Message:\n32" left aligned in a word
+	evmMessagePrefix = ecommon.RightPadBytes([]byte("\x19Ethereum Signed Message:\n32"), 32)
+)
+
+// evmAssembler builds EVM bytecode, resolving the jump labels once the code is complete
+type evmAssembler struct {
+	code   []byte
+	labels map[string]int
+	jumps  map[int]string
+}
+
+func newEvmAssembler() *evmAssembler {
+	return &evmAssembler{
+		labels: make(map[string]int),
+		jumps:  make(map[int]string),
+	}
+}
+
+func (a *evmAssembler) op(ops ...evm.OpCode) *evmAssembler {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+	return a
+}
+
+// push uses the smallest PUSH which fits value
+func (a *evmAssembler) push(value []byte) *evmAssembler {
+	value = new(big.Int).SetBytes(value).Bytes()
+	if len(value) == 0 {
+		value = []byte{0}
+	}
+	a.code = append(a.code, byte(evm.PUSH1)+byte(len(value)-1))
+	a.code = append(a.code, value...)
+	return a
+}
+
+func (a *evmAssembler) pushUint(value uint64) *evmAssembler {
+	return a.push(new(big.Int).SetUint64(value).Bytes())
+}
+
+// push2 always uses PUSH2, for values which must not change the size of the code
+func (a *evmAssembler) push2(value uint64) *evmAssembler {
+	a.code = append(a.code, byte(evm.PUSH2), byte(value>>8), byte(value))
+	return a
+}
+
+// calldata pushes the index-th word argument of the call
+func (a *evmAssembler) calldata(index uint64) *evmAssembler {
+	return a.pushUint(4 + 32*index).op(evm.CALLDATALOAD)
+}
+
+// mstore stores the value computed by emit at offset
+func (a *evmAssembler) mstore(offset uint64, emit func()) *evmAssembler {
+	emit()
+	return a.pushUint(offset).op(evm.MSTORE)
+}
+
+func (a *evmAssembler) label(name string) *evmAssembler {
+	a.labels[name] = len(a.code)
+	return a.op(evm.JUMPDEST)
+}
+
+func (a *evmAssembler) pushLabel(name string) *evmAssembler {
+	a.jumps[len(a.code)+1] = name
+	return a.push2(0)
+}
+
+// jumpIf jumps to label if the value on top of the stack is not zero
+func (a *evmAssembler) jumpIf(name string) *evmAssembler {
+	return a.pushLabel(name).op(evm.JUMPI)
+}
+
+// returnWord returns the value on top of the stack
+func (a *evmAssembler) returnWord() *evmAssembler {
+	a.pushUint(0).op(evm.MSTORE)
+	return a.pushUint(32).pushUint(0).op(evm.RETURN)
+}
+
+// checkTssSignature reverts unless the (v, r, s) arguments are the signature of the TSS for the message of size bytes
+// stored at offset. The message is hashed exactly like HashByNetworkClass does for the EVM class.
+func (a *evmAssembler) checkTssSignature(offset, size, v, r, s uint64) *evmAssembler {
+	a.pushUint(size).pushUint(offset).op(evm.KECCAK256)
+	a.mstore(0x200, func() { a.push(evmMessagePrefix) })
+	a.pushUint(0x21c).op(evm.MSTORE)
+	a.mstore(0x300, func() { a.pushUint(60).pushUint(0x200).op(evm.KECCAK256) })
+	// v is either 0/1 or 27/28
+	a.mstore(0x320, func() {
+		a.calldata(v).pushUint(27).op(evm.DUP2, evm.LT).pushUint(27).op(evm.MUL, evm.ADD)
+	})
+	a.mstore(0x340, func() { a.calldata(r) })
+	a.mstore(0x360, func() { a.calldata(s) })
+	a.mstore(0x400, func() { a.pushUint(0) })
+	// ecrecover precompile
+	a.pushUint(32).pushUint(0x400).pushUint(128).pushUint(0x300).pushUint(1).op(evm.GAS, evm.STATICCALL, evm.ISZERO)
+	a.jumpIf("revert")
+	a.pushUint(0x400).op(evm.MLOAD).pushUint(referenceTssSlot).op(evm.SLOAD, evm.EQ, evm.ISZERO)
+	return a.jumpIf("revert")
+}
+
+func (a *evmAssembler) assemble() []byte {
+	code := make([]byte, len(a.code))
+	copy(code, a.code)
+	for position, name := range a.jumps {
+		target, ok := a.labels[name]
+		if !ok {
+			panic(errors.Errorf("unknown label %v", name))
+		}
+		code[position] = byte(target >> 8)
+		code[position+1] = byte(target)
+	}
+	return code
+}
+
+// referenceBridgeCode returns the creation code of the reference bridge contract with tss as the initial TSS
+func referenceBridgeCode(contractABI eabi.ABI, tss ecommon.Address) []byte {
+	a := newEvmAssembler()
+
+	// dispatch by selector
+	a.pushUint(0).op(evm.CALLDATALOAD).pushUint(0xe0).op(evm.SHR)
+	for _, name := range []string{"redeem", "unwrap", "setTss", "balanceOf", "tss", "nonce"} {
+		a.op(evm.DUP1).push(contractABI.Methods[name].ID).op(evm.EQ).jumpIf(name)
+	}
+	a.label("revert").pushUint(0).op(evm.DUP1, evm.REVERT)
+
+	// redeem(id, to, token, amount, v, r, s)
+	a.label("redeem")
+	a.mstore(0x100, func() { a.pushUint(uint64(definition.EvmClass)) })
+	a.mstore(0x120, func() { a.op(evm.CHAINID) })
+	a.mstore(0x140, func() { a.op(evm.ADDRESS) })
+	for i := uint64(0); i < 4; i++ {
+		i := i
+		a.mstore(0x160+32*i, func() { a.calldata(i) })
+	}
+	a.checkTssSignature(0x100, 224, 4, 5, 6)
+	// each wrap request can be redeemed only once
+	a.mstore(0, func() { a.calldata(0) })
+	a.mstore(32, func() { a.pushUint(referenceRedeemSlot) })
+	a.pushUint(64).pushUint(0).op(evm.KECCAK256, evm.DUP1, evm.SLOAD).jumpIf("revert")
+	a.pushUint(1).op(evm.SWAP1, evm.SSTORE)
+	// balances[to][token] += amount
+	a.mstore(0, func() { a.calldata(1) })
+	a.mstore(32, func() { a.calldata(2) })
+	a.pushUint(64).pushUint(0).op(evm.KECCAK256, evm.DUP1, evm.SLOAD).calldata(3).op(evm.ADD, evm.SWAP1, evm.SSTORE, evm.STOP)
+
+	// unwrap(token, amount, to)
+	a.label("unwrap")
+	a.mstore(0, func() { a.op(evm.CALLER) })
+	a.mstore(32, func() { a.calldata(0) })
+	a.pushUint(64).pushUint(0).op(evm.KECCAK256, evm.DUP1, evm.SLOAD).calldata(1)
+	a.op(evm.DUP1, evm.DUP3, evm.LT).jumpIf("revert")
+	a.op(evm.SWAP1, evm.SUB, evm.SWAP1, evm.SSTORE)
+	a.mstore(0x100, func() { a.calldata(2) })
+	a.mstore(0x120, func() { a.calldata(0) })
+	a.mstore(0x140, func() { a.calldata(1) })
+	a.push(contractABI.Events["Unwrapped"].ID.Bytes()).pushUint(96).pushUint(0x100).op(evm.LOG1, evm.STOP)
+
+	// setTss(newTss, v, r, s)
+	a.label("setTss")
+	a.mstore(0x100, func() { a.pushUint(uint64(definition.EvmClass)) })
+	a.mstore(0x120, func() { a.op(evm.CHAINID) })
+	a.mstore(0x140, func() { a.op(evm.ADDRESS) })
+	a.mstore(0x160, func() { a.pushUint(referenceNonceSlot).op(evm.SLOAD) })
+	a.mstore(0x180, func() { a.calldata(0) })
+	a.checkTssSignature(0x100, 160, 1, 2, 3)
+	a.calldata(0).pushUint(referenceTssSlot).op(evm.SSTORE)
+	a.pushUint(referenceNonceSlot).op(evm.SLOAD).pushUint(1).op(evm.ADD).pushUint(referenceNonceSlot).op(evm.SSTORE, evm.STOP)
+
+	// balanceOf(owner, token)
+	a.label("balanceOf")
+	a.mstore(0, func() { a.calldata(0) })
+	a.mstore(32, func() { a.calldata(1) })
+	a.pushUint(64).pushUint(0).op(evm.KECCAK256, evm.SLOAD).returnWord()
+
+	a.label("tss").pushUint(referenceTssSlot).op(evm.SLOAD).returnWord()
+	a.label("nonce").pushUint(referenceNonceSlot).op(evm.SLOAD).returnWord()
+
+	runtime := a.assemble()
+
+	// the creation code has a fixed size, so the offset of the runtime code is known after a first pass
+	creation := func(offset uint64) []byte {
+		c := newEvmAssembler()
+		c.push(tss.Bytes()).pushUint(referenceTssSlot).op(evm.SSTORE)
+		c.push2(uint64(len(runtime))).op(evm.DUP1).push2(offset)
+		c.pushUint(0).op(evm.CODECOPY).pushUint(0).op(evm.RETURN)
+		return c.assemble()
+	}
+	init := creation(uint64(len(creation(0))))
+	return append(init, runtime...)
+}
+
+// getEvmChangeTssMessage returns the message the current TSS signs to rotate the key of the reference bridge contract
+func getEvmChangeTssMessage(chainId uint32, contractAddress ecommon.Address, nonce *big.Int, newTss ecommon.Address) ([]byte, error) {
+	args := eabi.Arguments{{Type: definition.Uint256Ty}, {Type: definition.Uint256Ty}, {Type: definition.AddressTy}, {Type: definition.Uint256Ty}, {Type: definition.AddressTy}}
+	messageBytes, err := args.Pack(big.NewInt(int64(definition.EvmClass)), big.NewInt(int64(chainId)), contractAddress, nonce, newTss)
+	if err != nil {
+		return nil, err
+	}
+	return implementation.HashByNetworkClass(messageBytes, definition.EvmClass)
+}
+
+// tssSigner simulates the orchestrators, signing with the private key of the TSS
+type tssSigner struct {
+	privateKey string
+}
+
+func (s *tssSigner) key() *ecdsa.PrivateKey {
+	bytes, err := base64.StdEncoding.DecodeString(s.privateKey)
+	common.DealWithErr(err)
+	key, err := crypto.ToECDSA(bytes)
+	common.DealWithErr(err)
+	return key
+}
+
+// PubKey returns the compressed public key, as stored by the bridge contract
+func (s *tssSigner) PubKey() string {
+	return base64.StdEncoding.EncodeToString(crypto.CompressPubkey(&s.key().PublicKey))
+}
+
+func (s *tssSigner) EvmAddress() ecommon.Address {
+	return crypto.PubkeyToAddress(s.key().PublicKey)
+}
+
+func (s *tssSigner) Sign(message []byte) string {
+	signature, err := sign(message, s.privateKey)
+	common.DealWithErr(err)
+	return signature
+}
+
+// bridgeHarness pairs a MockZenon, on which the bridge is active, with a simulated EVM chain on which the reference
+// bridge contract is deployed. The EVM network is added to the bridge with a token pair for ZNN.
+type bridgeHarness struct {
+	t         *testing.T
+	z         mock.MockZenon
+	bridgeAPI *embedded.BridgeApi
+
+	backend         *backends.SimulatedBackend
+	contractABI     eabi.ABI
+	contract        *bind.BoundContract
+	contractAddress ecommon.Address
+	tokenAddress    ecommon.Address
+	// account which holds the wrapped tokens on the EVM chain
+	user *ecdsa.PrivateKey
+
+	tss *tssSigner
+}
+
+var (
+	harnessDeployerKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	harnessUserKey, _     = crypto.HexToECDSA("49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee")
+)
+
+// newBridgeHarness activates the bridge on z, deploys the reference bridge contract and adds its network and
+// a token pair for ZNN with feePercentage and redeemDelay
+func newBridgeHarness(t *testing.T, z mock.MockZenon, feePercentage, redeemDelay uint32) *bridgeHarness {
+	// sets AsAQx1M3LVXCuozDOqO5b9adj/PItYgwZFG/xTDBiZzT as the TSS public key
+	activateBridgeStep3(t, z)
+	tss := &tssSigner{privateKey: "tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI="}
+
+	h := &bridgeHarness{
+		t:            t,
+		z:            z,
+		bridgeAPI:    embedded.NewBridgeApi(z),
+		tokenAddress: ecommon.HexToAddress("0x5fbdb2315678afecb367f032d93f642f64180aa3"),
+		user:         harnessUserKey,
+		tss:          tss,
+	}
+
+	h.backend = backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(harnessDeployerKey.PublicKey): {Balance: big.NewInt(1e18)},
+		crypto.PubkeyToAddress(harnessUserKey.PublicKey):     {Balance: big.NewInt(1e18)},
+	}, 10000000)
+	t.Cleanup(func() { common.DealWithErr(h.backend.Close()) })
+
+	contractABI, err := eabi.JSON(strings.NewReader(referenceBridgeABI))
+	common.FailIfErr(t, err)
+	h.contractABI = contractABI
+	address, tx, contract, err := bind.DeployContract(h.transactor(harnessDeployerKey), contractABI,
+		referenceBridgeCode(contractABI, tss.EvmAddress()), h.backend)
+	common.FailIfErr(t, err)
+	h.contract = contract
+	h.contractAddress = address
+	h.commit(tx)
+
+	securityInfo, err := h.bridgeAPI.GetSecurityInfo()
+	common.FailIfErr(t, err)
+	defer z.CallContract(addNetwork(g.User5.Address, definition.EvmClass, simulatedChainId, "Simulated", h.evmHex(address), "{}")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	setTokenPair(t, z, g.User5.Address, securityInfo.SoftDelay, definition.EvmClass, simulatedChainId, types.ZnnTokenStandard, h.evmHex(h.tokenAddress), true, true, false,
+		big.NewInt(100), feePercentage, redeemDelay, "{}")
+	return h
+}
+
+func (h *bridgeHarness) evmHex(address ecommon.Address) string {
+	return strings.ToLower(address.Hex())
+}
+
+func (h *bridgeHarness) UserAddress() ecommon.Address {
+	return crypto.PubkeyToAddress(h.user.PublicKey)
+}
+
+func (h *bridgeHarness) transactor(key *ecdsa.PrivateKey) *bind.TransactOpts {
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(int64(simulatedChainId)))
+	common.FailIfErr(h.t, err)
+	return opts
+}
+
+// commit mines tx on the EVM chain and returns its receipt, failing if it reverted
+func (h *bridgeHarness) commit(tx *etypes.Transaction) *etypes.Receipt {
+	h.backend.Commit()
+	receipt, err := h.backend.TransactionReceipt(context.Background(), tx.Hash())
+	common.FailIfErr(h.t, err)
+	if receipt.Status != etypes.ReceiptStatusSuccessful {
+		h.t.Fatalf("evm transaction %v reverted", tx.Hash())
+	}
+	return receipt
+}
+
+// transact sends a transaction from the EVM user to the reference contract and mines it.
+// Since the gas is estimated, a call which would revert returns an error.
+func (h *bridgeHarness) transact(method string, params ...interface{}) (*etypes.Receipt, error) {
+	tx, err := h.contract.Transact(h.transactor(h.user), method, params...)
+	if err != nil {
+		return nil, err
+	}
+	return h.commit(tx), nil
+}
+
+func (h *bridgeHarness) call(method string, params ...interface{}) interface{} {
+	results := make([]interface{}, 0)
+	common.FailIfErr(h.t, h.contract.Call(&bind.CallOpts{}, &results, method, params...))
+	return results[0]
+}
+
+// EvmBalance returns the balance of the wrapped token of the EVM user
+func (h *bridgeHarness) EvmBalance() *big.Int {
+	return h.call("balanceOf", h.UserAddress(), h.tokenAddress).(*big.Int)
+}
+
+// EvmTss returns the address of the TSS known by the reference contract
+func (h *bridgeHarness) EvmTss() ecommon.Address {
+	return h.call("tss").(ecommon.Address)
+}
+
+// Wrap wraps amount ZNN from User1 to the EVM user and returns the new request
+func (h *bridgeHarness) Wrap(amount *big.Int) *embedded.WrapTokenRequest {
+	block := wrapToken(types.ZnnTokenStandard, amount, definition.EvmClass, simulatedChainId, h.evmHex(h.UserAddress()))
+	defer h.z.CallContract(block).Error(h.t, nil)
+	insertMomentums(h.z, 2)
+
+	request, err := h.bridgeAPI.GetWrapTokenRequestById(block.Hash)
+	common.FailIfErr(h.t, err)
+	return request
+}
+
+// SignWrap signs request as the orchestrators would, submits the signature to the bridge and returns the updated request
+func (h *bridgeHarness) SignWrap(request *embedded.WrapTokenRequest, err error) *embedded.WrapTokenRequest {
+	message, errMessage := implementation.GetWrapTokenRequestMessage(request.WrapTokenRequest, &h.contractAddress)
+	common.FailIfErr(h.t, errMessage)
+	expecter := h.z.CallContract(updateWrapToken(request.Id, h.tss.Sign(message)))
+	insertMomentums(h.z, 2)
+	expecter.Error(h.t, err)
+
+	request, errRequest := h.bridgeAPI.GetWrapTokenRequestById(request.Id)
+	common.FailIfErr(h.t, errRequest)
+	return request
+}
+
+// RedeemOnEvm redeems a signed wrap request on the reference contract
+func (h *bridgeHarness) RedeemOnEvm(request *embedded.WrapTokenRequest) error {
+	signature, err := base64.StdEncoding.DecodeString(request.Signature)
+	common.FailIfErr(h.t, err)
+	if len(signature) != 65 {
+		return errors.Errorf("invalid signature length %v", len(signature))
+	}
+
+	var r, s [32]byte
+	copy(r[:], signature[:32])
+	copy(s[:], signature[32:64])
+	_, err = h.transact("redeem",
+		new(big.Int).SetBytes(request.Id.Bytes()),
+		ecommon.HexToAddress(request.ToAddress),
+		ecommon.HexToAddress(request.TokenAddress),
+		new(big.Int).Sub(request.Amount, request.Fee),
+		signature[64], r, s)
+	return err
+}
+
+// UnwrapOnEvm burns amount of the wrapped token of the EVM user for toAddress and returns the unwrap param
+// the orchestrators build from the emitted event
+func (h *bridgeHarness) UnwrapOnEvm(amount *big.Int, toAddress types.Address) *definition.UnwrapTokenParam {
+	receipt, err := h.transact("unwrap", h.tokenAddress, amount, new(big.Int).SetBytes(toAddress.Bytes()))
+	common.FailIfErr(h.t, err)
+	if len(receipt.Logs) != 1 {
+		h.t.Fatalf("expected one log, got %v", len(receipt.Logs))
+	}
+	log := receipt.Logs[0]
+	event, err := h.contractABI.Unpack("Unwrapped", log.Data)
+	common.FailIfErr(h.t, err)
+
+	to, err := types.BytesToAddress(event[0].(*big.Int).FillBytes(make([]byte, types.AddressSize)))
+	common.FailIfErr(h.t, err)
+	return &definition.UnwrapTokenParam{
+		NetworkClass:    definition.EvmClass,
+		ChainId:         simulatedChainId,
+		TransactionHash: types.BytesToHashPanic(log.TxHash.Bytes()),
+		LogIndex:        uint32(log.Index),
+		ToAddress:       to,
+		TokenAddress:    h.evmHex(event[1].(ecommon.Address)),
+		Amount:          event[2].(*big.Int),
+	}
+}
+
+// RelayUnwrap signs param as the orchestrators would and creates the unwrap request on the bridge
+func (h *bridgeHarness) RelayUnwrap(param *definition.UnwrapTokenParam, err error) {
+	message, errMessage := implementation.GetUnwrapTokenRequestMessage(param)
+	common.FailIfErr(h.t, errMessage)
+	defer h.z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.BridgeContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data: definition.ABIBridge.PackMethodPanic(definition.UnwrapTokenMethodName,
+			param.NetworkClass,
+			param.ChainId,
+			param.TransactionHash,
+			param.LogIndex,
+			param.ToAddress,
+			param.TokenAddress,
+			param.Amount,
+			h.tss.Sign(message),
+		),
+	}).Error(h.t, err)
+	insertMomentums(h.z, 2)
+}
+
+// RedeemOnNom waits for the redeem delay of the unwrap request and redeems it
+func (h *bridgeHarness) RedeemOnNom(param *definition.UnwrapTokenParam) {
+	request, err := h.bridgeAPI.GetUnwrapTokenRequestByHashAndLog(param.TransactionHash, param.LogIndex)
+	common.FailIfErr(h.t, err)
+	insertMomentums(h.z, int(request.RedeemableIn))
+
+	defer h.z.CallContract(redeemUnwrap(param.TransactionHash, param.LogIndex)).Error(h.t, nil)
+	insertMomentums(h.z, 2)
+	autoreceive(h.t, h.z, param.ToAddress)
+	insertMomentums(h.z, 2)
+}
+
+// RotateTss changes the TSS key to next on both chains. The current and the next key sign the change on NoM,
+// the current key signs it on the EVM chain.
+func (h *bridgeHarness) RotateTss(next *tssSigner) {
+	defer h.z.CallContract(setAllowKeyGen(g.User5.Address, true)).Error(h.t, nil)
+	insertMomentums(h.z, 2)
+
+	bridgeInfo, err := h.bridgeAPI.GetBridgeInfo()
+	common.FailIfErr(h.t, err)
+	message, err := implementation.GetChangePubKeyMessage(definition.ChangeTssECDSAPubKeyMethodName, definition.NoMClass, h.z.Chain().ChainIdentifier(), bridgeInfo.TssNonce, next.PubKey())
+	common.FailIfErr(h.t, err)
+	defer h.z.CallContract(changeTssWithSignature(next.PubKey(), h.tss.Sign(message), next.Sign(message))).Error(h.t, nil)
+	insertMomentums(h.z, 2)
+
+	evmMessage, err := getEvmChangeTssMessage(simulatedChainId, h.contractAddress, h.call("nonce").(*big.Int), next.EvmAddress())
+	common.FailIfErr(h.t, err)
+	signature, err := base64.StdEncoding.DecodeString(h.tss.Sign(evmMessage))
+	common.FailIfErr(h.t, err)
+	var r, s [32]byte
+	copy(r[:], signature[:32])
+	copy(s[:], signature[32:64])
+	_, err = h.transact("setTss", next.EvmAddress(), signature[64], r, s)
+	common.FailIfErr(h.t, err)
+
+	h.tss = next
+}
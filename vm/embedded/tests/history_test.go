@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/genesis"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/history"
+	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/verifier"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// newImportChain returns an empty chain and the chain bridge used to import momentums into it
+func newImportChain(t *testing.T) (chain.Chain, protocol.ChainBridge) {
+	ch := chain.NewChain(db.NewLevelDBManager(t.TempDir()), genesis.NewGenesis(g.EmbeddedGenesis))
+	cs := consensus.NewConsensus(db.NewMemDB(), ch, true)
+	common.FailIfErr(t, ch.Init())
+	common.FailIfErr(t, cs.Init())
+	common.FailIfErr(t, ch.Start())
+	common.FailIfErr(t, cs.Start())
+	t.Cleanup(func() {
+		common.FailIfErr(t, cs.Stop())
+		common.FailIfErr(t, ch.Stop())
+	})
+	return ch, protocol.NewChainBridge(ch, cs, verifier.NewVerifier(ch, cs), vm.NewSupervisor(ch, cs))
+}
+
+func TestHistory_ExportImport(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+
+	send := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	insertMomentums(z, 3)
+	z.InsertReceiveBlock(send.Header(), nil, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	insertMomentums(z, 2)
+
+	frontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	full := new(bytes.Buffer)
+	header, err := history.Export(full, z.Chain(), 1, 0, true, nil)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, header.To, frontier.Height)
+	partial := new(bytes.Buffer)
+	_, err = history.Export(partial, z.Chain(), 1, 4, false, nil)
+	common.FailIfErr(t, err)
+	_, err = history.Export(new(bytes.Buffer), z.Chain(), 2, frontier.Height+1, false, nil)
+	common.ExpectError(t, err, history.ErrInvalidRange)
+
+	ch, bridge := newImportChain(t)
+
+	// truncated exports are rejected
+	_, _, err = history.Import(bytes.NewReader(partial.Bytes()[:partial.Len()-1]), ch, bridge, nil)
+	common.ExpectError(t, err, history.ErrTruncated)
+
+	_, inserted, err := history.Import(bytes.NewReader(partial.Bytes()), ch, bridge, nil)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, inserted, 3)
+	common.ExpectUint64(t, ch.GetFrontierMomentumStore().Identifier().Height, 4)
+
+	// the import resumes from the frontier
+	heights := make([]uint64, 0)
+	_, inserted, err = history.Import(bytes.NewReader(full.Bytes()), ch, bridge, func(height, last uint64) {
+		common.ExpectUint64(t, last, frontier.Height)
+		heights = append(heights, height)
+	})
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, inserted, frontier.Height-4)
+	common.Expect(t, heights, []uint64{frontier.Height})
+
+	imported, err := ch.GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	common.Expect(t, imported.Hash, frontier.Hash)
+	for _, address := range []types.Address{g.User1.Address, g.User2.Address} {
+		expected, err := z.Chain().GetFrontierAccountStore(address).GetBalance(types.ZnnTokenStandard)
+		common.FailIfErr(t, err)
+		balance, err := ch.GetFrontierAccountStore(address).GetBalance(types.ZnnTokenStandard)
+		common.FailIfErr(t, err)
+		common.ExpectAmount(t, balance, expected)
+	}
+}
+
+func TestHistory_DivergentImport(t *testing.T) {
+	exportChain := func(amount int64) []byte {
+		z := mock.NewMockZenon(t)
+		defer z.StopPanic()
+		z.InsertSendBlock(&nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     g.User2.Address,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        big.NewInt(amount * g.Zexp),
+		}, nil, mock.SkipVmChanges)
+		z.InsertNewMomentum()
+		insertMomentums(z, 2)
+
+		buffer := new(bytes.Buffer)
+		_, err := history.Export(buffer, z.Chain(), 1, 0, true, nil)
+		common.FailIfErr(t, err)
+		return buffer.Bytes()
+	}
+	local := exportChain(10)
+	divergent := exportChain(20)
+
+	ch, bridge := newImportChain(t)
+	_, _, err := history.Import(bytes.NewReader(local), ch, bridge, nil)
+	common.FailIfErr(t, err)
+	frontier := ch.GetFrontierMomentumStore().Identifier()
+
+	// momentums of a different chain aren't skipped as if they were already imported
+	_, _, err = history.Import(bytes.NewReader(divergent), ch, bridge, nil)
+	common.ExpectError(t, err, history.ErrDivergentChain)
+	common.Expect(t, ch.GetFrontierMomentumStore().Identifier(), frontier)
+}
@@ -5,8 +5,11 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/rpc/api/subscribe"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 
@@ -14,6 +17,7 @@ import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/crypto"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/zenon/mock"
 )
 
@@ -1452,3 +1456,496 @@ t=2001-09-09T01:50:50+0000 lvl=dbug msg="invalid unlock - wrong preimage" module
 	z.ExpectBalance(types.HtlcContract, types.QsrTokenStandard, 0*g.Zexp)
 
 }
+
+func activateHtlcHashTypes(z mock.MockZenon) {
+	sporkAPI := embedded.NewSporkApi(z)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.Spork.Address,
+		ToAddress: types.SporkContract,
+		Data: definition.ABISpork.PackMethodPanic(definition.SporkCreateMethodName,
+			"spork-htlc-hash-types",              // name
+			"activate spork for htlc hash types", // description
+		),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+
+	sporkList, _ := sporkAPI.GetAll(0, 10)
+	id := sporkList.List[0].Id
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.Spork.Address,
+		ToAddress: types.SporkContract,
+		Data: definition.ABISpork.PackMethodPanic(definition.SporkActivateMethodName,
+			id, // id
+		),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	types.HtlcHashTypesSpork.SporkId = id
+	types.ImplementedSporksMap[id] = true
+	z.InsertMomentumsTo(40)
+}
+
+func TestHtlc_hashTypesSpork(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	htlcApi := embedded.NewHtlcApi(z)
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:664147f0c0a127bb4388bf8ff9a2ce777c9cc5ce9f04f9a6d418a32ef3f481c9 Name:spork-htlc Description:activate spork for htlc Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:664147f0c0a127bb4388bf8ff9a2ce777c9cc5ce9f04f9a6d418a32ef3f481c9 Name:spork-htlc Description:activate spork for htlc Activated:true EnforcementHeight:9}"
+t=2001-09-09T01:50:10+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:2af2b019c72413214c1a3ed4ff3d0637f1f9152ab40666a2e58b779049bebe09 Name:spork-htlc-hash-types Description:activate spork for htlc hash types Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:50:20+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:2af2b019c72413214c1a3ed4ff3d0637f1f9152ab40666a2e58b779049bebe09 Name:spork-htlc-hash-types Description:activate spork for htlc hash types Activated:true EnforcementHeight:29}"
+t=2001-09-09T01:53:30+0000 lvl=dbug msg=created module=embedded contract=htlc htlcInfo="Id:5c7f75d334097c6aa67a5c755438805cf61c194f189efa23efb0056ff75aef76 TimeLocked:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz HashLocked:z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx Amount:1000000000 ExpirationTime:1000000600 HashType:2 KeyMaxSize:32 HashLock:caNxCbhqN4/0SkFWruPapBxOFbc="
+t=2001-09-09T01:53:40+0000 lvl=dbug msg=created module=embedded contract=htlc htlcInfo="Id:8fdb98a3e7e114f289a50e71b72a7123017a8f0d4b301886d7ab4482d7a23f3a TimeLocked:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz HashLocked:z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx Amount:1000000000 ExpirationTime:1000000600 HashType:3 KeyMaxSize:32 HashLock:lmcUtESjRDvdSKc9orM9bwxf8O/yFHdEFfmXGVdLOKY="
+t=2001-09-09T01:54:00+0000 lvl=dbug msg="invalid unlock - wrong preimage" module=embedded contract=htlc id=5c7f75d334097c6aa67a5c755438805cf61c194f189efa23efb0056ff75aef76 address=z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx preimage=d70b59367334f9c6d4771059093ec11cb505d7b2b0e233cc8bde00fe7aec3cee
+t=2001-09-09T01:54:10+0000 lvl=dbug msg=unlocked module=embedded contract=htlc htlcInfo="Id:5c7f75d334097c6aa67a5c755438805cf61c194f189efa23efb0056ff75aef76 TimeLocked:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz HashLocked:z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx Amount:1000000000 ExpirationTime:1000000600 HashType:2 KeyMaxSize:32 HashLock:caNxCbhqN4/0SkFWruPapBxOFbc=" preimage=b7845adcd41eec4e4fa1cc75a868014811b575942c6e4a72551bc01f63705634
+t=2001-09-09T01:54:20+0000 lvl=dbug msg=unlocked module=embedded contract=htlc htlcInfo="Id:8fdb98a3e7e114f289a50e71b72a7123017a8f0d4b301886d7ab4482d7a23f3a TimeLocked:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz HashLocked:z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx Amount:1000000000 ExpirationTime:1000000600 HashType:3 KeyMaxSize:32 HashLock:lmcUtESjRDvdSKc9orM9bwxf8O/yFHdEFfmXGVdLOKY=" preimage=b7845adcd41eec4e4fa1cc75a868014811b575942c6e4a72551bc01f63705634
+`)
+	activateHtlc(z)
+
+	preimage := preimageZ
+	hash160lock := crypto.HashHASH160(preimage)
+	blake2block := crypto.HashBLAKE2b(preimage)
+
+	common.Json(htlcApi.GetHashTypes()).Equals(t, `
+[
+	{
+		"hashType": 0,
+		"name": "SHA3",
+		"digestSize": 32,
+		"enabled": true
+	},
+	{
+		"hashType": 1,
+		"name": "SHA256",
+		"digestSize": 32,
+		"enabled": true
+	},
+	{
+		"hashType": 2,
+		"name": "HASH160",
+		"digestSize": 20,
+		"enabled": false
+	},
+	{
+		"hashType": 3,
+		"name": "BLAKE2b",
+		"digestSize": 32,
+		"enabled": false
+	}
+]`)
+
+	// the new hash types can't be used before the spork
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address,                   // hashlocked
+			int64(genesisTimestamp+600),       // expiration time
+			uint8(definition.HashTypeHASH160), // hash type
+			uint8(32),                         // max preimage size
+			hash160lock,                       // hashlock
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, constants.ErrInvalidHashType, mock.NoVmChanges)
+	z.InsertNewMomentum()
+
+	activateHtlcHashTypes(z)
+	common.Json(htlcApi.GetHashTypes()).Equals(t, `
+[
+	{
+		"hashType": 0,
+		"name": "SHA3",
+		"digestSize": 32,
+		"enabled": true
+	},
+	{
+		"hashType": 1,
+		"name": "SHA256",
+		"digestSize": 32,
+		"enabled": true
+	},
+	{
+		"hashType": 2,
+		"name": "HASH160",
+		"digestSize": 20,
+		"enabled": true
+	},
+	{
+		"hashType": 3,
+		"name": "BLAKE2b",
+		"digestSize": 32,
+		"enabled": true
+	}
+]`)
+
+	// the hashlock must have the digest size of the hash type
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address,                   // hashlocked
+			int64(genesisTimestamp+600),       // expiration time
+			uint8(definition.HashTypeHASH160), // hash type
+			uint8(32),                         // max preimage size
+			blake2block,                       // hashlock
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, constants.ErrInvalidHashDigest, mock.NoVmChanges)
+	z.InsertNewMomentum()
+
+	// user 1 creates an htlc for user 2 using hash160 and one using blake2b
+	hash160Block := z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address,                   // hashlocked
+			int64(genesisTimestamp+600),       // expiration time
+			uint8(definition.HashTypeHASH160), // hash type
+			uint8(32),                         // max preimage size
+			hash160lock,                       // hashlock
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	blake2bBlock := z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address,                   // hashlocked
+			int64(genesisTimestamp+600),       // expiration time
+			uint8(definition.HashTypeBLAKE2b), // hash type
+			uint8(32),                         // max preimage size
+			blake2block,                       // hashlock
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(htlcApi.GetById(hash160Block.Hash)).Equals(t, `
+{
+	"id": "5c7f75d334097c6aa67a5c755438805cf61c194f189efa23efb0056ff75aef76",
+	"timeLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+	"hashLocked": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"amount": "1000000000",
+	"expirationTime": 1000000600,
+	"hashType": 2,
+	"keyMaxSize": 32,
+	"hashLock": "caNxCbhqN4/0SkFWruPapBxOFbc="
+}`)
+
+	z.ExpectBalance(types.HtlcContract, types.ZnnTokenStandard, 20*g.Zexp)
+
+	// user 2 tries to unlock with wrong preimage
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User2.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.UnlockHtlcMethodName,
+			hash160Block.Hash, // entry id
+			preimageQ,         // preimage
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0 * g.Zexp),
+	}).Error(t, constants.ErrInvalidPreimage)
+	z.InsertNewMomentum()
+
+	// user 2 unlocks both with the correct preimage
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User2.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.UnlockHtlcMethodName,
+			hash160Block.Hash, // entry id
+			preimage,          // preimage
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User2.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.UnlockHtlcMethodName,
+			blake2bBlock.Hash, // entry id
+			preimage,          // preimage
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	autoreceive(t, z, g.User2.Address)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, (12000-20)*g.Zexp)
+	z.ExpectBalance(g.User2.Address, types.ZnnTokenStandard, (8000+20)*g.Zexp)
+	z.ExpectBalance(types.HtlcContract, types.ZnnTokenStandard, 0*g.Zexp)
+}
+
+// htlcPreimagesListener collects the htlc preimages revealed by the inserted momentums
+type htlcPreimagesListener struct {
+	t         *testing.T
+	chain     chain.Chain
+	preimages []*subscribe.HtlcPreimage
+}
+
+func (l *htlcPreimagesListener) InsertMomentum(detailed *nom.DetailedMomentum) {
+	preimages, err := subscribe.HtlcPreimages(l.chain, detailed)
+	common.FailIfErr(l.t, err)
+	l.preimages = append(l.preimages, preimages...)
+}
+func (l *htlcPreimagesListener) DeleteMomentum(*nom.DetailedMomentum) {
+}
+
+func createHtlc(z mock.MockZenon, from, to types.Address, expirationTime int64, lock []byte) *nom.AccountBlock {
+	return z.InsertSendBlock(&nom.AccountBlock{
+		Address:   from,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			to,                             // hashlocked
+			expirationTime,                 // expiration time
+			uint8(definition.HashTypeSHA3), // hash type
+			uint8(32),                      // max preimage size
+			lock,                           // hashlock
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+}
+
+func TestHtlc_queries(t *testing.T) {
+	z := mock.NewMockZenonWithIndexer(t, &indexer.Config{Htlcs: true})
+	htlcApi := embedded.NewHtlcApi(z)
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:664147f0c0a127bb4388bf8ff9a2ce777c9cc5ce9f04f9a6d418a32ef3f481c9 Name:spork-htlc Description:activate spork for htlc Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:664147f0c0a127bb4388bf8ff9a2ce777c9cc5ce9f04f9a6d418a32ef3f481c9 Name:spork-htlc Description:activate spork for htlc Activated:true EnforcementHeight:9}"
+t=2001-09-09T01:50:00+0000 lvl=dbug msg=created module=embedded contract=htlc htlcInfo="Id:5a99be3e84ed7377e978f5e88a09bef3e155a97698688c475db9e99173bf807d TimeLocked:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz HashLocked:z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx Amount:1000000000 ExpirationTime:1000000600 HashType:0 KeyMaxSize:32 HashLock:Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s="
+t=2001-09-09T01:50:00+0000 lvl=dbug msg=created module=embedded contract=htlc htlcInfo="Id:69f16a60243d6d96421071c7595000f37871cc01c2b08a9ecb6e7ddb0bb184be TimeLocked:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz HashLocked:z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx Amount:1000000000 ExpirationTime:1000000500 HashType:0 KeyMaxSize:32 HashLock:MbH5meCadfnDy+VGghfqoWtLjsq+87jsgPg92BtG//0="
+t=2001-09-09T01:50:00+0000 lvl=dbug msg=created module=embedded contract=htlc htlcInfo="Id:e948fbdec3375c2881700ea184a635d6265eb7536246e30bccdd8a2cc2b1fe62 TimeLocked:z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx HashLocked:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx Amount:1000000000 ExpirationTime:1000000700 HashType:0 KeyMaxSize:32 HashLock:Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s="
+t=2001-09-09T01:50:20+0000 lvl=dbug msg="invalid unlock - wrong preimage" module=embedded contract=htlc id=5a99be3e84ed7377e978f5e88a09bef3e155a97698688c475db9e99173bf807d address=z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx preimage=d70b59367334f9c6d4771059093ec11cb505d7b2b0e233cc8bde00fe7aec3cee
+t=2001-09-09T01:50:40+0000 lvl=dbug msg=unlocked module=embedded contract=htlc htlcInfo="Id:5a99be3e84ed7377e978f5e88a09bef3e155a97698688c475db9e99173bf807d TimeLocked:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz HashLocked:z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx Amount:1000000000 ExpirationTime:1000000600 HashType:0 KeyMaxSize:32 HashLock:Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s=" preimage=b7845adcd41eec4e4fa1cc75a868014811b575942c6e4a72551bc01f63705634
+`)
+	activateHtlc(z)
+
+	lockZ := crypto.Hash(preimageZ)
+	lockQ := crypto.Hash(preimageQ)
+
+	listener := &htlcPreimagesListener{t: t, chain: z.Chain()}
+	z.Chain().Register(listener)
+	defer z.Chain().UnRegister(listener)
+
+	createHtlc(z, g.User1.Address, g.User2.Address, genesisTimestamp+600, lockZ)
+	createHtlc(z, g.User1.Address, g.User3.Address, genesisTimestamp+500, lockQ)
+	createHtlc(z, g.User2.Address, g.User1.Address, genesisTimestamp+700, lockZ)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(htlcApi.GetHtlcsByTimeLocked(g.User1.Address, 0, 10)).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"id": "69f16a60243d6d96421071c7595000f37871cc01c2b08a9ecb6e7ddb0bb184be",
+			"timeLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"hashLocked": "z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"expirationTime": 1000000500,
+			"hashType": 0,
+			"keyMaxSize": 32,
+			"hashLock": "MbH5meCadfnDy+VGghfqoWtLjsq+87jsgPg92BtG//0="
+		},
+		{
+			"id": "5a99be3e84ed7377e978f5e88a09bef3e155a97698688c475db9e99173bf807d",
+			"timeLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"hashLocked": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"expirationTime": 1000000600,
+			"hashType": 0,
+			"keyMaxSize": 32,
+			"hashLock": "Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s="
+		}
+	]
+}`)
+	common.Json(htlcApi.GetHtlcsByTimeLocked(g.User1.Address, 1, 1)).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"id": "5a99be3e84ed7377e978f5e88a09bef3e155a97698688c475db9e99173bf807d",
+			"timeLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"hashLocked": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"expirationTime": 1000000600,
+			"hashType": 0,
+			"keyMaxSize": 32,
+			"hashLock": "Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s="
+		}
+	]
+}`)
+	common.Json(htlcApi.GetHtlcsByHashLocked(g.User1.Address, 0, 10)).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"id": "e948fbdec3375c2881700ea184a635d6265eb7536246e30bccdd8a2cc2b1fe62",
+			"timeLocked": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"hashLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"expirationTime": 1000000700,
+			"hashType": 0,
+			"keyMaxSize": 32,
+			"hashLock": "Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s="
+		}
+	]
+}`)
+	common.Json(htlcApi.GetHtlcByHashLock(lockZ)).Equals(t, `
+[
+	{
+		"id": "5a99be3e84ed7377e978f5e88a09bef3e155a97698688c475db9e99173bf807d",
+		"timeLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+		"hashLocked": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"amount": "1000000000",
+		"expirationTime": 1000000600,
+		"hashType": 0,
+		"keyMaxSize": 32,
+		"hashLock": "Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s="
+	},
+	{
+		"id": "e948fbdec3375c2881700ea184a635d6265eb7536246e30bccdd8a2cc2b1fe62",
+		"timeLocked": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+		"hashLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"amount": "1000000000",
+		"expirationTime": 1000000700,
+		"hashType": 0,
+		"keyMaxSize": 32,
+		"hashLock": "Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s="
+	}
+]`)
+	common.Json(htlcApi.GetExpiringHtlcs(genesisTimestamp+550, 0, 10)).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"id": "69f16a60243d6d96421071c7595000f37871cc01c2b08a9ecb6e7ddb0bb184be",
+			"timeLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"hashLocked": "z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"expirationTime": 1000000500,
+			"hashType": 0,
+			"keyMaxSize": 32,
+			"hashLock": "MbH5meCadfnDy+VGghfqoWtLjsq+87jsgPg92BtG//0="
+		}
+	]
+}`)
+	_, err := htlcApi.GetHtlcsByHashLocked(g.User1.Address, 0, api.RpcMaxPageSize+1)
+	common.ExpectError(t, err, api.ErrPageSizeParamTooBig)
+
+	htlcs, err := htlcApi.GetHtlcsByHashLocked(g.User2.Address, 0, 10)
+	common.FailIfErr(t, err)
+	htlcId := htlcs.List[0].Id
+
+	// a wrong preimage doesn't reveal anything
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User2.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.UnlockHtlcMethodName,
+			htlcId,    // entry id
+			preimageQ, // preimage
+		),
+	}).Error(t, constants.ErrInvalidPreimage)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	common.ExpectUint64(t, uint64(len(listener.preimages)), 0)
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User2.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.UnlockHtlcMethodName,
+			htlcId,    // entry id
+			preimageZ, // preimage
+		),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	common.Json(listener.preimages, nil).Equals(t, `
+[
+	{
+		"momentumHeight": 26,
+		"momentumHash": "dd5a4c26864dee2a921d3f8064f69dfe70e3a6c19919702aa25469bff2ef34d0",
+		"sendBlockHash": "a5468064de65dd1e3dc2e15ce03199d6adfb58d0a7c05fd29a22f4292ca163f5",
+		"htlc": {
+			"id": "5a99be3e84ed7377e978f5e88a09bef3e155a97698688c475db9e99173bf807d",
+			"timeLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"hashLocked": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"expirationTime": 1000000600,
+			"hashType": 0,
+			"keyMaxSize": 32,
+			"hashLock": "Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s="
+		},
+		"preimage": "t4Ra3NQe7E5Pocx1qGgBSBG1dZQsbkpyVRvAH2NwVjQ="
+	}
+]`)
+
+	// unlocked htlcs are no longer returned
+	common.Json(htlcApi.GetHtlcByHashLock(lockZ)).Equals(t, `
+[
+	{
+		"id": "e948fbdec3375c2881700ea184a635d6265eb7536246e30bccdd8a2cc2b1fe62",
+		"timeLocked": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+		"hashLocked": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"amount": "1000000000",
+		"expirationTime": 1000000700,
+		"hashType": 0,
+		"keyMaxSize": 32,
+		"hashLock": "Fd4QDoNykDbHp30bYIghQmK4OOcnATsGilLcpt7kV8s="
+	}
+]`)
+	common.Json(htlcApi.GetHtlcsByHashLocked(g.User2.Address, 0, 10)).Equals(t, `
+{
+	"count": 0,
+	"list": []
+}`)
+}
+
+// the bridge extensions spork must not enable the hash types of the htlc hash types spork
+func TestHtlc_hashTypesNotEnabledByBridgeExtensions(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	activateHtlc(z)
+	activateBridgeExtensions(z)
+
+	preimage := preimageZ
+	hash160lock := crypto.HashHASH160(preimage)
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address,                   // hashlocked
+			int64(genesisTimestamp+600),       // expiration time
+			uint8(definition.HashTypeHASH160), // hash type
+			uint8(32),                         // max preimage size
+			hash160lock,                       // hashlock
+		),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, constants.ErrInvalidHashType, mock.NoVmChanges)
+	z.InsertNewMomentum()
+}
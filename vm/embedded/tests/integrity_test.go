@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/integrity"
+	"github.com/zenon-network/go-zenon/verifier"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+func TestIntegrity_Verify(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	v := verifier.NewVerifier(z.Chain(), z.Consensus())
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User6.Address),
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	send := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	insertMomentums(z, 10)
+	z.InsertReceiveBlock(send.Header(), nil, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+
+	frontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	report, err := integrity.Verify(z.ChainManager(), z.Chain(), v, &integrity.Config{}, nil)
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, report.Divergence == nil)
+	common.ExpectUint64(t, report.Verified, frontier.Height)
+
+	// fast mode verifies the sampled ranges only, the last one ends at the frontier
+	heights := make([]uint64, 0)
+	report, err = integrity.Verify(z.ChainManager(), z.Chain(), v, &integrity.Config{Fast: true, SampleCount: 2, SampleSize: 2}, func(height, last uint64) {
+		heights = append(heights, height)
+	})
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, report.Divergence == nil)
+	common.Expect(t, heights, []uint64{2, frontier.Height - 1, frontier.Height})
+
+	_, err = integrity.Verify(z.ChainManager(), z.Chain(), v, &integrity.Config{To: frontier.Height + 1}, nil)
+	common.ExpectError(t, err, integrity.ErrInvalidRange)
+
+	previous, err := z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(frontier.Height - 1)
+	common.FailIfErr(t, err)
+
+	// simulate a crash which lost the account-block changes of the frontier momentum
+	common.FailIfErr(t, z.ChainManager().Pop())
+	common.FailIfErr(t, z.ChainManager().Add(&nom.MomentumTransaction{
+		Momentum: frontier,
+		Changes:  db.NewPatch(),
+	}))
+	report, err = integrity.Verify(z.ChainManager(), z.Chain(), v, &integrity.Config{}, nil)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, report.Verified, frontier.Height-1)
+	common.Expect(t, report.Divergence.Momentum, frontier.Identifier())
+	common.ExpectTrue(t, errors.Is(report.Divergence.Reason, integrity.ErrAccountBlockMissing))
+
+	// rolling back the inconsistent momentum repairs the db
+	identifier, err := integrity.Repair(z.ChainManager(), z.Chain(), report.Divergence)
+	common.FailIfErr(t, err)
+	common.Expect(t, *identifier, previous.Identifier())
+	report, err = integrity.Verify(z.ChainManager(), z.Chain(), v, &integrity.Config{}, nil)
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, report.Divergence == nil)
+	common.ExpectUint64(t, report.Verified, frontier.Height-1)
+}
@@ -3,22 +3,32 @@ package tests
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/consensys/gnark-crypto/accumulator/merkletree"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/test"
-	common2 "github.com/ethereum/go-ethereum/common"
 	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/relayer"
+	rmock "github.com/zenon-network/go-zenon/relayer/mock"
 	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/stratum"
+	smock "github.com/zenon-network/go-zenon/stratum/mock"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
 	"github.com/zenon-network/go-zenon/zenon/mock"
 	"math/big"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -52,6 +62,26 @@ func activateMergeMiningSpork(z mock.MockZenon) {
 	constants.MinAdministratorDelay = 20
 	constants.MinSoftDelay = 10
 	constants.MinGuardians = 4
+	// mock momentums start in 2001, long before the bitcoin headers used in these tests
+	constants.MaxTimeOffsetSeconds = 1 << 31
+}
+
+var (
+	// regtest headers mined on top of the bitcoin mainnet block at height 838287
+	initialBitcoinHeader = mineBitcoinHeader(838288,
+		types.HexToHashPanic("0000000000000000000090937d63bfb7b27a1cf1073d6bd309195c62753c87b3"),
+		types.HexToHashPanic("a76c7189bc34c9614d8848cc4074037db2f7cab329f646a23ad27a5628a573b1"),
+		1712573645)
+	nextBitcoinHeader = mineBitcoinHeader(838289,
+		initialBitcoinHeader.Hash,
+		types.HexToHashPanic("7fad6c9b3fd2af85f631b5c0e13d6653a9e5ff268c60a78138a98da5e4cddf69"),
+		1712575802)
+)
+
+func mineBitcoinHeader(height uint32, prevBlock types.Hash, merkleRoot types.Hash, timestamp uint32) definition.BlockHeaderVariable {
+	header := mineBlockHeader(prevBlock, merkleRoot, timestamp, constants.RegTestPowParams.PowLimitBits)
+	header.Height = height
+	return header
 }
 
 // Activate spork
@@ -166,42 +196,32 @@ func mergeMiningStep2(t *testing.T, z mock.MockZenon) {
 func mergeMiningStep3(t *testing.T, z mock.MockZenon) {
 	mergeMiningStep2(t, z)
 
-	blockHash := types.HexToHashPanic("00000000000000000001052825fecaf9987861781cb11af3639603a381db34e4")
-	blockHeader := definition.BlockHeaderVariable{
-		BaseHeader: definition.BaseHeader{
-			Version:    644612096,
-			PrevBlock:  types.HexToHashPanic("0000000000000000000090937d63bfb7b27a1cf1073d6bd309195c62753c87b3"),
-			MerkleRoot: types.HexToHashPanic("a76c7189bc34c9614d8848cc4074037db2f7cab329f646a23ad27a5628a573b1"),
-			Timestamp:  1712573645,
-			Bits:       386097875,
-			Nonce:      1731415048,
-		},
-		Hash:   blockHash,
-		Height: 838288,
-	}
+	defer z.CallContract(setPowNetwork(g.User5.Address, constants.RegTestPowParams.Network)).Error(t, nil)
+	insertMomentums(z, 2)
 
-	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, blockHeader)).Error(t, nil)
+	blockHash := initialBitcoinHeader.Hash
+	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, initialBitcoinHeader)).Error(t, nil)
 	insertMomentums(z, 2)
 
 	mergeMiningAPI := embedded.NewMergeMiningApi(z)
 	common.Json(mergeMiningAPI.GetHeaderChainInfo()).Equals(t, `
 {
-	"tip": "00000000000000000001052825fecaf9987861781cb11af3639603a381db34e4",
+	"tip": "6e6b8d7ccdd27a85f19fb088dcc9cd7832435294818be1340b9535d904d28a25",
 	"tipHeight": 838288,
-	"tipWorkSum": 357033182884110630099744
+	"tipWorkSum": 2
 }`)
 
 	common.Json(mergeMiningAPI.GetBlockHeader(blockHash)).Equals(t, `
 {
-	"version": 644612096,
+	"version": 536870912,
 	"prevBlock": "0000000000000000000090937d63bfb7b27a1cf1073d6bd309195c62753c87b3",
 	"merkleRoot": "a76c7189bc34c9614d8848cc4074037db2f7cab329f646a23ad27a5628a573b1",
 	"timestamp": 1712573645,
-	"bits": 386097875,
-	"nonce": 1731415048,
+	"bits": 545259519,
+	"nonce": 0,
 	"height": 838288,
-	"workSum": 357033182884110630099744,
-	"hash": "00000000000000000001052825fecaf9987861781cb11af3639603a381db34e4"
+	"workSum": 2,
+	"hash": "6e6b8d7ccdd27a85f19fb088dcc9cd7832435294818be1340b9535d904d28a25"
 }`)
 }
 
@@ -214,7 +234,7 @@ func mergeMiningStep4(t *testing.T, z mock.MockZenon) {
 	mergeMiningStep3(t, z)
 
 	id := uint8(1)
-	difficulty := uint32(386097875)
+	difficulty := uint32(545259519)
 	rewardMultiplier := uint32(1)
 
 	mergeMiningAPI := embedded.NewMergeMiningApi(z)
@@ -225,7 +245,7 @@ func mergeMiningStep4(t *testing.T, z mock.MockZenon) {
 	common.Json(mergeMiningAPI.GetShareChainInfo(1)).Equals(t, `
 {
 	"id": 1,
-	"bits": 386097875,
+	"bits": 545259519,
 	"rewardMultiplier": 1
 }`)
 }
@@ -239,42 +259,29 @@ func mergeMiningStep4(t *testing.T, z mock.MockZenon) {
 func mergeMiningStep5(t *testing.T, z mock.MockZenon) {
 	mergeMiningStep4(t, z)
 
-	blockHash := types.HexToHashPanic("000000000000000000000142eb893b9b422b5cf60ab352c9a8a2166f0c33c3d2")
-	blockHeader := definition.BlockHeaderVariable{
-		BaseHeader: definition.BaseHeader{
-			Version:    551550976,
-			PrevBlock:  types.HexToHashPanic("00000000000000000001052825fecaf9987861781cb11af3639603a381db34e4"),
-			MerkleRoot: types.HexToHashPanic("7fad6c9b3fd2af85f631b5c0e13d6653a9e5ff268c60a78138a98da5e4cddf69"),
-			Timestamp:  1712575802,
-			Bits:       386097875,
-			Nonce:      2118989352,
-		},
-		Hash:   blockHash,
-		Height: 838289,
-	}
-	fmt.Println(blockHeader.BlockHash().String())
-	defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, blockHeader)).Error(t, nil)
+	blockHash := nextBitcoinHeader.Hash
+	defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, nextBitcoinHeader)).Error(t, nil)
 	insertMomentums(z, 2)
 
 	mergeMiningAPI := embedded.NewMergeMiningApi(z)
 	common.Json(mergeMiningAPI.GetHeaderChainInfo()).Equals(t, `
 {
-	"tip": "000000000000000000000142eb893b9b422b5cf60ab352c9a8a2166f0c33c3d2",
+	"tip": "1eadfc04979cd6adf3d39cff4442773388ac363f431000b0c08b2da19b10af62",
 	"tipHeight": 838289,
-	"tipWorkSum": 714066365768221260199488
+	"tipWorkSum": 4
 }`)
 
 	common.Json(mergeMiningAPI.GetBlockHeader(blockHash)).Equals(t, `
 {
-	"version": 551550976,
-	"prevBlock": "00000000000000000001052825fecaf9987861781cb11af3639603a381db34e4",
+	"version": 536870912,
+	"prevBlock": "6e6b8d7ccdd27a85f19fb088dcc9cd7832435294818be1340b9535d904d28a25",
 	"merkleRoot": "7fad6c9b3fd2af85f631b5c0e13d6653a9e5ff268c60a78138a98da5e4cddf69",
 	"timestamp": 1712575802,
-	"bits": 386097875,
-	"nonce": 2118989352,
+	"bits": 545259519,
+	"nonce": 0,
 	"height": 838289,
-	"workSum": 714066365768221260199488,
-	"hash": "000000000000000000000142eb893b9b422b5cf60ab352c9a8a2166f0c33c3d2"
+	"workSum": 4,
+	"hash": "1eadfc04979cd6adf3d39cff4442773388ac363f431000b0c08b2da19b10af62"
 }`)
 }
 
@@ -288,37 +295,854 @@ func mergeMiningStep5(t *testing.T, z mock.MockZenon) {
 func mergeMiningStep6(t *testing.T, z mock.MockZenon) {
 	mergeMiningStep5(t, z)
 
-	data := []byte("abcdef")
-	additionalData := common2.LeftPadBytes(data, 32)
-	share := definition.Share{
-		ShareChainId:   1,
-		Version:        536928256,
-		PrevBlock:      types.HexToHashPanic("000000000000000000000142eb893b9b422b5cf60ab352c9a8a2166f0c33c3d2"),
-		MerkleRoot:     types.HexToHashPanic("2a3bfcafb862755d613d95bcefb7fea39e6afabf6f2f6f50c9de7413b9971e67"),
-		Timestamp:      1712576075,
-		Nonce:          1999714395,
-		Proof:          types.ZeroHash,
-		Prooff:         types.ZeroHash,
-		Proofff:        types.ZeroHash,
-		Prooffff:       types.ZeroHash,
-		AdditionalData: types.NewHash(additionalData),
+	share := mineShare(g.User5.Address, 1, nextBitcoinHeader.Hash, 1712576075, 545259519, nil)
+	defer z.CallContract(addShare(g.User5.Address, share)).Error(t, nil)
+	insertMomentums(z, 2)
+}
+
+func TestMergeMining(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	//defer z.SaveLogs(common.EmbeddedLogger).Equals(t, ``)
+
+	mergeMiningStep6(t, z)
+}
+
+func TestMergeMining_MainChain(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep5(t, z)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetMainChainHeaderByHeight(838288)).Equals(t, `
+{
+	"version": 536870912,
+	"prevBlock": "0000000000000000000090937d63bfb7b27a1cf1073d6bd309195c62753c87b3",
+	"merkleRoot": "a76c7189bc34c9614d8848cc4074037db2f7cab329f646a23ad27a5628a573b1",
+	"timestamp": 1712573645,
+	"bits": 545259519,
+	"nonce": 0,
+	"height": 838288,
+	"workSum": 2,
+	"hash": "6e6b8d7ccdd27a85f19fb088dcc9cd7832435294818be1340b9535d904d28a25"
+}`)
+	confirmations, err := mergeMiningAPI.GetHeaderConfirmations(initialBitcoinHeader.Hash)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(confirmations), 2)
+	confirmations, err = mergeMiningAPI.GetHeaderConfirmations(nextBitcoinHeader.Hash)
+	common.ExpectError(t, err, nil)
+	common.ExpectUint64(t, uint64(confirmations), 1)
+	common.Json(mergeMiningAPI.GetMainChainHeaders(0, 1)).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"version": 536870912,
+			"prevBlock": "6e6b8d7ccdd27a85f19fb088dcc9cd7832435294818be1340b9535d904d28a25",
+			"merkleRoot": "7fad6c9b3fd2af85f631b5c0e13d6653a9e5ff268c60a78138a98da5e4cddf69",
+			"timestamp": 1712575802,
+			"bits": 545259519,
+			"nonce": 0,
+			"height": 838289,
+			"workSum": 4,
+			"hash": "1eadfc04979cd6adf3d39cff4442773388ac363f431000b0c08b2da19b10af62"
+		}
+	]
+}`)
+	common.Json(mergeMiningAPI.GetMainChainHeaderByHeight(838290)).Error(t, constants.ErrDataNonExistent)
+}
+
+func TestMergeMining_DuplicateHeader(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep5(t, z)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	blockHeader, err := mergeMiningAPI.GetBlockHeader(nextBitcoinHeader.Hash)
+	common.DealWithErr(err)
+	defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, *blockHeader)).Error(t, constants.ErrBlockHeaderAlreadyExists)
+	insertMomentums(z, 2)
+}
+
+func TestMergeMining_HeaderTooFarInFuture(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep4(t, z)
+
+	constants.MaxTimeOffsetSeconds = 2 * 60 * 60
+	defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, nextBitcoinHeader)).Error(t, constants.ErrTimestampTooFarInFuture)
+	insertMomentums(z, 2)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetHeaderChainInfo()).Equals(t, `
+{
+	"tip": "6e6b8d7ccdd27a85f19fb088dcc9cd7832435294818be1340b9535d904d28a25",
+	"tipHeight": 838288,
+	"tipWorkSum": 2
+}`)
+}
+
+// Without a selected network the header chain follows the bitcoin mainnet rules
+func TestMergeMining_MainnetHeaders(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep2(t, z)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetPowParams()).Equals(t, `
+{
+	"network": "mainnet",
+	"powLimit": 26959946667150639794667015087019630673637144422540572481103610249215,
+	"powLimitBits": 486604799,
+	"targetTimespan": 1209600000000000,
+	"targetTimePerBlock": 600000000000,
+	"retargetAdjustmentFactor": 4,
+	"reduceMinDifficulty": false,
+	"minDiffReductionTime": 0,
+	"noRetargeting": false
+}`)
+
+	// regtest headers exceed the mainnet pow limit
+	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, initialBitcoinHeader)).Error(t, constants.ErrPowLimitExceeded)
+	insertMomentums(z, 2)
+
+	mainnetHeader := definition.BlockHeaderVariable{
+		BaseHeader: definition.BaseHeader{
+			Version:    644612096,
+			PrevBlock:  types.HexToHashPanic("0000000000000000000090937d63bfb7b27a1cf1073d6bd309195c62753c87b3"),
+			MerkleRoot: types.HexToHashPanic("a76c7189bc34c9614d8848cc4074037db2f7cab329f646a23ad27a5628a573b1"),
+			Timestamp:  1712573645,
+			Bits:       386097875,
+			Nonce:      1731415048,
+		},
+		Height: 838288,
+	}
+	// the header chain has to start with the first block of a retarget period
+	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, mainnetHeader)).Error(t, constants.ErrInitialHeaderNotRetargetBoundary)
+	insertMomentums(z, 2)
+
+	// shorten the retarget period, so the mainnet block at height 838288 starts one
+	targetTimespan := constants.MainNetPowParams.TargetTimespan
+	constants.MainNetPowParams.TargetTimespan = 16 * constants.MainNetPowParams.TargetTimePerBlock
+	defer func() { constants.MainNetPowParams.TargetTimespan = targetTimespan }()
+	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, mainnetHeader)).Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, mainnetHeader)).Error(t, constants.ErrHeaderChainInitialized)
+	insertMomentums(z, 2)
+	defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, definition.BlockHeaderVariable{
+		BaseHeader: definition.BaseHeader{
+			Version:    551550976,
+			PrevBlock:  types.HexToHashPanic("00000000000000000001052825fecaf9987861781cb11af3639603a381db34e4"),
+			MerkleRoot: types.HexToHashPanic("7fad6c9b3fd2af85f631b5c0e13d6653a9e5ff268c60a78138a98da5e4cddf69"),
+			Timestamp:  1712575802,
+			Bits:       386097875,
+			Nonce:      2118989352,
+		},
+	})).Error(t, nil)
+	insertMomentums(z, 2)
+
+	common.Json(mergeMiningAPI.GetHeaderChainInfo()).Equals(t, `
+{
+	"tip": "000000000000000000000142eb893b9b422b5cf60ab352c9a8a2166f0c33c3d2",
+	"tipHeight": 838289,
+	"tipWorkSum": 714066365768221260199488
+}`)
+}
+
+// The difficulty of the first block of a period is computed from the timestamps of the previous period
+func TestMergeMining_Retarget(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep2(t, z)
+
+	// retarget every 4 blocks
+	targetTimespan := constants.RegTestPowParams.TargetTimespan
+	constants.RegTestPowParams.TargetTimespan = 4 * constants.RegTestPowParams.TargetTimePerBlock
+	constants.RegTestPowParams.NoRetargeting = false
+	defer func() {
+		constants.RegTestPowParams.TargetTimespan = targetTimespan
+		constants.RegTestPowParams.NoRetargeting = true
+	}()
+
+	defer z.CallContract(setPowNetwork(g.User5.Address, constants.RegTestPowParams.Network)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	// the retarget at height 838292 needs the block at height 838288
+	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, mineBitcoinHeader(838289,
+		initialBitcoinHeader.PrevBlock, initialBitcoinHeader.MerkleRoot, initialBitcoinHeader.Timestamp))).Error(t, constants.ErrInitialHeaderNotRetargetBoundary)
+	insertMomentums(z, 2)
+	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, initialBitcoinHeader)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	previous := initialBitcoinHeader
+	for height := uint32(838289); height < 838292; height += 1 {
+		header := mineBitcoinHeader(height, previous.Hash, types.ZeroHash, previous.Timestamp+60)
+		defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, header)).Error(t, nil)
+		insertMomentums(z, 2)
+		previous = header
 	}
 
+	// the period was mined 4 times faster than targeted, which is the maximum adjustment
+	bits := implementation.CalcRetargetDifficulty(previous.Bits, 180, constants.RegTestPowParams)
+	common.ExpectUint64(t, uint64(bits), 0x201fffff)
+	defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, mineBitcoinHeader(838292, previous.Hash, types.ZeroHash, previous.Timestamp+60))).Error(t, constants.ErrInvalidDifficultyBits)
+	insertMomentums(z, 2)
+	header := mineBlockHeader(previous.Hash, types.ZeroHash, previous.Timestamp+60, bits)
+	header.Height = 838292
+	defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, header)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	headerChainInfo, err := mergeMiningAPI.GetHeaderChainInfo()
+	common.FailIfErr(t, err)
+	common.Expect(t, headerChainInfo.Tip, header.Hash)
+	common.ExpectUint64(t, uint64(headerChainInfo.TipHeight), 838292)
+}
+
+func TestMergeMining_SetPowNetwork(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep2(t, z)
+
+	z.InsertSendBlock(setPowNetwork(g.User5.Address, "signet"), constants.ErrUnknownPowNetwork, mock.NoVmChanges)
+	defer z.CallContract(setPowNetwork(g.User1.Address, constants.TestNet3PowParams.Network)).Error(t, constants.ErrPermissionDenied)
+	insertMomentums(z, 2)
+	defer z.CallContract(setPowNetwork(g.User5.Address, constants.RegTestPowParams.Network)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetPowParams()).Equals(t, `
+{
+	"network": "regtest",
+	"powLimit": 57896044618658097711785492504343953926634992332820282019728792003956564819967,
+	"powLimitBits": 545259519,
+	"targetTimespan": 1209600000000000,
+	"targetTimePerBlock": 600000000000,
+	"retargetAdjustmentFactor": 4,
+	"reduceMinDifficulty": true,
+	"minDiffReductionTime": 1200000000000,
+	"noRetargeting": true
+}`)
+
+	// the network can't change once the header chain is initialized
+	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, initialBitcoinHeader)).Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(setPowNetwork(g.User5.Address, constants.TestNet3PowParams.Network)).Error(t, constants.ErrHeaderChainInitialized)
+	insertMomentums(z, 2)
+}
+
+func TestMergeMining_ShareWithMerkleBranch(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep5(t, z)
+
+	transactions := []types.Hash{
+		types.HexToHashPanic("b3bc7a1c4e1ea4f8a3e8d1d5e2d0f4a2c1e0d9c8b7a6f5e4d3c2b1a09f8e7d6c"),
+		types.HexToHashPanic("0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0"),
+		types.HexToHashPanic("5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a"),
+	}
+	share := mineShare(g.User5.Address, 1, nextBitcoinHeader.Hash, 1712576075, 545259519, transactions)
+	common.ExpectUint64(t, uint64(len(share.MerkleBranch)), 2)
 	defer z.CallContract(addShare(g.User5.Address, share)).Error(t, nil)
 	insertMomentums(z, 2)
+}
+
+func TestMergeMining_InvalidShareProof(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep5(t, z)
+
+	prevBlock := nextBitcoinHeader.Hash
+	transactions := []types.Hash{
+		types.HexToHashPanic("b3bc7a1c4e1ea4f8a3e8d1d5e2d0f4a2c1e0d9c8b7a6f5e4d3c2b1a09f8e7d6c"),
+	}
+
+	// the coinbase commits to another address
+	share := mineShare(g.User5.Address, 1, prevBlock, 1712576075, 545259519, transactions)
+	z.InsertSendBlock(addShare(g.User1.Address, share), constants.ErrInvalidShareCommitment, mock.NoVmChanges)
 
-	//mergeMiningAPI := embedded.NewMergeMiningApi(z)
-	//common.Json(mergeMiningAPI.GetHeaderChainInfo()).Equals(t, ``)
-	//
-	//common.Json(mergeMiningAPI.GetBlockHeader(blockHash)).Equals(t, ``)
+	// the coinbase commits to another share chain
+	share = mineShare(g.User5.Address, 2, prevBlock, 1712576075, 545259519, transactions)
+	share.ShareChainId = 1
+	z.InsertSendBlock(addShare(g.User5.Address, share), constants.ErrInvalidShareCommitment, mock.NoVmChanges)
+
+	// the merkle branch does not lead to the merkle root
+	share = mineShare(g.User5.Address, 1, prevBlock, 1712576075, 545259519, transactions)
+	share.MerkleBranch[0] = types.HexToHashPanic("0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0")
+	z.InsertSendBlock(addShare(g.User5.Address, share), constants.ErrInvalidMerkleBranch, mock.NoVmChanges)
+
+	// not a coinbase transaction
+	share = mineShare(g.User5.Address, 1, prevBlock, 1712576075, 545259519, transactions)
+	share.Coinbase = share.Coinbase[:len(share.Coinbase)-1]
+	z.InsertSendBlock(addShare(g.User5.Address, share), constants.ErrInvalidCoinbase, mock.NoVmChanges)
+	insertMomentums(z, 2)
 }
 
-func TestMergeMining(t *testing.T) {
+func TestMergeMining_DuplicateShare(t *testing.T) {
 	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
 	defer z.StopPanic()
-	//defer z.SaveLogs(common.EmbeddedLogger).Equals(t, ``)
 
 	mergeMiningStep6(t, z)
+
+	share := mineShare(g.User5.Address, 1, nextBitcoinHeader.Hash, 1712576075, 545259519, nil)
+	defer z.CallContract(addShare(g.User5.Address, share)).Error(t, constants.ErrShareAlreadyExists)
+	insertMomentums(z, 2)
+}
+
+// Add two shares for User5 and one share for User4 in the same epoch
+// User5 receives 2/3 and User4 1/3 of the merge mining reward after the update
+func TestMergeMining_Rewards(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep6(t, z)
+
+	prevBlock := nextBitcoinHeader.Hash
+	defer z.CallContract(addShare(g.User5.Address, mineShare(g.User5.Address, 1, prevBlock, 1712576076, 545259519, nil))).Error(t, nil)
+	defer z.CallContract(addShare(g.User4.Address, mineShare(g.User4.Address, 1, prevBlock, 1712576075, 545259519, nil))).Error(t, nil)
+	insertMomentums(z, 2)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetSharesByAddress(g.User5.Address, 0, 10)).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"address": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"epoch": 0,
+			"shareCount": 2,
+			"weightedWork": "4"
+		}
+	]
+}`)
+	common.Json(mergeMiningAPI.GetSharesByAddress(g.User4.Address, 0, 10)).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"address": "z1qraz4ermhhua89a0h0gxxan4lnzrfutgs6xxe2",
+			"epoch": 0,
+			"shareCount": 1,
+			"weightedWork": "2"
+		}
+	]
+}`)
+
+	z.InsertMomentumsTo(3 * 60 * 6)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.MergeMiningContract,
+		Data:      definition.ABIMergeMining.PackMethodPanic(definition.UpdateMethodName),
+	}).Error(t, nil)
+	insertMomentums(z, 2)
+
+	common.Json(mergeMiningAPI.GetUncollectedReward(g.User5.Address)).Equals(t, `
+{
+	"address": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+	"znnAmount": "28800000000",
+	"qsrAmount": "66666666666"
+}`)
+	common.Json(mergeMiningAPI.GetUncollectedReward(g.User4.Address)).Equals(t, `
+{
+	"address": "z1qraz4ermhhua89a0h0gxxan4lnzrfutgs6xxe2",
+	"znnAmount": "14400000000",
+	"qsrAmount": "33333333333"
+}`)
+	common.Json(mergeMiningAPI.GetFrontierRewardByPage(g.User4.Address, 1, 2)).Equals(t, `
+{
+	"count": 3,
+	"list": [
+		{
+			"epoch": 0,
+			"znnAmount": "14400000000",
+			"qsrAmount": "33333333333"
+		}
+	]
+}`)
+
+	z.ExpectBalance(g.User4.Address, types.ZnnTokenStandard, 500*g.Zexp)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User4.Address,
+		ToAddress: types.MergeMiningContract,
+		Data:      definition.ABIMergeMining.PackMethodPanic(definition.CollectRewardMethodName),
+	}).Error(t, nil)
+	insertMomentums(z, 3)
+	autoreceive(t, z, g.User4.Address)
+	z.ExpectBalance(g.User4.Address, types.ZnnTokenStandard, 500*g.Zexp+14400000000)
+	z.ExpectBalance(g.User4.Address, types.QsrTokenStandard, 500*g.Zexp+33333333333)
+	common.Json(mergeMiningAPI.GetUncollectedReward(g.User4.Address)).Equals(t, `
+{
+	"address": "z1qraz4ermhhua89a0h0gxxan4lnzrfutgs6xxe2",
+	"znnAmount": "0",
+	"qsrAmount": "0"
+}`)
+}
+
+// No share is added in epoch 0, so its reward is paid together with the reward of epoch 1
+func TestMergeMining_RewardsWithoutShares(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep5(t, z)
+
+	z.InsertMomentumsTo(60*6 + 10)
+	defer z.CallContract(addShare(g.User5.Address, mineShare(g.User5.Address, 1, nextBitcoinHeader.Hash, 1712576075, 545259519, nil))).Error(t, nil)
+	insertMomentums(z, 2)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetSharesByAddress(g.User5.Address, 0, 10)).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"address": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"epoch": 1,
+			"shareCount": 1,
+			"weightedWork": "2"
+		}
+	]
+}`)
+
+	z.InsertMomentumsTo(3 * 60 * 6)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.MergeMiningContract,
+		Data:      definition.ABIMergeMining.PackMethodPanic(definition.UpdateMethodName),
+	}).Error(t, nil)
+	insertMomentums(z, 2)
+
+	common.Json(mergeMiningAPI.GetUncollectedReward(g.User5.Address)).Equals(t, `
+{
+	"address": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+	"znnAmount": "86400000000",
+	"qsrAmount": "200000000000"
+}`)
+}
+
+// relayedHeaders returns the headers at heights 838288 and 838289 as served by a bitcoin node
+func relayedHeaders() []*wire.BlockHeader {
+	headers := []definition.BlockHeaderVariable{initialBitcoinHeader, nextBitcoinHeader}
+	wireHeaders := make([]*wire.BlockHeader, len(headers))
+	for index, header := range headers {
+		prevBlock, err := chainhash.NewHashFromStr(header.PrevBlock.String())
+		common.DealWithErr(err)
+		merkleRoot, err := chainhash.NewHashFromStr(header.MerkleRoot.String())
+		common.DealWithErr(err)
+		wireHeaders[index] = &wire.BlockHeader{
+			Version:    header.Version,
+			PrevBlock:  *prevBlock,
+			MerkleRoot: *merkleRoot,
+			Timestamp:  time.Unix(int64(header.Timestamp), 0),
+			Bits:       header.Bits,
+			Nonce:      header.Nonce,
+		}
+	}
+	return wireHeaders
+}
+
+func TestMergeMining_Relayer(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep4(t, z)
+
+	bitcoind := rmock.NewMockBitcoind(838288, relayedHeaders())
+	defer bitcoind.Close()
+	r := relayer.NewRelayer(&relayer.Config{
+		Endpoint: bitcoind.Endpoint(),
+	}, g.User5, z.Chain(), z.Consensus(), z.Broadcaster())
+
+	common.FailIfErr(t, r.Relay())
+	insertMomentums(z, 2)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetHeaderChainInfo()).Equals(t, `
+{
+	"tip": "1eadfc04979cd6adf3d39cff4442773388ac363f431000b0c08b2da19b10af62",
+	"tipHeight": 838289,
+	"tipWorkSum": 4
+}`)
+
+	// The header chain is up to date, nothing is submitted
+	frontier := z.Chain().GetFrontierAccountStore(g.User5.Address).Identifier()
+	common.FailIfErr(t, r.Relay())
+	common.Expect(t, z.Chain().GetFrontierAccountStore(g.User5.Address).Identifier(), frontier)
+}
+
+func TestMergeMining_RelayerRejected(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep4(t, z)
+
+	// The relayed header is too far in the future for the mock chain
+	constants.MaxTimeOffsetSeconds = 2 * 60 * 60
+	bitcoind := rmock.NewMockBitcoind(838288, relayedHeaders())
+	defer bitcoind.Close()
+	r := relayer.NewRelayer(&relayer.Config{
+		Endpoint:          bitcoind.Endpoint(),
+		SubmissionTimeout: time.Minute,
+	}, g.User5, z.Chain(), z.Consensus(), z.Broadcaster())
+
+	common.FailIfErr(t, r.Relay())
+	insertMomentums(z, 2)
+
+	// Still waiting for the submitted header
+	common.FailIfErr(t, r.Relay())
+	insertMomentums(z, 6)
+	common.ExpectError(t, r.Relay(), relayer.ErrSubmissionsRejected)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetHeaderChainInfo()).Equals(t, `
+{
+	"tip": "6e6b8d7ccdd27a85f19fb088dcc9cd7832435294818be1340b9535d904d28a25",
+	"tipHeight": 838288,
+	"tipWorkSum": 2
+}`)
+}
+
+func TestMergeMining_RelayerUnreachable(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep4(t, z)
+
+	bitcoind := rmock.NewMockBitcoind(838288, relayedHeaders())
+	endpoint := bitcoind.Endpoint()
+	bitcoind.Close()
+	r := relayer.NewRelayer(&relayer.Config{
+		Endpoint: endpoint,
+	}, g.User5, z.Chain(), z.Consensus(), z.Broadcaster())
+
+	common.ExpectTrue(t, r.Relay() != nil)
+}
+
+func startStratumServer(t *testing.T, z mock.MockZenon) stratum.Manager {
+	server := stratum.NewServer(&stratum.Config{
+		ListenAddress:     "127.0.0.1:0",
+		HTTPListenAddress: "127.0.0.1:0",
+		ShareChainId:      1,
+	}, g.User5, z.Chain(), z.Consensus(), z.Broadcaster())
+	common.FailIfErr(t, server.Init())
+	common.FailIfErr(t, server.Start())
+	common.FailIfErr(t, server.Refresh())
+	return server
+}
+
+// stratumHTTPCall calls a method of the JSON-RPC fallback of the stratum server
+func stratumHTTPCall(endpoint string, result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	common.DealWithErr(err)
+	response, err := http.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	rpcResult := new(struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	})
+	if err := json.NewDecoder(response.Body).Decode(rpcResult); err != nil {
+		return err
+	}
+	if rpcResult.Error != nil {
+		return errors.New(rpcResult.Error.Message)
+	}
+	return json.Unmarshal(rpcResult.Result, result)
+}
+
+// A CPU miner solves the work served over Stratum, the node submits the shares
+func TestMergeMining_Stratum(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep5(t, z)
+
+	server := startStratumServer(t, z)
+	defer server.Stop()
+
+	miner, err := smock.NewMockMiner(server.Endpoint(), "rig1")
+	common.FailIfErr(t, err)
+	defer miner.Close()
+	common.FailIfErr(t, miner.Mine(2))
+	insertMomentums(z, 2)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetSharesByAddress(g.User5.Address, 0, 10)).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"address": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"epoch": 0,
+			"shareCount": 2,
+			"weightedWork": "4"
+		}
+	]
+}`)
+}
+
+func TestMergeMining_StratumHTTP(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep5(t, z)
+
+	server := startStratumServer(t, z)
+	defer server.Stop()
+
+	template := new(stratum.Template)
+	common.FailIfErr(t, stratumHTTPCall(server.HTTPEndpoint(), template, "mining.getTemplate"))
+	common.Expect(t, template.PrevBlock, nextBitcoinHeader.Hash)
+	common.ExpectUint64(t, uint64(template.Height), 838290)
+	common.ExpectUint64(t, uint64(template.ShareBits), 545259519)
+	common.ExpectString(t, template.Address.String(), g.User5.Address.String())
+
+	solution, err := smock.SolveTemplate(template)
+	common.FailIfErr(t, err)
+	var hash types.Hash
+	common.FailIfErr(t, stratumHTTPCall(server.HTTPEndpoint(), &hash, "mining.submitSolution", solution))
+	err = stratumHTTPCall(server.HTTPEndpoint(), &hash, "mining.submitSolution", solution)
+	common.ExpectString(t, fmt.Sprint(err), stratum.ErrDuplicateShare.Error())
+	solution.JobId = "unknown"
+	err = stratumHTTPCall(server.HTTPEndpoint(), &hash, "mining.submitSolution", solution)
+	common.ExpectString(t, fmt.Sprint(err), stratum.ErrUnknownJob.Error())
+	insertMomentums(z, 2)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.GetSharesByAddress(g.User5.Address, 0, 10)).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"address": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"epoch": 0,
+			"shareCount": 1,
+			"weightedWork": "2"
+		}
+	]
+}`)
+
+	// a new tip makes the previous template stale
+	defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, mineBitcoinHeader(838290, nextBitcoinHeader.Hash, types.ZeroHash, 1712576402))).Error(t, nil)
+	insertMomentums(z, 2)
+	common.FailIfErr(t, server.Refresh())
+	common.FailIfErr(t, stratumHTTPCall(server.HTTPEndpoint(), template, "mining.getTemplate"))
+	common.ExpectUint64(t, uint64(template.Height), 838291)
+}
+
+// mineBlockHeader searches for a nonce that satisfies bits
+func mineBlockHeader(prevBlock types.Hash, merkleRoot types.Hash, timestamp uint32, bits uint32) definition.BlockHeaderVariable {
+	header := definition.BlockHeaderVariable{
+		BaseHeader: definition.BaseHeader{
+			Version:    536870912,
+			PrevBlock:  prevBlock,
+			MerkleRoot: merkleRoot,
+			Timestamp:  timestamp,
+			Bits:       bits,
+		},
+	}
+	target := blockchain.CompactToBig(bits)
+	for {
+		hash := header.BlockHashChain()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			break
+		}
+		header.Nonce += 1
+	}
+	header.Hash = header.BlockHash()
+	return header
+}
+
+// transactionMerkleBranch returns the merkle root of the transactions and the merkle branch of the one at index
+func transactionMerkleBranch(transactions [][]byte, index uint32) (types.Hash, []types.Hash) {
+	level := make([]chainhash.Hash, len(transactions))
+	for i, data := range transactions {
+		transaction := new(wire.MsgTx)
+		common.DealWithErr(transaction.Deserialize(bytes.NewReader(data)))
+		level[i] = transaction.TxHash()
+	}
+	branch := make([]types.Hash, 0)
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		branch = append(branch, types.HexToHashPanic(level[index^1].String()))
+		next := make([]chainhash.Hash, 0, len(level)/2)
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, chainhash.DoubleHashH(append(level[i][:], level[i+1][:]...)))
+		}
+		level = next
+		index >>= 1
+	}
+	return types.HexToHashPanic(level[0].String()), branch
+}
+
+func verifyTransaction(from types.Address, transaction []byte, merkleBranch []types.Hash, index uint32, blockHash types.Hash) *nom.AccountBlock {
+	return &nom.AccountBlock{
+		Address:       from,
+		ToAddress:     types.MergeMiningContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data: definition.ABIMergeMining.PackMethodPanic(definition.VerifyTransactionMethodName,
+			transaction,
+			merkleBranch,
+			index,
+			blockHash,
+		),
+	}
+}
+
+func TestMergeMining_VerifyTransaction(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep2(t, z)
+	defer z.CallContract(setPowNetwork(g.User5.Address, constants.RegTestPowParams.Network)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	transactions := make([][]byte, 3)
+	for i := range transactions {
+		transaction := wire.NewMsgTx(wire.TxVersion)
+		transaction.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, uint32(i)), []byte{0x51}, nil))
+		transaction.AddTxOut(wire.NewTxOut(int64(i+1)*100000, []byte{0x51}))
+		var buffer bytes.Buffer
+		common.DealWithErr(transaction.Serialize(&buffer))
+		transactions[i] = buffer.Bytes()
+	}
+	merkleRoot, merkleBranch := transactionMerkleBranch(transactions, 1)
+
+	timestamp := uint32(1712573645)
+	header := mineBlockHeader(types.ZeroHash, merkleRoot, timestamp, 0x207fffff)
+	header.Height = 1000
+	blockHash := header.Hash
+	defer z.CallContract(setInitialBitcoinBlockHeader(g.User5.Address, header)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	mergeMiningAPI := embedded.NewMergeMiningApi(z)
+	common.Json(mergeMiningAPI.VerifyTransaction(transactions[1], merkleBranch, 1, blockHash)).Equals(t, `
+{
+	"txId": "35457bb05428f5f50353cb9ad5c4d95681359b7e5ba49941d5eca6ca3a0f1194",
+	"blockHash": "5882bf8c3642ca1dca936b6a7c1dda32c0176c3097033015229c7a1b3ad995b7",
+	"blockHeight": 1000,
+	"index": 1,
+	"confirmations": 1,
+	"confirmed": false,
+	"recorded": false
+}`)
+	defer z.CallContract(verifyTransaction(g.User1.Address, transactions[1], merkleBranch, 1, blockHash)).Error(t, constants.ErrInsufficientConfirmations)
+	insertMomentums(z, 2)
+
+	for i := uint32(1); i < constants.TransactionConfirmations; i += 1 {
+		header = mineBlockHeader(header.Hash, types.ZeroHash, timestamp+600*i, 0x207fffff)
+		defer z.CallContract(addBitcoinBlockHeader(g.User5.Address, header)).Error(t, nil)
+		insertMomentums(z, 2)
+	}
+
+	defer z.CallContract(verifyTransaction(g.User1.Address, transactions[1], merkleBranch, 0, blockHash)).Error(t, constants.ErrInvalidMerkleBranch)
+	insertMomentums(z, 2)
+	defer z.CallContract(verifyTransaction(g.User1.Address, transactions[1], merkleBranch, 1, blockHash)).Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(verifyTransaction(g.User1.Address, transactions[1], merkleBranch, 1, blockHash)).Error(t, constants.ErrTransactionAlreadyVerified)
+	insertMomentums(z, 2)
+
+	common.Json(mergeMiningAPI.VerifyTransaction(transactions[1], merkleBranch, 1, blockHash)).Equals(t, `
+{
+	"txId": "35457bb05428f5f50353cb9ad5c4d95681359b7e5ba49941d5eca6ca3a0f1194",
+	"blockHash": "5882bf8c3642ca1dca936b6a7c1dda32c0176c3097033015229c7a1b3ad995b7",
+	"blockHeight": 1000,
+	"index": 1,
+	"confirmations": 6,
+	"confirmed": true,
+	"recorded": true
+}`)
+	verification, err := mergeMiningAPI.VerifyTransaction(transactions[1], merkleBranch, 1, blockHash)
+	common.DealWithErr(err)
+	common.Json(mergeMiningAPI.GetVerifiedTransaction(verification.TxId)).Equals(t, `
+{
+	"txId": "35457bb05428f5f50353cb9ad5c4d95681359b7e5ba49941d5eca6ca3a0f1194",
+	"blockHash": "5882bf8c3642ca1dca936b6a7c1dda32c0176c3097033015229c7a1b3ad995b7",
+	"blockHeight": 1000,
+	"index": 1,
+	"momentumHeight": 71
+}`)
+	common.Json(mergeMiningAPI.GetVerifiedTransaction(blockHash)).Error(t, constants.ErrDataNonExistent)
+}
+
+// mineShare builds a coinbase transaction committing to address and shareChainId, places it in a block
+// with the given transactions and searches for a nonce that satisfies the share chain bits
+func mineShare(address types.Address, shareChainId uint8, prevBlock types.Hash, timestamp uint32, shareChainBits uint32, transactions []types.Hash) definition.Share {
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	signatureScript := append([]byte{0x03, 0x92, 0xca, 0x0c}, definition.GetShareCommitment(shareChainId, address)...)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), signatureScript, nil))
+	coinbase.AddTxOut(wire.NewTxOut(625000000, []byte{0x51}))
+	var coinbaseBytes bytes.Buffer
+	common.DealWithErr(coinbase.Serialize(&coinbaseBytes))
+
+	level := []chainhash.Hash{coinbase.TxHash()}
+	for _, transaction := range transactions {
+		hash, err := chainhash.NewHashFromStr(transaction.String())
+		common.DealWithErr(err)
+		level = append(level, *hash)
+	}
+	merkleBranch := make([]types.Hash, 0)
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		merkleBranch = append(merkleBranch, types.HexToHashPanic(level[1].String()))
+		next := make([]chainhash.Hash, 0, len(level)/2)
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, chainhash.DoubleHashH(append(level[i][:], level[i+1][:]...)))
+		}
+		level = next
+	}
+
+	share := definition.Share{
+		ShareChainId: shareChainId,
+		Version:      536928256,
+		PrevBlock:    prevBlock,
+		MerkleRoot:   types.HexToHashPanic(level[0].String()),
+		Timestamp:    timestamp,
+		Coinbase:     coinbaseBytes.Bytes(),
+		MerkleBranch: merkleBranch,
+	}
+	header := definition.BaseHeader{
+		Version:    share.Version,
+		PrevBlock:  share.PrevBlock,
+		MerkleRoot: share.MerkleRoot,
+		Timestamp:  share.Timestamp,
+		Bits:       constants.RegTestPowParams.PowLimitBits,
+	}
+	target := blockchain.CompactToBig(shareChainBits)
+	for {
+		hash := header.BlockHashChain()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			break
+		}
+		header.Nonce += 1
+	}
+	share.Nonce = header.Nonce
+	return share
 }
 
 func TestSimpleMerkleTree(t *testing.T) {
@@ -458,11 +1282,8 @@ func addShare(from types.Address, share definition.Share) *nom.AccountBlock {
 			share.MerkleRoot,
 			share.Timestamp,
 			share.Nonce,
-			share.Proof,
-			share.Prooff,
-			share.Proofff,
-			share.Prooffff,
-			share.AdditionalData,
+			share.Coinbase,
+			share.MerkleBranch,
 		),
 	}
 }
@@ -556,3 +1377,32 @@ func setHeaderChainMetadata(administrator types.Address, metadata string) *nom.A
 			metadata),
 	}
 }
+
+func setPowNetwork(administrator types.Address, network string) *nom.AccountBlock {
+	return &nom.AccountBlock{
+		Address:       administrator,
+		ToAddress:     types.MergeMiningContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data: definition.ABIMergeMining.PackMethodPanic(definition.SetPowNetworkMethodName,
+			network),
+	}
+}
+
+// Shares follow the timestamp rules of block headers
+func TestMergeMining_ShareTimestamp(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	mergeMiningStep5(t, z)
+
+	prevBlock := nextBitcoinHeader.Hash
+	defer z.CallContract(addShare(g.User5.Address, mineShare(g.User5.Address, 1, prevBlock, nextBitcoinHeader.Timestamp, 545259519, nil))).
+		Error(t, constants.ErrTimestampTooOld)
+	insertMomentums(z, 2)
+
+	constants.MaxTimeOffsetSeconds = 2 * 60 * 60
+	defer z.CallContract(addShare(g.User5.Address, mineShare(g.User5.Address, 1, prevBlock, 1712576075, 545259519, nil))).
+		Error(t, constants.ErrTimestampTooFarInFuture)
+	insertMomentums(z, 2)
+}
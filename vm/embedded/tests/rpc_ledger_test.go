@@ -8,9 +8,18 @@ import (
 
 	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/crypto"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/indexer"
+	"github.com/zenon-network/go-zenon/pruner"
 	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/zenon/mock"
 )
 
@@ -742,3 +751,696 @@ func TestRPCLedger_Errors(t *testing.T) {
 	common.Json(ledgerApi.GetDetailedMomentumsByHeight(1, 1234)).Error(t, api.ErrCountParamTooBig)
 	common.Json(ledgerApi.GetAccountBlocksByPage(types.ZeroAddress, 0, 1234)).Error(t, api.ErrPageSizeParamTooBig)
 }
+
+func TestRPCLedger_SimulateAccountBlock(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	htlcApi := embedded.NewHtlcApi(z)
+	defer z.StopPanic()
+	activateHtlc(z)
+
+	simulate := func(block *nom.AccountBlock) *api.AccountBlockSimulation {
+		simulation, err := ledgerApi.SimulateAccountBlock(&api.AccountBlock{AccountBlock: *block})
+		common.FailIfErr(t, err)
+		return simulation
+	}
+
+	// simple transfer
+	simulation := simulate(&nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	})
+	common.ExpectString(t, simulation.SendError, "")
+	common.ExpectUint64(t, simulation.RequiredPlasma, 21000)
+	common.ExpectTrue(t, simulation.Receive == nil)
+	common.Json(simulation.BalanceDeltas, nil).Equals(t, `
+[
+	{
+		"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"delta": "-1000000000"
+	},
+	{
+		"address": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"delta": "1000000000"
+	}
+]`)
+
+	// rejected by the send-block validation
+	simulation = simulate(&nom.AccountBlock{
+		BlockType: nom.BlockTypeUserSend,
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address, int64(genesisTimestamp+300), uint8(definition.HashTypeSHA3), uint8(32), crypto.Hash(preimageZ)),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+	})
+	common.ExpectString(t, simulation.SendError, constants.ErrInvalidTokenOrAmount.Error())
+	common.ExpectTrue(t, simulation.Receive == nil)
+
+	// successful embedded call
+	simulation = simulate(&nom.AccountBlock{
+		BlockType: nom.BlockTypeUserSend,
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address, int64(genesisTimestamp+300), uint8(definition.HashTypeSHA3), uint8(32), crypto.Hash(preimageZ)),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	})
+	common.ExpectString(t, simulation.SendError, "")
+	common.ExpectUint64(t, simulation.ReceiveStatus, 1)
+	common.ExpectString(t, simulation.MethodError, "")
+	common.ExpectUint64(t, uint64(len(simulation.ReceiveChanges)), 2)
+	common.Json(simulation.BalanceDeltas, nil).Equals(t, `
+[
+	{
+		"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"delta": "-1000000000"
+	},
+	{
+		"address": "z1qxemdeddedxhtlcxxxxxxxxxxxxxxxxxygecvw",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"delta": "1000000000"
+	}
+]`)
+
+	// nothing is committed
+	common.Json(htlcApi.GetById(simulation.Block.Hash)).Error(t, constants.ErrDataNonExistent)
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 12000*g.Zexp)
+	z.ExpectBalance(types.HtlcContract, types.ZnnTokenStandard, 0)
+
+	// failing embedded call refunds the tokens
+	simulation = simulate(&nom.AccountBlock{
+		BlockType: nom.BlockTypeUserSend,
+		Address:   g.User1.Address,
+		ToAddress: types.HtlcContract,
+		Data: definition.ABIHtlc.PackMethodPanic(definition.CreateHtlcMethodName,
+			g.User2.Address, int64(genesisTimestamp), uint8(definition.HashTypeSHA3), uint8(32), crypto.Hash(preimageZ)),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(5 * g.Zexp),
+	})
+	common.ExpectString(t, simulation.SendError, "")
+	common.ExpectUint64(t, simulation.ReceiveStatus, 2)
+	common.ExpectString(t, simulation.MethodError, constants.ErrInvalidExpirationTime.Error())
+	common.Json(simulation.Receive, nil).Equals(t, `
+{
+	"version": 1,
+	"chainIdentifier": 100,
+	"blockType": 5,
+	"hash": "022e3e1fd218dc8335c8d8c9dc1e62d2ed9425b00af3cadd09229be06ee71532",
+	"previousHash": "0f8435dea19abb9d94513aad2c7b25013bcd6f238d106c89e502b0b334bf0309",
+	"height": 2,
+	"momentumAcknowledged": {
+		"hash": "b879e8a100386b4052bb1d28cdbc48ec4f6366517b48b620a078f0426b2b11f8",
+		"height": 20
+	},
+	"address": "z1qxemdeddedxhtlcxxxxxxxxxxxxxxxxxygecvw",
+	"toAddress": "z1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsggv2f",
+	"amount": "0",
+	"tokenStandard": "zts1qqqqqqqqqqqqqqqqtq587y",
+	"fromBlockHash": "40d3c3f97c8695ec2d8f799f10a64c56e7a89d6b4e576de075c1b16b1deec0cf",
+	"descendantBlocks": [
+		{
+			"version": 1,
+			"chainIdentifier": 100,
+			"blockType": 4,
+			"hash": "0f8435dea19abb9d94513aad2c7b25013bcd6f238d106c89e502b0b334bf0309",
+			"previousHash": "0000000000000000000000000000000000000000000000000000000000000000",
+			"height": 1,
+			"momentumAcknowledged": {
+				"hash": "b879e8a100386b4052bb1d28cdbc48ec4f6366517b48b620a078f0426b2b11f8",
+				"height": 20
+			},
+			"address": "z1qxemdeddedxhtlcxxxxxxxxxxxxxxxxxygecvw",
+			"toAddress": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"amount": "500000000",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"fromBlockHash": "0000000000000000000000000000000000000000000000000000000000000000",
+			"descendantBlocks": [],
+			"data": null,
+			"fusedPlasma": 0,
+			"difficulty": 0,
+			"nonce": "0000000000000000",
+			"basePlasma": 0,
+			"usedPlasma": 0,
+			"changesHash": "0000000000000000000000000000000000000000000000000000000000000000",
+			"publicKey": null,
+			"signature": null
+		}
+	],
+	"data": "AAAAAAAAAAI=",
+	"fusedPlasma": 0,
+	"difficulty": 0,
+	"nonce": "0000000000000000",
+	"basePlasma": 0,
+	"usedPlasma": 0,
+	"changesHash": "15468dd125811173b9d79a6f99e724b1fcaadf58681e61e9d64b6a7945c2c009",
+	"publicKey": null,
+	"signature": null
+}`)
+	common.Json(simulation.BalanceDeltas, nil).Equals(t, `[]`)
+
+	// only send-blocks can be simulated
+	common.Json(ledgerApi.SimulateAccountBlock(&api.AccountBlock{AccountBlock: nom.AccountBlock{
+		BlockType: nom.BlockTypeUserReceive,
+		Address:   g.User1.Address,
+	}})).Error(t, vm.ErrSimulationBlockType)
+}
+
+func TestRPCLedger_GetReceipt(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+	activateHtlc(z)
+
+	// user transfer, the receipt is pending until the send-block is confirmed and received
+	sendBlock := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	common.Json(ledgerApi.GetReceipt(sendBlock.Hash)).Equals(t, `null`)
+	z.InsertNewMomentum()
+	common.Json(ledgerApi.GetReceipt(sendBlock.Hash)).Equals(t, `
+{
+	"sendBlockHash": "d755015ca9a216ce10f71d44a36e034cc50e79e84b91a547f345073d8eaf1053",
+	"status": "pending",
+	"sendConfirmation": {
+		"numConfirmations": 1,
+		"momentumHeight": 21,
+		"momentumHash": "5b99a2ff1f099edc22142db7e42cdd7d17fa930339d9a7a12477c6aa0fbd2aff",
+		"momentumTimestamp": 1000000200
+	},
+	"receiveBlockHash": "0000000000000000000000000000000000000000000000000000000000000000",
+	"receiveConfirmation": null,
+	"embedded": false,
+	"executionStatus": "",
+	"refund": null,
+	"transfers": []
+}`)
+	receiveBlock := z.InsertReceiveBlock(sendBlock.Header(), nil, nil, mock.SkipVmChanges)
+	common.Json(ledgerApi.GetReceipt(sendBlock.Hash)).Equals(t, `
+{
+	"sendBlockHash": "d755015ca9a216ce10f71d44a36e034cc50e79e84b91a547f345073d8eaf1053",
+	"status": "received",
+	"sendConfirmation": {
+		"numConfirmations": 1,
+		"momentumHeight": 21,
+		"momentumHash": "5b99a2ff1f099edc22142db7e42cdd7d17fa930339d9a7a12477c6aa0fbd2aff",
+		"momentumTimestamp": 1000000200
+	},
+	"receiveBlockHash": "d24bb1713bbd63aca9f850da2869c9b0c8befef2f16e65cda3d8856dbe3b4787",
+	"receiveConfirmation": null,
+	"embedded": false,
+	"executionStatus": "",
+	"refund": null,
+	"transfers": []
+}`)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	receipt, err := ledgerApi.GetReceipt(sendBlock.Hash)
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, receipt.IsFinal())
+	common.ExpectTrue(t, receipt.ReceiveBlockHash == receiveBlock.Hash)
+	common.Json(receipt, err).Equals(t, `
+{
+	"sendBlockHash": "d755015ca9a216ce10f71d44a36e034cc50e79e84b91a547f345073d8eaf1053",
+	"status": "confirmed",
+	"sendConfirmation": {
+		"numConfirmations": 3,
+		"momentumHeight": 21,
+		"momentumHash": "5b99a2ff1f099edc22142db7e42cdd7d17fa930339d9a7a12477c6aa0fbd2aff",
+		"momentumTimestamp": 1000000200
+	},
+	"receiveBlockHash": "d24bb1713bbd63aca9f850da2869c9b0c8befef2f16e65cda3d8856dbe3b4787",
+	"receiveConfirmation": {
+		"numConfirmations": 2,
+		"momentumHeight": 22,
+		"momentumHash": "fe6e02eb05ee81f83f005713da39f2ce2420f392f77cc1c76db258e34ceb9f24",
+		"momentumTimestamp": 1000000210
+	},
+	"embedded": false,
+	"executionStatus": "",
+	"refund": null,
+	"transfers": []
+}`)
+
+	// successful embedded call
+	sendBlock = createHtlc(z, g.User1.Address, g.User2.Address, genesisTimestamp+1000, crypto.Hash(preimageZ))
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	common.Json(ledgerApi.GetReceipt(sendBlock.Hash)).Equals(t, `
+{
+	"sendBlockHash": "7bddb5ad155cafbab791336d4f9262addf23dca9e6336d7dbc18fcde4c26f2cc",
+	"status": "confirmed",
+	"sendConfirmation": {
+		"numConfirmations": 2,
+		"momentumHeight": 24,
+		"momentumHash": "5f95fe01a0b9fea943f45035620c4947776619435f4c64eea68a97ba4a259991",
+		"momentumTimestamp": 1000000230
+	},
+	"receiveBlockHash": "cd5e0ccb3052001a7c8a1855b8a9b32c4837510dbe889ad23e34c46d70ddc43d",
+	"receiveConfirmation": {
+		"numConfirmations": 1,
+		"momentumHeight": 25,
+		"momentumHash": "ba983eb7b711ca6cd379d1d5cb144293770e43f1edc5d09aea297f87d64bbbcc",
+		"momentumTimestamp": 1000000240
+	},
+	"embedded": true,
+	"executionStatus": "success",
+	"refund": null,
+	"transfers": []
+}`)
+
+	// failed embedded call, the sent tokens are refunded
+	sendBlock = createHtlc(z, g.User1.Address, g.User2.Address, genesisTimestamp, crypto.Hash(preimageZ))
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+	common.Json(ledgerApi.GetReceipt(sendBlock.Hash)).Equals(t, `
+{
+	"sendBlockHash": "a12b01204460b8eb53cb82f0c1093a132b75a21f4483f6e82191ca4321f58461",
+	"status": "confirmed",
+	"sendConfirmation": {
+		"numConfirmations": 2,
+		"momentumHeight": 26,
+		"momentumHash": "f59b665a88ace9d298eee79ca934c72d08080a5b3036f70af05e706365eee083",
+		"momentumTimestamp": 1000000250
+	},
+	"receiveBlockHash": "3ea27ae4291ba0b6038fb4d8c3fd325d2979b2c9341a8ff8c93db7da6ef757a0",
+	"receiveConfirmation": {
+		"numConfirmations": 1,
+		"momentumHeight": 27,
+		"momentumHash": "6fc227efb927a0fa259881c2d2f13a77acd56490f0739496fc8f1b83645bd12f",
+		"momentumTimestamp": 1000000260
+	},
+	"embedded": true,
+	"executionStatus": "failed",
+	"refund": {
+		"hash": "dfc7da3538ac8da927de7980f383a9c5e6b9aca0520d991a2a12cecef89a8437",
+		"toAddress": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"amount": "1000000000"
+	},
+	"transfers": []
+}`)
+}
+
+func TestRPCLedger_GetTransfers(t *testing.T) {
+	z := mock.NewMockZenonWithIndexer(t, &indexer.Config{Transfers: true})
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+	activateHtlc(z)
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User3.Address,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(5 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User2.Address,
+		ToAddress:     g.User1.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(1 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	// failed embedded call, the refund is an incoming transfer
+	createHtlc(z, g.User1.Address, g.User2.Address, genesisTimestamp, crypto.Hash(preimageZ))
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(ledgerApi.GetTransfers(g.User1.Address, nil, "", 10)).Equals(t, `
+{
+	"list": [
+		{
+			"direction": "in",
+			"counterparty": "z1qxemdeddedxhtlcxxxxxxxxxxxxxxxxxygecvw",
+			"blockHash": "3f9c007e81b8dad367c3ca6d26a0fcdb23b04ff2fa42eee80a79ec5f533c272b",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"momentumHeight": 23,
+			"momentumTimestamp": 1000000220,
+			"cursor": "00000000000000170000000001"
+		},
+		{
+			"direction": "in",
+			"counterparty": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"blockHash": "cad540120276d49212e69160700c794b114d76e5b889673868626bd661fdc4e7",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "100000000",
+			"momentumHeight": 22,
+			"momentumTimestamp": 1000000210,
+			"cursor": "00000000000000160000000101"
+		},
+		{
+			"direction": "out",
+			"counterparty": "z1qxemdeddedxhtlcxxxxxxxxxxxxxxxxxygecvw",
+			"blockHash": "be314b65b91dacf402705424b43c62dab1f510c4e63bd74b7f8a28610ab94d8f",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"momentumHeight": 22,
+			"momentumTimestamp": 1000000210,
+			"cursor": "00000000000000160000000000"
+		},
+		{
+			"direction": "out",
+			"counterparty": "z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+			"blockHash": "bdbc902e8580078f35add8f68d64adbbdaece468535731ab3cec18224432f3ec",
+			"tokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll",
+			"amount": "500000000",
+			"momentumHeight": 21,
+			"momentumTimestamp": 1000000200,
+			"cursor": "00000000000000150000000100"
+		},
+		{
+			"direction": "out",
+			"counterparty": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"blockHash": "d755015ca9a216ce10f71d44a36e034cc50e79e84b91a547f345073d8eaf1053",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"momentumHeight": 21,
+			"momentumTimestamp": 1000000200,
+			"cursor": "00000000000000150000000000"
+		}
+	],
+	"nextCursor": "",
+	"indexedHeight": 23
+}`)
+	common.Json(ledgerApi.GetTransfers(g.User1.Address, &indexer.TransferFilter{Direction: indexer.DirectionIn}, "", 10)).Equals(t, `
+{
+	"list": [
+		{
+			"direction": "in",
+			"counterparty": "z1qxemdeddedxhtlcxxxxxxxxxxxxxxxxxygecvw",
+			"blockHash": "3f9c007e81b8dad367c3ca6d26a0fcdb23b04ff2fa42eee80a79ec5f533c272b",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"momentumHeight": 23,
+			"momentumTimestamp": 1000000220,
+			"cursor": "00000000000000170000000001"
+		},
+		{
+			"direction": "in",
+			"counterparty": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"blockHash": "cad540120276d49212e69160700c794b114d76e5b889673868626bd661fdc4e7",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "100000000",
+			"momentumHeight": 22,
+			"momentumTimestamp": 1000000210,
+			"cursor": "00000000000000160000000101"
+		}
+	],
+	"nextCursor": "",
+	"indexedHeight": 23
+}`)
+	qsr := types.QsrTokenStandard
+	common.Json(ledgerApi.GetTransfers(g.User1.Address, &indexer.TransferFilter{TokenStandard: &qsr}, "", 10)).Equals(t, `
+{
+	"list": [
+		{
+			"direction": "out",
+			"counterparty": "z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+			"blockHash": "bdbc902e8580078f35add8f68d64adbbdaece468535731ab3cec18224432f3ec",
+			"tokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll",
+			"amount": "500000000",
+			"momentumHeight": 21,
+			"momentumTimestamp": 1000000200,
+			"cursor": "00000000000000150000000100"
+		}
+	],
+	"nextCursor": "",
+	"indexedHeight": 23
+}`)
+	counterparty := g.User2.Address
+	common.Json(ledgerApi.GetTransfers(g.User1.Address, &indexer.TransferFilter{Counterparty: &counterparty, ToHeight: 21}, "", 10)).Equals(t, `
+{
+	"list": [
+		{
+			"direction": "out",
+			"counterparty": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"blockHash": "d755015ca9a216ce10f71d44a36e034cc50e79e84b91a547f345073d8eaf1053",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"momentumHeight": 21,
+			"momentumTimestamp": 1000000200,
+			"cursor": "00000000000000150000000000"
+		}
+	],
+	"nextCursor": "",
+	"indexedHeight": 23
+}`)
+
+	// pages stay the same when new transfers are indexed
+	page, err := ledgerApi.GetTransfers(g.User1.Address, nil, "", 2)
+	common.FailIfErr(t, err)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(2 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	common.Json(ledgerApi.GetTransfers(g.User1.Address, nil, page.NextCursor, 2)).Equals(t, `
+{
+	"list": [
+		{
+			"direction": "out",
+			"counterparty": "z1qxemdeddedxhtlcxxxxxxxxxxxxxxxxxygecvw",
+			"blockHash": "be314b65b91dacf402705424b43c62dab1f510c4e63bd74b7f8a28610ab94d8f",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"amount": "1000000000",
+			"momentumHeight": 22,
+			"momentumTimestamp": 1000000210,
+			"cursor": "00000000000000160000000000"
+		},
+		{
+			"direction": "out",
+			"counterparty": "z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+			"blockHash": "bdbc902e8580078f35add8f68d64adbbdaece468535731ab3cec18224432f3ec",
+			"tokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll",
+			"amount": "500000000",
+			"momentumHeight": 21,
+			"momentumTimestamp": 1000000200,
+			"cursor": "00000000000000150000000100"
+		}
+	],
+	"nextCursor": "00000000000000150000000100",
+	"indexedHeight": 24
+}`)
+
+	common.Json(ledgerApi.GetTransfers(g.User1.Address, &indexer.TransferFilter{Direction: "sideways"}, "", 10)).Error(t, indexer.ErrInvalidDirection)
+	common.Json(ledgerApi.GetTransfers(g.User1.Address, nil, "00", 10)).Error(t, indexer.ErrInvalidCursor)
+}
+
+func TestRPCLedger_HistoricalState(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	stakeApi := embedded.NewStakeApi(z)
+	defer z.StopPanic()
+
+	before, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.StakeContract,
+		Data:          definition.ABIStake.PackMethodPanic(definition.StakeMethodName, constants.StakeTimeMinSec),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}).Error(t, nil)
+	insertMomentums(z, 2)
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 11990*g.Zexp)
+
+	byHeight := &api.MomentumRef{Height: before.Height}
+	byHash := &api.MomentumRef{Hash: &before.Hash}
+	ledgerAtHeight, err := ledgerApi.At(byHeight)
+	common.FailIfErr(t, err)
+	ledgerAtHash, err := ledgerApi.At(byHash)
+	common.FailIfErr(t, err)
+	stakeAtHeight, err := stakeApi.At(byHeight)
+	common.FailIfErr(t, err)
+	common.Json(ledgerAtHeight.GetAccountInfoByAddress(g.User1.Address)).Equals(t, `
+{
+	"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+	"accountHeight": 1,
+	"balanceInfoMap": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": {
+			"token": {
+				"name": "QuasarCoin",
+				"symbol": "QSR",
+				"domain": "zenon.network",
+				"totalSupply": "180550000000000",
+				"decimals": 8,
+				"owner": "z1qxemdeddedxstakexxxxxxxxxxxxxxxxjv8v62",
+				"tokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll",
+				"maxSupply": "4611686018427387903",
+				"isBurnable": true,
+				"isMintable": true,
+				"isUtility": true
+			},
+			"balance": "12000000000000"
+		},
+		"zts1znnxxxxxxxxxxxxx9z4ulx": {
+			"token": {
+				"name": "Zenon Coin",
+				"symbol": "ZNN",
+				"domain": "zenon.network",
+				"totalSupply": "19500000000000",
+				"decimals": 8,
+				"owner": "z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg",
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"maxSupply": "4611686018427387903",
+				"isBurnable": true,
+				"isMintable": true,
+				"isUtility": true
+			},
+			"balance": "1200000000000"
+		}
+	}
+}`)
+	common.Json(ledgerAtHash.GetAccountInfoByAddress(g.User1.Address)).Equals(t, `
+{
+	"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+	"accountHeight": 1,
+	"balanceInfoMap": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": {
+			"token": {
+				"name": "QuasarCoin",
+				"symbol": "QSR",
+				"domain": "zenon.network",
+				"totalSupply": "180550000000000",
+				"decimals": 8,
+				"owner": "z1qxemdeddedxstakexxxxxxxxxxxxxxxxjv8v62",
+				"tokenStandard": "zts1qsrxxxxxxxxxxxxxmrhjll",
+				"maxSupply": "4611686018427387903",
+				"isBurnable": true,
+				"isMintable": true,
+				"isUtility": true
+			},
+			"balance": "12000000000000"
+		},
+		"zts1znnxxxxxxxxxxxxx9z4ulx": {
+			"token": {
+				"name": "Zenon Coin",
+				"symbol": "ZNN",
+				"domain": "zenon.network",
+				"totalSupply": "19500000000000",
+				"decimals": 8,
+				"owner": "z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg",
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"maxSupply": "4611686018427387903",
+				"isBurnable": true,
+				"isMintable": true,
+				"isUtility": true
+			},
+			"balance": "1200000000000"
+		}
+	}
+}`)
+	common.Json(stakeAtHeight.GetEntriesByAddress(g.User1.Address, 0, 10)).Equals(t, `
+{
+	"totalAmount": "0",
+	"totalWeightedAmount": "0",
+	"count": 0,
+	"list": []
+}`)
+	common.Json(stakeApi.GetEntriesByAddress(g.User1.Address, 0, 10)).Equals(t, `
+{
+	"totalAmount": "1000000000",
+	"totalWeightedAmount": "1000000000",
+	"count": 1,
+	"list": [
+		{
+			"amount": "1000000000",
+			"weightedAmount": "1000000000",
+			"startTimestamp": 1000000010,
+			"expirationTimestamp": 1000003610,
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"id": "60be4b855f8b1871ccfbe13b348039c4106a15a902b2e983dc289c809691f545"
+		}
+	]
+}`)
+
+	common.ExpectError(t, ledgerAtHeight.PublishRawTransaction(&api.AccountBlock{}), api.ErrFrontierOnly)
+	common.Json(ledgerAtHeight.SimulateAccountBlock(&api.AccountBlock{})).Error(t, api.ErrFrontierOnly)
+
+	common.Json(ledgerApi.At(&api.MomentumRef{Height: before.Height + 100})).Error(t, api.ErrMomentumNotFound)
+	insertMomentums(z, api.HistoricalStateWindow)
+	common.Json(stakeApi.At(byHeight)).Error(t, api.ErrHistoricalStateUnavailable)
+}
+
+func TestRPCLedger_Pruning(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	// send1 is received and isn't the frontier of User1 anymore, so it is pruned
+	// send2 is the frontier of User1 and isn't received, so it is kept
+	send1 := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertReceiveBlock(send1.Header(), nil, nil, mock.SkipVmChanges)
+	send2 := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	insertMomentums(z, 2)
+
+	frontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	common.FailIfErr(t, pruner.NewPruner(&pruner.Config{Retention: 2}, z.Chain(), nil).Prune())
+	common.ExpectUint64(t, z.Chain().GetPrunedHeight(), frontier.Height-2)
+
+	common.Json(ledgerApi.GetAccountBlockByHash(send1.Hash)).Error(t, store.ErrDataPruned)
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 2)).Error(t, store.ErrDataPruned)
+	common.Json(ledgerApi.GetDetailedMomentumsByHeight(frontier.Height-3, 1)).Error(t, store.ErrDataPruned)
+	common.Json(ledgerApi.At(&api.MomentumRef{Height: frontier.Height - 2})).Error(t, store.ErrDataPruned)
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 3, 1)).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 3,
+	"list": [
+		{
+			"height": 3
+		}
+	]
+}`)
+
+	prunedMomentum, err := z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(frontier.Height - 3)
+	common.FailIfErr(t, err)
+	insert := z.Chain().AcquireInsert("rollback below the pruned height")
+	common.ExpectTrue(t, z.Chain().RollbackTo(insert, prunedMomentum.Identifier()) != nil)
+	lastPrunedMomentum, err := z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(frontier.Height - 2)
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, z.Chain().RollbackTo(insert, lastPrunedMomentum.Identifier()) != nil)
+	insert.Unlock()
+
+	// the unreceived send2 can still be received
+	z.InsertReceiveBlock(send2.Header(), nil, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.ExpectBalance(g.User2.Address, types.ZnnTokenStandard, 8020*g.Zexp)
+
+	// the indexes can't be rebuilt from the pruned chain
+	_, indexerDb := db.NewLevelDB(t.TempDir())
+	defer indexerDb.Close()
+	common.ExpectError(t, indexer.NewIndexer(&indexer.Config{Transfers: true}, z.Chain(), indexerDb).Init(), indexer.ErrRebuildOnPrunedNode)
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/genesis"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/snapshot"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+func TestSnapshot_ExportImport(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	// the replayed momentums are produced by the producers of the momentums before the base
+	insertMomentums(z, 20)
+
+	send := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	insertMomentums(z, 3)
+	z.InsertReceiveBlock(send.Header(), nil, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	insertMomentums(z, 2)
+
+	frontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	buffer := new(bytes.Buffer)
+	header, root, err := snapshot.Export(buffer, z.ChainManager(), z.ConsensusDB(), z.Chain(), 0, 4)
+	common.FailIfErr(t, err)
+	common.Expect(t, header.Momentum, frontier.Identifier())
+	common.ExpectUint64(t, header.Base.Height, frontier.Height-4)
+	data := buffer.Bytes()
+
+	// corrupted snapshots are rejected
+	corrupted := bytes.Clone(data)
+	corrupted[len(corrupted)/2] ^= 0xff
+	_, _, err = snapshot.Import(bytes.NewReader(corrupted), t.TempDir(), db.NewMemDB(), z.Chain(), root)
+	common.ExpectError(t, err, snapshot.ErrInvalidChecksum)
+
+	// snapshots which don't match the trusted root are rejected
+	_, _, err = snapshot.Import(bytes.NewReader(data), t.TempDir(), db.NewMemDB(), z.Chain(), send.Hash)
+	common.ExpectError(t, err, snapshot.ErrUntrustedSnapshot)
+
+	// momentums replayed from a base without enough history can't be checked
+	early := new(bytes.Buffer)
+	_, earlyRoot, err := snapshot.Export(early, z.ChainManager(), z.ConsensusDB(), z.Chain(), 0, frontier.Height-2)
+	common.FailIfErr(t, err)
+	_, _, err = snapshot.Import(bytes.NewReader(early.Bytes()), t.TempDir(), db.NewMemDB(), z.Chain(), earlyRoot)
+	common.ExpectError(t, err, snapshot.ErrUnknownProducer)
+
+	dir := t.TempDir()
+	consensusDB := db.NewMemDB()
+	imported, importedRoot, err := snapshot.Import(bytes.NewReader(data), dir, consensusDB, z.Chain(), root)
+	common.FailIfErr(t, err)
+	common.Expect(t, *imported, *header)
+	common.Expect(t, importedRoot, root)
+
+	chainManager := db.NewLevelDBManager(dir)
+	defer chainManager.Stop()
+	restored := chain.NewChain(chainManager, genesis.NewGenesis(g.EmbeddedGenesis))
+	restoredFrontier, err := restored.GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	common.Expect(t, restoredFrontier.Hash, frontier.Hash)
+	common.ExpectUint64(t, restored.GetPrunedHeight(), header.Base.Height)
+	for _, address := range []types.Address{g.User1.Address, g.User2.Address} {
+		expected, err := z.Chain().GetFrontierAccountStore(address).GetBalance(types.ZnnTokenStandard)
+		common.FailIfErr(t, err)
+		balance, err := restored.GetFrontierAccountStore(address).GetBalance(types.ZnnTokenStandard)
+		common.FailIfErr(t, err)
+		common.ExpectAmount(t, balance, expected)
+	}
+
+	// the recent momentums can be served, older ones are pruned
+	common.ExpectTrue(t, restored.GetMomentumPatch(frontier.Identifier()) != nil)
+	common.ExpectTrue(t, chainManager.Get(header.Base) == nil)
+}
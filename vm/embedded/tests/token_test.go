@@ -8,6 +8,7 @@ import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/rpc/api/embedded"
 	"github.com/zenon-network/go-zenon/verifier"
@@ -1144,3 +1145,85 @@ t=2001-09-09T01:48:10+0000 lvl=dbug msg="burned ZTS" module=embedded contract=to
 	"isUtility": false
 }`)
 }
+
+func TestToken_Holders(t *testing.T) {
+	z := mock.NewMockZenonWithIndexer(t, &indexer.Config{Holders: true})
+	defer z.StopPanic()
+	tokenAPI := embedded.NewTokenApi(z)
+
+	common.Json(tokenAPI.GetHolders(types.QsrTokenStandard, 0, 3)).Equals(t, `
+{
+	"count": 12,
+	"list": [
+		{
+			"address": "z1qqfmjdays57w488sta69ykc2ey7r6d0q9wdvtj",
+			"balance": "45000000000000"
+		},
+		{
+			"address": "z1qzv6ch3znujldgkq3krlzq38hu5n2pqg3xsjgv",
+			"balance": "20000000000000"
+		},
+		{
+			"address": "z1qplpsv3wcm64js30jlumxlatgxxkqr6hgv30fg",
+			"balance": "20000000000000"
+		}
+	],
+	"indexedHeight": 1
+}`)
+
+	// the balance of the receiver changes only once the transfer is received
+	sendBlock := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(1000 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	common.Json(tokenAPI.GetDistribution(types.QsrTokenStandard, 1)).Equals(t, `
+{
+	"holderCount": 12,
+	"heldSupply": "180450000000000",
+	"top": 1,
+	"topBalance": "45000000000000",
+	"topShare": 0.24937655860349128,
+	"indexedHeight": 2
+}`)
+	z.InsertReceiveBlock(sendBlock.Header(), nil, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	common.Json(tokenAPI.GetHolders(types.QsrTokenStandard, 1, 2)).Equals(t, `
+{
+	"count": 12,
+	"list": [
+		{
+			"address": "z1qplpsv3wcm64js30jlumxlatgxxkqr6hgv30fg",
+			"balance": "20000000000000"
+		},
+		{
+			"address": "z1qq56p6e9s6emkjj689ayz05laz466nwxme4r8z",
+			"balance": "20000000000000"
+		}
+	],
+	"indexedHeight": 3
+}`)
+	common.Json(tokenAPI.GetDistribution(types.QsrTokenStandard, 2)).Equals(t, `
+{
+	"holderCount": 12,
+	"heldSupply": "180550000000000",
+	"top": 2,
+	"topBalance": "65000000000000",
+	"topShare": 0.3600110772639158,
+	"indexedHeight": 3
+}`)
+	common.Json(tokenAPI.GetDistribution(types.ZnnTokenStandard, 1)).Equals(t, `
+{
+	"holderCount": 15,
+	"heldSupply": "19500000000000",
+	"top": 1,
+	"topBalance": "4500000000000",
+	"topShare": 0.23076923076923078,
+	"indexedHeight": 3
+}`)
+
+	common.Json(tokenAPI.GetHolders(types.QsrTokenStandard, 0, api.RpcMaxPageSize+1)).Error(t, api.ErrPageSizeParamTooBig)
+	common.Json(tokenAPI.GetDistribution(types.QsrTokenStandard, api.RpcMaxCountSize+1)).Error(t, api.ErrCountParamTooBig)
+}
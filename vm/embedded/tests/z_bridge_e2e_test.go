@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// Runs full bridge cycles between NoM and a simulated EVM chain, before and after a TSS key rotation
+func TestBridge_EndToEnd(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:true EnforcementHeight:9}"
+t=2001-09-09T02:06:00+0000 lvl=eror msg=Unwrap-ErrInvalidSignature module=embedded contract=bridge error="invalid secp256k1 signature" result=false signature="16CewvualsMrhad1FH9GJTstSuXOxqFIRE9tXaTKD6988eTcL0qxIK+r5kobxcApHJ05Wde7js+vB2yFUN7hnAA="
+`)
+
+	h := newBridgeHarness(t, z, 100, 10)
+
+	// wrap, sign and redeem on the EVM chain
+	request := h.Wrap(big.NewInt(100 * g.Zexp))
+	request = h.SignWrap(request, nil)
+	common.FailIfErr(t, h.RedeemOnEvm(request))
+	common.ExpectAmount(t, h.EvmBalance(), big.NewInt(99*g.Zexp))
+
+	// a wrap request can't be redeemed twice
+	common.ExpectTrue(t, h.RedeemOnEvm(request) != nil)
+	common.ExpectAmount(t, h.EvmBalance(), big.NewInt(99*g.Zexp))
+
+	// unwrap on the EVM chain and redeem on NoM
+	z.ExpectBalance(g.User2.Address, types.ZnnTokenStandard, 800000000000)
+	param := h.UnwrapOnEvm(big.NewInt(50*g.Zexp), g.User2.Address)
+	common.ExpectAmount(t, h.EvmBalance(), big.NewInt(49*g.Zexp))
+	h.RelayUnwrap(param, nil)
+	h.RedeemOnNom(param)
+	z.ExpectBalance(g.User2.Address, types.ZnnTokenStandard, 805000000000)
+
+	// rotate the TSS key on both chains
+	old := h.tss
+	h.RotateTss(&tssSigner{privateKey: "Sf12dS9DI7xsiKrmQfPR8zQE1HUIYkd8x0XZ6fkAxXo="})
+	bridgeInfo, err := h.bridgeAPI.GetBridgeInfo()
+	common.FailIfErr(t, err)
+	common.ExpectString(t, bridgeInfo.CompressedTssECDSAPubKey, "AhOiqdjx002Cj8o1jxTM5LqywbgNFZwUPJuR9ffdQwFP")
+	common.ExpectString(t, h.EvmTss().Hex(), h.tss.EvmAddress().Hex())
+
+	// full cycle with the new key
+	request = h.Wrap(big.NewInt(200 * g.Zexp))
+	request = h.SignWrap(request, nil)
+	common.FailIfErr(t, h.RedeemOnEvm(request))
+	common.ExpectAmount(t, h.EvmBalance(), big.NewInt(247*g.Zexp))
+
+	param = h.UnwrapOnEvm(big.NewInt(100*g.Zexp), g.User2.Address)
+	h.RelayUnwrap(param, nil)
+	h.RedeemOnNom(param)
+	z.ExpectBalance(g.User2.Address, types.ZnnTokenStandard, 815000000000)
+
+	// signatures of the old key are rejected by both chains
+	next := h.tss
+	h.tss = old
+	request = h.Wrap(big.NewInt(100 * g.Zexp))
+	h.SignWrap(request, constants.ErrInvalidECDSASignature)
+	message, err := implementation.GetWrapTokenRequestMessage(request.WrapTokenRequest, &h.contractAddress)
+	common.FailIfErr(t, err)
+	request.Signature = old.Sign(message)
+	common.ExpectTrue(t, h.RedeemOnEvm(request) != nil)
+
+	param = h.UnwrapOnEvm(big.NewInt(100*g.Zexp), g.User2.Address)
+	h.RelayUnwrap(param, constants.ErrInvalidECDSASignature)
+	h.tss = next
+	h.RelayUnwrap(param, nil)
+	common.ExpectAmount(t, h.EvmBalance(), big.NewInt(47*g.Zexp))
+}
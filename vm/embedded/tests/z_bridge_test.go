@@ -3,6 +3,8 @@ package tests
 import (
 	"crypto/ecdsa"
 	"encoding/base64"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	eabi "github.com/ethereum/go-ethereum/accounts/abi"
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -12,11 +14,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zenon-network/go-zenon/chain"
 	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/rpc/api/subscribe"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/zenon/mock"
@@ -49,6 +54,40 @@ func activateBridge(z mock.MockZenon) {
 	types.ImplementedSporksMap[id] = true
 }
 
+// activateBridgeExtensions activates the spork which adds the Bitcoin network class on top of the bridge
+func activateBridgeExtensions(z mock.MockZenon) {
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.Spork.Address,
+		ToAddress: types.SporkContract,
+		Data: definition.ABISpork.PackMethodPanic(definition.SporkCreateMethodName,
+			"spork-bridge-extensions",              // name
+			"activate spork for bridge extensions", // description
+		),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+
+	sporkAPI := embedded.NewSporkApi(z)
+	sporkList, _ := sporkAPI.GetAll(0, 10)
+	var id types.Hash
+	for _, spork := range sporkList.List {
+		if spork.Name == "spork-bridge-extensions" {
+			id = spork.Id
+		}
+	}
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.Spork.Address,
+		ToAddress: types.SporkContract,
+		Data: definition.ABISpork.PackMethodPanic(definition.SporkActivateMethodName,
+			id, // id
+		),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	types.BridgeExtensionsSpork.SporkId = id
+	types.ImplementedSporksMap[id] = true
+	insertMomentums(z, int(constants.SporkMinHeightDelay))
+}
+
 // Activate spork
 func activateBridgeStep0(t *testing.T, z mock.MockZenon) {
 	activateBridge(z)
@@ -1980,6 +2019,204 @@ t=2001-09-09T02:05:00+0000 lvl=eror msg=Unwrap-ErrInvalidSignature module=embedd
 }`)
 }
 
+func TestBridge_BitcoinBeforeSpork(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:true EnforcementHeight:9}"
+`)
+
+	activateBridgeStep1(t, z)
+
+	// Until the bridge extensions spork, the Bitcoin network class has to use an EVM contract address
+	z.InsertSendBlock(addNetwork(g.User5.Address, definition.BtcClass, 3, "Bitcoin", "bcrt1p5s2vll4ntr8en93fj90jxu9gmq7uzmvmsyyl2rnrcwdxupxzxrdqgsepzq", "{}"),
+		constants.ErrInvalidContractAddress, mock.SkipVmChanges)
+
+	txId := types.HexToHashPanic("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b")
+	amount := big.NewInt(2 * g.Zexp)
+	z.InsertSendBlock(unwrapBitcoinToken(3, txId, 1, amount, getUnwrapBitcoinTokenSignature(t, 3, txId, 1, amount, true)),
+		constants.ErrContractMethodNotFound, mock.SkipVmChanges)
+}
+
+func TestBridge_BitcoinWrapToken(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:true EnforcementHeight:9}"
+t=2001-09-09T01:48:40+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:2caa89a06b451c1eb5c147c81e53b5c348690fd157c0aecd51aabfcd06935aec Name:spork-bridge-extensions Description:activate spork for bridge extensions Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:48:50+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:2caa89a06b451c1eb5c147c81e53b5c348690fd157c0aecd51aabfcd06935aec Name:spork-bridge-extensions Description:activate spork for bridge extensions Activated:true EnforcementHeight:20}"
+`)
+
+	activateBridgeStep1(t, z)
+	activateBridgeExtensions(z)
+
+	bridgeAPI := embedded.NewBridgeApi(z)
+	securityInfo, err := bridgeAPI.GetSecurityInfo()
+	common.DealWithErr(err)
+
+	networkClass := definition.BtcClass
+	chainId := uint32(3) // regtest
+	custodyAddress := "bcrt1p5s2vll4ntr8en93fj90jxu9gmq7uzmvmsyyl2rnrcwdxupxzxrdqgsepzq"
+
+	// The contract address is the custody address on the bitcoin network of the chain id
+	z.InsertSendBlock(addNetwork(g.User5.Address, networkClass, 10, "Bitcoin", custodyAddress, "{}"),
+		constants.ErrUnknownBitcoinChain, mock.SkipVmChanges)
+	z.InsertSendBlock(addNetwork(g.User5.Address, networkClass, chainId, "Bitcoin", "0x323b5d4c32345ced77393b3530b1eed0f346429d", "{}"),
+		constants.ErrInvalidContractAddress, mock.SkipVmChanges)
+	z.InsertSendBlock(addNetwork(g.User5.Address, networkClass, chainId, "Bitcoin", "bc1p5s2vll4ntr8en93fj90jxu9gmq7uzmvmsyyl2rnrcwdxupxzxrdqjp9gd4", "{}"),
+		constants.ErrInvalidContractAddress, mock.SkipVmChanges)
+	defer z.CallContract(addNetwork(g.User5.Address, networkClass, chainId, "Bitcoin", custodyAddress, "{}")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+
+	// Only native BTC can be paired
+	z.InsertSendBlock(setTokenPairStep(g.User5.Address, networkClass, chainId, types.ZnnTokenStandard, "0x5fbdb2315678afecb367f032d93f642f64180aa3", true, true, false,
+		big.NewInt(100), 15, 20, "{}"), constants.ErrForbiddenParam, mock.SkipVmChanges)
+	setTokenPair(t, z, g.User5.Address, securityInfo.SoftDelay, networkClass, chainId, types.ZnnTokenStandard, definition.BtcTokenAddress, true, true, false,
+		big.NewInt(100), 15, 20, "{}")
+
+	guardians := []types.Address{g.User1.Address, g.User2.Address, g.User3.Address, g.User4.Address, g.User5.Address}
+	nominateGuardians(t, z, g.User5.Address, guardians, securityInfo.AdministratorDelay)
+	tssPubKey := "AsAQx1M3LVXCuozDOqO5b9adj/PItYgwZFG/xTDBiZzT" // priv tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=
+	changeTssWithAdministrator(t, z, g.User5.Address, tssPubKey, securityInfo.SoftDelay)
+
+	// Destination addresses have to belong to the bitcoin network of the chain id
+	z.InsertSendBlock(wrapToken(types.ZnnTokenStandard, big.NewInt(15*g.Zexp), networkClass, chainId, "0xb794f5ea0ba39494ce839613fffba74279579268"),
+		constants.ErrInvalidToAddress, mock.SkipVmChanges)
+	z.InsertSendBlock(wrapToken(types.ZnnTokenStandard, big.NewInt(15*g.Zexp), networkClass, chainId, "bc1qk80chkr9w6a4nz70j3dsg5r4kv8c7psgse38ar"),
+		constants.ErrInvalidToAddress, mock.SkipVmChanges)
+
+	// Bech32 addresses are stored in lowercase, base58 ones as they are
+	defer z.CallContract(wrapToken(types.ZnnTokenStandard, big.NewInt(15*g.Zexp), networkClass, chainId, "BCRT1QK80CHKR9W6A4NZ70J3DSG5R4KV8C7PSGCKNE3E")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(wrapToken(types.ZnnTokenStandard, big.NewInt(10*g.Zexp), networkClass, chainId, "mwjThGuzxnpgtip6dvaNMPmUxJXbQjiMGm")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+
+	wrapRequests, err := bridgeAPI.GetAllWrapTokenRequests(0, 5)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, uint64(wrapRequests.Count), 2)
+	addresses := []string{wrapRequests.List[0].ToAddress, wrapRequests.List[1].ToAddress}
+	common.ExpectTrue(t, (addresses[0] == "bcrt1qk80chkr9w6a4nz70j3dsg5r4kv8c7psgckne3e" && addresses[1] == "mwjThGuzxnpgtip6dvaNMPmUxJXbQjiMGm") ||
+		(addresses[1] == "bcrt1qk80chkr9w6a4nz70j3dsg5r4kv8c7psgckne3e" && addresses[0] == "mwjThGuzxnpgtip6dvaNMPmUxJXbQjiMGm"))
+
+	// The EVM encoded message is not accepted
+	request := wrapRequests.List[0]
+	evmRequest := *request.WrapTokenRequest
+	evmRequest.NetworkClass = definition.EvmClass
+	contractAddress := ecommon.HexToAddress("0x323b5d4c32345ced77393b3530b1eed0f346429d")
+	message, err := implementation.GetWrapTokenRequestMessage(&evmRequest, &contractAddress)
+	common.FailIfErr(t, err)
+	signature, err := sign(message, "tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=")
+	common.FailIfErr(t, err)
+	defer z.CallContract(updateWrapToken(request.Id, signature)).Error(t, constants.ErrInvalidECDSASignature)
+	insertMomentums(z, 2)
+
+	// Both BIP340 and ECDSA signatures of the bitcoin message are accepted
+	message, err = implementation.GetBitcoinWrapTokenRequestMessage(request.WrapTokenRequest, custodyAddress)
+	common.FailIfErr(t, err)
+	signature, err = signSchnorr(message, "tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=")
+	common.FailIfErr(t, err)
+	defer z.CallContract(updateWrapToken(request.Id, signature)).Error(t, nil)
+	insertMomentums(z, 2)
+	request = wrapRequests.List[1]
+	message, err = implementation.GetBitcoinWrapTokenRequestMessage(request.WrapTokenRequest, custodyAddress)
+	common.FailIfErr(t, err)
+	signature, err = sign(message, "tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=")
+	common.FailIfErr(t, err)
+	defer z.CallContract(updateWrapToken(request.Id, signature)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	// A BIP340 signature of another key is rejected
+	signature, err = signSchnorr(message, "Sf12dS9DI7xsiKrmQfPR8zQE1HUIYkd8x0XZ6fkAxXo=")
+	common.FailIfErr(t, err)
+	defer z.CallContract(updateWrapToken(request.Id, signature)).Error(t, constants.ErrInvalidECDSASignature)
+	insertMomentums(z, 2)
+
+	wrapRequests, err = bridgeAPI.GetAllWrapTokenRequests(0, 5)
+	common.FailIfErr(t, err)
+	for _, request := range wrapRequests.List {
+		common.ExpectTrue(t, request.Signature != "")
+	}
+}
+
+func TestBridge_BitcoinUnwrapToken(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:true EnforcementHeight:9}"
+t=2001-09-09T01:48:40+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:2caa89a06b451c1eb5c147c81e53b5c348690fd157c0aecd51aabfcd06935aec Name:spork-bridge-extensions Description:activate spork for bridge extensions Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:48:50+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:2caa89a06b451c1eb5c147c81e53b5c348690fd157c0aecd51aabfcd06935aec Name:spork-bridge-extensions Description:activate spork for bridge extensions Activated:true EnforcementHeight:20}"
+t=2001-09-09T01:59:40+0000 lvl=eror msg=UnwrapBitcoin-ErrInvalidSignature module=embedded contract=bridge error="invalid secp256k1 signature" result=false signature="nzZY9LMI4Oz1eO4oadVYd8TJ5WgSUebegg1dMOTZKsVGmfdE1+hW+5cbuCRskI7iHTU8o3Wk9RJ2OWh5VaPRlwE="
+`)
+
+	activateBridgeStep1(t, z)
+	activateBridgeExtensions(z)
+
+	bridgeAPI := embedded.NewBridgeApi(z)
+	securityInfo, err := bridgeAPI.GetSecurityInfo()
+	common.DealWithErr(err)
+
+	networkClass := definition.BtcClass
+	chainId := uint32(3) // regtest
+	defer z.CallContract(addNetwork(g.User5.Address, networkClass, chainId, "Bitcoin", "bcrt1p5s2vll4ntr8en93fj90jxu9gmq7uzmvmsyyl2rnrcwdxupxzxrdqgsepzq", "{}")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	setTokenPair(t, z, g.User5.Address, securityInfo.SoftDelay, networkClass, chainId, types.ZnnTokenStandard, definition.BtcTokenAddress, true, true, false,
+		big.NewInt(100), 15, 20, "{}")
+
+	guardians := []types.Address{g.User1.Address, g.User2.Address, g.User3.Address, g.User4.Address, g.User5.Address}
+	nominateGuardians(t, z, g.User5.Address, guardians, securityInfo.AdministratorDelay)
+	tssPubKey := "AsAQx1M3LVXCuozDOqO5b9adj/PItYgwZFG/xTDBiZzT" // priv tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=
+	changeTssWithAdministrator(t, z, g.User5.Address, tssPubKey, securityInfo.SoftDelay)
+
+	txId := types.HexToHashPanic("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b")
+	amount := big.NewInt(2 * g.Zexp)
+
+	// Bitcoin deposits can't be unwrapped with EVM identifiers
+	signature := getUnwrapTokenSignature(t, networkClass, chainId, txId, 1, definition.BtcTokenAddress, amount, definition.EvmClass)
+	z.InsertSendBlock(unwrapToken(networkClass, chainId, txId, 1, definition.BtcTokenAddress, amount, signature),
+		constants.ErrForbiddenParam, mock.SkipVmChanges)
+	z.InsertSendBlock(unwrapBitcoinToken(10, txId, 1, amount, signature),
+		constants.ErrUnknownBitcoinChain, mock.SkipVmChanges)
+	defer z.CallContract(unwrapBitcoinToken(chainId, txId, 1, amount, getUnwrapBitcoinTokenSignature(t, chainId, txId, 0, amount, false))).
+		Error(t, constants.ErrInvalidECDSASignature)
+	insertMomentums(z, 2)
+
+	defer z.CallContract(unwrapBitcoinToken(chainId, txId, 1, amount, getUnwrapBitcoinTokenSignature(t, chainId, txId, 1, amount, false))).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	// Another output of the same transaction
+	defer z.CallContract(unwrapBitcoinToken(chainId, txId, 2, amount, getUnwrapBitcoinTokenSignature(t, chainId, txId, 2, amount, true))).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	// The same output can't be unwrapped twice
+	defer z.CallContract(unwrapBitcoinToken(chainId, txId, 2, amount, getUnwrapBitcoinTokenSignature(t, chainId, txId, 2, amount, false))).
+		Error(t, constants.ErrInvalidTransactionHash)
+	insertMomentums(z, 2)
+
+	request, err := bridgeAPI.GetUnwrapTokenRequestByHashAndLog(txId, 2)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, uint64(request.NetworkClass), uint64(definition.BtcClass))
+	common.ExpectString(t, request.TokenAddress, definition.BtcTokenAddress)
+	common.ExpectString(t, request.Amount.String(), amount.String())
+
+	// Redeem by txid and vout, the bridge holds the wrapped tokens
+	defer z.CallContract(wrapToken(types.ZnnTokenStandard, big.NewInt(15*g.Zexp), networkClass, chainId, "bcrt1qk80chkr9w6a4nz70j3dsg5r4kv8c7psgckne3e")).
+		Error(t, nil)
+	insertMomentums(z, 20)
+	z.ExpectBalance(g.User2.Address, types.ZnnTokenStandard, 8000*g.Zexp)
+	defer z.CallContract(redeemUnwrap(txId, 2)).Error(t, nil)
+	insertMomentums(z, 3)
+	autoreceive(t, z, g.User2.Address)
+	insertMomentums(z, 2)
+	z.ExpectBalance(g.User2.Address, types.ZnnTokenStandard, 8000*g.Zexp+2*g.Zexp)
+}
+
 func TestBridge_Redeem(t *testing.T) {
 	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
 	defer z.StopPanic()
@@ -2549,92 +2786,696 @@ t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork
 }`)
 }
 
-func TestBridge_Halt(t *testing.T) {
+func TestBridge_TokenPairLimits(t *testing.T) {
 	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
 	defer z.StopPanic()
 	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
 t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
 t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:true EnforcementHeight:9}"
-t=2001-09-09T01:53:00+0000 lvl=eror msg=Halt-ErrInvalidSignature module=embedded contract=bridge error="invalid decompressed secp256k1 public key length" result=false
-t=2001-09-09T01:59:20+0000 lvl=eror msg=Halt-ErrInvalidSignature module=embedded contract=bridge error="invalid secp256k1 signature" result=false
-t=2001-09-09T01:59:40+0000 lvl=eror msg=Halt-ErrInvalidSignature module=embedded contract=bridge error="invalid secp256k1 signature" result=false
+t=2001-09-09T01:48:40+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:2caa89a06b451c1eb5c147c81e53b5c348690fd157c0aecd51aabfcd06935aec Name:spork-bridge-extensions Description:activate spork for bridge extensions Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:48:50+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:2caa89a06b451c1eb5c147c81e53b5c348690fd157c0aecd51aabfcd06935aec Name:spork-bridge-extensions Description:activate spork for bridge extensions Activated:true EnforcementHeight:20}"
+t=2001-09-09T02:05:00+0000 lvl=eror msg="halted bridge" module=embedded contract=bridge reason="redeem limit" token-standard=zts1znnxxxxxxxxxxxxx9z4ulx amount=400000000
 `)
 
-	// We have orcInfo and guardians
-	activateBridgeStep2(t, z)
+	activateBridgeStep1(t, z)
+	// Limits come with the bridge extensions spork
+	z.InsertSendBlock(setTokenPairLimitsStep(g.User5.Address, 2, 123, types.ZnnTokenStandard, 100, big.NewInt(20*g.Zexp), big.NewInt(5*g.Zexp)),
+		constants.ErrContractMethodNotFound, mock.SkipVmChanges)
+	activateBridgeExtensions(z)
 
 	bridgeAPI := embedded.NewBridgeApi(z)
-	bridgeInfo, err := bridgeAPI.GetBridgeInfo()
-	common.FailIfErr(t, err)
-
-	// try to halt with signature with no tss set
-	defer z.CallContract(haltWithSignature(getHaltSignature(t, z, bridgeInfo.TssNonce))).
-		Error(t, constants.ErrInvalidECDSASignature)
-	insertMomentums(z, 2)
+	securityInfo, err := bridgeAPI.GetSecurityInfo()
+	common.DealWithErr(err)
 
-	// admin should pass
-	defer z.CallContract(haltWithAdmin(g.User5.Address)).Error(t, nil)
+	networkClass := uint32(2) // evm
+	chainId := uint32(123)
+	defer z.CallContract(addNetwork(g.User5.Address, networkClass, chainId, "Ethereum", "0x323b5d4c32345ced77393b3530b1eed0f346429d", "{}")).
+		Error(t, nil)
 	insertMomentums(z, 2)
+	tokenAddress := "0x5fbdb2315678afecb367f032d93f642f64180aa3"
+	setTokenPair(t, z, g.User5.Address, securityInfo.SoftDelay, networkClass, chainId, types.ZnnTokenStandard, tokenAddress, true, true, false,
+		big.NewInt(100), 0, 1, "{}")
 
-	defer z.CallContract(unhalt(g.User5.Address)).Error(t, nil)
-	insertMomentums(z, int(2+bridgeInfo.UnhaltDurationInMomentums))
-
-	// add tss
+	guardians := []types.Address{g.User1.Address, g.User2.Address, g.User3.Address, g.User4.Address, g.User5.Address}
+	nominateGuardians(t, z, g.User5.Address, guardians, securityInfo.AdministratorDelay)
 	tssPubKey := "AsAQx1M3LVXCuozDOqO5b9adj/PItYgwZFG/xTDBiZzT" // priv tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=
-	securityInfo, err := bridgeAPI.GetSecurityInfo()
-	common.DealWithErr(err)
 	changeTssWithAdministrator(t, z, g.User5.Address, tssPubKey, securityInfo.SoftDelay)
 
-	// halt with sig should work
-	defer z.CallContract(haltWithSignature(getHaltSignature(t, z, bridgeInfo.TssNonce))).
+	// No limits by default
+	common.Json(bridgeAPI.GetTokenPairLimits(networkClass, chainId, types.ZnnTokenStandard)).Equals(t, `
+{
+	"networkClass": 2,
+	"chainId": 123,
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"windowSize": 0,
+	"maxWrapAmount": "0",
+	"maxRedeemAmount": "0",
+	"wrapVolume": "0",
+	"redeemVolume": "0",
+	"remainingWrapAmount": null,
+	"remainingRedeemAmount": null
+}`)
+
+	z.InsertSendBlock(setTokenPairLimitsStep(g.User5.Address, networkClass, chainId, types.ZnnTokenStandard, 0, big.NewInt(20*g.Zexp), big.NewInt(5*g.Zexp)),
+		constants.ErrForbiddenParam, mock.SkipVmChanges)
+	defer z.CallContract(setTokenPairLimitsStep(g.User4.Address, networkClass, chainId, types.ZnnTokenStandard, 100, big.NewInt(20*g.Zexp), big.NewInt(5*g.Zexp))).
+		Error(t, constants.ErrPermissionDenied)
+	insertMomentums(z, 2)
+	defer z.CallContract(setTokenPairLimitsStep(g.User5.Address, networkClass, chainId, types.QsrTokenStandard, 100, big.NewInt(20*g.Zexp), big.NewInt(5*g.Zexp))).
+		Error(t, constants.ErrTokenNotFound)
+	insertMomentums(z, 2)
+
+	// Limits go through the time challenge
+	defer z.CallContract(setTokenPairLimitsStep(g.User5.Address, networkClass, chainId, types.ZnnTokenStandard, 100, big.NewInt(20*g.Zexp), big.NewInt(5*g.Zexp))).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(setTokenPairLimitsStep(g.User5.Address, networkClass, chainId, types.ZnnTokenStandard, 100, big.NewInt(20*g.Zexp), big.NewInt(5*g.Zexp))).
+		Error(t, constants.ErrTimeChallengeNotDue)
+	insertMomentums(z, 2)
+	common.Json(bridgeAPI.GetTokenPairLimits(networkClass, chainId, types.ZnnTokenStandard)).Equals(t, `
+{
+	"networkClass": 2,
+	"chainId": 123,
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"windowSize": 0,
+	"maxWrapAmount": "0",
+	"maxRedeemAmount": "0",
+	"wrapVolume": "0",
+	"redeemVolume": "0",
+	"remainingWrapAmount": null,
+	"remainingRedeemAmount": null
+}`)
+	frMom, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	z.InsertMomentumsTo(frMom.Height + securityInfo.SoftDelay)
+	defer z.CallContract(setTokenPairLimitsStep(g.User5.Address, networkClass, chainId, types.ZnnTokenStandard, 100, big.NewInt(20*g.Zexp), big.NewInt(5*g.Zexp))).
 		Error(t, nil)
 	insertMomentums(z, 2)
 
-	// try to unhalt with non admin
-	defer z.CallContract(unhalt(g.User4.Address)).Error(t, constants.ErrPermissionDenied)
+	defer z.CallContract(wrapToken(types.ZnnTokenStandard, big.NewInt(15*g.Zexp), networkClass, chainId, "0xb794f5ea0ba39494ce839613fffba74279579268")).
+		Error(t, nil)
 	insertMomentums(z, 2)
+	common.Json(bridgeAPI.GetTokenPairLimits(networkClass, chainId, types.ZnnTokenStandard)).Equals(t, `
+{
+	"networkClass": 2,
+	"chainId": 123,
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"windowSize": 100,
+	"maxWrapAmount": "2000000000",
+	"maxRedeemAmount": "500000000",
+	"wrapVolume": "1500000000",
+	"redeemVolume": "0",
+	"remainingWrapAmount": "500000000",
+	"remainingRedeemAmount": "500000000"
+}`)
 
-	defer z.CallContract(unhalt(g.User5.Address)).Error(t, nil)
-	insertMomentums(z, int(2+bridgeInfo.UnhaltDurationInMomentums))
+	// A wrap over the limit is refunded, anyone could send one so it doesn't halt the bridge
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 1198500000000)
+	defer z.CallContract(wrapToken(types.ZnnTokenStandard, big.NewInt(10*g.Zexp), networkClass, chainId, "0xb794f5ea0ba39494ce839613fffba74279579268")).
+		Error(t, constants.ErrTokenPairLimitExceeded)
+	insertMomentums(z, 2)
+	autoreceive(t, z, g.User1.Address)
+	insertMomentums(z, 2)
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 1198500000000)
+	z.ExpectBalance(types.BridgeContract, types.ZnnTokenStandard, 15*g.Zexp)
+	bridgeInfo, err := bridgeAPI.GetBridgeInfo()
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, !bridgeInfo.Halted)
+	wrapRequests, err := bridgeAPI.GetAllWrapTokenRequests(0, 5)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, uint64(wrapRequests.Count), 1)
 
-	// using the same tss nonce should fail
-	defer z.CallContract(haltWithSignature(getHaltSignature(t, z, bridgeInfo.TssNonce))).
-		Error(t, constants.ErrInvalidECDSASignature)
+	// Redeems of TSS signed requests over the limit halt the bridge, the request stays redeemable
+	hash := types.HexToHashPanic("0123456789012345678901234567890123456789012345678901234567890123")
+	for logIndex, amount := range []int64{3 * g.Zexp, 4 * g.Zexp} {
+		signature := getUnwrapTokenSignature(t, networkClass, chainId, hash, uint32(logIndex), tokenAddress, big.NewInt(amount), networkClass)
+		defer z.CallContract(unwrapToken(networkClass, chainId, hash, uint32(logIndex), tokenAddress, big.NewInt(amount), signature)).
+			Error(t, nil)
+		insertMomentums(z, 2)
+	}
+	defer z.CallContract(redeemUnwrap(hash, 0)).Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(redeemUnwrap(hash, 1)).Error(t, nil)
 	insertMomentums(z, 2)
+	bridgeInfo, err = bridgeAPI.GetBridgeInfo()
+	common.FailIfErr(t, err)
+	common.ExpectTrue(t, bridgeInfo.Halted)
+	request, err := bridgeAPI.GetUnwrapTokenRequestByHashAndLog(hash, 1)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, uint64(request.Redeemed), 0)
+	common.Json(bridgeAPI.GetTokenPairLimits(networkClass, chainId, types.ZnnTokenStandard)).Equals(t, `
+{
+	"networkClass": 2,
+	"chainId": 123,
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"windowSize": 100,
+	"maxWrapAmount": "2000000000",
+	"maxRedeemAmount": "500000000",
+	"wrapVolume": "1320000000",
+	"redeemVolume": "300000000",
+	"remainingWrapAmount": "680000000",
+	"remainingRedeemAmount": "200000000"
+}`)
 
-	// using random tss nonce
-	defer z.CallContract(haltWithSignature(getHaltSignature(t, z, uint64(100)))).
-		Error(t, constants.ErrInvalidECDSASignature)
+	// The previous window weighs less as the rolling window moves on
+	defer z.CallContract(unhalt(g.User5.Address)).Error(t, nil)
 	insertMomentums(z, 2)
+	frMom, err = z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	z.InsertMomentumsTo(frMom.Height - frMom.Height%100 + 190)
+	common.Json(bridgeAPI.GetTokenPairLimits(networkClass, chainId, types.ZnnTokenStandard)).Equals(t, `
+{
+	"networkClass": 2,
+	"chainId": 123,
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"windowSize": 100,
+	"maxWrapAmount": "2000000000",
+	"maxRedeemAmount": "500000000",
+	"wrapVolume": "0",
+	"redeemVolume": "30000000",
+	"remainingWrapAmount": "2000000000",
+	"remainingRedeemAmount": "470000000"
+}`)
+	defer z.CallContract(redeemUnwrap(hash, 1)).Error(t, nil)
+	insertMomentums(z, 2)
+	request, err = bridgeAPI.GetUnwrapTokenRequestByHashAndLog(hash, 1)
+	common.FailIfErr(t, err)
+	common.ExpectUint64(t, uint64(request.Redeemed), 1)
 }
 
-func TestBridge_Emergency(t *testing.T) {
+func TestBridge_FeeDistribution(t *testing.T) {
 	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
 	defer z.StopPanic()
 	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
 t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
 t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:true EnforcementHeight:9}"
+t=2001-09-09T01:48:40+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:2caa89a06b451c1eb5c147c81e53b5c348690fd157c0aecd51aabfcd06935aec Name:spork-bridge-extensions Description:activate spork for bridge extensions Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:48:50+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:2caa89a06b451c1eb5c147c81e53b5c348690fd157c0aecd51aabfcd06935aec Name:spork-bridge-extensions Description:activate spork for bridge extensions Activated:true EnforcementHeight:20}"
+t=2001-09-09T02:03:00+0000 lvl=dbug msg="distributed fees" module=embedded contract=bridge token-standard=zts1znnxxxxxxxxxxxxx9z4ulx amount=150000000 destinations=2
+t=2001-09-09T02:03:10+0000 lvl=info msg="received donation" module=embedded contract=common embedded=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae from-address=z1qxemdeddedxdrydgexxxxxxxxxxxxxxxmqgr0d zts=zts1znnxxxxxxxxxxxxx9z4ulx amount=60000000
 `)
 
-	// We have orcInfo
 	activateBridgeStep1(t, z)
+	// Fee distribution comes with the bridge extensions spork
+	z.InsertSendBlock(distributeFees(g.User1.Address, types.ZnnTokenStandard), constants.ErrContractMethodNotFound, mock.SkipVmChanges)
+	activateBridgeExtensions(z)
 
 	bridgeAPI := embedded.NewBridgeApi(z)
 	securityInfo, err := bridgeAPI.GetSecurityInfo()
-	common.FailIfErr(t, err)
+	common.DealWithErr(err)
 
-	defer z.CallContract(activateEmergency(g.User5.Address)).Error(t, constants.ErrSecurityNotInitialized)
+	networkClass := uint32(2) // evm
+	chainId := uint32(123)
+	defer z.CallContract(addNetwork(g.User5.Address, networkClass, chainId, "Ethereum", "0x323b5d4c32345ced77393b3530b1eed0f346429d", "{}")).
+		Error(t, nil)
 	insertMomentums(z, 2)
+	tokenAddress := "0x5fbdb2315678afecb367f032d93f642f64180aa3"
+	setTokenPair(t, z, g.User5.Address, securityInfo.SoftDelay, networkClass, chainId, types.ZnnTokenStandard, tokenAddress, true, true, false,
+		big.NewInt(100), 1000, 1, "{}")
 
 	guardians := []types.Address{g.User1.Address, g.User2.Address, g.User3.Address, g.User4.Address, g.User5.Address}
 	nominateGuardians(t, z, g.User5.Address, guardians, securityInfo.AdministratorDelay)
+	tssPubKey := "AsAQx1M3LVXCuozDOqO5b9adj/PItYgwZFG/xTDBiZzT" // priv tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=
+	changeTssWithAdministrator(t, z, g.User5.Address, tssPubKey, securityInfo.SoftDelay)
 
-	// activate as non admin
-	defer z.CallContract(activateEmergency(g.User4.Address)).Error(t, constants.ErrPermissionDenied)
+	defer z.CallContract(wrapToken(types.ZnnTokenStandard, big.NewInt(15*g.Zexp), networkClass, chainId, "0xb794f5ea0ba39494ce839613fffba74279579268")).
+		Error(t, nil)
 	insertMomentums(z, 2)
+	common.Json(bridgeAPI.GetFeeTokenPair(types.ZnnTokenStandard)).Equals(t, `
+{
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"accumulatedFee": "150000000"
+}`)
 
-	// activate with no tss set
-	defer z.CallContract(activateEmergency(g.User5.Address)).Error(t, nil)
+	// Fees can't be distributed before a distribution is set
+	defer z.CallContract(distributeFees(g.User2.Address, types.ZnnTokenStandard)).
+		Error(t, constants.ErrFeeDistributionNotSet)
+	insertMomentums(z, 2)
+
+	z.InsertSendBlock(setFeeDistributionStep(g.User5.Address, []types.Address{g.User3.Address, types.LiquidityContract}, []uint32{6000, 3000}),
+		constants.ErrInvalidPercentages, mock.SkipVmChanges)
+	z.InsertSendBlock(setFeeDistributionStep(g.User5.Address, []types.Address{g.User3.Address, types.PillarContract}, []uint32{6000, 4000}),
+		constants.ErrForbiddenParam, mock.SkipVmChanges)
+	z.InsertSendBlock(setFeeDistributionStep(g.User5.Address, []types.Address{g.User3.Address, g.User3.Address}, []uint32{6000, 4000}),
+		constants.ErrForbiddenParam, mock.SkipVmChanges)
+	z.InsertSendBlock(setFeeDistributionStep(g.User5.Address, []types.Address{g.User3.Address}, []uint32{6000, 4000}),
+		constants.ErrForbiddenParam, mock.SkipVmChanges)
+	defer z.CallContract(setFeeDistributionStep(g.User4.Address, []types.Address{g.User3.Address, types.LiquidityContract}, []uint32{6000, 4000})).
+		Error(t, constants.ErrPermissionDenied)
+	insertMomentums(z, 2)
+
+	setFeeDistribution(t, z, g.User5.Address, securityInfo.SoftDelay, []types.Address{g.User3.Address, types.LiquidityContract}, []uint32{6000, 4000})
+	common.Json(bridgeAPI.GetFeeDistribution()).Equals(t, `
+{
+	"destinations": [
+		"z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+		"z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae"
+	],
+	"percentages": [
+		6000,
+		4000
+	]
+}`)
+
+	// Anyone can distribute the fees
+	z.ExpectBalance(g.User3.Address, types.ZnnTokenStandard, 100000000000)
+	z.ExpectBalance(types.LiquidityContract, types.ZnnTokenStandard, 0)
+	defer z.CallContract(distributeFees(g.User2.Address, types.ZnnTokenStandard)).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	autoreceive(t, z, g.User3.Address)
+	insertMomentums(z, 2)
+	z.ExpectBalance(g.User3.Address, types.ZnnTokenStandard, 100000000000+90000000)
+	z.ExpectBalance(types.LiquidityContract, types.ZnnTokenStandard, 60000000)
+	z.ExpectBalance(types.BridgeContract, types.ZnnTokenStandard, 15*g.Zexp-150000000)
+	common.Json(bridgeAPI.GetFeeTokenPair(types.ZnnTokenStandard)).Equals(t, `
+{
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"accumulatedFee": "0"
+}`)
+	common.Json(bridgeAPI.GetFeeDistributionHistory(0, 5)).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"id": "1cd816d70eac01709328e5e19ef702915549d4d2f9cd0f0320f185fbec5d21b4",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"momentumHeight": 99,
+			"destinations": [
+				"z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+				"z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae"
+			],
+			"amounts": [
+				"90000000",
+				"60000000"
+			]
+		}
+	]
+}`)
+
+	defer z.CallContract(distributeFees(g.User2.Address, types.ZnnTokenStandard)).
+		Error(t, constants.ErrNothingToWithdraw)
+	insertMomentums(z, 2)
+
+	// Fees stay in the bridge while it's halted
+	defer z.CallContract(wrapToken(types.ZnnTokenStandard, big.NewInt(10*g.Zexp), networkClass, chainId, "0xb794f5ea0ba39494ce839613fffba74279579268")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(haltWithAdmin(g.User5.Address)).Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(distributeFees(g.User2.Address, types.ZnnTokenStandard)).
+		Error(t, constants.ErrBridgeHalted)
+	insertMomentums(z, 2)
+	common.Json(bridgeAPI.GetFeeTokenPair(types.ZnnTokenStandard)).Equals(t, `
+{
+	"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+	"accumulatedFee": "100000000"
+}`)
+}
+
+func TestBridge_Events(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+
+	activateBridgeStep1(t, z)
+
+	bridgeAPI := embedded.NewBridgeApi(z)
+	securityInfo, err := bridgeAPI.GetSecurityInfo()
+	common.DealWithErr(err)
+
+	networkClass := uint32(2) // evm
+	chainId := uint32(123)
+	defer z.CallContract(addNetwork(g.User5.Address, networkClass, chainId, "Ethereum", "0x323b5d4c32345ced77393b3530b1eed0f346429d", "{}")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	tokenAddress := "0x5fbdb2315678afecb367f032d93f642f64180aa3"
+	setTokenPair(t, z, g.User5.Address, securityInfo.SoftDelay, networkClass, chainId, types.ZnnTokenStandard, tokenAddress, true, true, false,
+		big.NewInt(100), 0, 1, "{}")
+
+	guardians := []types.Address{g.User1.Address, g.User2.Address, g.User3.Address, g.User4.Address, g.User5.Address}
+	nominateGuardians(t, z, g.User5.Address, guardians, securityInfo.AdministratorDelay)
+	tssPubKey := "AsAQx1M3LVXCuozDOqO5b9adj/PItYgwZFG/xTDBiZzT" // priv tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=
+	changeTssWithAdministrator(t, z, g.User5.Address, tssPubKey, securityInfo.SoftDelay)
+
+	listener := &bridgeEventsListener{t: t, chain: z.Chain()}
+	z.Chain().Register(listener)
+	defer z.Chain().UnRegister(listener)
+
+	defer z.CallContract(wrapToken(types.ZnnTokenStandard, big.NewInt(15*g.Zexp), networkClass, chainId, "0xb794f5ea0ba39494ce839613fffba74279579268")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	common.Json(listener.events[0], nil).Equals(t, `
+{
+	"type": "wrapRequestCreated",
+	"momentumHeight": 72,
+	"momentumHash": "1e2d78d86b8522f6b171db171ae0e10f1afb536a0d5909406a026956183e45e4",
+	"sendBlockHash": "1d3db9c05079935cc3401739a4f7bae205358d77e1c7996f9004c62fb3354505",
+	"wrapRequest": {
+		"networkClass": 2,
+		"chainId": 123,
+		"id": "1d3db9c05079935cc3401739a4f7bae205358d77e1c7996f9004c62fb3354505",
+		"toAddress": "0xb794f5ea0ba39494ce839613fffba74279579268",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+		"amount": "1500000000",
+		"fee": "0",
+		"signature": "",
+		"creationMomentumHeight": 71
+	},
+	"unwrapRequest": null,
+	"bridgeInfo": null
+}`)
+
+	// Only successful calls are events
+	wrapRequests, err := bridgeAPI.GetAllWrapTokenRequests(0, 5)
+	common.FailIfErr(t, err)
+	contractAddress := ecommon.HexToAddress("0x323b5d4c32345ced77393b3530b1eed0f346429d")
+	defer z.CallContract(updateWrapToken(wrapRequests.List[0].Id, getUpdateWrapTokenSignature(wrapRequests.List[0], contractAddress, "m+FFTkjBn4VT3FPBDqd7Oi8Kk8+pxQmtDD1yWoTizXo="))).
+		Error(t, constants.ErrInvalidECDSASignature)
+	insertMomentums(z, 2)
+	defer z.CallContract(updateWrapToken(wrapRequests.List[0].Id, getUpdateWrapTokenSignature(wrapRequests.List[0], contractAddress, "tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI="))).
+		Error(t, nil)
+	insertMomentums(z, 2)
+
+	hash := types.HexToHashPanic("0123456789012345678901234567890123456789012345678901234567890123")
+	for logIndex := uint32(0); logIndex < 2; logIndex++ {
+		signature := getUnwrapTokenSignature(t, networkClass, chainId, hash, logIndex, tokenAddress, big.NewInt(g.Zexp), networkClass)
+		defer z.CallContract(unwrapToken(networkClass, chainId, hash, logIndex, tokenAddress, big.NewInt(g.Zexp), signature)).
+			Error(t, nil)
+		insertMomentums(z, 2)
+	}
+	signature := getUnwrapTokenSignature(t, networkClass, chainId, hash, 0, tokenAddress, big.NewInt(g.Zexp), networkClass)
+	defer z.CallContract(unwrapToken(networkClass, chainId, hash, 0, tokenAddress, big.NewInt(g.Zexp), signature)).
+		Error(t, constants.ErrInvalidTransactionHash)
+	insertMomentums(z, 2)
+
+	defer z.CallContract(redeemUnwrap(hash, 0)).Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(revokeUnwrap(g.User5.Address, hash, 1)).Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(redeemUnwrap(hash, 1)).Error(t, constants.ErrInvalidRedeemRequest)
+	insertMomentums(z, 2)
+
+	defer z.CallContract(haltWithAdmin(g.User5.Address)).Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(unhalt(g.User5.Address)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	eventTypes := make([]string, len(listener.events))
+	for i, event := range listener.events {
+		eventTypes[i] = event.Type
+	}
+	common.Json(eventTypes, nil).Equals(t, `
+[
+	"wrapRequestCreated",
+	"wrapRequestSigned",
+	"unwrapRequestCreated",
+	"unwrapRequestCreated",
+	"unwrapRequestRedeemed",
+	"unwrapRequestRevoked",
+	"bridgeHalted",
+	"bridgeUnhalted"
+]`)
+	common.Json(listener.events[4], nil).Equals(t, `
+{
+	"type": "unwrapRequestRedeemed",
+	"momentumHeight": 84,
+	"momentumHash": "0747536df7926df1d742101826c7599af02fe356aa4a67b37508f9d5082237e6",
+	"sendBlockHash": "01b916e1e0ff58a5dbe407d197587e3da8c8b98da2651b76023c0358d861d8e4",
+	"wrapRequest": null,
+	"unwrapRequest": {
+		"registrationMomentumHeight": 77,
+		"networkClass": 2,
+		"chainId": 123,
+		"transactionHash": "0123456789012345678901234567890123456789012345678901234567890123",
+		"logIndex": 0,
+		"toAddress": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+		"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"amount": "100000000",
+		"signature": "+scOZvU3PqqXjBVaLSWOozMNQSjGJhrW6joVB2nUKGowO+ijwOVLfOKDems/ip8l5vgn+c1K3nzAmmN24Eq4sAE=",
+		"redeemed": 1,
+		"revoked": 0
+	},
+	"bridgeInfo": null
+}`)
+}
+
+func TestBridge_RequestFilters(t *testing.T) {
+	z := mock.NewMockZenonWithIndexer(t, &indexer.Config{Bridge: true})
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:true EnforcementHeight:9}"
+t=2001-09-09T01:58:20+0000 lvl=dbug msg="issued ZTS" module=embedded contract=token token="{Owner:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz TokenName:test.tok3n_na-m3 TokenSymbol:TEST TokenDomain: TotalSupply:+100000 MaxSupply:+1000000 Decimals:1 IsMintable:true IsBurnable:true IsUtility:false TokenStandard:zts1qanamzukd2v0pp8j2wzx6m}"
+t=2001-09-09T01:58:40+0000 lvl=dbug msg="updating token owner" module=embedded contract=token old=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz new=z1qxemdeddedxdrydgexxxxxxxxxxxxxxxmqgr0d
+t=2001-09-09T01:58:40+0000 lvl=dbug msg="updated ZTS" module=embedded contract=token token="&{Owner:z1qxemdeddedxdrydgexxxxxxxxxxxxxxxmqgr0d TokenName:test.tok3n_na-m3 TokenSymbol:TEST TokenDomain: TotalSupply:+100000 MaxSupply:+1000000 Decimals:1 IsMintable:true IsBurnable:true IsUtility:false TokenStandard:zts1qanamzukd2v0pp8j2wzx6m}"
+t=2001-09-09T02:01:50+0000 lvl=dbug msg="burned ZTS" module=embedded contract=token token="&{Owner:z1qxemdeddedxdrydgexxxxxxxxxxxxxxxmqgr0d TokenName:test.tok3n_na-m3 TokenSymbol:TEST TokenDomain: TotalSupply:+95050 MaxSupply:+1000000 Decimals:1 IsMintable:true IsBurnable:true IsUtility:false TokenStandard:zts1qanamzukd2v0pp8j2wzx6m}" burned-amount=4950
+`)
+
+	// 2 signed wraps and 2 unwraps
+	activateBridgeStep8(t, z)
+
+	bridgeAPI := embedded.NewBridgeApi(z)
+	networkClass := uint32(2)
+	chainId := uint32(123)
+
+	// one more unsigned wrap and one revoked unwrap
+	defer z.CallContract(wrapToken(types.ZnnTokenStandard, big.NewInt(200*g.Zexp), networkClass, chainId, "0xb794f5ea0ba39494ce839613fffba74279579268")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	unwrapRequests, err := bridgeAPI.GetAllUnwrapTokenRequests(0, 5)
+	common.FailIfErr(t, err)
+	defer z.CallContract(revokeUnwrap(g.User5.Address, unwrapRequests.List[0].TransactionHash, unwrapRequests.List[0].LogIndex)).
+		Error(t, nil)
+	insertMomentums(z, 2)
+
+	unsigned := indexer.RequestStatusUnsigned
+	wrapRequests, err := bridgeAPI.GetWrapTokenRequestsByFilter(&indexer.BridgeRequestFilter{Status: unsigned}, 0, 5)
+	common.Json(wrapRequests, err).HideHashes().Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"networkClass": 2,
+			"chainId": 123,
+			"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+			"toAddress": "0xb794f5ea0ba39494ce839613fffba74279579268",
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+			"amount": "20000000000",
+			"fee": "30000000",
+			"signature": "",
+			"creationMomentumHeight": 101,
+			"token": {
+				"name": "Zenon Coin",
+				"symbol": "ZNN",
+				"domain": "zenon.network",
+				"totalSupply": "19500000000000",
+				"decimals": 8,
+				"owner": "z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg",
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"maxSupply": "4611686018427387903",
+				"isBurnable": true,
+				"isMintable": true,
+				"isUtility": true
+			},
+			"confirmationsToFinality": 12
+		}
+	]
+}`)
+
+	zts := types.ZnnTokenStandard
+	wrapRequests, err = bridgeAPI.GetWrapTokenRequestsByFilter(&indexer.BridgeRequestFilter{TokenStandard: &zts, Status: indexer.RequestStatusSigned}, 0, 5)
+	common.FailIfErr(t, err)
+	common.String(strconv.Itoa(wrapRequests.Count)).Equals(t, `1`)
+
+	wrapRequests, err = bridgeAPI.GetWrapTokenRequestsByFilter(&indexer.BridgeRequestFilter{NetworkClass: &networkClass, ChainId: &chainId}, 0, 5)
+	common.FailIfErr(t, err)
+	common.String(strconv.Itoa(wrapRequests.Count)).Equals(t, `3`)
+
+	wrapRequests, err = bridgeAPI.GetWrapTokenRequestsByFilter(nil, 0, 5)
+	common.FailIfErr(t, err)
+	common.String(strconv.Itoa(wrapRequests.Count)).Equals(t, `3`)
+
+	otherChainId := uint32(124)
+	wrapRequests, err = bridgeAPI.GetWrapTokenRequestsByFilter(&indexer.BridgeRequestFilter{ChainId: &otherChainId}, 0, 5)
+	common.FailIfErr(t, err)
+	common.String(strconv.Itoa(wrapRequests.Count)).Equals(t, `0`)
+
+	_, err = bridgeAPI.GetWrapTokenRequestsByFilter(&indexer.BridgeRequestFilter{Status: indexer.RequestStatusRedeemed}, 0, 5)
+	common.ExpectError(t, err, indexer.ErrInvalidRequestStatus)
+
+	unwrapList, err := bridgeAPI.GetUnwrapTokenRequestsByFilter(&indexer.BridgeRequestFilter{Status: indexer.RequestStatusRevoked}, 0, 5)
+	common.Json(unwrapList, err).HideHashes().Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"registrationMomentumHeight": 95,
+			"networkClass": 2,
+			"chainId": 123,
+			"transactionHash": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+			"logIndex": 200,
+			"toAddress": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"tokenAddress": "0x5aaaa2315678afecb367f032d93f642f64180aa3",
+			"tokenStandard": "zts1qanamzukd2v0pp8j2wzx6m",
+			"amount": "800",
+			"signature": "eCKubWhwnuqqX9vjGl9ltqxCNwE2V9Xi4bO1q404JYNCUlJ0c3h5Cq558pLxtimrS73hPStjtz281+GcfNPTyAE=",
+			"redeemed": 0,
+			"revoked": 1,
+			"token": {
+				"name": "test.tok3n_na-m3",
+				"symbol": "TEST",
+				"domain": "",
+				"totalSupply": "95050",
+				"decimals": 1,
+				"owner": "z1qxemdeddedxdrydgexxxxxxxxxxxxxxxmqgr0d",
+				"tokenStandard": "zts1qanamzukd2v0pp8j2wzx6m",
+				"maxSupply": "1000000",
+				"isBurnable": true,
+				"isMintable": true,
+				"isUtility": false
+			},
+			"redeemableIn": 6
+		}
+	]
+}`)
+
+	unwrapList, err = bridgeAPI.GetUnwrapTokenRequestsByFilter(&indexer.BridgeRequestFilter{Status: indexer.RequestStatusPending}, 0, 5)
+	common.FailIfErr(t, err)
+	common.String(strconv.Itoa(unwrapList.Count)).Equals(t, `1`)
+
+	// only the first unwrap was registered before momentum 94
+	unwrapList, err = bridgeAPI.GetUnwrapTokenRequestsByFilter(&indexer.BridgeRequestFilter{FromMomentumHeight: 1, ToMomentumHeight: 94}, 0, 5)
+	common.FailIfErr(t, err)
+	common.String(strconv.Itoa(unwrapList.Count)).Equals(t, `1`)
+
+	_, err = bridgeAPI.GetUnwrapTokenRequestsByFilter(&indexer.BridgeRequestFilter{Status: unsigned}, 0, 5)
+	common.ExpectError(t, err, indexer.ErrInvalidRequestStatus)
+
+	common.Json(bridgeAPI.GetTokenPairStats()).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"networkClass": 2,
+			"chainId": 123,
+			"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+			"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+			"owned": false,
+			"wrapCount": 2,
+			"unwrapCount": 1,
+			"totalWrapped": "35000000000",
+			"totalFees": "52500000",
+			"totalUnwrapped": "10000000000",
+			"totalRedeemed": "0",
+			"outstanding": "24947500000"
+		},
+		{
+			"networkClass": 2,
+			"chainId": 123,
+			"tokenStandard": "zts1qanamzukd2v0pp8j2wzx6m",
+			"tokenAddress": "0x5aaaa2315678afecb367f032d93f642f64180aa3",
+			"owned": true,
+			"wrapCount": 1,
+			"unwrapCount": 0,
+			"totalWrapped": "5000",
+			"totalFees": "50",
+			"totalUnwrapped": "0",
+			"totalRedeemed": "0",
+			"outstanding": "-4950"
+		}
+	]
+}`)
+}
+
+func TestBridge_Halt(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:true EnforcementHeight:9}"
+t=2001-09-09T01:53:00+0000 lvl=eror msg=Halt-ErrInvalidSignature module=embedded contract=bridge error="invalid decompressed secp256k1 public key length" result=false
+t=2001-09-09T01:59:20+0000 lvl=eror msg=Halt-ErrInvalidSignature module=embedded contract=bridge error="invalid secp256k1 signature" result=false
+t=2001-09-09T01:59:40+0000 lvl=eror msg=Halt-ErrInvalidSignature module=embedded contract=bridge error="invalid secp256k1 signature" result=false
+`)
+
+	// We have orcInfo and guardians
+	activateBridgeStep2(t, z)
+
+	bridgeAPI := embedded.NewBridgeApi(z)
+	bridgeInfo, err := bridgeAPI.GetBridgeInfo()
+	common.FailIfErr(t, err)
+
+	// try to halt with signature with no tss set
+	defer z.CallContract(haltWithSignature(getHaltSignature(t, z, bridgeInfo.TssNonce))).
+		Error(t, constants.ErrInvalidECDSASignature)
+	insertMomentums(z, 2)
+
+	// admin should pass
+	defer z.CallContract(haltWithAdmin(g.User5.Address)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	defer z.CallContract(unhalt(g.User5.Address)).Error(t, nil)
+	insertMomentums(z, int(2+bridgeInfo.UnhaltDurationInMomentums))
+
+	// add tss
+	tssPubKey := "AsAQx1M3LVXCuozDOqO5b9adj/PItYgwZFG/xTDBiZzT" // priv tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=
+	securityInfo, err := bridgeAPI.GetSecurityInfo()
+	common.DealWithErr(err)
+	changeTssWithAdministrator(t, z, g.User5.Address, tssPubKey, securityInfo.SoftDelay)
+
+	// halt with sig should work
+	defer z.CallContract(haltWithSignature(getHaltSignature(t, z, bridgeInfo.TssNonce))).
+		Error(t, nil)
+	insertMomentums(z, 2)
+
+	// try to unhalt with non admin
+	defer z.CallContract(unhalt(g.User4.Address)).Error(t, constants.ErrPermissionDenied)
+	insertMomentums(z, 2)
+
+	defer z.CallContract(unhalt(g.User5.Address)).Error(t, nil)
+	insertMomentums(z, int(2+bridgeInfo.UnhaltDurationInMomentums))
+
+	// using the same tss nonce should fail
+	defer z.CallContract(haltWithSignature(getHaltSignature(t, z, bridgeInfo.TssNonce))).
+		Error(t, constants.ErrInvalidECDSASignature)
+	insertMomentums(z, 2)
+
+	// using random tss nonce
+	defer z.CallContract(haltWithSignature(getHaltSignature(t, z, uint64(100)))).
+		Error(t, constants.ErrInvalidECDSASignature)
+	insertMomentums(z, 2)
+}
+
+func TestBridge_Emergency(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:c6a597f757168bd5c9fddf52b16b3bf38e2ef781fb8edeea1bf2ae0d3225230d Name:spork-bridge Description:activate spork for bridge Activated:true EnforcementHeight:9}"
+`)
+
+	// We have orcInfo
+	activateBridgeStep1(t, z)
+
+	bridgeAPI := embedded.NewBridgeApi(z)
+	securityInfo, err := bridgeAPI.GetSecurityInfo()
+	common.FailIfErr(t, err)
+
+	defer z.CallContract(activateEmergency(g.User5.Address)).Error(t, constants.ErrSecurityNotInitialized)
+	insertMomentums(z, 2)
+
+	guardians := []types.Address{g.User1.Address, g.User2.Address, g.User3.Address, g.User4.Address, g.User5.Address}
+	nominateGuardians(t, z, g.User5.Address, guardians, securityInfo.AdministratorDelay)
+
+	// activate as non admin
+	defer z.CallContract(activateEmergency(g.User4.Address)).Error(t, constants.ErrPermissionDenied)
+	insertMomentums(z, 2)
+
+	// activate with no tss set
+	defer z.CallContract(activateEmergency(g.User5.Address)).Error(t, nil)
 	insertMomentums(z, 2)
 
 	common.Json(bridgeAPI.GetBridgeInfo()).Equals(t, `
@@ -3515,6 +4356,55 @@ func setTokenPair(t *testing.T, z mock.MockZenon, administrator types.Address, d
 	insertMomentums(z, 2)
 }
 
+func setTokenPairLimitsStep(administrator types.Address, networkClass, chainId uint32, zts types.ZenonTokenStandard, windowSize uint64, maxWrapAmount, maxRedeemAmount *big.Int) *nom.AccountBlock {
+	return &nom.AccountBlock{
+		Address:       administrator,
+		ToAddress:     types.BridgeContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data: definition.ABIBridge.PackMethodPanic(definition.SetTokenPairLimitsMethodName,
+			networkClass,
+			chainId,
+			zts,
+			windowSize,
+			maxWrapAmount,
+			maxRedeemAmount,
+		),
+	}
+}
+
+func setFeeDistributionStep(administrator types.Address, destinations []types.Address, percentages []uint32) *nom.AccountBlock {
+	return &nom.AccountBlock{
+		Address:       administrator,
+		ToAddress:     types.BridgeContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data:          definition.ABIBridge.PackMethodPanic(definition.SetFeeDistributionMethodName, destinations, percentages),
+	}
+}
+
+func setFeeDistribution(t *testing.T, z mock.MockZenon, administrator types.Address, delay uint64, destinations []types.Address, percentages []uint32) {
+	defer z.CallContract(setFeeDistributionStep(administrator, destinations, percentages)).Error(t, nil)
+	insertMomentums(z, 2)
+
+	frMom, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	z.InsertMomentumsTo(frMom.Height + delay)
+
+	defer z.CallContract(setFeeDistributionStep(administrator, destinations, percentages)).Error(t, nil)
+	insertMomentums(z, 2)
+}
+
+func distributeFees(address types.Address, zts types.ZenonTokenStandard) *nom.AccountBlock {
+	return &nom.AccountBlock{
+		Address:       address,
+		ToAddress:     types.BridgeContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data:          definition.ABIBridge.PackMethodPanic(definition.DistributeFeesMethodName, zts),
+	}
+}
+
 func createZtsOwnedByBridge(t *testing.T, z mock.MockZenon) types.ZenonTokenStandard {
 	tokenAPI := embedded.NewTokenApi(z)
 
@@ -3595,6 +4485,42 @@ func getUnwrapTokenSignature(t *testing.T, networkClass, chainId uint32, txHash
 	return signature
 }
 
+func unwrapBitcoinToken(chainId uint32, txId types.Hash, vout uint32, amount *big.Int, signature string) *nom.AccountBlock {
+	return &nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.BridgeContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data: definition.ABIBridge.PackMethodPanic(definition.UnwrapBitcoinTokenMethodName,
+			chainId,
+			txId,
+			vout,
+			g.User2.Address, // ToAddress
+			amount,
+			signature,
+		),
+	}
+}
+
+func getUnwrapBitcoinTokenSignature(t *testing.T, chainId uint32, txId types.Hash, vout uint32, amount *big.Int, useSchnorr bool) string {
+	message, err := implementation.GetBitcoinUnwrapTokenRequestMessage(&definition.UnwrapBitcoinTokenParam{
+		ChainId:   chainId,
+		TxId:      txId,
+		Vout:      vout,
+		ToAddress: g.User2.Address,
+		Amount:    amount,
+	})
+	common.FailIfErr(t, err)
+	var signature string
+	if useSchnorr {
+		signature, err = signSchnorr(message, "tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=")
+	} else {
+		signature, err = sign(message, "tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=")
+	}
+	common.FailIfErr(t, err)
+	return signature
+}
+
 func getHaltSignature(t *testing.T, z mock.MockZenon, tssNonce uint64) string {
 	message, err := implementation.GetBasicMethodMessage(definition.HaltMethodName, tssNonce, definition.NoMClass, z.Chain().ChainIdentifier())
 	common.FailIfErr(t, err)
@@ -3696,6 +4622,35 @@ func sign(hash []byte, privateKey string) (string, error) {
 	}
 }
 
+// signSchnorr returns the BIP340 signature of hash
+func signSchnorr(hash []byte, privateKey string) (string, error) {
+	bytes, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return "", err
+	}
+	key, _ := btcec.PrivKeyFromBytes(bytes)
+	sig, err := schnorr.Sign(key, hash)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig.Serialize()), nil
+}
+
+// bridgeEventsListener collects the bridge events of the inserted momentums
+type bridgeEventsListener struct {
+	t      *testing.T
+	chain  chain.Chain
+	events []*subscribe.BridgeEvent
+}
+
+func (l *bridgeEventsListener) InsertMomentum(detailed *nom.DetailedMomentum) {
+	events, err := subscribe.BridgeEvents(l.chain, detailed)
+	common.FailIfErr(l.t, err)
+	l.events = append(l.events, events...)
+}
+func (l *bridgeEventsListener) DeleteMomentum(*nom.DetailedMomentum) {
+}
+
 func insertMomentums(z mock.MockZenon, target int) {
 	for i := 0; i < target; i++ {
 		z.InsertNewMomentum()
@@ -0,0 +1,193 @@
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"runtime/debug"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+)
+
+var (
+	ErrSimulationBlockType = errors.New("only user send blocks can be simulated")
+)
+
+type BalanceDelta struct {
+	Address       types.Address
+	TokenStandard types.ZenonTokenStandard
+	Delta         *big.Int
+}
+
+// Simulation is the outcome of applying an account-block template on top of the frontier stores, without committing it.
+// For calls to embedded contracts, the contract receive-block is generated as well, as if it would be auto-received
+// in the current frontier momentum. Blocks which are already waiting to be received by the contract are not applied first.
+type Simulation struct {
+	Block          *nom.AccountBlock
+	Changes        db.Patch
+	RequiredPlasma uint64
+	SendError      error
+
+	Receive        *nom.AccountBlock
+	ReceiveChanges db.Patch
+	ReceiveStatus  uint64
+	MethodError    error
+
+	BalanceDeltas []*BalanceDelta
+}
+
+// SimulateAccountBlock fills the template like GenerateFromTemplate and applies it on snapshots of the frontier stores.
+// The template isn't signed and isn't checked by the verifier, so difficulty can be set without computing a nonce.
+// Errors of the send-block or of the embedded method are returned in the Simulation, the error is only set for
+// templates which can't be simulated at all.
+func (s *Supervisor) SimulateAccountBlock(template *nom.AccountBlock) (simulation *Simulation, internalErr error) {
+	defer func() {
+		if err := recover(); err != nil {
+			s.log.Error("vm panic when simulating block", "reason", err, "stack", string(debug.Stack()))
+
+			simulation = nil
+			internalErr = constants.ErrVmRunPanic
+		}
+	}()
+
+	if template.BlockType != nom.BlockTypeUserSend {
+		return nil, ErrSimulationBlockType
+	}
+	if err := s.setAll(template); err != nil {
+		return nil, err
+	}
+	context := s.newBlockContext(template)
+	if err := s.setBlockPlasma(context, template); err != nil {
+		return nil, err
+	}
+	requiredPlasma, err := GetBasePlasmaForAccountBlock(context, template)
+	if err != nil {
+		return nil, err
+	}
+	simulation = &Simulation{
+		Block:          template,
+		RequiredPlasma: requiredPlasma,
+	}
+
+	if err := NewVM(context).applyBlock(template); err != nil {
+		simulation.SendError = err
+		return simulation, nil
+	}
+	template.Hash = template.ComputeHash()
+	if simulation.Changes, err = context.Changes(); err != nil {
+		return nil, err
+	}
+
+	deltas := newBalanceDeltas()
+	deltas.add(template.Address, template.TokenStandard, new(big.Int).Neg(template.Amount))
+	if !types.IsEmbeddedAddress(template.ToAddress) {
+		deltas.add(template.ToAddress, template.TokenStandard, template.Amount)
+		simulation.BalanceDeltas = deltas.list()
+		return simulation, nil
+	}
+
+	receiveTemplate := &nom.AccountBlock{
+		BlockType:            nom.BlockTypeContractReceive,
+		Address:              template.ToAddress,
+		FromBlockHash:        template.Hash,
+		MomentumAcknowledged: template.MomentumAcknowledged,
+	}
+	if err := s.setBlockHH(receiveTemplate); err != nil {
+		return nil, err
+	}
+	receiveContext := s.newBlockContext(receiveTemplate)
+	receive, methodErr, err := NewVM(receiveContext).receiveEmbedded(template)
+	if err != nil {
+		return nil, err
+	}
+	// a nil amount hashes like a zero amount, set it for readability
+	s.setBlockFields(receive)
+	simulation.Receive = receive
+	simulation.ReceiveStatus = errToStatus(methodErr)
+	simulation.MethodError = methodErr
+	if simulation.ReceiveChanges, err = receiveContext.Changes(); err != nil {
+		return nil, err
+	}
+
+	if err := deltas.addContract(s.chain.GetFrontierAccountStore(template.ToAddress), receiveContext, template, receive); err != nil {
+		return nil, err
+	}
+	for _, dBlock := range receive.DescendantBlocks {
+		deltas.add(dBlock.ToAddress, dBlock.TokenStandard, dBlock.Amount)
+	}
+	simulation.BalanceDeltas = deltas.list()
+	return simulation, nil
+}
+
+type balanceDeltas map[types.Address]map[types.ZenonTokenStandard]*big.Int
+
+func newBalanceDeltas() balanceDeltas {
+	return make(balanceDeltas)
+}
+func (bd balanceDeltas) add(address types.Address, zts types.ZenonTokenStandard, amount *big.Int) {
+	if zts == types.ZeroTokenStandard || amount == nil || amount.Sign() == 0 {
+		return
+	}
+	if _, ok := bd[address]; !ok {
+		bd[address] = make(map[types.ZenonTokenStandard]*big.Int)
+	}
+	if _, ok := bd[address][zts]; !ok {
+		bd[address][zts] = big.NewInt(0)
+	}
+	bd[address][zts].Add(bd[address][zts], amount)
+}
+
+// addContract adds the balance changes of an embedded contract, which can also burn the tokens it receives
+func (bd balanceDeltas) addContract(before, after store.Account, sendBlock, receive *nom.AccountBlock) error {
+	tokens := []types.ZenonTokenStandard{sendBlock.TokenStandard}
+	for _, dBlock := range receive.DescendantBlocks {
+		tokens = append(tokens, dBlock.TokenStandard)
+	}
+	seen := make(map[types.ZenonTokenStandard]bool)
+	for _, zts := range tokens {
+		if seen[zts] || zts == types.ZeroTokenStandard {
+			continue
+		}
+		seen[zts] = true
+		previous, err := before.GetBalance(zts)
+		if err != nil {
+			return err
+		}
+		current, err := after.GetBalance(zts)
+		if err != nil {
+			return err
+		}
+		bd.add(sendBlock.ToAddress, zts, new(big.Int).Sub(current, previous))
+	}
+	return nil
+}
+
+// list returns the non-zero deltas sorted by address and token standard
+func (bd balanceDeltas) list() []*BalanceDelta {
+	result := make([]*BalanceDelta, 0)
+	for address, tokens := range bd {
+		for zts, delta := range tokens {
+			if delta.Sign() == 0 {
+				continue
+			}
+			result = append(result, &BalanceDelta{
+				Address:       address,
+				TokenStandard: zts,
+				Delta:         delta,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if c := bytes.Compare(result[i].Address.Bytes(), result[j].Address.Bytes()); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(result[i].TokenStandard.Bytes(), result[j].TokenStandard.Bytes()) < 0
+	})
+	return result
+}
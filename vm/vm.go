@@ -19,18 +19,12 @@ var (
 	log = common.VmLogger
 )
 
-const (
-	resultInvalid uint64 = iota
-	resultSuccess
-	resultFail
-)
-
 func errToStatus(err error) uint64 {
 	switch err {
 	case nil:
-		return resultSuccess
+		return constants.ReceiveResultSuccess
 	default:
-		return resultFail
+		return constants.ReceiveResultFail
 	}
 }
 
@@ -164,12 +158,18 @@ func (vm *VM) generateEmbeddedReceive(fromBlockHash types.Hash) (*nom.AccountBlo
 	if err != nil {
 		return nil, nil, err
 	}
+	return vm.receiveEmbedded(sendBlock)
+}
+
+// receiveEmbedded calls the embedded method of sendBlock and generates the receive nom.AccountBlock.
+// The send-block doesn't need to be in the momentum store, which allows simulating calls.
+func (vm *VM) receiveEmbedded(sendBlock *nom.AccountBlock) (*nom.AccountBlock, error, error) {
 	method, err := embedded.GetEmbeddedMethod(vm.context, sendBlock.ToAddress, sendBlock.Data)
 
 	// can happen when a method is deleted in a spork (height 100) and someone calls it before the spork (height 95)
 	// and the autoReceive uses momentum height 105 for various reasons
 	if err == constants.ErrContractMethodNotFound {
-		return vm.rollbackEmbedded(fromBlockHash, err)
+		return vm.rollbackEmbedded(sendBlock, err)
 	}
 
 	vm.context.Save()
@@ -178,24 +178,21 @@ func (vm *VM) generateEmbeddedReceive(fromBlockHash types.Hash) (*nom.AccountBlo
 	// call code
 	descendantBlocks, err := method.ReceiveBlock(vm.context, sendBlock)
 	if err != nil {
-		return vm.rollbackEmbedded(fromBlockHash, err)
+		return vm.rollbackEmbedded(sendBlock, err)
 	}
 	// apply send-descendant-blocks
 	for _, dblock := range descendantBlocks {
 		err := vm.applySend(dblock)
 		if err != nil {
-			return vm.rollbackEmbedded(fromBlockHash, err)
+			return vm.rollbackEmbedded(sendBlock, err)
 		}
 	}
 
 	// everything went right, no rollback required
 	vm.context.Done()
-	return vm.finalizeEmbedded(fromBlockHash, descendantBlocks, nil)
+	return vm.finalizeEmbedded(sendBlock.Hash, descendantBlocks, nil)
 }
-func (vm *VM) rollbackEmbedded(fromBlockHash types.Hash, methodErr error) (*nom.AccountBlock, error, error) {
-	sendBlock, err := vm.context.MomentumStore().GetAccountBlockByHash(fromBlockHash)
-	common.DealWithErr(err) // impossible to not find send-block at rollback
-
+func (vm *VM) rollbackEmbedded(sendBlock *nom.AccountBlock, methodErr error) (*nom.AccountBlock, error, error) {
 	vm.context.Reset()
 	// If sendBlock contains amount, add current amount to embedded to be able to refund it
 	// This operation was rollbacked with vm.context.Reset()
@@ -221,7 +218,7 @@ func (vm *VM) rollbackEmbedded(fromBlockHash types.Hash, methodErr error) (*nom.
 		descendantBlocks = append(descendantBlocks, dBlock)
 	}
 
-	return vm.finalizeEmbedded(fromBlockHash, descendantBlocks, methodErr)
+	return vm.finalizeEmbedded(sendBlock.Hash, descendantBlocks, methodErr)
 }
 func (vm *VM) finalizeEmbedded(fromBlockHash types.Hash, descendantBlocks []*nom.AccountBlock, executionError error) (*nom.AccountBlock, error, error) {
 	var err error
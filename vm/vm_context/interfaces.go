@@ -32,6 +32,8 @@ type AccountVmContext interface {
 
 	// ====== Spork ======
 
+	IsBridgeExtensionsSporkEnforced() bool
+	IsHtlcHashTypesSporkEnforced() bool
 	IsMergeMiningEnforced() bool
 	IsAcceleratorSporkEnforced() bool
 	IsHtlcSporkEnforced() bool
@@ -11,6 +11,18 @@ func (ctx *accountVmContext) IsMergeMiningEnforced() bool {
 	return active
 }
 
+func (ctx *accountVmContext) IsBridgeExtensionsSporkEnforced() bool {
+	active, err := ctx.momentumStore.IsSporkActive(types.BridgeExtensionsSpork)
+	common.DealWithErr(err)
+	return active
+}
+
+func (ctx *accountVmContext) IsHtlcHashTypesSporkEnforced() bool {
+	active, err := ctx.momentumStore.IsSporkActive(types.HtlcHashTypesSpork)
+	common.DealWithErr(err)
+	return active
+}
+
 func (ctx *accountVmContext) IsAcceleratorSporkEnforced() bool {
 	active, err := ctx.momentumStore.IsSporkActive(types.AcceleratorSpork)
 	common.DealWithErr(err)
@@ -7,6 +7,10 @@ import (
 
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/indexer"
+	"github.com/zenon-network/go-zenon/pruner"
+	"github.com/zenon-network/go-zenon/relayer"
+	"github.com/zenon-network/go-zenon/stratum"
 	"github.com/zenon-network/go-zenon/wallet"
 )
 
@@ -14,7 +18,14 @@ type Config struct {
 	MinPeers          int
 	MinConnectedPeers int
 	DataDir           string
+	Archive           bool
 	ProducingKeyPair  *wallet.KeyPair
+	RelayerKeyPair    *wallet.KeyPair
+	Relayer           *relayer.Config
+	StratumKeyPair    *wallet.KeyPair
+	Stratum           *stratum.Config
+	Indexer           *indexer.Config
+	Pruner            *pruner.Config
 	GenesisConfig     store.Genesis
 }
 
@@ -3,6 +3,7 @@ package zenon
 import (
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/pillar"
 	"github.com/zenon-network/go-zenon/protocol"
 	"github.com/zenon-network/go-zenon/verifier"
@@ -20,4 +21,6 @@ type Zenon interface {
 	Producer() pillar.Manager
 	Config() *Config
 	Broadcaster() protocol.Broadcaster
+	// Indexer is nil if no index is enabled
+	Indexer() indexer.Manager
 }
@@ -3,6 +3,7 @@ package mock
 import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/zenon"
 )
@@ -11,6 +12,10 @@ type MockZenon interface {
 	zenon.Zenon
 	StopPanic()
 
+	// ChainManager and ConsensusDB expose the databases of the node, used to test snapshots
+	ChainManager() db.Manager
+	ConsensusDB() db.DB
+
 	InsertNewMomentum()
 	InsertMomentumsTo(targetHeight uint64)
 
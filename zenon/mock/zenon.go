@@ -18,6 +18,7 @@ import (
 	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/pillar"
 	"github.com/zenon-network/go-zenon/protocol"
 	"github.com/zenon-network/go-zenon/verifier"
@@ -122,10 +123,13 @@ type mockZenon struct {
 	log              log15.Logger
 	producerLogSaver *ProducerLogSaver
 
-	pillars    []pillar.Manager
-	chain      chain.Chain
-	consensus  consensus.Consensus
-	supervisor *vm.Supervisor
+	pillars      []pillar.Manager
+	chainManager db.Manager
+	chain        chain.Chain
+	consensusDB  db.DB
+	consensus    consensus.Consensus
+	supervisor   *vm.Supervisor
+	indexer      indexer.Manager
 
 	loggers              []log15.Logger
 	handlers             []log15.Handler
@@ -297,6 +301,9 @@ func (zenon *mockZenon) Init() error {
 	for _, pillarE := range zenon.pillars {
 		common.DealWithErr(pillarE.Init())
 	}
+	if zenon.indexer != nil {
+		common.DealWithErr(zenon.indexer.Init())
+	}
 	return nil
 }
 func (zenon *mockZenon) Start() error {
@@ -305,9 +312,15 @@ func (zenon *mockZenon) Start() error {
 	for _, pillarE := range zenon.pillars {
 		common.DealWithErr(pillarE.Start())
 	}
+	if zenon.indexer != nil {
+		common.DealWithErr(zenon.indexer.Start())
+	}
 	return nil
 }
 func (zenon *mockZenon) Stop() error {
+	if zenon.indexer != nil {
+		common.DealWithErr(zenon.indexer.Stop())
+	}
 	for _, pillarE := range zenon.pillars {
 		common.DealWithErr(pillarE.Stop())
 	}
@@ -317,6 +330,7 @@ func (zenon *mockZenon) Stop() error {
 	zenon.chain = nil
 	zenon.consensus = nil
 	zenon.pillars = nil
+	zenon.indexer = nil
 
 	for i := range zenon.loggers {
 		zenon.loggers[i].SetHandler(zenon.handlers[i])
@@ -333,6 +347,12 @@ func (zenon *mockZenon) StopPanic() {
 func (zenon *mockZenon) Chain() chain.Chain {
 	return zenon.chain
 }
+func (zenon *mockZenon) ChainManager() db.Manager {
+	return zenon.chainManager
+}
+func (zenon *mockZenon) ConsensusDB() db.DB {
+	return zenon.consensusDB
+}
 func (zenon *mockZenon) Consensus() consensus.Consensus {
 	return zenon.consensus
 }
@@ -351,28 +371,38 @@ func (zenon *mockZenon) Config() *zenon.Config {
 func (zenon *mockZenon) Broadcaster() protocol.Broadcaster {
 	return zenon
 }
+func (zenon *mockZenon) Indexer() indexer.Manager {
+	return zenon.indexer
+}
 
 func NewMockZenon(t common.T) MockZenon {
-	return newMockZenon(t, consensus.EpochDuration)
+	return newMockZenon(t, consensus.EpochDuration, nil)
 }
 func NewMockZenonWithCustomEpochDuration(t common.T, epochDuration time.Duration) MockZenon {
-	return newMockZenon(t, epochDuration)
+	return newMockZenon(t, epochDuration, nil)
+}
+func NewMockZenonWithIndexer(t common.T, config *indexer.Config) MockZenon {
+	return newMockZenon(t, consensus.EpochDuration, config)
 }
 
-func newMockZenon(t common.T, customEpochDuration time.Duration) MockZenon {
+func newMockZenon(t common.T, customEpochDuration time.Duration, indexerConfig *indexer.Config) MockZenon {
 	// silence loggers
 	common.ChainLogger.SetHandler(log15.LvlFilterHandler(log15.LvlError, log15.StderrHandler))
 	common.ConsensusLogger.SetHandler(log15.LvlFilterHandler(log15.LvlError, log15.StderrHandler))
 	common.SupervisorLogger.SetHandler(log15.LvlFilterHandler(log15.LvlError, log15.StderrHandler))
 	consensus.EpochDuration = customEpochDuration
 
-	ch := chain.NewChain(db.NewLevelDBManager(t.TempDir()), genesis.NewGenesis(g.EmbeddedGenesis))
-	cs := consensus.NewConsensus(db.NewMemDB(), ch, true)
+	chainManager := db.NewLevelDBManager(t.TempDir())
+	consensusDB := db.NewMemDB()
+	ch := chain.NewChain(chainManager, genesis.NewGenesis(g.EmbeddedGenesis))
+	cs := consensus.NewConsensus(consensusDB, ch, true)
 	supervisor := vm.NewSupervisor(ch, cs)
 	zenon := &mockZenon{
 		t:                    t,
 		log:                  common.ZenonLogger,
+		chainManager:         chainManager,
 		chain:                ch,
+		consensusDB:          consensusDB,
 		consensus:            cs,
 		supervisor:           supervisor,
 		loggers:              make([]log15.Logger, len(AllLoggers)),
@@ -411,6 +441,11 @@ func newMockZenon(t common.T, customEpochDuration time.Duration) MockZenon {
 	}
 	zenon.pillars = pillars
 
+	if indexerConfig != nil {
+		_, indexerDb := db.NewLevelDB(t.TempDir())
+		zenon.indexer = indexer.NewIndexer(indexerConfig, ch, indexerDb)
+	}
+
 	zenon.Init()
 	zenon.Start()
 
@@ -5,9 +5,13 @@ import (
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/pillar"
 	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/pruner"
+	"github.com/zenon-network/go-zenon/relayer"
 	"github.com/zenon-network/go-zenon/rpc/api/subscribe"
+	"github.com/zenon-network/go-zenon/stratum"
 	"github.com/zenon-network/go-zenon/verifier"
 	"github.com/zenon-network/go-zenon/vm"
 )
@@ -20,6 +24,10 @@ type zenon struct {
 	verifier    verifier.Verifier
 	chain       chain.Chain
 	pillar      pillar.Manager
+	relayer     relayer.Manager
+	stratum     stratum.Manager
+	indexer     indexer.Manager
+	pruner      pruner.Manager
 	consensus   consensus.Consensus
 	evPrinter   EventPrinter
 	broadcaster protocol.Broadcaster
@@ -48,6 +56,19 @@ func NewZenon(cfg *Config) (Zenon, error) {
 	if cfg.ProducingKeyPair != nil {
 		z.pillar.SetCoinBase(cfg.ProducingKeyPair)
 	}
+	if cfg.Relayer != nil && cfg.RelayerKeyPair != nil {
+		z.relayer = relayer.NewRelayer(cfg.Relayer, cfg.RelayerKeyPair, z.chain, z.consensus, z.broadcaster)
+	}
+	if cfg.Stratum != nil && cfg.StratumKeyPair != nil {
+		z.stratum = stratum.NewServer(cfg.Stratum, cfg.StratumKeyPair, z.chain, z.consensus, z.broadcaster)
+	}
+	if cfg.Indexer != nil {
+		_, indexerDb := cfg.NewLevelDB("indexer")
+		z.indexer = indexer.NewIndexer(cfg.Indexer, z.chain, indexerDb)
+	}
+	if cfg.Pruner != nil {
+		z.pruner = pruner.NewPruner(cfg.Pruner, z.chain, z.indexer)
+	}
 
 	return z, nil
 }
@@ -69,6 +90,26 @@ func (z *zenon) Init() error {
 	if err := z.pillar.Init(); err != nil {
 		return err
 	}
+	if z.relayer != nil {
+		if err := z.relayer.Init(); err != nil {
+			return err
+		}
+	}
+	if z.stratum != nil {
+		if err := z.stratum.Init(); err != nil {
+			return err
+		}
+	}
+	if z.indexer != nil {
+		if err := z.indexer.Init(); err != nil {
+			return err
+		}
+	}
+	if z.pruner != nil {
+		if err := z.pruner.Init(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -88,12 +129,52 @@ func (z *zenon) Start() error {
 	if err := z.pillar.Start(); err != nil {
 		return err
 	}
+	if z.relayer != nil {
+		if err := z.relayer.Start(); err != nil {
+			return err
+		}
+	}
+	if z.stratum != nil {
+		if err := z.stratum.Start(); err != nil {
+			return err
+		}
+	}
+	if z.indexer != nil {
+		if err := z.indexer.Start(); err != nil {
+			return err
+		}
+	}
+	if z.pruner != nil {
+		if err := z.pruner.Start(); err != nil {
+			return err
+		}
+	}
 	z.protocol.Start()
 
 	return nil
 }
 func (z *zenon) Stop() error {
 	z.protocol.Stop()
+	if z.pruner != nil {
+		if err := z.pruner.Stop(); err != nil {
+			return err
+		}
+	}
+	if z.indexer != nil {
+		if err := z.indexer.Stop(); err != nil {
+			return err
+		}
+	}
+	if z.stratum != nil {
+		if err := z.stratum.Stop(); err != nil {
+			return err
+		}
+	}
+	if z.relayer != nil {
+		if err := z.relayer.Stop(); err != nil {
+			return err
+		}
+	}
 	if err := z.pillar.Stop(); err != nil {
 		return err
 	}
@@ -137,3 +218,6 @@ func (z *zenon) Config() *Config {
 func (z *zenon) Broadcaster() protocol.Broadcaster {
 	return z.broadcaster
 }
+func (z *zenon) Indexer() indexer.Manager {
+	return z.indexer
+}